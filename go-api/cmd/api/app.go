@@ -14,6 +14,9 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"github.com/rusgainew/tunduck-app/internal/conf"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/scheduler"
+	"github.com/rusgainew/tunduck-app/internal/services"
 	"github.com/rusgainew/tunduck-app/internal/services/service_impl"
 	"github.com/rusgainew/tunduck-app/pkg/container"
 	"github.com/rusgainew/tunduck-app/pkg/entity"
@@ -92,13 +95,20 @@ func NewApp(ctx context.Context, envPath string) (*App, error) {
 	}
 
 	// Выполняем миграции БД
-	if err := app.db.AutoMigrate(&entity.User{}, &entity.EstOrganization{}); err != nil {
+	if err := app.db.AutoMigrate(&entity.User{}, &entity.EstOrganization{}, &entity.EsfReferenceVersion{}, &entity.EsfReferenceItem{}, &entity.CurrencyRate{}); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 	app.logger.Info("Database migrations completed successfully")
 
+	// Загружаем встроенные справочники кодов ЭСФ
+	referenceService := service_impl.NewEsfReferenceService(repositorypostgres.NewEsfReferenceRepositoryPostgres(app.db, app.logger), app.logger)
+	if err := referenceService.SeedBundled(ctx); err != nil {
+		return nil, fmt.Errorf("failed to seed reference catalogs: %w", err)
+	}
+
 	// Создаем Fiber приложение
-	app.fiber = fiber.New()
+	// Лимит тела запроса учитывает загрузку вложений документов
+	app.fiber = fiber.New(fiber.Config{BodyLimit: service_impl.MaxAttachmentSize + 1024*1024})
 
 	// Инициализируем Prometheus метрики
 	app.metrics = metrics.NewMetrics()
@@ -163,6 +173,9 @@ func NewApp(ctx context.Context, envPath string) (*App, error) {
 	// Регистрируем все handlers с контейнером зависимостей
 	RegisterHandlers(app.fiber, app.container, organizationDBService)
 
+	// Запускаем выпуск повторяющихся документов по шаблонам
+	app.startRecurringScheduler(referenceService)
+
 	// Регистрируем Prometheus metrics endpoint в правильном формате
 	// Prometheus scraper ожидает текстовый формат по пути /metrics
 	metricsHandler := promhttp.Handler()
@@ -288,6 +301,35 @@ func (a *App) Shutdown() error {
 	return nil
 }
 
+// startRecurringScheduler запускает планировщик повторяющихся документов.
+// Интервал задается RECURRING_SCHEDULER_INTERVAL (например, 30m); значение off отключает планировщик.
+func (a *App) startRecurringScheduler(references services.EsfReferenceService) {
+	setting := a.conf.GetConValue("RECURRING_SCHEDULER_INTERVAL")
+	if setting == "off" {
+		a.logger.Info("Recurring document scheduler is disabled")
+		return
+	}
+
+	var interval time.Duration
+	if setting != "" {
+		parsed, err := time.ParseDuration(setting)
+		if err != nil {
+			a.logger.WithError(err).Warnf("Invalid RECURRING_SCHEDULER_INTERVAL %q, using default %s", setting, scheduler.DefaultRecurringInterval)
+		} else {
+			interval = parsed
+		}
+	}
+
+	documentService := service_impl.NewEsfDocumentService(repositorypostgres.NewEsfDocumentRepositoryPostgres(a.db, a.logger), a.db, a.logger)
+	documentService.SetReferenceService(references)
+	documentService.SetCurrencyRates(service_impl.NewCurrencyRateService(repositorypostgres.NewCurrencyRateRepositoryPostgres(a.db, a.logger), a.logger))
+	documentService.SetContractorRepository(repositorypostgres.NewEsfContractorRepositoryPostgres(a.db, a.logger))
+
+	recurringService := service_impl.NewEsfRecurringService(repositorypostgres.NewEsfRecurringRepositoryPostgres(a.db, a.logger), documentService, a.logger)
+
+	scheduler.NewRecurringScheduler(recurringService, repositorypostgres.NewEsfOrganizationRepositoryPostgres(a.db, a.logger), interval, a.logger).Start(a.ctx)
+}
+
 // warmCache предварительно загружает часто используемые данные в кеш
 func (a *App) warmCache() {
 	if a.container == nil || a.container.GetCacheManager() == nil {
@@ -17,8 +17,18 @@ func RegisterHandlers(app *fiber.App, cnt *container.Container, orgDBService ser
 	// Инициализируем контроллеры с зависимостями из контейнера
 	// Передаем сервисы из контейнера вместо их создания в контроллерах
 	controllers.NewAuthController(app, cnt.GetUserService(), logger, cnt.GetCacheManager())
-	controllers.NewEsfDocumentController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfDocumentController(app, cnt.GetLogrus(), cnt.GetDatabase(), cnt.GetCacheManager())
+	controllers.NewEsfContractorController(app, cnt.GetLogrus(), cnt.GetDatabase(), cnt.GetCacheManager())
+	controllers.NewEsfNumberingController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfSigningKeyController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfApprovalPolicyController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfCommentController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfAttachmentController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfReportController(app, cnt.GetLogrus(), cnt.GetDatabase(), cnt.GetCacheManager())
+	controllers.NewEsfRecurringController(app, cnt.GetLogrus(), cnt.GetDatabase(), cnt.GetCacheManager())
 	controllers.NewEsfOrganizationController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewEsfReferenceController(app, cnt.GetLogrus(), cnt.GetDatabase())
+	controllers.NewCurrencyRateController(app, cnt.GetLogrus(), cnt.GetDatabase())
 	controllers.NewUserController(app, cnt.GetLogrus(), cnt.GetDatabase())
 
 	// Применяем Rate Limiting для публичных endpoints (регистрация, логин)
@@ -0,0 +1,103 @@
+// Команда esfsign создает ключи подписи и подписывает документы ЭСФ на стороне клиента.
+// Закрытый ключ не покидает компьютер подписанта; в организации регистрируется только открытый ключ
+// (POST /api/esf-signing-keys).
+//
+// Создание пары ключей (key.pem - закрытый, key.pub.pem - открытый):
+//
+//	go run ./cmd/esfsign -keygen -algorithm ed25519 -out key
+//
+// Подпись представления документа из GET /api/esf-documents/{id}/signing-payload
+// (файл с полем payload как есть или ответ API целиком; "-" - стандартный ввод):
+//
+//	go run ./cmd/esfsign -key key.pem -payload payload.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/rusgainew/tunduck-app/pkg/signature"
+)
+
+func main() {
+	keygen := flag.Bool("keygen", false, "создать пару ключей")
+	algorithm := flag.String("algorithm", string(signature.Ed25519), "алгоритм для -keygen: ed25519 или ecdsa-p256")
+	out := flag.String("out", "key", "префикс файлов ключей для -keygen")
+	keyFile := flag.String("key", "", "закрытый ключ в PEM для подписи")
+	payloadFile := flag.String("payload", "", "файл с подписываемым представлением документа")
+	flag.Parse()
+
+	if *keygen {
+		generate(signature.Algorithm(*algorithm), *out)
+		return
+	}
+	if *keyFile == "" || *payloadFile == "" {
+		log.Fatal("Укажите -keygen либо -key и -payload")
+	}
+	sign(*keyFile, *payloadFile)
+}
+
+func generate(algorithm signature.Algorithm, out string) {
+	privatePEM, publicPEM, err := signature.GenerateKey(algorithm)
+	if err != nil {
+		log.Fatalf("Ошибка создания ключей: %v", err)
+	}
+	if err := os.WriteFile(out+".pem", privatePEM, 0o600); err != nil {
+		log.Fatalf("Ошибка записи закрытого ключа: %v", err)
+	}
+	if err := os.WriteFile(out+".pub.pem", publicPEM, 0o644); err != nil {
+		log.Fatalf("Ошибка записи открытого ключа: %v", err)
+	}
+
+	pub, err := signature.ParsePublicKey(string(publicPEM))
+	if err != nil {
+		log.Fatalf("Ошибка чтения открытого ключа: %v", err)
+	}
+	log.Printf("Ключи созданы: %s.pem, %s.pub.pem (отпечаток %s)", out, out, pub.Fingerprint)
+}
+
+func sign(keyFile, payloadFile string) {
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		log.Fatalf("Ошибка чтения ключа: %v", err)
+	}
+	key, err := signature.ParsePrivateKey(keyData)
+	if err != nil {
+		log.Fatalf("Ошибка чтения ключа: %v", err)
+	}
+
+	var data []byte
+	if payloadFile == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(payloadFile)
+	}
+	if err != nil {
+		log.Fatalf("Ошибка чтения представления документа: %v", err)
+	}
+	payload := extractPayload(data)
+
+	sig, err := signature.Sign(key, payload)
+	if err != nil {
+		log.Fatalf("Ошибка подписи: %v", err)
+	}
+	log.Printf("Подписано представление с digest %s", signature.Digest(payload))
+	fmt.Println(signature.EncodeSignature(sig))
+}
+
+// extractPayload достает поле payload из ответа API; иначе файл подписывается как есть
+func extractPayload(data []byte) []byte {
+	var response struct {
+		Data struct {
+			Payload string `json:"payload"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err == nil && response.Data.Payload != "" {
+		return []byte(response.Data.Payload)
+	}
+	return data
+}
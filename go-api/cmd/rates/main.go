@@ -0,0 +1,87 @@
+// Команда rates загружает официальные курсы валют в основную БД.
+//
+// Загрузка файла (json, csv или xlsx; формат по расширению или -format):
+//
+//	go run ./cmd/rates -file rates.csv
+//
+// Загрузка курсов на дату из источника, заданного CURRENCY_RATES_FILE:
+//
+//	go run ./cmd/rates -fetch -date 2024-01-15
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rusgainew/tunduck-app/internal/conf"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+func main() {
+	envPath := flag.String("env", ".env", "путь к файлу с переменными окружения")
+	file := flag.String("file", "", "файл курсов для загрузки")
+	format := flag.String("format", "", "формат файла: json, csv или xlsx (по умолчанию по расширению)")
+	fetch := flag.Bool("fetch", false, "загрузить курсы из источника CURRENCY_RATES_FILE")
+	date := flag.String("date", "", "дата курсов для -fetch в формате YYYY-MM-DD (по умолчанию сегодня)")
+	flag.Parse()
+
+	if (*file == "") == !*fetch {
+		log.Fatal("Укажите либо -file, либо -fetch")
+	}
+
+	logger := logrus.New()
+	db := conf.NewConf(logger, *envPath).DBConnect()
+	if err := db.AutoMigrate(&entity.CurrencyRate{}); err != nil {
+		log.Fatalf("Ошибка миграции таблицы курсов: %v", err)
+	}
+
+	service := service_impl.NewCurrencyRateService(repositorypostgres.NewCurrencyRateRepositoryPostgres(db, logger), logger)
+	ctx := context.Background()
+
+	var result *models.CurrencyRateImportResponse
+	if *fetch {
+		fetcher, name := currency.NewFetcherFromEnv()
+		if fetcher == nil {
+			log.Fatal("Источник курсов не настроен (CURRENCY_RATES_FILE)")
+		}
+		service.SetFetcher(fetcher, name)
+
+		day := time.Now()
+		if *date != "" {
+			parsed, err := time.Parse(currency.DateLayout, *date)
+			if err != nil {
+				log.Fatalf("Неверная дата %q: ожидается YYYY-MM-DD", *date)
+			}
+			day = parsed
+		}
+
+		var err error
+		if result, err = service.FetchRates(ctx, day); err != nil {
+			log.Fatalf("Ошибка загрузки курсов: %v", err)
+		}
+	} else {
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			log.Fatalf("Ошибка чтения файла: %v", err)
+		}
+		if *format == "" {
+			*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		}
+		if result, err = service.ImportRates(ctx, data, *format, "file:"+filepath.Base(*file)); err != nil {
+			log.Fatalf("Ошибка загрузки курсов: %v", err)
+		}
+	}
+
+	log.Printf("Загружено курсов: %d, валюты: %s", result.Imported, strings.Join(result.Currencies, ", "))
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+)
+
+// defaultRatesPeriod период выдачи курсов, если он не указан
+const defaultRatesPeriod = 30
+
+// CurrencyRateController выдает и загружает официальные курсы валют
+type CurrencyRateController struct {
+	logger      *logger.Logger
+	service     services.CurrencyRateService
+	roleService services.RoleService
+}
+
+func NewCurrencyRateController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	service := serviceimpl.NewCurrencyRateService(repositorypostgres.NewCurrencyRateRepositoryPostgres(db, log), log)
+	if fetcher, name := currency.NewFetcherFromEnv(); fetcher != nil {
+		service.SetFetcher(fetcher, name)
+	}
+
+	controller := &CurrencyRateController{
+		logger:      logger.New(log),
+		service:     service,
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "CurrencyRateController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *CurrencyRateController) registerRoutes(app *fiber.App) {
+	rateGroup := app.Group("/api/currency-rates")
+
+	// Публичные routes (без JWT)
+	rateGroup.Get("/", c.getCurrencyRates)
+	rateGroup.Get("/:currency", c.getCurrencyRate)
+
+	// Protected routes: курсы общие для всех организаций, загружает их только администратор
+	protected := rateGroup.Group("")
+	protected.Use(middleware.JWTMiddleware(), c.loadUserContext, rbac.RequirePermission(rbac.PermissionManageCurrencyRates))
+	protected.Post("/import", c.importCurrencyRates)
+	protected.Post("/fetch", c.fetchCurrencyRates)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *CurrencyRateController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getCurrencyRates возвращает курсы за период ?from=&to= (по умолчанию последние 30 дней), ?currency= фильтрует валюту
+func (c *CurrencyRateController) getCurrencyRates(ctx *fiber.Ctx) error {
+	to, err := queryDate(ctx, "to", time.Now())
+	if err != nil {
+		return c.respondError(ctx, err, "invalid period")
+	}
+	from, err := queryDate(ctx, "from", to.AddDate(0, 0, -defaultRatesPeriod))
+	if err != nil {
+		return c.respondError(ctx, err, "invalid period")
+	}
+
+	rates, err := c.service.GetRates(ctx.Context(), ctx.Query("currency"), from, to)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch currency rates")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rates,
+		"message": "Currency rates retrieved successfully",
+	})
+}
+
+// getCurrencyRate возвращает курс валюты, действующий на дату ?date= (по умолчанию сегодня)
+func (c *CurrencyRateController) getCurrencyRate(ctx *fiber.Ctx) error {
+	date, err := queryDate(ctx, "date", time.Now())
+	if err != nil {
+		return c.respondError(ctx, err, "invalid date")
+	}
+
+	rate, err := c.service.RateOn(ctx.Context(), ctx.Params("currency"), date)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch currency rate")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    rate,
+		"message": "Currency rate retrieved successfully",
+	})
+}
+
+// importCurrencyRates загружает файл курсов: multipart в поле file или тело запроса.
+// Формат (json, csv, xlsx) берется из ?format= или расширения файла.
+func (c *CurrencyRateController) importCurrencyRates(ctx *fiber.Ctx) error {
+	content, err := uploadedContent(ctx, "file")
+	if err != nil || len(content) == 0 {
+		appErr := apperror.New(apperror.ErrInvalidRequest, "rates file is required")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	format := ctx.Query("format", ctx.FormValue("format"))
+	source := "upload"
+	if file, err := ctx.FormFile("file"); err == nil {
+		source = "upload:" + file.Filename
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		}
+	}
+	if format == "" {
+		format = currency.FormatJSON
+	}
+
+	result, err := c.service.ImportRates(ctx.Context(), content, format, source)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to import currency rates")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Currency rates imported successfully",
+	})
+}
+
+// fetchCurrencyRates загружает курсы на дату ?date= (по умолчанию сегодня) из настроенного источника
+func (c *CurrencyRateController) fetchCurrencyRates(ctx *fiber.Ctx) error {
+	date, err := queryDate(ctx, "date", time.Now())
+	if err != nil {
+		return c.respondError(ctx, err, "invalid date")
+	}
+
+	result, err := c.service.FetchRates(ctx.Context(), date)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch currency rates")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Currency rates fetched successfully",
+	})
+}
+
+func (c *CurrencyRateController) respondError(ctx *fiber.Ctx, err error, message string) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"currency": ctx.Params("currency")})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
+
+// queryDate разбирает дату из query параметра в формате YYYY-MM-DD
+func queryDate(ctx *fiber.Ctx, name string, fallback time.Time) (time.Time, error) {
+	raw := ctx.Query(name)
+	if raw == "" {
+		return time.Date(fallback.Year(), fallback.Month(), fallback.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	date, err := time.Parse(currency.DateLayout, raw)
+	if err != nil {
+		return time.Time{}, apperror.NewWithDetails(apperror.ErrInvalidRequest, "invalid "+name+" date", "expected format YYYY-MM-DD")
+	}
+	return date, nil
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// getEsfDocumentApproval возвращает итог согласования документа и решения согласующих
+func (c *EsfDocumentController) getEsfDocumentApproval(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetDocumentApproval(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch document approval", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document approval retrieved successfully",
+	})
+}
+
+// decideEsfDocumentApproval создает обработчик решения согласующего
+func (c *EsfDocumentController) decideEsfDocumentApproval(decision entity.ApprovalDecision) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		orgID, docID, appErr := c.resolveDocumentParams(ctx)
+		if appErr != nil {
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+
+		// Тело запроса необязательно: причина нужна только для отклонения
+		var req models.EsfApprovalDecisionRequest
+		if len(ctx.Body()) > 0 {
+			if err := ctx.BodyParser(&req); err != nil {
+				c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+				appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+				return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+			}
+		}
+
+		result, err := c.service.DecideDocumentApproval(actorContext(ctx), orgID, docID, decision, &req)
+		if err != nil {
+			return c.respondError(ctx, err, "failed to record approval decision", orgID, docID)
+		}
+
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data":    result,
+			"message": "Approval decision recorded successfully",
+		})
+	}
+}
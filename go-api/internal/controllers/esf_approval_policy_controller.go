@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EsfApprovalPolicyController управляет политикой согласования документов организации.
+// Решения согласующих по документам обрабатывает EsfDocumentController.
+type EsfApprovalPolicyController struct {
+	logger      *logger.Logger
+	service     services.EsfApprovalPolicyService
+	roleService services.RoleService
+}
+
+func NewEsfApprovalPolicyController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	controller := &EsfApprovalPolicyController{
+		logger:      logger.New(log),
+		service:     serviceimpl.NewEsfApprovalPolicyService(repositorypostgres.NewEsfApprovalPolicyRepositoryPostgres(db, log), log),
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfApprovalPolicyController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfApprovalPolicyController) registerRoutes(app *fiber.App) {
+	policyGroup := app.Group("/api/esf-approval-policy")
+
+	// Public routes
+	policyGroup.Get("/", c.getApprovalPolicy)
+
+	// Protected routes: политику меняет пользователь с правом изменения организации
+	protected := policyGroup.Group("")
+	protected.Use(middleware.JWTMiddleware(), c.loadUserContext, rbac.RequirePermission(rbac.PermissionUpdateOrganization))
+	protected.Put("/", c.updateApprovalPolicy)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfApprovalPolicyController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getApprovalPolicy возвращает политику согласования организации
+func (c *EsfApprovalPolicyController) getApprovalPolicy(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetApprovalPolicy(ctx.Context(), orgID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch approval policy", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Approval policy retrieved successfully",
+	})
+}
+
+// updateApprovalPolicy изменяет порог суммы, виды операции и число согласований
+func (c *EsfApprovalPolicyController) updateApprovalPolicy(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfApprovalPolicyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.UpdateApprovalPolicy(actorContext(ctx), orgID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to update approval policy", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Approval policy updated successfully",
+	})
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfApprovalPolicyController) respondError(ctx *fiber.Ctx, err error, message string, orgID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String()})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
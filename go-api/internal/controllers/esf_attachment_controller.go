@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/blobstore"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// checksumHeader заголовок с SHA-256 содержимого вложения (шестнадцатеричный)
+const checksumHeader = "X-Checksum-SHA256"
+
+// EsfAttachmentController прикладывает к документам файлы (договоры, сканы) и выдает их.
+// Содержимое хранится вне БД в хранилище, выбранном переменными окружения.
+type EsfAttachmentController struct {
+	logger      *logger.Logger
+	service     services.EsfAttachmentService
+	roleService services.RoleService
+}
+
+func NewEsfAttachmentController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	controller := &EsfAttachmentController{
+		logger: logger.New(log),
+		service: serviceimpl.NewEsfAttachmentService(
+			repositorypostgres.NewEsfAttachmentRepositoryPostgres(db, log),
+			repositorypostgres.NewEsfDocumentRepositoryPostgres(db, log),
+			log,
+		),
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	if store, name, err := blobstore.NewFromEnv(); err != nil {
+		controller.logger.Warn(context.Background(), "Document attachments are disabled", logrus.Fields{"error": err.Error()})
+	} else {
+		controller.service.SetBlobStore(store)
+		controller.logger.Info(context.Background(), "Document attachments storage configured", logrus.Fields{"storage": name})
+	}
+
+	controller.logger.Info(context.Background(), "EsfAttachmentController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+// registerRoutes регистрирует маршруты вложений.
+// Вложения документа доступны только пользователям с правом чтения документов.
+func (c *EsfAttachmentController) registerRoutes(app *fiber.App) {
+	attachmentGroup := app.Group("/api/esf-documents/:id/attachments")
+	attachmentGroup.Use(middleware.JWTMiddleware(), c.loadUserContext)
+
+	canRead := rbac.RequirePermission(rbac.PermissionReadDocument)
+	canUpdate := rbac.RequirePermission(rbac.PermissionUpdateDocument)
+	attachmentGroup.Get("/", canRead, c.getEsfDocumentAttachments)
+	attachmentGroup.Get("/:attachmentId", canRead, c.downloadEsfDocumentAttachment)
+	attachmentGroup.Post("/", canUpdate, c.uploadEsfDocumentAttachment)
+	attachmentGroup.Delete("/:attachmentId", canUpdate, c.deleteEsfDocumentAttachment)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfAttachmentController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getEsfDocumentAttachments возвращает вложения документа
+func (c *EsfAttachmentController) getEsfDocumentAttachments(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetAttachments(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch attachments", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Attachments retrieved successfully",
+	})
+}
+
+// uploadEsfDocumentAttachment прикладывает файл к документу.
+// Файл передается multipart в поле file; ожидаемый SHA-256 - в заголовке X-Checksum-SHA256 или поле checksum.
+func (c *EsfAttachmentController) uploadEsfDocumentAttachment(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Attachment file is missing", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "file is required (multipart field file)")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+	content, err := file.Open()
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to open uploaded file", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+	defer content.Close()
+
+	checksum := ctx.Get(checksumHeader)
+	if checksum == "" {
+		checksum = ctx.FormValue("checksum")
+	}
+
+	result, err := c.service.UploadAttachment(actorContext(ctx), orgID, docID, &models.EsfAttachmentUpload{
+		FileName: file.Filename,
+		Content:  content,
+		Checksum: checksum,
+	})
+	if err != nil {
+		return c.respondError(ctx, err, "failed to upload attachment", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Attachment uploaded successfully",
+	})
+}
+
+// downloadEsfDocumentAttachment отдает содержимое вложения; ?inline=true открывает файл в браузере
+func (c *EsfAttachmentController) downloadEsfDocumentAttachment(ctx *fiber.Ctx) error {
+	orgID, docID, attachmentID, appErr := c.resolveAttachmentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	attachment, content, err := c.service.GetAttachmentContent(ctx.Context(), orgID, docID, attachmentID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to download attachment", orgID, docID)
+	}
+
+	disposition := "attachment"
+	if ctx.QueryBool("inline") {
+		disposition = "inline"
+	}
+	ctx.Set(fiber.HeaderContentType, attachment.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, contentDisposition(disposition, attachment.FileName))
+	ctx.Set(checksumHeader, attachment.Checksum)
+	ctx.Set(fiber.HeaderETag, `"`+attachment.Checksum+`"`)
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	return ctx.Status(http.StatusOK).Send(content)
+}
+
+// deleteEsfDocumentAttachment удаляет вложение документа
+func (c *EsfAttachmentController) deleteEsfDocumentAttachment(ctx *fiber.Ctx) error {
+	orgID, docID, attachmentID, appErr := c.resolveAttachmentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	if err := c.service.DeleteAttachment(actorContext(ctx), orgID, docID, attachmentID); err != nil {
+		return c.respondError(ctx, err, "failed to delete attachment", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// resolveAttachmentParams достает идентификаторы организации, документа и вложения из запроса
+func (c *EsfAttachmentController) resolveAttachmentParams(ctx *fiber.Ctx) (uuid.UUID, uuid.UUID, uuid.UUID, *apperror.AppError) {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return uuid.Nil, uuid.Nil, uuid.Nil, appErr
+	}
+
+	id := ctx.Params("attachmentId")
+	attachmentID, err := uuid.Parse(id)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		return uuid.Nil, uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid attachment ID format")
+	}
+
+	return orgID, docID, attachmentID, nil
+}
+
+// contentDisposition формирует заголовок с именем файла: ASCII-вариантом для старых клиентов
+// и filename* в UTF-8 для кириллических имен (RFC 6266)
+func contentDisposition(disposition, fileName string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, fileName)
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback, url.PathEscape(fileName))
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfAttachmentController) respondError(ctx *fiber.Ctx, err error, message string, orgID, docID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String(), "attachment_id": ctx.Params("attachmentId")})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EsfCommentController ведет ветки комментариев к документам.
+// Читать и писать комментарии может любой пользователь с правом чтения документов.
+type EsfCommentController struct {
+	logger      *logger.Logger
+	service     services.EsfCommentService
+	roleService services.RoleService
+}
+
+func NewEsfCommentController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	userRepo := repositorypostgres.NewUserRepositoryPostgres(db, log)
+	controller := &EsfCommentController{
+		logger: logger.New(log),
+		service: serviceimpl.NewEsfCommentService(
+			repositorypostgres.NewEsfCommentRepositoryPostgres(db, log),
+			repositorypostgres.NewEsfDocumentRepositoryPostgres(db, log),
+			userRepo,
+			log,
+		),
+		roleService: serviceimpl.NewRoleService(userRepo, log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfCommentController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfCommentController) registerRoutes(app *fiber.App) {
+	commentGroup := app.Group("/api/esf-documents/:id/comments")
+	commentGroup.Use(middleware.JWTMiddleware(), c.loadUserContext, rbac.RequirePermission(rbac.PermissionReadDocument))
+
+	commentGroup.Get("/", c.getEsfDocumentComments)
+	commentGroup.Post("/", c.createEsfDocumentComment)
+	commentGroup.Put("/:commentId", c.updateEsfDocumentComment)
+	commentGroup.Delete("/:commentId", c.deleteEsfDocumentComment)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfCommentController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getEsfDocumentComments возвращает комментарии документа
+func (c *EsfCommentController) getEsfDocumentComments(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetComments(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch comments", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Comments retrieved successfully",
+	})
+}
+
+// createEsfDocumentComment добавляет комментарий или ответ (parentId) к документу
+func (c *EsfCommentController) createEsfDocumentComment(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfCommentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.CreateComment(actorContext(ctx), orgID, docID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to create comment", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Comment created successfully",
+	})
+}
+
+// updateEsfDocumentComment изменяет текст комментария
+func (c *EsfCommentController) updateEsfDocumentComment(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+	commentID, appErr := c.resolveCommentID(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfCommentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.UpdateComment(actorContext(ctx), orgID, docID, commentID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to update comment", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Comment updated successfully",
+	})
+}
+
+// deleteEsfDocumentComment удаляет комментарий
+func (c *EsfCommentController) deleteEsfDocumentComment(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := resolveDocumentParams(ctx, c.logger)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+	commentID, appErr := c.resolveCommentID(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	if err := c.service.DeleteComment(actorContext(ctx), orgID, docID, commentID); err != nil {
+		return c.respondError(ctx, err, "failed to delete comment", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Comment deleted successfully",
+	})
+}
+
+func (c *EsfCommentController) resolveCommentID(ctx *fiber.Ctx) (uuid.UUID, *apperror.AppError) {
+	commentID, err := uuid.Parse(ctx.Params("commentId"))
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid comment ID format", logrus.Fields{"comment_id": ctx.Params("commentId")})
+		return uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid comment ID format")
+	}
+	return commentID, nil
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfCommentController) respondError(ctx *fiber.Ctx, err error, message string, orgID, docID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String(), "comment_id": ctx.Params("commentId")})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EsfContractorController ведет справочник контрагентов организации
+type EsfContractorController struct {
+	logger      *logger.Logger
+	service     services.EsfContractorService
+	roleService services.RoleService
+}
+
+func NewEsfContractorController(app *fiber.App, log *logrus.Logger, db *gorm.DB, cacheManager cache.CacheManager) {
+	service := serviceimpl.NewEsfContractorService(repositorypostgres.NewEsfContractorRepositoryPostgres(db, log), log)
+	if cacheManager != nil {
+		service.SetCacheManager(cacheManager)
+	}
+
+	controller := &EsfContractorController{
+		logger:      logger.New(log),
+		service:     service,
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfContractorController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfContractorController) registerRoutes(app *fiber.App) {
+	contractorGroup := app.Group("/api/esf-contractors")
+
+	// Public routes
+	contractorGroup.Get("/", c.getContractors)
+	contractorGroup.Get("/:id", c.getContractor)
+
+	// Protected routes
+	protected := contractorGroup.Group("")
+	protected.Use(middleware.JWTMiddleware(), c.loadUserContext)
+
+	// Справочник ведут исполнители с правами на документы: контрагент подставляется в документы
+	canCreate := rbac.RequirePermission(rbac.PermissionCreateDocument)
+	canUpdate := rbac.RequirePermission(rbac.PermissionUpdateDocument)
+	protected.Post("/", canCreate, c.createContractor)
+	protected.Put("/:id", canUpdate, c.updateContractor)
+	protected.Delete("/:id", canUpdate, c.deleteContractor)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfContractorController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getContractors возвращает контрагентов организации; ?q= ищет по наименованию и ИНН
+func (c *EsfContractorController) getContractors(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetContractors(ctx.Context(), orgID, ctx.Query("q"))
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch contractors", orgID, uuid.Nil)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Contractors retrieved successfully",
+	})
+}
+
+// getContractor возвращает контрагента по идентификатору
+func (c *EsfContractorController) getContractor(ctx *fiber.Ctx) error {
+	orgID, contractorID, appErr := c.resolveContractorParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetContractor(ctx.Context(), orgID, contractorID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch contractor", orgID, contractorID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Contractor retrieved successfully",
+	})
+}
+
+// createContractor добавляет контрагента в справочник
+func (c *EsfContractorController) createContractor(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfContractorModel
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.CreateContractor(actorContext(ctx), orgID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to create contractor", orgID, uuid.Nil)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Contractor created successfully",
+	})
+}
+
+// updateContractor изменяет контрагента; реквизиты обновляются и в черновиках, которые на него ссылаются
+func (c *EsfContractorController) updateContractor(ctx *fiber.Ctx) error {
+	orgID, contractorID, appErr := c.resolveContractorParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfContractorModel
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.UpdateContractor(actorContext(ctx), orgID, contractorID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to update contractor", orgID, contractorID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Contractor updated successfully",
+	})
+}
+
+// deleteContractor удаляет контрагента из справочника
+func (c *EsfContractorController) deleteContractor(ctx *fiber.Ctx) error {
+	orgID, contractorID, appErr := c.resolveContractorParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	if err := c.service.DeleteContractor(actorContext(ctx), orgID, contractorID); err != nil {
+		return c.respondError(ctx, err, "failed to delete contractor", orgID, contractorID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Contractor deleted successfully",
+	})
+}
+
+// resolveContractorParams достает идентификаторы организации и контрагента из запроса
+func (c *EsfContractorController) resolveContractorParams(ctx *fiber.Ctx) (uuid.UUID, uuid.UUID, *apperror.AppError) {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		return uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+	}
+
+	id := ctx.Params("id")
+	contractorID, err := uuid.Parse(id)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		return uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid contractor ID format")
+	}
+
+	return orgID, contractorID, nil
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfContractorController) respondError(ctx *fiber.Ctx, err error, message string, orgID, contractorID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String(), "contractor_id": contractorID.String()})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
@@ -1,41 +1,75 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/gateway"
 	"github.com/rusgainew/tunduck-app/internal/models"
 	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
 	"github.com/rusgainew/tunduck-app/internal/services"
 	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
 	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/esfxml"
 	"github.com/rusgainew/tunduck-app/pkg/logger"
 	"github.com/rusgainew/tunduck-app/pkg/middleware"
 	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/pdf"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type EsfDocumentController struct {
-	logger  *logger.Logger
-	service services.EsfDocumentService
-	db      *gorm.DB
+	logger      *logger.Logger
+	service     services.EsfDocumentService
+	roleService services.RoleService
+	db          *gorm.DB
 }
 
-func NewEsfDocumentController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+func NewEsfDocumentController(app *fiber.App, log *logrus.Logger, db *gorm.DB, cacheManager cache.CacheManager) {
 	// Инициализируем слои
 	repo := repositorypostgres.NewEsfDocumentRepositoryPostgres(db, log)
 	service := serviceimpl.NewEsfDocumentService(repo, db, log)
+	if cacheManager != nil {
+		service.SetCacheManager(cacheManager)
+	}
+	service.SetReferenceService(serviceimpl.NewEsfReferenceService(repositorypostgres.NewEsfReferenceRepositoryPostgres(db, log), log))
+	service.SetCurrencyRates(serviceimpl.NewCurrencyRateService(repositorypostgres.NewCurrencyRateRepositoryPostgres(db, log), log))
+	orgRepo := repositorypostgres.NewEsfOrganizationRepositoryPostgres(db, log)
+	service.SetOrganizationRepository(orgRepo)
+	service.SetContractorRepository(repositorypostgres.NewEsfContractorRepositoryPostgres(db, log))
+	service.SetSigningKeyRepository(repositorypostgres.NewEsfSigningKeyRepositoryPostgres(db, log))
+	service.SetApprovalPolicyRepository(repositorypostgres.NewEsfApprovalPolicyRepositoryPostgres(db, log))
+	if gw := gateway.NewEsfGatewayFromEnv(log); gw != nil {
+		service.SetSubmissionGateway(gw)
+	}
 
 	l := logger.New(log)
 
+	// PDF формируется без внешних программ, но требует TTF шрифтов с кириллицей
+	if renderer, err := pdf.NewRenderer(""); err != nil {
+		l.Warn(context.Background(), "PDF rendering is disabled", logrus.Fields{"error": err.Error()})
+	} else {
+		service.SetPrintRenderer(renderer)
+	}
+
 	controller := &EsfDocumentController{
-		logger:  l,
-		service: service,
-		db:      db,
+		logger:      l,
+		service:     service,
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+		db:          db,
 	}
 
 	l.Info(context.Background(), "EsfDocumentController initialized")
@@ -48,21 +82,71 @@ func (c *EsfDocumentController) registerRoutes(app *fiber.App) {
 	// Публичные routes (без JWT)
 	esfDocumentGroup.Get("/", c.getEsfDocuments)
 	esfDocumentGroup.Get("/paginated", c.getEsfDocumentsPaginated)
+	esfDocumentGroup.Get("/search", c.searchEsfDocuments)
+	esfDocumentGroup.Get("/print-template", c.getPrintTemplate)
 	esfDocumentGroup.Get("/:id", c.getByEsfDocument)
+	esfDocumentGroup.Get("/:id/pdf", c.getEsfDocumentPDF)
+	esfDocumentGroup.Get("/:id/xml", c.getEsfDocumentXML)
+	esfDocumentGroup.Get("/:id/corrections", c.getEsfDocumentCorrections)
+	esfDocumentGroup.Get("/:id/signing-payload", c.getEsfDocumentSigningPayload)
+	esfDocumentGroup.Get("/:id/signatures", c.getEsfDocumentSignatures)
+	esfDocumentGroup.Get("/:id/signatures/verify", c.verifyEsfDocumentSignatures)
+	esfDocumentGroup.Get("/:id/approval", c.getEsfDocumentApproval)
 
 	// Защищенные routes (с JWT)
 	protected := esfDocumentGroup.Group("")
 	protected.Use(middleware.JWTMiddleware())
-	protected.Post("/", c.createEsfDocument)
-	protected.Put("/:id", c.updateEsfDocument)
-	protected.Delete("/:id", c.deleteEsfDocument)
+
+	// Документ создает и изменяет исполнитель с правами create/update:document;
+	// согласующий и наблюдатель не могут сами подготовить и отправить документ
+	canCreate := rbac.RequirePermission(rbac.PermissionCreateDocument)
+	canUpdate := rbac.RequirePermission(rbac.PermissionUpdateDocument)
+	canDelete := rbac.RequirePermission(rbac.PermissionDeleteDocument)
+	protected.Post("/", c.loadUserContext, canCreate, c.createEsfDocument)
+	protected.Post("/batch", c.loadUserContext, canCreate, c.batchEsfDocuments)
+	protected.Post("/import/xml", c.loadUserContext, canCreate, c.importEsfDocumentsXML)
+	protected.Post("/import/table", c.loadUserContext, canCreate, c.importEsfDocumentsTable)
+	// Шаблон печатной формы общий для организации и меняется только администратором
+	canUpdateOrganization := rbac.RequirePermission(rbac.PermissionUpdateOrganization)
+	protected.Put("/print-template", c.loadUserContext, canUpdateOrganization, c.uploadPrintTemplate)
+	protected.Delete("/print-template", c.loadUserContext, canUpdateOrganization, c.deletePrintTemplate)
+	protected.Put("/:id", c.loadUserContext, canUpdate, c.updateEsfDocument)
+	protected.Delete("/:id", c.loadUserContext, canDelete, c.deleteEsfDocument)
+
+	// История изменений раскрывает авторов правок и полные снимки документа
+	canRead := rbac.RequirePermission(rbac.PermissionReadDocument)
+	protected.Get("/:id/revisions", c.loadUserContext, canRead, c.getEsfDocumentRevisions)
+	protected.Get("/:id/revisions/diff", c.loadUserContext, canRead, c.diffEsfDocumentRevisions)
+	protected.Get("/:id/revisions/:revision", c.loadUserContext, canRead, c.getEsfDocumentRevision)
+
+	// Переходы жизненного цикла документа
+	protected.Post("/:id/draft", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusDraft))
+	protected.Post("/:id/ready", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusReady))
+	protected.Post("/:id/sign", c.loadUserContext, canUpdate, c.signEsfDocument)
+	protected.Post("/:id/submit", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusSubmitted))
+	protected.Post("/:id/accept", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusAccepted))
+	protected.Post("/:id/reject", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusRejected))
+	protected.Post("/:id/cancel", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusCancelled))
+
+	// Согласование по политике организации: решение принимает пользователь с правом approve:document
+	canApprove := rbac.RequirePermission(rbac.PermissionApproveDocument)
+	protected.Post("/:id/approval/approve", c.loadUserContext, canApprove, c.decideEsfDocumentApproval(entity.ApprovalDecisionApproved))
+	protected.Post("/:id/approval/reject", c.loadUserContext, canApprove, c.decideEsfDocumentApproval(entity.ApprovalDecisionRejected))
+
+	// Доставка покупателю на платформе; покупатель принимает (/accept) или оспаривает копию
+	protected.Post("/:id/deliver", c.loadUserContext, canUpdate, c.deliverEsfDocument)
+	protected.Post("/:id/dispute", c.loadUserContext, canUpdate, c.changeEsfDocumentStatus(entity.DocumentStatusRejected))
+
+	// Корректировочные счета-фактуры
+	protected.Post("/:id/corrections", c.loadUserContext, canCreate, c.createEsfDocumentCorrection)
+	protected.Post("/:id/clone", c.loadUserContext, canCreate, c.cloneEsfDocument)
 }
 
 // getEsfDocuments возвращает все документы ЭСФ
 func (c *EsfDocumentController) getEsfDocuments(ctx *fiber.Ctx) error {
 	c.logger.Info(ctx.Context(), "Fetching ESF documents")
 
-	orgID, err := c.resolveOrgID(ctx)
+	orgID, err := resolveOrgID(ctx)
 	if err != nil {
 		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
 		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
@@ -95,7 +179,7 @@ func (c *EsfDocumentController) getEsfDocuments(ctx *fiber.Ctx) error {
 func (c *EsfDocumentController) getEsfDocumentsPaginated(ctx *fiber.Ctx) error {
 	c.logger.Info(ctx.Context(), "Вибірка документів ЕСФ з пагінацією")
 
-	orgID, err := c.resolveOrgID(ctx)
+	orgID, err := resolveOrgID(ctx)
 	if err != nil {
 		c.logger.Warn(ctx.Context(), "Не вдалося визначити ID організації", logrus.Fields{"error": err.Error()})
 		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
@@ -129,12 +213,37 @@ func (c *EsfDocumentController) getEsfDocumentsPaginated(ctx *fiber.Ctx) error {
 	return ctx.Status(http.StatusOK).JSON(response)
 }
 
+// searchEsfDocuments выполняет полнотекстовый поиск документов ЭСФ (?q=...)
+func (c *EsfDocumentController) searchEsfDocuments(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	paginationParams := pagination.ExtractPaginationParams(ctx)
+
+	results, totalCount, err := c.service.SearchDocuments(ctx.Context(), orgID, ctx.Query("q"), paginationParams)
+	if err != nil {
+		appErr, ok := err.(*apperror.AppError)
+		if !ok {
+			appErr = apperror.New(apperror.ErrInternal, "failed to search documents").WithError(err)
+		}
+		c.logger.Error(ctx.Context(), "Failed to search documents", err, logrus.Fields{"org_id": orgID.String()})
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	response := pagination.NewPaginatedResponse(results, paginationParams.Page, paginationParams.PageSize, totalCount)
+	return ctx.Status(http.StatusOK).JSON(response)
+}
+
 // getByEsfDocument возвращает документ ЭСФ по ID
 func (c *EsfDocumentController) getByEsfDocument(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
 	c.logger.Debug(ctx.Context(), "Fetching document by ID", logrus.Fields{"doc_id": id})
 
-	orgID, err := c.resolveOrgID(ctx)
+	orgID, err := resolveOrgID(ctx)
 	if err != nil {
 		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
 		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
@@ -180,7 +289,7 @@ func (c *EsfDocumentController) getByEsfDocument(ctx *fiber.Ctx) error {
 func (c *EsfDocumentController) createEsfDocument(ctx *fiber.Ctx) error {
 	c.logger.Info(ctx.Context(), "Creating new ESF document")
 
-	orgID, err := c.resolveOrgID(ctx)
+	orgID, err := resolveOrgID(ctx)
 	if err != nil {
 		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
 		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
@@ -200,7 +309,7 @@ func (c *EsfDocumentController) createEsfDocument(ctx *fiber.Ctx) error {
 		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
 	}
 
-	response, err := c.service.CreateDocument(ctx.Context(), orgID, &req)
+	response, err := c.service.CreateDocument(actorContext(ctx), orgID, &req)
 	if err != nil {
 		appErr, ok := err.(*apperror.AppError)
 		if !ok {
@@ -218,12 +327,66 @@ func (c *EsfDocumentController) createEsfDocument(ctx *fiber.Ctx) error {
 	})
 }
 
+// batchEsfDocuments создает и изменяет документы пакетом.
+// Принимает {"mode": "atomic|bestEffort", "items": [...]} или массив документов с режимом в ?mode=.
+// Элементы без id создаются, элементы с id изменяют существующие черновики.
+func (c *EsfDocumentController) batchEsfDocuments(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfDocumentBatchRequest
+	body := bytes.TrimSpace(ctx.Body())
+	if len(body) > 0 && body[0] == '[' {
+		err = json.Unmarshal(body, &req.Items)
+	} else {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+	if mode := ctx.Query("mode"); mode != "" {
+		req.Mode = mode
+	}
+
+	result, err := c.service.ProcessDocumentBatch(actorContext(ctx), orgID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to process document batch", orgID, uuid.Nil)
+	}
+
+	switch {
+	case result.Failed == 0:
+		return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+			"success": true,
+			"data":    result,
+			"message": "Document batch processed successfully",
+		})
+	case result.Succeeded > 0:
+		return ctx.Status(http.StatusMultiStatus).JSON(fiber.Map{
+			"success": true,
+			"data":    result,
+			"message": "Document batch partially processed",
+		})
+	default:
+		return ctx.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"data":    result,
+			"message": "Document batch rejected",
+		})
+	}
+}
+
 // updateEsfDocument обновляет документ ЭСФ
 func (c *EsfDocumentController) updateEsfDocument(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
 	c.logger.Info(ctx.Context(), "Updating ESF document", logrus.Fields{"doc_id": id})
 
-	orgID, err := c.resolveOrgID(ctx)
+	orgID, err := resolveOrgID(ctx)
 	if err != nil {
 		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
 		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
@@ -253,7 +416,7 @@ func (c *EsfDocumentController) updateEsfDocument(ctx *fiber.Ctx) error {
 		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
 	}
 
-	if err := c.service.UpdateDocument(ctx.Context(), orgID, &req); err != nil {
+	if err := c.service.UpdateDocument(actorContext(ctx), orgID, &req); err != nil {
 		appErr, ok := err.(*apperror.AppError)
 		if !ok {
 			appErr = apperror.New(apperror.ErrInternal, "failed to update document").WithError(err)
@@ -274,7 +437,7 @@ func (c *EsfDocumentController) deleteEsfDocument(ctx *fiber.Ctx) error {
 	id := ctx.Params("id")
 	c.logger.Info(ctx.Context(), "Deleting ESF document", logrus.Fields{"doc_id": id})
 
-	orgID, err := c.resolveOrgID(ctx)
+	orgID, err := resolveOrgID(ctx)
 	if err != nil {
 		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
 		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
@@ -288,7 +451,7 @@ func (c *EsfDocumentController) deleteEsfDocument(ctx *fiber.Ctx) error {
 		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
 	}
 
-	if err := c.service.DeleteDocument(ctx.Context(), orgID, docID); err != nil {
+	if err := c.service.DeleteDocument(actorContext(ctx), orgID, docID); err != nil {
 		appErr, ok := err.(*apperror.AppError)
 		if !ok {
 			appErr = apperror.New(apperror.ErrInternal, "failed to delete document").WithError(err)
@@ -304,18 +467,516 @@ func (c *EsfDocumentController) deleteEsfDocument(ctx *fiber.Ctx) error {
 	})
 }
 
-// resolveOrgID достает идентификатор организации из заголовка X-Org-Id или query orgId.
-func (c *EsfDocumentController) resolveOrgID(ctx *fiber.Ctx) (uuid.UUID, error) {
-	raw := ctx.Get("X-Org-Id")
-	if raw == "" {
-		raw = ctx.Query("orgId")
+// changeEsfDocumentStatus возвращает handler перевода документа ЭСФ в указанный статус
+func (c *EsfDocumentController) changeEsfDocumentStatus(status entity.DocumentStatus) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		c.logger.Info(ctx.Context(), "Changing ESF document status", logrus.Fields{"doc_id": id, "status": status})
+
+		orgID, err := resolveOrgID(ctx)
+		if err != nil {
+			c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+			appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+
+		docID, err := uuid.Parse(id)
+		if err != nil {
+			c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+			appErr := apperror.New(apperror.ErrInvalidRequest, "invalid document ID format")
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+
+		// Тело запроса необязательно: причина нужна только для отклонения и аннулирования
+		var req models.EsfDocumentStatusRequest
+		if len(ctx.Body()) > 0 {
+			if err := ctx.BodyParser(&req); err != nil {
+				c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+				appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+				return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+			}
+		}
+
+		result, err := c.service.ChangeDocumentStatus(actorContext(ctx), orgID, docID, status, req.Reason)
+		if err != nil {
+			appErr, ok := err.(*apperror.AppError)
+			if !ok {
+				appErr = apperror.New(apperror.ErrInternal, "failed to change document status").WithError(err)
+			}
+			c.logger.Error(ctx.Context(), "Failed to change document status", err, logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String()})
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+
+		c.logger.Info(ctx.Context(), "Document status changed successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String(), "status": status})
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data":    result,
+			"message": "Document status changed successfully",
+		})
+	}
+}
+
+// getEsfDocumentCorrections возвращает цепочку корректировок документа ЭСФ с итоговыми суммами
+func (c *EsfDocumentController) getEsfDocumentCorrections(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	c.logger.Debug(ctx.Context(), "Fetching document correction chain", logrus.Fields{"doc_id": id})
+
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	docID, err := uuid.Parse(id)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid document ID format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	chain, err := c.service.GetCorrectionChain(ctx.Context(), orgID, docID)
+	if err != nil {
+		appErr, ok := err.(*apperror.AppError)
+		if !ok {
+			appErr = apperror.New(apperror.ErrInternal, "failed to fetch correction chain").WithError(err)
+		}
+		c.logger.Error(ctx.Context(), "Failed to fetch correction chain", err, logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String()})
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    chain,
+		"message": "Correction chain retrieved successfully",
+	})
+}
+
+// createEsfDocumentCorrection создает корректировочную счет-фактуру к принятому документу
+func (c *EsfDocumentController) createEsfDocumentCorrection(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+	c.logger.Info(ctx.Context(), "Creating ESF document correction", logrus.Fields{"doc_id": id})
+
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	docID, err := uuid.Parse(id)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid document ID format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfCorrectionRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.CreateCorrection(actorContext(ctx), orgID, docID, &req)
+	if err != nil {
+		appErr, ok := err.(*apperror.AppError)
+		if !ok {
+			appErr = apperror.New(apperror.ErrInternal, "failed to create correction").WithError(err)
+		}
+		c.logger.Error(ctx.Context(), "Failed to create correction", err, logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String()})
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	c.logger.Info(ctx.Context(), "Correction created successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String(), "correction_id": result.DocumentUuid})
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Correction created successfully",
+	})
+}
+
+// cloneEsfDocument копирует документ в новый черновик; тело запроса необязательно
+func (c *EsfDocumentController) cloneEsfDocument(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfCloneRequest
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+			appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+	}
+
+	// Привязки пользователей к организациям нет, поэтому копировать документ
+	// в другую организацию может только пользователь с доступом ко всем организациям
+	if req.TargetOrgId != nil && *req.TargetOrgId != uuid.Nil && *req.TargetOrgId != orgID {
+		if userCtx := rbac.ExtractUserContext(ctx); userCtx == nil || !userCtx.HasPermission(rbac.PermissionAccessAllOrganizations) {
+			c.logger.Warn(ctx.Context(), "Cross-organization clone denied", logrus.Fields{
+				"org_id":        orgID.String(),
+				"doc_id":        docID.String(),
+				"target_org_id": req.TargetOrgId.String(),
+			})
+			appErr := apperror.New(apperror.ErrForbidden, "no access to the target organization")
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+	}
+
+	result, err := c.service.CloneDocument(actorContext(ctx), orgID, docID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to clone document", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document cloned successfully",
+	})
+}
+
+// deliverEsfDocument доставляет отправленный документ покупателю - другой организации платформы
+func (c *EsfDocumentController) deliverEsfDocument(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.DeliverDocument(actorContext(ctx), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to deliver document", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document delivered successfully",
+	})
+}
+
+// getEsfDocumentPDF возвращает документ ЭСФ в формате PDF по печатной форме организации
+func (c *EsfDocumentController) getEsfDocumentPDF(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	content, err := c.service.RenderDocumentPDF(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to render document PDF", orgID, docID)
+	}
+
+	disposition := "inline"
+	if ctx.QueryBool("download") {
+		disposition = "attachment"
+	}
+	ctx.Set(fiber.HeaderContentType, "application/pdf")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`%s; filename="esf-%s.pdf"`, disposition, docID.String()))
+	return ctx.Status(http.StatusOK).Send(content)
+}
+
+// getEsfDocumentXML возвращает документ ЭСФ в XML формате обмена налоговой службы
+func (c *EsfDocumentController) getEsfDocumentXML(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	content, err := c.service.ExportDocumentXML(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to export document to XML", orgID, docID)
+	}
+
+	ctx.Set(fiber.HeaderContentType, esfxml.ContentType+"; charset=utf-8")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="esf-%s.xml"`, docID.String()))
+	return ctx.Status(http.StatusOK).Send(content)
+}
+
+// importEsfDocumentsXML импортирует один или несколько документов из XML файла обмена.
+// Принимает файл multipart в поле file или XML в теле запроса.
+func (c *EsfDocumentController) importEsfDocumentsXML(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	content, err := uploadedContent(ctx, "file")
+	if err != nil || len(content) == 0 {
+		c.logger.Warn(ctx.Context(), "Failed to read import file", logrus.Fields{"org_id": orgID.String()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "xml file is required")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.ImportDocumentsXML(actorContext(ctx), orgID, content)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to import documents from XML", orgID, uuid.Nil)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Documents imported successfully",
+	})
+}
+
+// importEsfDocumentsTable импортирует документы из CSV или XLSX файла в поле file.
+// Параметры (format, mapping - JSON, delimiter, sheet, dryRun) передаются полями формы.
+// При dryRun возвращается только отчет проверки; при ошибках документы не создаются.
+func (c *EsfDocumentController) importEsfDocumentsTable(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		appErr := apperror.New(apperror.ErrInvalidRequest, "import file is required")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	content, err := uploadedContent(ctx, "file")
+	if err != nil || len(content) == 0 {
+		c.logger.Warn(ctx.Context(), "Failed to read import file", logrus.Fields{"org_id": orgID.String()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "import file is required")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	opts := models.EsfTableImportOptions{
+		Format:    ctx.FormValue("format"),
+		Delimiter: ctx.FormValue("delimiter"),
+		Sheet:     ctx.FormValue("sheet"),
+		DryRun:    ctx.FormValue("dryRun") == "true" || ctx.QueryBool("dryRun"),
+	}
+	if opts.Format == "" {
+		opts.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+	}
+	if mapping := ctx.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			appErr := apperror.New(apperror.ErrInvalidRequest, "mapping must be a JSON object of field to column")
+			return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+		}
+	}
+
+	report, err := c.service.ImportDocumentsTable(actorContext(ctx), orgID, content, opts)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to import documents from table", orgID, uuid.Nil)
+	}
+
+	switch {
+	case report.DryRun:
+		message := "Import file is valid"
+		if !report.Valid {
+			message = "Import file contains errors"
+		}
+		return ctx.Status(http.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"data":    report,
+			"message": message,
+		})
+	case !report.Valid:
+		return ctx.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"success": false,
+			"data":    report,
+			"message": "Import file contains errors, no documents were imported",
+		})
+	default:
+		return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+			"success": true,
+			"data":    report,
+			"message": "Documents imported successfully",
+		})
+	}
+}
+
+// getPrintTemplate возвращает печатную форму организации (или форму по умолчанию)
+func (c *EsfDocumentController) getPrintTemplate(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetPrintTemplate(ctx.Context(), orgID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch print template", orgID, uuid.Nil)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Print template retrieved successfully",
+	})
+}
+
+// uploadPrintTemplate загружает печатную форму организации.
+// Принимает JSON {"content": "..."}, файл multipart в поле template или HTML в теле запроса.
+func (c *EsfDocumentController) uploadPrintTemplate(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	content, err := printTemplateContent(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to read print template", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.SavePrintTemplate(actorContext(ctx), orgID, content)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to save print template", orgID, uuid.Nil)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Print template saved successfully",
+	})
+}
+
+// deletePrintTemplate удаляет печатную форму организации, возвращая форму по умолчанию
+func (c *EsfDocumentController) deletePrintTemplate(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	if err := c.service.DeletePrintTemplate(actorContext(ctx), orgID); err != nil {
+		return c.respondError(ctx, err, "failed to delete print template", orgID, uuid.Nil)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Print template reset to default",
+	})
+}
+
+// printTemplateContent достает текст печатной формы из запроса
+func printTemplateContent(ctx *fiber.Ctx) (string, error) {
+	if strings.HasPrefix(ctx.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		var req models.EsfPrintTemplateRequest
+		if err := ctx.BodyParser(&req); err != nil {
+			return "", err
+		}
+		return req.Content, nil
+	}
+
+	content, err := uploadedContent(ctx, "template")
+	return string(content), err
+}
+
+// uploadedContent возвращает файл multipart из поля field или тело запроса
+func uploadedContent(ctx *fiber.Ctx, field string) ([]byte, error) {
+	if file, err := ctx.FormFile(field); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+	return ctx.Body(), nil
+}
+
+// getEsfDocumentRevisions возвращает список ревизий документа ЭСФ
+func (c *EsfDocumentController) getEsfDocumentRevisions(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	revisions, err := c.service.GetDocumentRevisions(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch document revisions", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    revisions,
+		"message": "Document revisions retrieved successfully",
+	})
+}
+
+// getEsfDocumentRevision возвращает ревизию документа ЭСФ со снимком
+func (c *EsfDocumentController) getEsfDocumentRevision(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	revision, err := strconv.Atoi(ctx.Params("revision"))
+	if err != nil {
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid revision number")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetDocumentRevision(ctx.Context(), orgID, docID, revision)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch document revision", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document revision retrieved successfully",
+	})
+}
+
+// diffEsfDocumentRevisions возвращает пополевую разницу между ревизиями ?from=N&to=M
+func (c *EsfDocumentController) diffEsfDocumentRevisions(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
 	}
-	if raw == "" {
-		return uuid.Nil, fmt.Errorf("organization id is required (header X-Org-Id or query orgId)")
+
+	from, fromErr := strconv.Atoi(ctx.Query("from"))
+	to, toErr := strconv.Atoi(ctx.Query("to"))
+	if fromErr != nil || toErr != nil {
+		appErr := apperror.New(apperror.ErrInvalidRequest, "query parameters from and to must be revision numbers")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
 	}
-	orgID, err := uuid.Parse(raw)
+
+	result, err := c.service.DiffDocumentRevisions(ctx.Context(), orgID, docID, from, to)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid organization id: %w", err)
+		return c.respondError(ctx, err, "failed to compare document revisions", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document revisions compared successfully",
+	})
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfDocumentController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// resolveDocumentParams достает идентификаторы организации и документа из запроса
+func (c *EsfDocumentController) resolveDocumentParams(ctx *fiber.Ctx) (uuid.UUID, uuid.UUID, *apperror.AppError) {
+	return resolveDocumentParams(ctx, c.logger)
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfDocumentController) respondError(ctx *fiber.Ctx, err error, message string, orgID, docID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
 	}
-	return orgID, nil
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String(), "doc_id": docID.String()})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
 }
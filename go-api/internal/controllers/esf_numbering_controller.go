@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EsfNumberingController выдает и изменяет настройки нумерации документов организации
+type EsfNumberingController struct {
+	logger      *logger.Logger
+	service     services.EsfNumberingService
+	roleService services.RoleService
+}
+
+func NewEsfNumberingController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	controller := &EsfNumberingController{
+		logger:      logger.New(log),
+		service:     serviceimpl.NewEsfNumberingService(repositorypostgres.NewEsfNumberingRepositoryPostgres(db, log), log),
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfNumberingController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+// registerRoutes регистрирует маршруты настроек нумерации документов
+func (c *EsfNumberingController) registerRoutes(app *fiber.App) {
+	numberingGroup := app.Group("/api/esf-numbering")
+
+	// Public routes
+	numberingGroup.Get("/", c.getNumbering)
+
+	// Protected routes
+	protected := numberingGroup.Group("")
+	protected.Use(middleware.JWTMiddleware(), c.loadUserContext, rbac.RequirePermission(rbac.PermissionUpdateOrganization))
+	protected.Put("/", c.updateNumbering)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfNumberingController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getNumbering возвращает настройки нумерации, счетчики по годам и следующий номер
+func (c *EsfNumberingController) getNumbering(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetNumbering(ctx.Context(), orgID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch numbering settings", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Numbering settings retrieved successfully",
+	})
+}
+
+// updateNumbering изменяет префикс и число цифр номера для следующих документов
+func (c *EsfNumberingController) updateNumbering(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfNumberingRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.UpdateNumbering(actorContext(ctx), orgID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to update numbering settings", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Numbering settings updated successfully",
+	})
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfNumberingController) respondError(ctx *fiber.Ctx, err error, message string, orgID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String()})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
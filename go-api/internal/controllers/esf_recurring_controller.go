@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultRecurringPreview число выпусков в предпросмотре по умолчанию
+const defaultRecurringPreview = 12
+
+// EsfRecurringController ведет шаблоны повторяющихся документов.
+// Черновики по шаблонам создает планировщик; POST /run запускает выпуск для организации немедленно.
+type EsfRecurringController struct {
+	logger      *logger.Logger
+	service     services.EsfRecurringService
+	roleService services.RoleService
+}
+
+func NewEsfRecurringController(app *fiber.App, log *logrus.Logger, db *gorm.DB, cacheManager cache.CacheManager) {
+	// Документы шаблонов проверяются и создаются так же, как через API документов
+	documents := serviceimpl.NewEsfDocumentService(repositorypostgres.NewEsfDocumentRepositoryPostgres(db, log), db, log)
+	if cacheManager != nil {
+		documents.SetCacheManager(cacheManager)
+	}
+	documents.SetReferenceService(serviceimpl.NewEsfReferenceService(repositorypostgres.NewEsfReferenceRepositoryPostgres(db, log), log))
+	documents.SetCurrencyRates(serviceimpl.NewCurrencyRateService(repositorypostgres.NewCurrencyRateRepositoryPostgres(db, log), log))
+	documents.SetContractorRepository(repositorypostgres.NewEsfContractorRepositoryPostgres(db, log))
+
+	controller := &EsfRecurringController{
+		logger:      logger.New(log),
+		service:     serviceimpl.NewEsfRecurringService(repositorypostgres.NewEsfRecurringRepositoryPostgres(db, log), documents, log),
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfRecurringController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfRecurringController) registerRoutes(app *fiber.App) {
+	recurringGroup := app.Group("/api/esf-recurring")
+
+	// Public routes
+	recurringGroup.Get("/", c.getRecurringTemplates)
+	recurringGroup.Get("/:id", c.getRecurringTemplate)
+	recurringGroup.Get("/:id/preview", c.previewRecurringTemplate)
+	recurringGroup.Get("/:id/runs", c.getRecurringRuns)
+
+	// Protected routes: выпуск по шаблону создает документы, поэтому права те же, что у создания документа
+	protected := recurringGroup.Group("")
+	protected.Use(middleware.JWTMiddleware(), c.loadUserContext)
+	canCreate := rbac.RequirePermission(rbac.PermissionCreateDocument)
+	canUpdate := rbac.RequirePermission(rbac.PermissionUpdateDocument)
+	protected.Post("/", canCreate, c.createRecurringTemplate)
+	protected.Post("/run", canCreate, c.runRecurringTemplates)
+	protected.Put("/:id", canUpdate, c.updateRecurringTemplate)
+	protected.Delete("/:id", canUpdate, c.deleteRecurringTemplate)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfRecurringController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getRecurringTemplates возвращает шаблоны повторяющихся документов организации
+func (c *EsfRecurringController) getRecurringTemplates(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetRecurringTemplates(ctx.Context(), orgID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch recurring templates", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring templates retrieved successfully",
+	})
+}
+
+// getRecurringTemplate возвращает шаблон повторяющегося документа
+func (c *EsfRecurringController) getRecurringTemplate(ctx *fiber.Ctx) error {
+	orgID, templateID, appErr := c.resolveRecurringParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetRecurringTemplate(ctx.Context(), orgID, templateID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch recurring template", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring template retrieved successfully",
+	})
+}
+
+// previewRecurringTemplate возвращает предстоящие выпуски; ?count= задает их число (по умолчанию 12)
+func (c *EsfRecurringController) previewRecurringTemplate(ctx *fiber.Ctx) error {
+	orgID, templateID, appErr := c.resolveRecurringParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.PreviewRecurringTemplate(ctx.Context(), orgID, templateID, ctx.QueryInt("count", defaultRecurringPreview))
+	if err != nil {
+		return c.respondError(ctx, err, "failed to preview recurring template", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring template preview retrieved successfully",
+	})
+}
+
+// getRecurringRuns возвращает выполненные выпуски по шаблону
+func (c *EsfRecurringController) getRecurringRuns(ctx *fiber.Ctx) error {
+	orgID, templateID, appErr := c.resolveRecurringParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetRecurringRuns(ctx.Context(), orgID, templateID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch recurring runs", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring runs retrieved successfully",
+	})
+}
+
+// createRecurringTemplate создает шаблон повторяющегося документа
+func (c *EsfRecurringController) createRecurringTemplate(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfRecurringTemplateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.CreateRecurringTemplate(actorContext(ctx), orgID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to create recurring template", orgID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring template created successfully",
+	})
+}
+
+// updateRecurringTemplate заменяет шаблон повторяющегося документа
+func (c *EsfRecurringController) updateRecurringTemplate(ctx *fiber.Ctx) error {
+	orgID, templateID, appErr := c.resolveRecurringParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfRecurringTemplateRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.UpdateRecurringTemplate(actorContext(ctx), orgID, templateID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to update recurring template", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring template updated successfully",
+	})
+}
+
+// deleteRecurringTemplate удаляет шаблон; созданные по нему документы сохраняются
+func (c *EsfRecurringController) deleteRecurringTemplate(ctx *fiber.Ctx) error {
+	orgID, templateID, appErr := c.resolveRecurringParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	if err := c.service.DeleteRecurringTemplate(actorContext(ctx), orgID, templateID); err != nil {
+		return c.respondError(ctx, err, "failed to delete recurring template", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Recurring template deleted successfully",
+	})
+}
+
+// runRecurringTemplates создает черновики по наступившим шаблонам организации, не дожидаясь планировщика
+func (c *EsfRecurringController) runRecurringTemplates(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.RunRecurringTemplates(actorContext(ctx), orgID, time.Now())
+	if err != nil {
+		return c.respondError(ctx, err, "failed to run recurring templates", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Recurring templates processed",
+	})
+}
+
+// resolveRecurringParams достает идентификаторы организации и шаблона из запроса
+func (c *EsfRecurringController) resolveRecurringParams(ctx *fiber.Ctx) (uuid.UUID, uuid.UUID, *apperror.AppError) {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		return uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+	}
+
+	id := ctx.Params("id")
+	templateID, err := uuid.Parse(id)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		return uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid recurring template ID format")
+	}
+
+	return orgID, templateID, nil
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfRecurringController) respondError(ctx *fiber.Ctx, err error, message string, orgID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String(), "template_id": ctx.Params("id")})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
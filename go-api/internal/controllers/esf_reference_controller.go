@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+// EsfReferenceController выдает справочники кодов ЭСФ для выпадающих списков
+type EsfReferenceController struct {
+	logger  *logger.Logger
+	service services.EsfReferenceService
+}
+
+func NewEsfReferenceController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	repo := repositorypostgres.NewEsfReferenceRepositoryPostgres(db, log)
+
+	controller := &EsfReferenceController{
+		logger:  logger.New(log),
+		service: serviceimpl.NewEsfReferenceService(repo, log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfReferenceController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfReferenceController) registerRoutes(app *fiber.App) {
+	referenceGroup := app.Group("/api/esf-references")
+
+	// Публичные routes (без JWT)
+	referenceGroup.Get("/", c.getReferenceCatalogs)
+	referenceGroup.Get("/:catalog", c.getReferenceCatalog)
+}
+
+// getReferenceCatalogs возвращает активные версии справочников
+func (c *EsfReferenceController) getReferenceCatalogs(ctx *fiber.Ctx) error {
+	catalogs, err := c.service.ListCatalogs(ctx.Context())
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch reference catalogs")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    catalogs,
+		"message": "Reference catalogs retrieved successfully",
+	})
+}
+
+// getReferenceCatalog возвращает коды активной версии справочника
+func (c *EsfReferenceController) getReferenceCatalog(ctx *fiber.Ctx) error {
+	catalog, err := c.service.GetCatalog(ctx.Context(), ctx.Params("catalog"))
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch reference catalog")
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    catalog,
+		"message": "Reference catalog retrieved successfully",
+	})
+}
+
+func (c *EsfReferenceController) respondError(ctx *fiber.Ctx, err error, message string) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"catalog": ctx.Params("catalog")})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
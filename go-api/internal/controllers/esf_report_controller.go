@@ -0,0 +1,272 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EsfReportController строит отчеты по документам организации: книги продаж и покупок,
+// зачет НДС и аналитику для панели администратора
+type EsfReportController struct {
+	logger      *logger.Logger
+	service     services.EsfReportService
+	roleService services.RoleService
+}
+
+func NewEsfReportController(app *fiber.App, log *logrus.Logger, db *gorm.DB, cacheManager cache.CacheManager) {
+	service := serviceimpl.NewEsfReportService(
+		repositorypostgres.NewEsfReportRepositoryPostgres(db, log),
+		repositorypostgres.NewEsfContractorRepositoryPostgres(db, log),
+		log,
+	)
+	if cacheManager != nil {
+		service.SetCacheManager(cacheManager)
+	}
+
+	controller := &EsfReportController{
+		logger:      logger.New(log),
+		service:     service,
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfReportController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfReportController) registerRoutes(app *fiber.App) {
+	reportGroup := app.Group("/api/esf-reports")
+
+	// Отчеты раскрывают суммы и контрагентов организации, поэтому доступны
+	// только пользователям с правом чтения документов
+	reportGroup.Use(middleware.JWTMiddleware(), c.loadUserContext, rbac.RequirePermission(rbac.PermissionReadDocument))
+	reportGroup.Get("/vat-ledger", c.getVATLedger)
+	reportGroup.Get("/vat-balance", c.getVATBalance)
+	reportGroup.Get("/analytics/summary", c.getAnalyticsSummary)
+	reportGroup.Get("/analytics/monthly", c.getMonthlyAnalytics)
+	reportGroup.Get("/analytics/contractors", c.getTopContractors)
+	reportGroup.Get("/analytics/vat-rates", c.getVATByRate)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfReportController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// getVATLedger возвращает книгу продаж за период ?from=&to= (по умолчанию текущий месяц),
+// ?ledger=purchases - книгу покупок по входящим документам.
+// ?format=csv выгружает CSV, ?status=signed,accepted задает учитываемые статусы документов.
+func (c *EsfReportController) getVATLedger(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	now := time.Now()
+	from, err := queryDate(ctx, "from", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return c.respondError(ctx, err, "invalid period", orgID)
+	}
+	to, err := queryDate(ctx, "to", from.AddDate(0, 1, -1))
+	if err != nil {
+		return c.respondError(ctx, err, "invalid period", orgID)
+	}
+
+	req := &models.EsfVatLedgerRequest{From: from, To: to, Ledger: ctx.Query("ledger")}
+	if raw := ctx.Query("status"); raw != "" {
+		req.Statuses = strings.Split(raw, ",")
+	}
+
+	switch format := strings.ToLower(ctx.Query("format", "json")); format {
+	case "json":
+	case "csv":
+		content, err := c.service.ExportVATLedgerCSV(ctx.Context(), orgID, req)
+		if err != nil {
+			return c.respondError(ctx, err, "failed to export VAT ledger", orgID)
+		}
+		ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		name := "vat-ledger"
+		if strings.EqualFold(strings.TrimSpace(req.Ledger), "purchases") {
+			name = "vat-purchase-ledger"
+		}
+		ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s-%s-%s.csv"`,
+			name, from.Format(currency.DateLayout), to.Format(currency.DateLayout)))
+		return ctx.Status(http.StatusOK).Send(content)
+	default:
+		appErr := apperror.NewWithDetails(apperror.ErrInvalidRequest, "unsupported report format", "expected json or csv, got "+format)
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetVATLedger(ctx.Context(), orgID, req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build VAT ledger", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "VAT ledger built successfully",
+	})
+}
+
+// getVATBalance возвращает НДС к уплате за период ?from=&to= (по умолчанию текущий месяц):
+// начисленный НДС за вычетом входящего НДС поставщиков по ставкам и в целом
+func (c *EsfReportController) getVATBalance(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	now := time.Now()
+	from, err := queryDate(ctx, "from", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return c.respondError(ctx, err, "invalid period", orgID)
+	}
+	to, err := queryDate(ctx, "to", from.AddDate(0, 1, -1))
+	if err != nil {
+		return c.respondError(ctx, err, "invalid period", orgID)
+	}
+
+	result, err := c.service.GetVATBalance(ctx.Context(), orgID, &models.EsfVatBalanceRequest{From: from, To: to})
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build VAT balance", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "VAT balance built successfully",
+	})
+}
+
+// getAnalyticsSummary возвращает итоги документов за период и среднюю сумму документа
+func (c *EsfReportController) getAnalyticsSummary(ctx *fiber.Ctx) error {
+	orgID, req, err := c.analyticsRequest(ctx)
+	if err != nil {
+		return c.respondError(ctx, err, "invalid analytics parameters", orgID)
+	}
+
+	result, err := c.service.GetAnalyticsSummary(ctx.Context(), orgID, req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build analytics", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Analytics summary built successfully",
+	})
+}
+
+// getMonthlyAnalytics возвращает итоги документов по месяцам
+func (c *EsfReportController) getMonthlyAnalytics(ctx *fiber.Ctx) error {
+	orgID, req, err := c.analyticsRequest(ctx)
+	if err != nil {
+		return c.respondError(ctx, err, "invalid analytics parameters", orgID)
+	}
+
+	result, err := c.service.GetMonthlyAnalytics(ctx.Context(), orgID, req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build analytics", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Monthly analytics built successfully",
+	})
+}
+
+// getTopContractors возвращает покупателей с наибольшей суммой документов, ?limit= (по умолчанию 10)
+func (c *EsfReportController) getTopContractors(ctx *fiber.Ctx) error {
+	orgID, req, err := c.analyticsRequest(ctx)
+	if err != nil {
+		return c.respondError(ctx, err, "invalid analytics parameters", orgID)
+	}
+
+	result, err := c.service.GetTopContractors(ctx.Context(), orgID, req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build analytics", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Top contractors built successfully",
+	})
+}
+
+// getVATByRate возвращает итоги документов по ставкам НДС
+func (c *EsfReportController) getVATByRate(ctx *fiber.Ctx) error {
+	orgID, req, err := c.analyticsRequest(ctx)
+	if err != nil {
+		return c.respondError(ctx, err, "invalid analytics parameters", orgID)
+	}
+
+	result, err := c.service.GetVATByRate(ctx.Context(), orgID, req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build analytics", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "VAT by rate built successfully",
+	})
+}
+
+// analyticsRequest разбирает параметры аналитики: ?from=&to= (по умолчанию последние 12 месяцев
+// с начала месяца), ?status= через запятую и ?limit=
+func (c *EsfReportController) analyticsRequest(ctx *fiber.Ctx) (uuid.UUID, *models.EsfAnalyticsRequest, error) {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		return uuid.Nil, nil, apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+	}
+
+	to, err := queryDate(ctx, "to", time.Now())
+	if err != nil {
+		return orgID, nil, err
+	}
+	from, err := queryDate(ctx, "from", time.Date(to.Year(), to.Month()-11, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return orgID, nil, err
+	}
+
+	req := &models.EsfAnalyticsRequest{From: from, To: to, Limit: ctx.QueryInt("limit")}
+	if raw := ctx.Query("status"); raw != "" {
+		req.Statuses = strings.Split(raw, ",")
+	}
+	return orgID, req, nil
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfReportController) respondError(ctx *fiber.Ctx, err error, message string, orgID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String()})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
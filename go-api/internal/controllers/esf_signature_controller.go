@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/sirupsen/logrus"
+)
+
+// getEsfDocumentSigningPayload возвращает каноническое представление документа, которое подписывает клиент
+func (c *EsfDocumentController) getEsfDocumentSigningPayload(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetSigningPayload(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to build signing payload", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Signing payload built successfully",
+	})
+}
+
+// signEsfDocument принимает подпись документа и переводит его в статус signed
+func (c *EsfDocumentController) signEsfDocument(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfSignRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.SignDocument(actorContext(ctx), orgID, docID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to sign document", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document signed successfully",
+	})
+}
+
+// getEsfDocumentSignatures возвращает подписи документа
+func (c *EsfDocumentController) getEsfDocumentSignatures(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetDocumentSignatures(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch document signatures", orgID, docID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Document signatures retrieved successfully",
+	})
+}
+
+// verifyEsfDocumentSignatures проверяет подписи документа и изменения после подписания
+func (c *EsfDocumentController) verifyEsfDocumentSignatures(ctx *fiber.Ctx) error {
+	orgID, docID, appErr := c.resolveDocumentParams(ctx)
+	if appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.VerifyDocumentSignatures(ctx.Context(), orgID, docID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to verify document signatures", orgID, docID)
+	}
+
+	message := "Document signatures are valid"
+	switch {
+	case !result.Signed:
+		message = "Document is not signed"
+	case result.Modified:
+		message = "Document has been modified after signing"
+	case !result.Valid:
+		message = "Document signatures are not valid"
+	}
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": message,
+	})
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	repositorypostgres "github.com/rusgainew/tunduck-app/internal/repository/repository_postgres"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	serviceimpl "github.com/rusgainew/tunduck-app/internal/services/service_impl"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// EsfSigningKeyController регистрирует и отзывает ключи подписи организации.
+// Регистрируется только открытая часть ключа, документ подписывает клиент.
+type EsfSigningKeyController struct {
+	logger      *logger.Logger
+	service     services.EsfSigningKeyService
+	roleService services.RoleService
+}
+
+func NewEsfSigningKeyController(app *fiber.App, log *logrus.Logger, db *gorm.DB) {
+	controller := &EsfSigningKeyController{
+		logger:      logger.New(log),
+		service:     serviceimpl.NewEsfSigningKeyService(repositorypostgres.NewEsfSigningKeyRepositoryPostgres(db, log), log),
+		roleService: serviceimpl.NewRoleService(repositorypostgres.NewUserRepositoryPostgres(db, log), log),
+	}
+
+	controller.logger.Info(context.Background(), "EsfSigningKeyController initialized", logrus.Fields{})
+	controller.registerRoutes(app)
+}
+
+func (c *EsfSigningKeyController) registerRoutes(app *fiber.App) {
+	keyGroup := app.Group("/api/esf-signing-keys")
+
+	// Public routes
+	keyGroup.Get("/", c.getSigningKeys)
+
+	// Protected routes: личный ключ регистрирует пользователь с правом изменения документов,
+	// ключ организации - пользователь с правом изменения организации (проверяется в обработчике)
+	protected := keyGroup.Group("")
+	protected.Use(middleware.JWTMiddleware(), c.loadUserContext, rbac.RequirePermission(rbac.PermissionUpdateDocument))
+	protected.Post("/", c.registerSigningKey)
+	protected.Delete("/:id", c.revokeSigningKey)
+}
+
+// loadUserContext загружает роль пользователя из JWT для проверки разрешений RBAC
+func (c *EsfSigningKeyController) loadUserContext(ctx *fiber.Ctx) error {
+	return loadUserContext(ctx, c.logger, c.roleService)
+}
+
+// requireOrganizationKeyAccess проверяет право управлять ключами подписи организации.
+// Подпись ключом организации принимается от любого пользователя с правом изменения документов,
+// поэтому регистрировать и отзывать такие ключи может только пользователь с правом изменения организации.
+func requireOrganizationKeyAccess(ctx *fiber.Ctx, scope string) *apperror.AppError {
+	if !strings.EqualFold(strings.TrimSpace(scope), string(entity.SigningKeyScopeOrganization)) {
+		return nil
+	}
+	if userCtx := rbac.ExtractUserContext(ctx); userCtx == nil || !userCtx.HasPermission(rbac.PermissionUpdateOrganization) {
+		return apperror.New(apperror.ErrForbidden, "organization signing keys require permission to update the organization")
+	}
+	return nil
+}
+
+// getSigningKeys возвращает ключи подписи организации, включая отозванные
+func (c *EsfSigningKeyController) getSigningKeys(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.GetSigningKeys(ctx.Context(), orgID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to fetch signing keys", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Signing keys retrieved successfully",
+	})
+}
+
+// registerSigningKey регистрирует открытый ключ текущего пользователя или организации
+func (c *EsfSigningKeyController) registerSigningKey(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	var req models.EsfSigningKeyRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to parse request body", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid request format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+	if appErr := requireOrganizationKeyAccess(ctx, req.Scope); appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	result, err := c.service.RegisterSigningKey(actorContext(ctx), orgID, &req)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to register signing key", orgID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    result,
+		"message": "Signing key registered successfully",
+	})
+}
+
+// revokeSigningKey отзывает ключ подписи; сделанные им подписи остаются проверяемыми
+func (c *EsfSigningKeyController) revokeSigningKey(ctx *fiber.Ctx) error {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	id := ctx.Params("id")
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		c.logger.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		appErr := apperror.New(apperror.ErrInvalidRequest, "invalid signing key ID format")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	key, err := c.service.GetSigningKey(ctx.Context(), orgID, keyID)
+	if err != nil {
+		return c.respondError(ctx, err, "failed to revoke signing key", orgID)
+	}
+	if appErr := requireOrganizationKeyAccess(ctx, key.Scope); appErr != nil {
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	if err := c.service.RevokeSigningKey(actorContext(ctx), orgID, keyID); err != nil {
+		return c.respondError(ctx, err, "failed to revoke signing key", orgID)
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{
+		"success": true,
+		"message": "Signing key revoked successfully",
+	})
+}
+
+// respondError отправляет ошибку сервиса клиенту
+func (c *EsfSigningKeyController) respondError(ctx *fiber.Ctx, err error, message string, orgID uuid.UUID) error {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, message).WithError(err)
+	}
+	c.logger.Error(ctx.Context(), message, err, logrus.Fields{"org_id": orgID.String(), "key_id": ctx.Params("id")})
+	return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/middleware"
+	"github.com/rusgainew/tunduck-app/pkg/rbac"
+)
+
+// loadUserContext загружает роль пользователя из JWT и сохраняет контекст RBAC в запросе.
+// Вызывается после JWTMiddleware и перед rbac.RequirePermission: в токене роль не хранится.
+func loadUserContext(ctx *fiber.Ctx, log *logger.Logger, roleService services.RoleService) error {
+	userID, err := middleware.GetUserIDFromContext(ctx)
+	if err != nil {
+		appErr := apperror.New(apperror.ErrUnauthorized, "user is not authenticated")
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	role, err := roleService.GetUserRole(ctx.Context(), userID)
+	if err != nil {
+		log.Warn(ctx.Context(), "Failed to resolve user role", logrus.Fields{"user_id": userID.String(), "error": err.Error()})
+		appErr, ok := err.(*apperror.AppError)
+		if !ok || appErr.Code == apperror.ErrUserNotFound {
+			appErr = apperror.New(apperror.ErrUnauthorized, "user is not authenticated")
+		}
+		return ctx.Status(appErr.HTTPStatus).JSON(appErr.ToResponse())
+	}
+
+	rbac.SetUserContext(ctx, userID, role)
+	return ctx.Next()
+}
+
+// actorContext добавляет в контекст автора изменения из JWT (для истории ревизий)
+func actorContext(ctx *fiber.Ctx) context.Context {
+	reqCtx := context.Context(ctx.Context())
+	if userID, err := middleware.GetUserIDFromContext(ctx); err == nil {
+		reqCtx = logger.WithContext(reqCtx, logger.UserIDKey, userID.String())
+	}
+	if username, err := middleware.GetUsernameFromContext(ctx); err == nil {
+		reqCtx = logger.WithContext(reqCtx, logger.UsernameKey, username)
+	}
+	return reqCtx
+}
+
+// resolveOrgID достает идентификатор организации из заголовка X-Org-Id или query orgId.
+func resolveOrgID(ctx *fiber.Ctx) (uuid.UUID, error) {
+	raw := ctx.Get("X-Org-Id")
+	if raw == "" {
+		raw = ctx.Query("orgId")
+	}
+	if raw == "" {
+		return uuid.Nil, fmt.Errorf("organization id is required (header X-Org-Id or query orgId)")
+	}
+	orgID, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid organization id: %w", err)
+	}
+	return orgID, nil
+}
+
+// resolveDocumentParams достает идентификаторы организации и документа (параметр :id) из запроса
+func resolveDocumentParams(ctx *fiber.Ctx, log *logger.Logger) (uuid.UUID, uuid.UUID, *apperror.AppError) {
+	orgID, err := resolveOrgID(ctx)
+	if err != nil {
+		log.Warn(ctx.Context(), "Failed to resolve org ID", logrus.Fields{"error": err.Error()})
+		return uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid organization ID")
+	}
+
+	id := ctx.Params("id")
+	docID, err := uuid.Parse(id)
+	if err != nil {
+		log.Warn(ctx.Context(), "Invalid UUID format", logrus.Fields{"id": id})
+		return uuid.Nil, uuid.Nil, apperror.New(apperror.ErrInvalidRequest, "invalid document ID format")
+	}
+
+	return orgID, docID, nil
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// EsfGateway отправляет документы ЭСФ в информационную систему налоговой службы.
+// token - токен организации (EstOrganization.Token), от имени которой выполняется запрос.
+type EsfGateway interface {
+	// CreateInvoice регистрирует новую счет-фактуру
+	CreateInvoice(ctx context.Context, token string, req *models.EsfCreateDocumentRequest) (*models.EsfCreateDocumentResponse, error)
+
+	// EditInvoice редактирует ранее зарегистрированную счет-фактуру
+	EditInvoice(ctx context.Context, token string, documentUUID string, req *models.EsfCreateDocumentRequest) (*models.EsfCreateDocumentResponse, error)
+
+	// CancelInvoice аннулирует ранее зарегистрированную счет-фактуру
+	CancelInvoice(ctx context.Context, token string, documentUUID string, reason string) error
+}
+
+// Пути API налоговой службы
+const (
+	createInvoicePath = "/api/command/invoice/create"
+	editInvoicePath   = "/api/command/invoice/edit/"
+	cancelInvoicePath = "/api/command/invoice/cancel/"
+)
+
+// EsfCancelInvoiceRequest запрос на аннулирование счет-фактуры
+type EsfCancelInvoiceRequest struct {
+	Reason string `json:"reason"`
+}
+
+// NewEsfGatewayFromEnv создает шлюз по переменным окружения:
+//   - ESF_GATEWAY_MOCK=true - запускает встроенный mock-сервер (для разработки и тестов);
+//   - ESF_GATEWAY_URL - адрес API налоговой службы;
+//   - ESF_GATEWAY_TIMEOUT - таймаут запросов в секундах (по умолчанию 30).
+//
+// Если шлюз не настроен, возвращает nil: документы переводятся в статус submitted без отправки.
+func NewEsfGatewayFromEnv(log *logrus.Logger) EsfGateway {
+	timeout := 30 * time.Second
+	if raw := os.Getenv("ESF_GATEWAY_TIMEOUT"); raw != "" {
+		if seconds, err := strconv.Atoi(raw); err == nil && seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if mockEnabled, _ := strconv.ParseBool(os.Getenv("ESF_GATEWAY_MOCK")); mockEnabled {
+		server := NewMockEsfServer()
+		log.WithField("url", server.URL()).Warn("ESF gateway is running against the in-process mock server")
+		return NewEsfHTTPClient(server.URL(), timeout, log)
+	}
+
+	baseURL := os.Getenv("ESF_GATEWAY_URL")
+	if baseURL == "" {
+		log.Info("ESF gateway is not configured (ESF_GATEWAY_URL is empty)")
+		return nil
+	}
+
+	return NewEsfHTTPClient(baseURL, timeout, log)
+}
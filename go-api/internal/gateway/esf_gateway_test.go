@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+func newTestInvoice() *models.EsfCreateDocumentRequest {
+	return &models.EsfCreateDocumentRequest{
+		ContractorTin:  "01234567890123",
+		TaxRateVATCode: "1",
+		CatalogEntries: []models.EsfEntriesModel{
+			{UnitClassificationCode: "796", SalesTaxCode: "001", Quantity: 1, Price: money.MustParse("100")},
+		},
+	}
+}
+
+// TestEsfHTTPClient_InvoiceLifecycle tests create, edit and cancel against the mock server
+func TestEsfHTTPClient_InvoiceLifecycle(t *testing.T) {
+	server := NewMockEsfServer()
+	defer server.Close()
+
+	client := NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New())
+	ctx := context.Background()
+
+	created, err := client.CreateInvoice(ctx, "org-token", newTestInvoice())
+	require.NoError(t, err)
+	require.NotEmpty(t, created.DocumentUuid)
+	assert.NotEmpty(t, created.ResponseId)
+
+	edit := newTestInvoice()
+	edit.Comment = "updated"
+	edited, err := client.EditInvoice(ctx, "org-token", created.DocumentUuid, edit)
+	require.NoError(t, err)
+	assert.Equal(t, created.DocumentUuid, edited.DocumentUuid)
+
+	require.NoError(t, client.CancelInvoice(ctx, "org-token", created.DocumentUuid, "duplicate"))
+
+	inv, ok := server.Invoice(created.DocumentUuid)
+	require.True(t, ok)
+	assert.Equal(t, 2, inv.Revision)
+	assert.Equal(t, "updated", inv.Request.Comment)
+	assert.True(t, inv.Cancelled)
+	assert.Equal(t, "duplicate", inv.CancelReason)
+}
+
+// TestEsfHTTPClient_Errors tests mapping of API errors to AppError
+func TestEsfHTTPClient_Errors(t *testing.T) {
+	server := NewMockEsfServer()
+	defer server.Close()
+
+	client := NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New())
+	ctx := context.Background()
+
+	t.Run("Empty token", func(t *testing.T) {
+		_, err := client.CreateInvoice(ctx, "", newTestInvoice())
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrExternalService, appErr.Code)
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		req := newTestInvoice()
+		req.ContractorTin = ""
+		_, err := client.CreateInvoice(ctx, "org-token", req)
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrExternalService, appErr.Code)
+		assert.Contains(t, appErr.Details, "contractorTin")
+	})
+
+	t.Run("Unknown invoice", func(t *testing.T) {
+		err := client.CancelInvoice(ctx, "org-token", "missing", "reason")
+		require.Error(t, err)
+	})
+
+	t.Run("Foreign organization", func(t *testing.T) {
+		created, err := client.CreateInvoice(ctx, "org-a", newTestInvoice())
+		require.NoError(t, err)
+
+		_, err = client.EditInvoice(ctx, "org-b", created.DocumentUuid, newTestInvoice())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+// esfErrorResponse формат ошибки, возвращаемой API налоговой службы
+type esfErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// esfHTTPClient реализует EsfGateway поверх HTTP API налоговой службы
+type esfHTTPClient struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *logger.Logger
+}
+
+// NewEsfHTTPClient создает HTTP клиент API налоговой службы
+func NewEsfHTTPClient(baseURL string, timeout time.Duration, log *logrus.Logger) EsfGateway {
+	return &esfHTTPClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		logger:     logger.New(log),
+	}
+}
+
+// CreateInvoice регистрирует новую счет-фактуру
+func (c *esfHTTPClient) CreateInvoice(ctx context.Context, token string, req *models.EsfCreateDocumentRequest) (*models.EsfCreateDocumentResponse, error) {
+	var resp models.EsfCreateDocumentResponse
+	if err := c.do(ctx, http.MethodPost, createInvoicePath, token, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// EditInvoice редактирует ранее зарегистрированную счет-фактуру
+func (c *esfHTTPClient) EditInvoice(ctx context.Context, token string, documentUUID string, req *models.EsfCreateDocumentRequest) (*models.EsfCreateDocumentResponse, error) {
+	var resp models.EsfCreateDocumentResponse
+	if err := c.do(ctx, http.MethodPut, editInvoicePath+url.PathEscape(documentUUID), token, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CancelInvoice аннулирует ранее зарегистрированную счет-фактуру
+func (c *esfHTTPClient) CancelInvoice(ctx context.Context, token string, documentUUID string, reason string) error {
+	return c.do(ctx, http.MethodPost, cancelInvoicePath+url.PathEscape(documentUUID), token, &EsfCancelInvoiceRequest{Reason: reason}, nil)
+}
+
+// do выполняет запрос к API и декодирует ответ в out (если out не nil)
+func (c *esfHTTPClient) do(ctx context.Context, method, path, token string, body interface{}, out interface{}) error {
+	if token == "" {
+		return apperror.New(apperror.ErrExternalService, "organization token is required to call ESF API")
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return apperror.New(apperror.ErrInternal, "failed to encode ESF request").WithError(err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return apperror.New(apperror.ErrInternal, "failed to build ESF request").WithError(err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+
+	c.logger.Debug(ctx, "Sending request to ESF API", logrus.Fields{"method": method, "path": path})
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		c.logger.Error(ctx, "ESF API request failed", err, logrus.Fields{"method": method, "path": path})
+		return apperror.New(apperror.ErrExternalService, "ESF API is unavailable").WithError(err)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, 1<<20))
+	if err != nil {
+		return apperror.New(apperror.ErrExternalService, "failed to read ESF API response").WithError(err)
+	}
+
+	if httpResp.StatusCode < 200 || httpResp.StatusCode >= 300 {
+		var apiErr esfErrorResponse
+		_ = json.Unmarshal(respBody, &apiErr)
+		details := apiErr.Message
+		if details == "" {
+			details = strings.TrimSpace(string(respBody))
+		}
+
+		c.logger.Warn(ctx, "ESF API returned an error", logrus.Fields{
+			"method": method,
+			"path":   path,
+			"status": httpResp.StatusCode,
+			"code":   apiErr.Code,
+		})
+
+		return apperror.NewWithDetails(apperror.ErrExternalService,
+			fmt.Sprintf("ESF API rejected the request (HTTP %d)", httpResp.StatusCode), details)
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return apperror.New(apperror.ErrExternalService, "failed to decode ESF API response").WithError(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// MockInvoice счет-фактура, зарегистрированная в mock-сервере
+type MockInvoice struct {
+	DocumentUUID string
+	Token        string
+	Request      models.EsfCreateDocumentRequest
+	Revision     int
+	Cancelled    bool
+	CancelReason string
+}
+
+// MockEsfServer встроенный HTTP сервер, имитирующий API налоговой службы.
+// Позволяет проверить полный цикл отправки документов без доступа к реальному API.
+type MockEsfServer struct {
+	server   *httptest.Server
+	mu       sync.RWMutex
+	invoices map[string]*MockInvoice
+}
+
+// NewMockEsfServer запускает mock-сервер на случайном локальном порту
+func NewMockEsfServer() *MockEsfServer {
+	m := &MockEsfServer{invoices: make(map[string]*MockInvoice)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(createInvoicePath, m.handleCreate)
+	mux.HandleFunc(editInvoicePath, m.handleEdit)
+	mux.HandleFunc(cancelInvoicePath, m.handleCancel)
+
+	m.server = httptest.NewServer(m.authenticate(mux))
+	return m
+}
+
+// URL возвращает базовый адрес mock-сервера
+func (m *MockEsfServer) URL() string {
+	return m.server.URL
+}
+
+// Close останавливает mock-сервер
+func (m *MockEsfServer) Close() {
+	m.server.Close()
+}
+
+// Invoice возвращает копию зарегистрированной счет-фактуры
+func (m *MockEsfServer) Invoice(documentUUID string) (MockInvoice, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inv, ok := m.invoices[documentUUID]
+	if !ok {
+		return MockInvoice{}, false
+	}
+	return *inv, true
+}
+
+// InvoiceCount возвращает число зарегистрированных счетов-фактур
+func (m *MockEsfServer) InvoiceCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.invoices)
+}
+
+// authenticate проверяет наличие токена организации
+func (m *MockEsfServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || token == r.Header.Get("Authorization") {
+			writeMockError(w, http.StatusUnauthorized, "UNAUTHORIZED", "organization token is required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (m *MockEsfServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMockError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "use POST")
+		return
+	}
+
+	var req models.EsfCreateDocumentRequest
+	if !decodeMockRequest(w, r, &req) {
+		return
+	}
+
+	inv := &MockInvoice{
+		DocumentUUID: uuid.New().String(),
+		Token:        bearerToken(r),
+		Request:      req,
+		Revision:     1,
+	}
+
+	m.mu.Lock()
+	m.invoices[inv.DocumentUUID] = inv
+	m.mu.Unlock()
+
+	writeMockJSON(w, http.StatusOK, models.EsfCreateDocumentResponse{
+		ResponseId:   uuid.New().String(),
+		DocumentUuid: inv.DocumentUUID,
+	})
+}
+
+func (m *MockEsfServer) handleEdit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeMockError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "use PUT")
+		return
+	}
+
+	var req models.EsfCreateDocumentRequest
+	if !decodeMockRequest(w, r, &req) {
+		return
+	}
+
+	documentUUID := strings.TrimPrefix(r.URL.Path, editInvoicePath)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inv, ok := m.ownedInvoice(w, r, documentUUID)
+	if !ok {
+		return
+	}
+	if inv.Cancelled {
+		writeMockError(w, http.StatusConflict, "INVOICE_CANCELLED", "cancelled invoice cannot be edited")
+		return
+	}
+
+	inv.Request = req
+	inv.Revision++
+
+	writeMockJSON(w, http.StatusOK, models.EsfCreateDocumentResponse{
+		ResponseId:   uuid.New().String(),
+		DocumentUuid: inv.DocumentUUID,
+	})
+}
+
+func (m *MockEsfServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMockError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "use POST")
+		return
+	}
+
+	var req EsfCancelInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMockError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid JSON body")
+		return
+	}
+
+	documentUUID := strings.TrimPrefix(r.URL.Path, cancelInvoicePath)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inv, ok := m.ownedInvoice(w, r, documentUUID)
+	if !ok {
+		return
+	}
+
+	inv.Cancelled = true
+	inv.CancelReason = req.Reason
+	w.WriteHeader(http.StatusOK)
+}
+
+// ownedInvoice находит счет-фактуру и проверяет, что она принадлежит организации из токена.
+// Вызывается под блокировкой m.mu.
+func (m *MockEsfServer) ownedInvoice(w http.ResponseWriter, r *http.Request, documentUUID string) (*MockInvoice, bool) {
+	inv, ok := m.invoices[documentUUID]
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "INVOICE_NOT_FOUND", "invoice not found")
+		return nil, false
+	}
+	if inv.Token != bearerToken(r) {
+		writeMockError(w, http.StatusForbidden, "FORBIDDEN", "invoice belongs to another organization")
+		return nil, false
+	}
+	return inv, true
+}
+
+// decodeMockRequest декодирует тело счет-фактуры и проверяет обязательные поля
+func decodeMockRequest(w http.ResponseWriter, r *http.Request, req *models.EsfCreateDocumentRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeMockError(w, http.StatusBadRequest, "INVALID_REQUEST", "invalid JSON body")
+		return false
+	}
+	if req.ContractorTin == "" {
+		writeMockError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "contractorTin is required")
+		return false
+	}
+	if len(req.CatalogEntries) == 0 {
+		writeMockError(w, http.StatusUnprocessableEntity, "VALIDATION_ERROR", "catalogEntries must not be empty")
+		return false
+	}
+	return true
+}
+
+func bearerToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+func writeMockJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeMockError(w http.ResponseWriter, status int, code, message string) {
+	writeMockJSON(w, status, esfErrorResponse{Code: code, Message: message})
+}
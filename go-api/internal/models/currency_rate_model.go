@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// CurrencyRateModel официальный курс валюты к сому
+type CurrencyRateModel struct {
+	CurrencyCode string    `json:"currencyCode"`
+	Date         time.Time `json:"date"`
+	// Количество сомов за Nominal единиц валюты
+	Rate    float64 `json:"rate"`
+	Nominal int     `json:"nominal"`
+	// Курс за одну единицу валюты, используется в документах
+	UnitRate float64 `json:"unitRate"`
+	Source   string  `json:"source,omitempty"`
+}
+
+// CurrencyRateImportResponse результат загрузки курсов
+type CurrencyRateImportResponse struct {
+	Imported   int        `json:"imported"`
+	Currencies []string   `json:"currencies"`
+	From       *time.Time `json:"from,omitempty"`
+	To         *time.Time `json:"to,omitempty"`
+	Source     string     `json:"source"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// EsfAnalyticsRequest период и статусы документов для аналитики
+type EsfAnalyticsRequest struct {
+	From time.Time
+	To   time.Time
+	// Статусы учитываемых документов; по умолчанию подписанные, отправленные и принятые
+	Statuses []string
+	// Число покупателей в рейтинге; по умолчанию 10
+	Limit int
+}
+
+// EsfAnalyticsAmounts суммы документов группы в сомах
+type EsfAnalyticsAmounts struct {
+	Documents      int          `json:"documents"`
+	TaxableBase    money.Amount `json:"taxableBase"`
+	VatAmount      money.Amount `json:"vatAmount"`
+	SalesTaxAmount money.Amount `json:"salesTaxAmount"`
+	TotalAmount    money.Amount `json:"totalAmount"`
+	// Средняя сумма документа с налогами
+	AverageAmount money.Amount `json:"averageAmount"`
+}
+
+// EsfAnalyticsSummary итоги документов за период
+type EsfAnalyticsSummary struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Currency string    `json:"currency"`
+	Statuses []string  `json:"statuses"`
+	EsfAnalyticsAmounts
+}
+
+// EsfAnalyticsMonth итоги месяца; месяцы без документов возвращаются с нулевыми суммами
+type EsfAnalyticsMonth struct {
+	// Месяц в формате YYYY-MM
+	Month string `json:"month"`
+	EsfAnalyticsAmounts
+}
+
+// EsfAnalyticsContractor итоги покупателя
+type EsfAnalyticsContractor struct {
+	ContractorTin  string `json:"contractorTin"`
+	ContractorName string `json:"contractorName,omitempty"`
+	// Доля в сумме с налогами всех документов периода, %
+	Share float64 `json:"share"`
+	EsfAnalyticsAmounts
+}
+
+// EsfAnalyticsVatRate итоги ставки НДС
+type EsfAnalyticsVatRate struct {
+	VatCode    string  `json:"vatCode"`
+	VatName    string  `json:"vatName"`
+	VatPercent float64 `json:"vatPercent"`
+	EsfAnalyticsAmounts
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// EsfApprovalPolicyRequest политика согласования исходящих документов организации.
+// Без порога и видов операции согласования требуют все исходящие документы.
+type EsfApprovalPolicyRequest struct {
+	Enabled bool `json:"enabled"`
+	// Документы с суммой в сомах больше порога требуют согласования; 0 - сумма не учитывается
+	ThresholdAmount money.Amount `json:"thresholdAmount"`
+	// Коды видов операции, документы которых требуют согласования независимо от суммы
+	OperationTypeCodes []string `json:"operationTypeCodes"`
+	// Число согласований разных пользователей с правом approve:document
+	RequiredApprovals int `json:"requiredApprovals"`
+}
+
+// EsfApprovalPolicyModel политика согласования организации
+type EsfApprovalPolicyModel struct {
+	Enabled            bool         `json:"enabled"`
+	ThresholdAmount    money.Amount `json:"thresholdAmount"`
+	OperationTypeCodes []string     `json:"operationTypeCodes"`
+	RequiredApprovals  int          `json:"requiredApprovals"`
+	UpdatedAt          *time.Time   `json:"updatedAt,omitempty"`
+	UpdatedBy          string       `json:"updatedBy,omitempty"`
+}
+
+// EsfApprovalDecisionRequest решение согласующего; причина обязательна при отклонении
+type EsfApprovalDecisionRequest struct {
+	Reason string `json:"reason"`
+	// SHA-256 содержимого, которое видел согласующий (digest из статуса согласования);
+	// если документ изменился, решение не сохраняется
+	Digest string `json:"digest"`
+}
+
+// EsfApprovalModel решение согласующего по документу
+type EsfApprovalModel struct {
+	ID           uuid.UUID `json:"id"`
+	Decision     string    `json:"decision"`
+	Reason       string    `json:"reason,omitempty"`
+	Digest       string    `json:"digest"`
+	Final        bool      `json:"final"`
+	ApproverID   string    `json:"approverId"`
+	ApproverName string    `json:"approverName"`
+	CreatedAt    time.Time `json:"createdAt"`
+	// Решение относится к текущему содержимому документа и учитывается в итоге
+	Current bool `json:"current"`
+}
+
+// EsfApprovalStatusModel итог согласования текущего содержимого документа
+type EsfApprovalStatusModel struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	// not_required, pending, approved или rejected
+	Status string `json:"status"`
+	// Причины, по которым документ требует согласования
+	Reasons           []string `json:"reasons,omitempty"`
+	RequiredApprovals int      `json:"requiredApprovals"`
+	Approvals         int      `json:"approvals"`
+	// SHA-256 канонического представления документа, к которому относятся решения
+	Digest    string             `json:"digest"`
+	Decisions []EsfApprovalModel `json:"decisions"`
+}
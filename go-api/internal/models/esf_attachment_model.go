@@ -0,0 +1,29 @@
+package models
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EsfAttachmentModel файл, приложенный к документу
+type EsfAttachmentModel struct {
+	ID          uuid.UUID `json:"id"`
+	DocumentID  uuid.UUID `json:"documentId"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	// SHA-256 содержимого в шестнадцатеричном виде
+	Checksum   string    `json:"checksum"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UploadedBy string    `json:"uploadedBy,omitempty"`
+}
+
+// EsfAttachmentUpload загружаемый файл
+type EsfAttachmentUpload struct {
+	FileName string
+	Content  io.Reader
+	// Ожидаемый SHA-256 содержимого (необязателен); при несовпадении файл отклоняется
+	Checksum string
+}
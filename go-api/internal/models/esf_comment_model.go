@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EsfCommentRequest текст комментария к документу.
+// Пользователи упоминаются в тексте через @username.
+type EsfCommentRequest struct {
+	Body string `json:"body"`
+	// false Комментарий, на который дается ответ; ответ на ответ добавляется в ту же ветку
+	ParentId *uuid.UUID `json:"parentId,omitempty"`
+}
+
+// EsfCommentModel комментарий к документу
+type EsfCommentModel struct {
+	ID         uuid.UUID  `json:"id"`
+	DocumentID uuid.UUID  `json:"documentId"`
+	ParentID   *uuid.UUID `json:"parentId,omitempty"`
+	AuthorID   string     `json:"authorId"`
+	AuthorName string     `json:"authorName,omitempty"`
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	EditedAt   *time.Time `json:"editedAt,omitempty"`
+	// Упомянутые пользователи в порядке упоминания
+	Mentions []EsfCommentMentionModel `json:"mentions"`
+}
+
+// EsfCommentMentionModel упомянутый в комментарии пользователь
+type EsfCommentMentionModel struct {
+	UserID   string `json:"userId"`
+	Username string `json:"username"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EsfContractorBankAccountModel банковский счет контрагента
+type EsfContractorBankAccountModel struct {
+	Account  string `json:"account"`
+	BankName string `json:"bankName"`
+	Bic      string `json:"bic"`
+	// Счет, подставляемый в документы, если счет покупателя не указан
+	IsDefault bool `json:"isDefault"`
+}
+
+// EsfContractorModel контрагент из справочника организации
+type EsfContractorModel struct {
+	// Только чтение
+	ID *uuid.UUID `json:"id,omitempty"`
+	// true Наименование
+	Name string `json:"name"`
+	// true ИНН; для резидентов проверяются формат и дата регистрации
+	Tin string `json:"tin"`
+	// true Субъект Кыргызской Республики
+	IsResident bool `json:"isResident"`
+	// Код страны; обязателен для нерезидентов
+	CountryCode   string                          `json:"countryCode"`
+	ContactPerson string                          `json:"contactPerson"`
+	Phone         string                          `json:"phone"`
+	Email         string                          `json:"email"`
+	Address       string                          `json:"address"`
+	BankAccounts  []EsfContractorBankAccountModel `json:"bankAccounts"`
+	// Только чтение
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "github.com/rusgainew/tunduck-app/pkg/apperror"
+
+const (
+	// BatchModeAtomic пакет применяется целиком: при любой ошибке не изменяется ни один документ
+	BatchModeAtomic = "atomic"
+	// BatchModeBestEffort каждый элемент пакета применяется независимо от остальных
+	BatchModeBestEffort = "bestEffort"
+)
+
+// Статусы элементов пакета
+const (
+	BatchItemCreated = "created"
+	BatchItemUpdated = "updated"
+	BatchItemFailed  = "failed"
+	// Элемент корректен, но не применен из-за ошибок в других элементах (режим atomic)
+	BatchItemSkipped = "skipped"
+)
+
+// EsfDocumentBatchRequest пакет создания и изменения документов.
+// Элемент без id создает документ, элемент с id изменяет существующий черновик.
+type EsfDocumentBatchRequest struct {
+	// atomic (по умолчанию) или bestEffort
+	Mode  string                   `json:"mode"`
+	Items []EsfEditDocumentRequest `json:"items"`
+}
+
+// EsfDocumentBatchItemResult результат обработки элемента пакета
+type EsfDocumentBatchItemResult struct {
+	// Номер элемента в запросе (с 0)
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	// Идентификатор созданного или измененного документа
+	DocumentUuid string `json:"documentUuid,omitempty"`
+	// Код документа во внешней системе, для сопоставления результатов на стороне интеграции
+	OwnedCrmReceiptCode string                  `json:"ownedCrmReceiptCode,omitempty"`
+	Error               *apperror.ErrorResponse `json:"error,omitempty"`
+}
+
+// EsfDocumentBatchResponse результат пакетной операции
+type EsfDocumentBatchResponse struct {
+	Mode      string                       `json:"mode"`
+	Total     int                          `json:"total"`
+	Succeeded int                          `json:"succeeded"`
+	Failed    int                          `json:"failed"`
+	Items     []EsfDocumentBatchItemResult `json:"items"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// EsfCloneRequest запрос на копирование документа в новый черновик
+type EsfCloneRequest struct {
+	// false Организация, в которой создается копия; по умолчанию организация исходного документа.
+	// Другую организацию может указать только пользователь с доступом ко всем организациям.
+	TargetOrgId *uuid.UUID `json:"targetOrgId,omitempty"`
+	// false Поля документа, заменяющие скопированные, в формате запроса создания документа.
+	// catalogEntries заменяет все позиции; поля только для чтения игнорируются.
+	Overrides json.RawMessage `json:"overrides,omitempty"`
+}
+
+// EsfCloneResponse созданная копия документа
+type EsfCloneResponse struct {
+	DocumentUuid       string    `json:"documentUuid"`
+	OrgId              uuid.UUID `json:"orgId"`
+	SourceDocumentUuid string    `json:"sourceDocumentUuid"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// EsfCorrectionEntryRequest изменение позиции исходного документа
+type EsfCorrectionEntryRequest struct {
+	// true Позиция исходного документа
+	OriginalEntryId uuid.UUID `json:"originalEntryId" valid:"required"`
+	// false Изменение количества (отрицательное - уменьшение)
+	QuantityDelta float64 `json:"quantityDelta"`
+	// false Изменение цены (отрицательное - уменьшение)
+	PriceDelta money.Amount `json:"priceDelta"`
+}
+
+// EsfCorrectionRequest запрос на создание корректировочной счет-фактуры
+type EsfCorrectionRequest struct {
+	// true Причина корректировки
+	Reason string `json:"reason" valid:"required"`
+	// true Корректируемые позиции
+	Entries []EsfCorrectionEntryRequest `json:"entries" valid:"required"`
+}
+
+// EsfNetEntryModel состояние позиции исходного документа с учетом всех корректировок
+type EsfNetEntryModel struct {
+	EntryId                uuid.UUID    `json:"entryId"`
+	UnitClassificationCode string       `json:"unitClassificationCode"`
+	SalesTaxCode           string       `json:"salesTaxCode"`
+	Quantity               float64      `json:"quantity"`
+	Price                  money.Amount `json:"price"`
+	VatAmount              money.Amount `json:"vatAmount"`
+	SalesTaxAmount         money.Amount `json:"salesTaxAmount"`
+	AmountWithoutTaxes     money.Amount `json:"amountWithoutTaxes"`
+	TotalAmount            money.Amount `json:"totalAmount"`
+}
+
+// EsfNetTotalsModel итоговые суммы цепочки документов
+type EsfNetTotalsModel struct {
+	AmountWithoutTaxes money.Amount `json:"amountWithoutTaxes"`
+	VatAmount          money.Amount `json:"vatAmount"`
+	SalesTaxAmount     money.Amount `json:"salesTaxAmount"`
+	TotalAmount        money.Amount `json:"totalAmount"`
+}
+
+// EsfCorrectionChainResponse исходный документ, все его корректировки и итоговые суммы.
+// В итогах учитываются только отправленные и принятые корректировки; изменения
+// неотправленных корректировок выводятся отдельно.
+type EsfCorrectionChainResponse struct {
+	Original    EsfCreateDocumentRequest   `json:"original"`
+	Corrections []EsfCreateDocumentRequest `json:"corrections"`
+	NetEntries  []EsfNetEntryModel         `json:"netEntries"`
+	NetTotals   EsfNetTotalsModel          `json:"netTotals"`
+	// Неотправленные корректировки и сумма их изменений
+	PendingCorrectionIds []uuid.UUID       `json:"pendingCorrectionIds"`
+	PendingTotals        EsfNetTotalsModel `json:"pendingTotals"`
+}
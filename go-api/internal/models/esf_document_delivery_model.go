@@ -0,0 +1,13 @@
+package models
+
+import "github.com/google/uuid"
+
+// EsfDeliveryResponse результат доставки исходящего документа покупателю на платформе
+type EsfDeliveryResponse struct {
+	DocumentUuid string `json:"documentUuid"`
+	// Организация покупателя и входящая копия документа в ней
+	BuyerOrgId        uuid.UUID `json:"buyerOrgId"`
+	BuyerDocumentUuid string    `json:"buyerDocumentUuid"`
+	// Решение покупателя: delivered, accepted, disputed, cancelled
+	BuyerStatus string `json:"buyerStatus"`
+}
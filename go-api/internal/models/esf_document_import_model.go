@@ -0,0 +1,44 @@
+package models
+
+// EsfDocumentImportResponse результат импорта документов из файла.
+// Документы импортируются целиком: при любой ошибке не создается ни один.
+type EsfDocumentImportResponse struct {
+	Imported  int                         `json:"imported"`
+	Documents []EsfCreateDocumentResponse `json:"documents"`
+}
+
+// EsfTableImportOptions параметры импорта документов из CSV или XLSX
+type EsfTableImportOptions struct {
+	// csv или xlsx; если не задан, определяется по расширению файла
+	Format string `json:"format"`
+	// Сопоставление полей колонкам файла: имя поля -> заголовок колонки.
+	// Поля без сопоставления ищутся в колонке с заголовком, равным имени поля.
+	// Строки с одинаковым значением documentKey объединяются в один документ.
+	Mapping map[string]string `json:"mapping"`
+	// Разделитель CSV; по умолчанию определяется автоматически
+	Delimiter string `json:"delimiter"`
+	// Лист XLSX; по умолчанию первый
+	Sheet string `json:"sheet"`
+	// Только проверка без создания документов
+	DryRun bool `json:"dryRun"`
+}
+
+// EsfImportRowError ошибка в строке файла импорта
+type EsfImportRowError struct {
+	// Номер строки в файле; строка 1 - заголовки
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// EsfTableImportReport отчет об импорте CSV/XLSX
+type EsfTableImportReport struct {
+	DryRun    bool                `json:"dryRun"`
+	Valid     bool                `json:"valid"`
+	Rows      int                 `json:"rows"`
+	Documents int                 `json:"documents"`
+	Errors    []EsfImportRowError `json:"errors"`
+	// Созданные документы; заполняется только при импорте без dryRun
+	Imported []EsfCreateDocumentResponse `json:"imported,omitempty"`
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
 )
 
 // Create document
@@ -11,8 +12,32 @@ import (
 // METHOD: POST
 // PATH: /api/command/invoice/create
 type EsfCreateDocumentRequest struct {
+	// Только чтение: идентификатор документа, заполняется при выдаче
+	ID *uuid.UUID `json:"id,omitempty"`
+	// Номер документа: исходящему присваивается при создании (например, INV-2026-000123),
+	// для входящего указывается номер, присвоенный поставщиком
+	Number string `json:"number,omitempty"`
+	// Только чтение: статус жизненного цикла документа
+	Status string `json:"status,omitempty"`
+	// Только чтение: идентификатор документа в налоговой службе
+	ExternalDocumentUuid string `json:"externalDocumentUuid,omitempty"`
+	// Только чтение: исходный документ и причина корректировки
+	OriginalDocumentId *uuid.UUID `json:"originalDocumentId,omitempty"`
+	CorrectionReason   string     `json:"correctionReason,omitempty"`
 	// false Наименование иностранца или Наименование на иностранном языке
 	ForeignName string `json:"foreignName"`
+	// false Направление: outgoing (по умолчанию) - документ организации, incoming - документ поставщика.
+	// Не изменяется после создания
+	Direction string `json:"direction,omitempty"`
+	// ИНН и наименование поставщика; обязательны для входящего документа
+	SupplierTin  string `json:"supplierTin,omitempty"`
+	SupplierName string `json:"supplierName,omitempty"`
+	// Только чтение: связанный документ другой организации платформы и решение покупателя
+	// по доставленному документу (delivered, accepted, disputed, cancelled)
+	CounterpartyOrgId      *uuid.UUID `json:"counterpartyOrgId,omitempty"`
+	CounterpartyDocumentId *uuid.UUID `json:"counterpartyDocumentId,omitempty"`
+	BuyerStatus            string     `json:"buyerStatus,omitempty"`
+	BuyerStatusReason      string     `json:"buyerStatusReason,omitempty"`
 	// false Отправить от имени филиала
 	IsBranchDataSent bool `json:"isBranchDataSent"`
 	// false Цена без налогов
@@ -31,6 +56,8 @@ type EsfCreateDocumentRequest struct {
 	DeliveryTypeCode string `json:"deliveryTypeCode"`
 	// false Субъект Кыргызской Республики
 	IsResident bool `json:"isResident"`
+	// false Контрагент из справочника; ИНН, резидентство, страна и счет покупателя заполняются из него
+	ContractorId *uuid.UUID `json:"contractorId,omitempty"`
 	// false ИНН покупателя
 	ContractorTin string `json:"contractorTin"`
 	// false Номер банковского счета поставщика
@@ -44,9 +71,9 @@ type EsfCreateDocumentRequest struct {
 	// false Курс валюты к сому
 	CurrencyRate *float64 `json:"currencyRate"`
 	// false Общая стоимость в валюте
-	TotalCurrencyValue *float64 `json:"totalCurrencyValue"`
+	TotalCurrencyValue *money.Amount `json:"totalCurrencyValue"`
 	// false Общая стоимость в валюте без налогов
-	TotalCurrencyValueWithoutTaxes *float64 `json:"totalCurrencyValueWithoutTaxes"`
+	TotalCurrencyValueWithoutTaxes *money.Amount `json:"totalCurrencyValueWithoutTaxes"`
 	// false Номер договора на поставку
 	SupplyContractNumber string `json:"supplyContractNumber"`
 	// false Дата договора на поставку
@@ -62,19 +89,19 @@ type EsfCreateDocumentRequest struct {
 	// false Товары и услуги
 	CatalogEntries []EsfEntriesModel `json:"catalogEntries"`
 	// false Начальные остатки,сальдо на начало периода
-	OpeningBalances *float64 `json:"openingBalances"`
+	OpeningBalances *money.Amount `json:"openingBalances"`
 	// false Начисленные взносы
-	AssessedContributionsAmount *float64 `json:"assessedContributionsAmount"`
+	AssessedContributionsAmount *money.Amount `json:"assessedContributionsAmount"`
 	// false Поступления,оплачено
-	PaidAmount *float64 `json:"paidAmount"`
+	PaidAmount *money.Amount `json:"paidAmount"`
 	// false Штрафы
-	PenaltiesAmount *float64 `json:"penaltiesAmount"`
+	PenaltiesAmount *money.Amount `json:"penaltiesAmount"`
 	// false пени
-	FinesAmount *float64 `json:"finesAmount"`
+	FinesAmount *money.Amount `json:"finesAmount"`
 	// false Конечные остатки,сальдо на конец периода
-	ClosingBalances *float64 `json:"closingBalances"`
+	ClosingBalances *money.Amount `json:"closingBalances"`
 	// false Сумма к оплате
-	AmountToBePaid *float64 `json:"amountToBePaid"`
+	AmountToBePaid *money.Amount `json:"amountToBePaid"`
 	// false Лицевой счет
 	PersonalAccountNumber string `json:"personalAccountNumber"`
 }
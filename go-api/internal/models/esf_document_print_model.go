@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// EsfPrintTemplateRequest загрузка печатной формы организации
+type EsfPrintTemplateRequest struct {
+	// Шаблон html/template; данные шаблона описаны в EsfPrintData
+	Content string `json:"content"`
+}
+
+// EsfPrintTemplateModel печатная форма организации
+type EsfPrintTemplateModel struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	// true, если организация использует встроенную форму
+	IsDefault bool       `json:"isDefault"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	UpdatedBy string     `json:"updatedBy,omitempty"`
+}
+
+// EsfPrintData данные, доступные в печатной форме документа.
+//
+// Кроме полей доступны функции: money (сумма "1 120,00"), quantity (количество
+// без лишних нулей), date (дата "02.01.2006") и amountInWords (сумма прописью
+// в валюте документа).
+type EsfPrintData struct {
+	Document     EsfCreateDocumentRequest
+	Organization EsfPrintOrganization
+	Totals       EsfPrintTotals
+	// Итоговая сумма прописью в сомах
+	TotalInWords string
+	PrintedAt    time.Time
+}
+
+// EsfPrintTotals итоги по позициям документа
+type EsfPrintTotals struct {
+	AmountWithoutTaxes money.Amount
+	VatAmount          money.Amount
+	SalesTaxAmount     money.Amount
+	TotalAmount        money.Amount
+}
+
+// EsfPrintOrganization реквизиты организации-поставщика
+type EsfPrintOrganization struct {
+	ID          string
+	Name        string
+	Description string
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/diff"
+)
+
+// EsfDocumentRevisionModel ревизия документа ЭСФ
+type EsfDocumentRevisionModel struct {
+	Revision   int       `json:"revision"`
+	Action     string    `json:"action"`
+	AuthorId   string    `json:"authorId,omitempty"`
+	AuthorName string    `json:"authorName,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// Снимок документа; не заполняется в списке ревизий
+	Document *EsfCreateDocumentRequest `json:"document,omitempty"`
+}
+
+// EsfDocumentRevisionDiffResponse пополевая разница между двумя ревизиями документа
+type EsfDocumentRevisionDiffResponse struct {
+	DocumentUuid string        `json:"documentUuid"`
+	FromRevision int           `json:"fromRevision"`
+	ToRevision   int           `json:"toRevision"`
+	Changes      []diff.Change `json:"changes"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// EsfDocumentSearchResult найденный документ ЭСФ с релевантностью и подсветкой совпадений.
+// Highlights содержит только поля с совпадениями; совпадения размечены <mark>...</mark>,
+// остальной текст экранирован для HTML.
+type EsfDocumentSearchResult struct {
+	Document   EsfCreateDocumentRequest `json:"document"`
+	Rank       float64                  `json:"rank"`
+	Highlights map[string]string        `json:"highlights"`
+}
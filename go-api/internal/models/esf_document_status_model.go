@@ -0,0 +1,20 @@
+package models
+
+// EsfDocumentStatusRequest запрос на смену статуса документа ЭСФ
+type EsfDocumentStatusRequest struct {
+	// false Причина смены статуса (обязательна при отклонении и аннулировании)
+	Reason string `json:"reason"`
+}
+
+// EsfDocumentStatusResponse результат смены статуса документа ЭСФ
+type EsfDocumentStatusResponse struct {
+	DocumentUuid       string   `json:"documentUuid"`
+	PreviousStatus     string   `json:"previousStatus"`
+	Status             string   `json:"status"`
+	AllowedTransitions []string `json:"allowedTransitions"`
+	// Заполняются после отправки документа в налоговую службу
+	ExternalResponseId   string `json:"externalResponseId,omitempty"`
+	ExternalDocumentUuid string `json:"externalDocumentUuid,omitempty"`
+	// Заполняется, если отправленный документ доставлен покупателю на платформе
+	DeliveredDocumentUuid string `json:"deliveredDocumentUuid,omitempty"`
+}
@@ -1,5 +1,10 @@
 package models
 
+import (
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
 // EsfEntriesModel представляет модель записи в электронной счет-фактуре (ЭСФ).
 // Содержит информацию о товаре или услуге, включая коды классификации,
 // количественные и стоимостные показатели, а также данные о налогах.
@@ -24,23 +29,29 @@ type EsfEntriesModel struct {
 
 	// Price - цена за единицу товара или услуги
 	// без учета налогов
-	Price float64 `json:"price" valid:"required"`
+	Price money.Amount `json:"price" valid:"required"`
 
 	// VatAmount - сумма налога на добавленную стоимость (НДС)
 	// для данной позиции
-	VatAmount float64 `json:"vatAmount" valid:"required"`
+	VatAmount money.Amount `json:"vatAmount" valid:"required"`
 
 	// SalesTaxAmount - сумма акцизного налога
 	// для подакцизных товаров
-	SalesTaxAmount float64 `json:"salesTaxAmount" valid:"required"`
+	SalesTaxAmount money.Amount `json:"salesTaxAmount" valid:"required"`
 
 	// AmountWithoutTaxes - общая сумма за позицию
 	// без учета НДС и акцизов
-	AmountWithoutTaxes float64 `json:"amountWithoutTaxes" valid:"required"`
+	AmountWithoutTaxes money.Amount `json:"amountWithoutTaxes" valid:"required"`
 
 	// TotalAmount - итоговая сумма за позицию
 	// с учетом всех налогов
-	TotalAmount float64 `json:"totalAmount" valid:"required"`
+	TotalAmount money.Amount `json:"totalAmount" valid:"required"`
+
+	// Только чтение: корректируемая позиция исходного документа и изменения
+	// количества и цены (для корректировочных счетов-фактур)
+	OriginalEntryId *uuid.UUID   `json:"originalEntryId,omitempty"`
+	QuantityDelta   float64      `json:"quantityDelta,omitempty"`
+	PriceDelta      money.Amount `json:"priceDelta,omitempty"`
 }
 
 // CatalogEntriesModels представляет список товаров и услуг
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// EsfNumberingRequest настройки нумерации документов организации
+type EsfNumberingRequest struct {
+	// Префикс номера: латинские буквы, цифры, '-', '_' и '/'; пустой префикс - номер начинается с года
+	Prefix string `json:"prefix"`
+	// Число цифр порядкового номера
+	Width int `json:"width"`
+}
+
+// EsfNumberingModel настройки и счетчики нумерации документов.
+// Номер имеет вид ПРЕФИКС-ГОД-ПОРЯДКОВЫЙ_НОМЕР, например INV-2026-000123;
+// порядковый номер начинается с 1 в каждом году и идет без пропусков.
+type EsfNumberingModel struct {
+	Prefix string `json:"prefix"`
+	Width  int    `json:"width"`
+	// Номер, который получит следующий документ текущего года
+	NextNumber string                   `json:"nextNumber"`
+	Sequences  []EsfNumberSequenceModel `json:"sequences"`
+	UpdatedAt  *time.Time               `json:"updatedAt,omitempty"`
+	UpdatedBy  string                   `json:"updatedBy,omitempty"`
+}
+
+// EsfNumberSequenceModel счетчик номеров за год
+type EsfNumberSequenceModel struct {
+	Year int `json:"year"`
+	// Последний присвоенный порядковый номер
+	LastNumber int64 `json:"lastNumber"`
+}
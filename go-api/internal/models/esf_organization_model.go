@@ -4,6 +4,7 @@ type EsfOrganizationModel struct {
 	ID          string `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Tin         string `json:"tin"`
 	Token       string `json:"token"`
 	DBName      string `json:"dbName"`
 }
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EsfRecurrenceRuleModel правило повторения: документ выпускается в день dayOfMonth
+// каждые interval месяцев; если в месяце нет такого дня, - в последний день месяца
+type EsfRecurrenceRuleModel struct {
+	// true Периодичность; поддерживается monthly
+	Frequency string `json:"frequency"`
+	// false Число месяцев между выпусками, по умолчанию 1
+	Interval int `json:"interval"`
+	// true День месяца выпуска (1-31)
+	DayOfMonth int `json:"dayOfMonth"`
+	// true Дата начала; первый выпуск не раньше этой даты и не раньше дня сохранения шаблона
+	StartDate time.Time `json:"startDate"`
+	// false Дата окончания
+	EndDate *time.Time `json:"endDate,omitempty"`
+}
+
+// EsfRecurringTemplateRequest создание и изменение шаблона повторяющегося документа
+type EsfRecurringTemplateRequest struct {
+	Name string                 `json:"name"`
+	Rule EsfRecurrenceRuleModel `json:"rule"`
+	// Документ, по которому создаются черновики; дата поставки заполняется датой выпуска
+	Document EsfCreateDocumentRequest `json:"document"`
+	// false Выпуск включен, по умолчанию true
+	IsActive *bool `json:"isActive,omitempty"`
+}
+
+// EsfRecurringTemplateModel шаблон повторяющегося документа
+type EsfRecurringTemplateModel struct {
+	ID       uuid.UUID                `json:"id"`
+	Name     string                   `json:"name"`
+	Rule     EsfRecurrenceRuleModel   `json:"rule"`
+	Document EsfCreateDocumentRequest `json:"document"`
+	IsActive bool                     `json:"isActive"`
+	// Дата следующего выпуска; отсутствует, если все выпуски по правилу выполнены
+	NextRunDate *time.Time `json:"nextRunDate,omitempty"`
+	// Ошибка последнего выпуска; выпуск повторяется при следующем запуске планировщика
+	LastError string    `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+}
+
+// Статусы выпуска в предпросмотре
+const (
+	RecurringRunScheduled = "scheduled" // Выпуск запланирован
+	RecurringRunDue       = "due"       // Дата выпуска наступила, черновик будет создан при следующем запуске планировщика
+)
+
+// EsfRecurringPreviewItem предстоящий выпуск документа по шаблону
+type EsfRecurringPreviewItem struct {
+	// Расчетный период в формате 2006-01
+	Period  string    `json:"period"`
+	RunDate time.Time `json:"runDate"`
+	Status  string    `json:"status"`
+}
+
+// EsfRecurringRunModel выполненный выпуск документа по шаблону
+type EsfRecurringRunModel struct {
+	TemplateID uuid.UUID `json:"templateId"`
+	Period     string    `json:"period"`
+	RunDate    time.Time `json:"runDate"`
+	// Созданный черновик; отсутствует, если выпуск прерван после резервирования периода
+	DocumentID *uuid.UUID `json:"documentId,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// EsfRecurringRunFailure выпуск, который не удалось выполнить
+type EsfRecurringRunFailure struct {
+	TemplateID uuid.UUID `json:"templateId"`
+	Period     string    `json:"period"`
+	Error      string    `json:"error"`
+}
+
+// EsfRecurringRunReport результат запуска планировщика для организации
+type EsfRecurringRunReport struct {
+	Created []EsfRecurringRunModel   `json:"created"`
+	Failed  []EsfRecurringRunFailure `json:"failed"`
+	// Периоды, за которые документ уже был создан ранее
+	Skipped int `json:"skipped"`
+}
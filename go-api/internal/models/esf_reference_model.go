@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EsfReferenceItemModel код справочника
+type EsfReferenceItemModel struct {
+	Code    string `json:"code"`
+	AltCode string `json:"altCode,omitempty"`
+	Name    string `json:"name"`
+}
+
+// EsfReferenceCatalogModel активная версия справочника кодов ЭСФ
+type EsfReferenceCatalogModel struct {
+	Catalog   string    `json:"catalog"`
+	Version   string    `json:"version"`
+	LoadedAt  time.Time `json:"loadedAt"`
+	ItemCount int       `json:"itemCount"`
+	// Коды заполняются только при запросе конкретного справочника
+	Items []EsfReferenceItemModel `json:"items,omitempty"`
+}
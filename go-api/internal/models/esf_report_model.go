@@ -0,0 +1,111 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// EsfVatLedgerRequest параметры книги продаж или покупок за период
+type EsfVatLedgerRequest struct {
+	From time.Time
+	To   time.Time
+	// sales - книга продаж (по умолчанию), purchases - книга покупок по входящим документам
+	Ledger string
+	// Статусы учитываемых документов; по умолчанию в книге продаж подписанные, отправленные
+	// и принятые, в книге покупок - принятые к учету
+	Statuses []string
+}
+
+// EsfVatLedgerReport книга продаж или покупок: документы периода по ставкам НДС и контрагентам.
+// Суммы в сомах; итоги групп равны сумме входящих в них документов.
+type EsfVatLedgerReport struct {
+	Ledger   string              `json:"ledger"`
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Currency string              `json:"currency"`
+	Statuses []string            `json:"statuses"`
+	Groups   []EsfVatLedgerGroup `json:"groups"`
+	Totals   EsfVatLedgerTotals  `json:"totals"`
+}
+
+// EsfVatLedgerGroup документы одной ставки НДС
+type EsfVatLedgerGroup struct {
+	VatCode     string                   `json:"vatCode"`
+	VatName     string                   `json:"vatName"`
+	VatPercent  float64                  `json:"vatPercent"`
+	Contractors []EsfVatLedgerContractor `json:"contractors"`
+	Totals      EsfVatLedgerTotals       `json:"totals"`
+}
+
+// EsfVatLedgerContractor документы одного контрагента в группе ставки:
+// покупателя в книге продаж или поставщика в книге покупок
+type EsfVatLedgerContractor struct {
+	ContractorTin  string                 `json:"contractorTin"`
+	ContractorName string                 `json:"contractorName,omitempty"`
+	Documents      []EsfVatLedgerDocument `json:"documents"`
+	Totals         EsfVatLedgerTotals     `json:"totals"`
+}
+
+// EsfVatLedgerDocument строка книги продаж - суммы позиций документа
+type EsfVatLedgerDocument struct {
+	ID           uuid.UUID `json:"id"`
+	Number       string    `json:"number"`
+	DeliveryDate time.Time `json:"deliveryDate"`
+	Status       string    `json:"status"`
+	// Корректировочный документ; его суммы - разница с исходным и могут быть отрицательными
+	IsCorrection bool   `json:"isCorrection"`
+	CurrencyCode string `json:"currencyCode,omitempty"`
+	Entries      int    `json:"entries"`
+	// Облагаемая база - сумма позиций без налогов
+	TaxableBase    money.Amount `json:"taxableBase"`
+	VatAmount      money.Amount `json:"vatAmount"`
+	SalesTaxAmount money.Amount `json:"salesTaxAmount"`
+	TotalAmount    money.Amount `json:"totalAmount"`
+}
+
+// EsfVatLedgerTotals итоги группы или отчета
+type EsfVatLedgerTotals struct {
+	Documents      int          `json:"documents"`
+	Entries        int          `json:"entries"`
+	TaxableBase    money.Amount `json:"taxableBase"`
+	VatAmount      money.Amount `json:"vatAmount"`
+	SalesTaxAmount money.Amount `json:"salesTaxAmount"`
+	TotalAmount    money.Amount `json:"totalAmount"`
+}
+
+// EsfVatBalanceRequest параметры расчета НДС к уплате за период
+type EsfVatBalanceRequest struct {
+	From time.Time
+	To   time.Time
+}
+
+// EsfVatBalanceReport НДС к уплате: начисленный НДС по исходящим документам за вычетом
+// входящего НДС по принятым документам поставщиков, по ставкам и в целом
+type EsfVatBalanceReport struct {
+	From     time.Time           `json:"from"`
+	To       time.Time           `json:"to"`
+	Currency string              `json:"currency"`
+	Rates    []EsfVatBalanceRate `json:"rates"`
+	Totals   EsfVatBalanceTotals `json:"totals"`
+}
+
+// EsfVatBalanceRate зачет НДС по одной ставке
+type EsfVatBalanceRate struct {
+	VatCode    string  `json:"vatCode"`
+	VatName    string  `json:"vatName"`
+	VatPercent float64 `json:"vatPercent"`
+	EsfVatBalanceTotals
+}
+
+// EsfVatBalanceTotals начисленный и входящий НДС; NetVat > 0 - НДС к уплате, NetVat < 0 - к возмещению
+type EsfVatBalanceTotals struct {
+	OutputDocuments int          `json:"outputDocuments"`
+	OutputBase      money.Amount `json:"outputBase"`
+	OutputVat       money.Amount `json:"outputVat"`
+	InputDocuments  int          `json:"inputDocuments"`
+	InputBase       money.Amount `json:"inputBase"`
+	InputVat        money.Amount `json:"inputVat"`
+	NetVat          money.Amount `json:"netVat"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/diff"
+)
+
+// EsfSigningKeyRequest регистрация открытого ключа подписи
+type EsfSigningKeyRequest struct {
+	Name string `json:"name"`
+	// user - личный ключ текущего пользователя (по умолчанию), organization - ключ организации
+	Scope string `json:"scope"`
+	// Открытый ключ Ed25519 или ECDSA P-256 в PEM или base64 DER
+	PublicKey string `json:"publicKey"`
+}
+
+// EsfSigningKeyModel открытый ключ подписи
+type EsfSigningKeyModel struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Scope       string     `json:"scope"`
+	UserID      string     `json:"userId,omitempty"`
+	Algorithm   string     `json:"algorithm"`
+	PublicKey   string     `json:"publicKey"`
+	Fingerprint string     `json:"fingerprint"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CreatedBy   string     `json:"createdBy,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
+
+// EsfSigningPayload каноническое представление документа, которое подписывает клиент.
+// Подписываются байты Payload в UTF-8 без изменений.
+type EsfSigningPayload struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	Format     string    `json:"format"`
+	Payload    string    `json:"payload"`
+	// SHA-256 от Payload в шестнадцатеричном виде
+	Digest string `json:"digest"`
+}
+
+// EsfSignRequest подпись документа ключом, зарегистрированным в организации
+type EsfSignRequest struct {
+	KeyID uuid.UUID `json:"keyId"`
+	// Подпись Payload в base64
+	Signature string `json:"signature"`
+	// Digest подписанного представления (необязателен); позволяет отличить
+	// изменение документа после получения представления от неверной подписи
+	Digest string `json:"digest"`
+}
+
+// EsfDocumentSignatureModel подпись документа
+type EsfDocumentSignatureModel struct {
+	ID          uuid.UUID `json:"id"`
+	KeyID       uuid.UUID `json:"keyId"`
+	KeyName     string    `json:"keyName,omitempty"`
+	Algorithm   string    `json:"algorithm"`
+	Fingerprint string    `json:"fingerprint"`
+	Digest      string    `json:"digest"`
+	Signature   string    `json:"signature"`
+	SignerID    string    `json:"signerId,omitempty"`
+	SignerName  string    `json:"signerName,omitempty"`
+	SignedAt    time.Time `json:"signedAt"`
+}
+
+// EsfSignatureCheck результат проверки одной подписи
+type EsfSignatureCheck struct {
+	EsfDocumentSignatureModel
+	// Подпись математически верна для подписанного представления
+	SignatureValid bool `json:"signatureValid"`
+	// Документ не изменился после подписания
+	ContentMatches bool `json:"contentMatches"`
+	// Ключ отозван после подписания
+	KeyRevoked bool   `json:"keyRevoked"`
+	Error      string `json:"error,omitempty"`
+	// Изменения документа после подписания
+	Changes []diff.Change `json:"changes,omitempty"`
+}
+
+// EsfSignatureVerification результат проверки подписей документа.
+// Valid - документ подписан, все подписи верны и последняя подпись соответствует текущему содержимому.
+type EsfSignatureVerification struct {
+	DocumentID uuid.UUID           `json:"documentId"`
+	Digest     string              `json:"digest"`
+	Signed     bool                `json:"signed"`
+	Valid      bool                `json:"valid"`
+	Modified   bool                `json:"modified"`
+	Signatures []EsfSignatureCheck `json:"signatures"`
+}
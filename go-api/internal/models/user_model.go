@@ -10,7 +10,7 @@ type RegisterRequest struct {
 	Phone           string `json:"phone" validate:"omitempty,min=10,max=20"`
 	Password        string `json:"password" validate:"required,min=6,max=100"`
 	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=Password"`
-	Role            string `json:"role" validate:"omitempty,oneof=user viewer admin"`
+	Role            string `json:"role" validate:"omitempty,oneof=user viewer"` // approver и admin назначает администратор
 }
 
 // AdminRegisterRequest запрос на регистрацию администратора
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// CurrencyRateRepository официальные курсы валют в основной БД
+type CurrencyRateRepository interface {
+	// GetRate возвращает последний курс валюты, установленный не позже date и не раньше since
+	GetRate(ctx context.Context, currencyCode string, date, since time.Time) (*entity.CurrencyRate, error)
+	// GetRates возвращает курсы за период; пустой currencyCode - по всем валютам
+	GetRates(ctx context.Context, currencyCode string, from, to time.Time) ([]entity.CurrencyRate, error)
+	// SaveRates создает курсы или заменяет курсы той же валюты на ту же дату
+	SaveRates(ctx context.Context, rates []entity.CurrencyRate) error
+}
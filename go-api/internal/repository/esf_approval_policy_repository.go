@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfApprovalPolicyRepository политика согласования документов в БД организации
+type EsfApprovalPolicyRepository interface {
+	// GetApprovalPolicy возвращает политику; без сохраненной политики возвращается выключенная
+	GetApprovalPolicy(ctx context.Context, orgID uuid.UUID) (*entity.EsfApprovalPolicy, error)
+	// SaveApprovalPolicy сохраняет политику; она применяется к несогласованным документам
+	SaveApprovalPolicy(ctx context.Context, orgID uuid.UUID, policy *entity.EsfApprovalPolicy) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfAttachmentRepository описания вложений документов в БД организации; содержимое хранится в хранилище вложений
+type EsfAttachmentRepository interface {
+	GetAttachments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfAttachment, error)
+	GetAttachmentByID(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfAttachment, error)
+	CreateAttachment(ctx context.Context, orgID uuid.UUID, attachment *entity.EsfAttachment) error
+	DeleteAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error
+}
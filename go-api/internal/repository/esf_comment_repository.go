@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfCommentRepository комментарии к документам в БД организации; удаленные комментарии не возвращаются
+type EsfCommentRepository interface {
+	GetComments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentComment, error)
+	GetCommentByID(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfDocumentComment, error)
+	CreateComment(ctx context.Context, orgID uuid.UUID, comment *entity.EsfDocumentComment) error
+	// UpdateComment сохраняет текст комментария и заменяет его упоминания
+	UpdateComment(ctx context.Context, orgID uuid.UUID, comment *entity.EsfDocumentComment) error
+	DeleteComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfContractorRepository справочник контрагентов в БД организации
+type EsfContractorRepository interface {
+	// GetContractors возвращает контрагентов по наименованию; query ищет по наименованию и ИНН
+	GetContractors(ctx context.Context, orgID uuid.UUID, query string) ([]entity.EsfContractor, error)
+	GetContractorByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfContractor, error)
+	GetContractorByTin(ctx context.Context, orgID uuid.UUID, tin string) (*entity.EsfContractor, error)
+	CreateContractor(ctx context.Context, orgID uuid.UUID, contractor *entity.EsfContractor) error
+	// UpdateContractor обновляет контрагента и его реквизиты в черновиках; возвращает идентификаторы черновиков
+	UpdateContractor(ctx context.Context, orgID uuid.UUID, contractor *entity.EsfContractor) ([]uuid.UUID, error)
+	DeleteContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
+}
@@ -12,9 +12,66 @@ type EsfDocumentRepository interface {
 	GetAllDocuments(ctx context.Context, orgID uuid.UUID) ([]entity.EsfDocument, error)
 	GetDocumentByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfDocument, error)
 	CreateDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) error
+	// CreateDocuments атомарно создает несколько документов (импорт)
+	CreateDocuments(ctx context.Context, orgID uuid.UUID, docs []entity.EsfDocument) error
+	// UpdateDocument обновляет документ, только пока он в статусе черновика, иначе ErrDocumentLocked
 	UpdateDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) error
+	// SaveDocuments атомарно создает и обновляет несколько документов (пакетные операции)
+	SaveDocuments(ctx context.Context, orgID uuid.UUID, created, updated []entity.EsfDocument) error
+	// DeleteDocument удаляет только черновик без номера, иначе ErrDocumentLocked
 	DeleteDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
 
+	// UpdateDocumentStatus атомарно переводит документ из статуса from в статус to;
+	// при выходе из черновика присваивает номер по настройкам нумерации организации
+	UpdateDocumentStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from, to entity.DocumentStatus, reason string) error
+	// MarkDocumentSubmitted атомарно переводит документ в статус submitted и сохраняет идентификаторы налоговой службы
+	MarkDocumentSubmitted(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from entity.DocumentStatus, responseID, externalUUID string) error
+
+	// UpdateBuyerStatus сохраняет статус документа у покупателя и, если указаны, ссылки на копию у контрагента
+	UpdateBuyerStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, update BuyerStatusUpdate) error
+	// GetDeliveredCopy возвращает входящую копию документа продавца в организации покупателя orgID
+	GetDeliveredCopy(ctx context.Context, orgID uuid.UUID, sellerOrgID uuid.UUID, sellerDocID uuid.UUID) (*entity.EsfDocument, error)
+
+	// GetDocumentCorrections возвращает корректировки исходного документа в порядке создания
+	GetDocumentCorrections(ctx context.Context, orgID uuid.UUID, originalID uuid.UUID) ([]entity.EsfDocument, error)
+
+	// История ревизий: ревизии записываются автоматически при каждом изменении документа
+	GetDocumentRevisions(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentRevision, error)
+	GetDocumentRevision(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, revision int) (*entity.EsfDocumentRevision, error)
+
+	// Печатные формы организации
+	GetPrintTemplate(ctx context.Context, orgID uuid.UUID, name string) (*entity.EsfPrintTemplate, error)
+	SavePrintTemplate(ctx context.Context, orgID uuid.UUID, tpl *entity.EsfPrintTemplate) error
+	DeletePrintTemplate(ctx context.Context, orgID uuid.UUID, name string) error
+
+	// Подписи документов; ключи подписи хранит EsfSigningKeyRepository
+	GetDocumentSignatures(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentSignature, error)
+	// SignDocument сохраняет подпись и переводит документ в статус to, если документ не менялся после чтения
+	SignDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, signature *entity.EsfDocumentSignature, to entity.DocumentStatus) error
+
+	// Решения согласования документов; политику хранит EsfApprovalPolicyRepository
+	GetDocumentApprovals(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentApproval, error)
+	// DecideDocumentApproval сохраняет решение и переводит документ в статус to, если документ не менялся после чтения
+	DecideDocumentApproval(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, approval *entity.EsfDocumentApproval, to entity.DocumentStatus) error
+
+	// Полнотекстовый поиск с ранжированием
+	SearchDocuments(ctx context.Context, orgID uuid.UUID, query string, params pagination.PaginationParams) ([]DocumentSearchHit, int64, error)
+
 	// Пагіновані методи
 	GetAllDocumentsPaginated(ctx context.Context, orgID uuid.UUID, params pagination.PaginationParams, filters pagination.DocumentFilterParams) ([]entity.EsfDocument, int64, error)
 }
+
+// BuyerStatusUpdate статус доставленного документа у покупателя.
+// Пустые CounterpartyOrgID и CounterpartyDocumentID не изменяют сохраненные ссылки.
+type BuyerStatusUpdate struct {
+	Status                 entity.BuyerStatus
+	Reason                 string
+	CounterpartyOrgID      *uuid.UUID
+	CounterpartyDocumentID *uuid.UUID
+}
+
+// DocumentSearchHit найденный документ и его релевантность
+type DocumentSearchHit struct {
+	Document entity.EsfDocument
+	Rank     float64
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfNumberingRepository настройки нумерации документов в БД организации.
+// Сам номер присваивается в EsfDocumentRepository.UpdateDocumentStatus при выходе документа из черновика.
+type EsfNumberingRepository interface {
+	// GetNumberingSettings возвращает настройки; если они не сохранялись - значения по умолчанию
+	GetNumberingSettings(ctx context.Context, orgID uuid.UUID) (*entity.EsfNumberingSettings, error)
+	SaveNumberingSettings(ctx context.Context, orgID uuid.UUID, settings *entity.EsfNumberingSettings) error
+	// GetNumberSequences возвращает счетчики номеров по годам, начиная с последнего
+	GetNumberSequences(ctx context.Context, orgID uuid.UUID) ([]entity.EsfNumberSequence, error)
+}
@@ -11,6 +11,8 @@ type EsfOrganizationRepository interface {
 	// Define methods for EsfOrganizationRepository here
 	GetAll(ctx context.Context) ([]*entity.EstOrganization, error)
 	GetByID(ctx context.Context, id string) (*entity.EstOrganization, error)
+	// GetByTin возвращает организацию платформы по ИНН или nil, если такой организации нет
+	GetByTin(ctx context.Context, tin string) (*entity.EstOrganization, error)
 	Insert(ctx context.Context, org *entity.EstOrganization) error
 	Update(ctx context.Context, org *entity.EstOrganization) error
 	Delete(ctx context.Context, id string) error
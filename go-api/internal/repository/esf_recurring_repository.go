@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfRecurringRepository шаблоны повторяющихся документов и выпуски по ним в БД организации
+type EsfRecurringRepository interface {
+	GetRecurringTemplates(ctx context.Context, orgID uuid.UUID) ([]entity.EsfRecurringTemplate, error)
+	GetRecurringTemplateByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfRecurringTemplate, error)
+	GetDueRecurringTemplates(ctx context.Context, orgID uuid.UUID, date time.Time) ([]entity.EsfRecurringTemplate, error)
+	CreateRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate) error
+	UpdateRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate) error
+	DeleteRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
+	SaveRecurringSchedule(ctx context.Context, orgID uuid.UUID, id uuid.UUID, nextRunDate *time.Time, lastError string) error
+	GetRecurringRuns(ctx context.Context, orgID uuid.UUID, templateID uuid.UUID) ([]entity.EsfRecurringRun, error)
+	// ReserveRecurringRun возвращает false, если выпуск за период уже существует
+	ReserveRecurringRun(ctx context.Context, orgID uuid.UUID, run *entity.EsfRecurringRun) (bool, error)
+	CompleteRecurringRun(ctx context.Context, orgID uuid.UUID, runID uuid.UUID, documentID uuid.UUID) error
+	DeleteRecurringRun(ctx context.Context, orgID uuid.UUID, runID uuid.UUID) error
+}
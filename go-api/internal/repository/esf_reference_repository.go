@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfReferenceRepository справочники кодов ЭСФ в основной БД
+type EsfReferenceRepository interface {
+	// GetActiveVersions возвращает активные версии всех справочников
+	GetActiveVersions(ctx context.Context) ([]entity.EsfReferenceVersion, error)
+	// GetActiveVersion возвращает активную версию справочника
+	GetActiveVersion(ctx context.Context, catalog string) (*entity.EsfReferenceVersion, error)
+	// GetItems возвращает коды версий в порядке сортировки
+	GetItems(ctx context.Context, versionIDs ...uuid.UUID) ([]entity.EsfReferenceItem, error)
+	// VersionExists проверяет, загружена ли версия справочника
+	VersionExists(ctx context.Context, catalog, version string) (bool, error)
+	// CreateVersion атомарно создает версию с кодами; при activate версия становится единственной активной
+	CreateVersion(ctx context.Context, version *entity.EsfReferenceVersion, items []entity.EsfReferenceItem, activate bool) error
+}
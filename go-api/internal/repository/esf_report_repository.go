@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// EsfReportRepository суммы документов организации для книг продаж и покупок и аналитики
+type EsfReportRepository interface {
+	// Документы периода с суммами позиций для книги продаж
+	GetLedgerDocuments(ctx context.Context, orgID uuid.UUID, filter LedgerFilter) ([]LedgerDocument, error)
+	// Суммы документов периода, сгруппированные по groupBy; limit > 0 ограничивает число групп
+	GetDocumentAggregates(ctx context.Context, orgID uuid.UUID, filter LedgerFilter, groupBy AggregateGroup, limit int) ([]AggregateBucket, error)
+}
+
+// LedgerFilter отбор документов для отчетов: дата поставки в [From, To), статус из Statuses
+// и направление Direction; пустое направление отбирает документы обоих направлений
+type LedgerFilter struct {
+	From      time.Time
+	To        time.Time
+	Statuses  []entity.DocumentStatus
+	Direction entity.DocumentDirection
+}
+
+// LedgerDocument документ и суммы его позиций в сомах.
+// ContractorTin - ИНН контрагента: покупателя исходящего документа или поставщика входящего.
+type LedgerDocument struct {
+	ID                 uuid.UUID
+	Number             string
+	DeliveryDate       time.Time
+	Status             entity.DocumentStatus
+	Direction          entity.DocumentDirection
+	TaxRateVATCode     string
+	ContractorTin      string
+	ContractorID       *uuid.UUID
+	SupplierName       string
+	OriginalDocumentID *uuid.UUID
+	CurrencyCode       string
+	Entries            int
+	AmountWithoutTaxes money.Amount
+	VatAmount          money.Amount
+	SalesTaxAmount     money.Amount
+	TotalAmount        money.Amount
+}
+
+// AggregateGroup группировка сумм документов в аналитике
+type AggregateGroup string
+
+const (
+	// AggregateTotal одна группа с итогами всех документов
+	AggregateTotal AggregateGroup = "total"
+	// AggregateByMonth месяц даты поставки в формате YYYY-MM, по возрастанию
+	AggregateByMonth AggregateGroup = "month"
+	// AggregateByContractor ИНН покупателя, по убыванию суммы с налогами
+	AggregateByContractor AggregateGroup = "contractor"
+	// AggregateByVATCode код ставки НДС, по возрастанию
+	AggregateByVATCode AggregateGroup = "vat_code"
+)
+
+// AggregateBucket суммы позиций документов одной группы в сомах
+type AggregateBucket struct {
+	Key                string       `json:"key"`
+	Documents          int          `json:"documents"`
+	AmountWithoutTaxes money.Amount `json:"amountWithoutTaxes"`
+	VatAmount          money.Amount `json:"vatAmount"`
+	SalesTaxAmount     money.Amount `json:"salesTaxAmount"`
+	TotalAmount        money.Amount `json:"totalAmount"`
+}
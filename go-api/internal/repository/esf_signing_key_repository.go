@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfSigningKeyRepository открытые ключи подписи документов в БД организации
+type EsfSigningKeyRepository interface {
+	// GetSigningKeys возвращает ключи организации, включая отозванные
+	GetSigningKeys(ctx context.Context, orgID uuid.UUID) ([]entity.EsfSigningKey, error)
+	GetSigningKeyByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfSigningKey, error)
+	GetSigningKeyByFingerprint(ctx context.Context, orgID uuid.UUID, fingerprint string) (*entity.EsfSigningKey, error)
+	CreateSigningKey(ctx context.Context, orgID uuid.UUID, key *entity.EsfSigningKey) error
+	// RevokeSigningKey отзывает ключ; подписи, сделанные им, остаются проверяемыми
+	RevokeSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID, revokedAt time.Time) error
+}
@@ -0,0 +1,85 @@
+package repositorypostgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+// currencyRatePostgres реализует интерфейс CurrencyRateRepository для PostgreSQL
+type currencyRatePostgres struct {
+	logger *logger.Logger
+	db     *gorm.DB
+}
+
+// NewCurrencyRateRepositoryPostgres создает репозиторий курсов валют
+func NewCurrencyRateRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.CurrencyRateRepository {
+	return &currencyRatePostgres{
+		logger: logger.New(log),
+		db:     db,
+	}
+}
+
+// GetRate возвращает последний курс валюты в интервале [since, date]
+func (crp *currencyRatePostgres) GetRate(ctx context.Context, currencyCode string, date, since time.Time) (*entity.CurrencyRate, error) {
+	var rate entity.CurrencyRate
+	err := crp.db.WithContext(ctx).
+		Where("currency_code = ? AND date <= ? AND date >= ?", currencyCode, date, since).
+		Order("date DESC").
+		First(&rate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFoundError("currency rate")
+		}
+		crp.logger.Error(ctx, "Failed to fetch currency rate", err, logrus.Fields{"currency": currencyCode})
+		return nil, apperror.DatabaseError("fetching currency rate", err)
+	}
+	return &rate, nil
+}
+
+// GetRates возвращает курсы за период, упорядоченные по дате и валюте
+func (crp *currencyRatePostgres) GetRates(ctx context.Context, currencyCode string, from, to time.Time) ([]entity.CurrencyRate, error) {
+	db := crp.db.WithContext(ctx).Where("date BETWEEN ? AND ?", from, to)
+	if currencyCode != "" {
+		db = db.Where("currency_code = ?", currencyCode)
+	}
+
+	var rates []entity.CurrencyRate
+	if err := db.Order("date DESC, currency_code").Find(&rates).Error; err != nil {
+		crp.logger.Error(ctx, "Failed to fetch currency rates", err, logrus.Fields{"currency": currencyCode})
+		return nil, apperror.DatabaseError("fetching currency rates", err)
+	}
+	return rates, nil
+}
+
+// SaveRates сохраняет курсы; курс той же валюты на ту же дату заменяется
+func (crp *currencyRatePostgres) SaveRates(ctx context.Context, rates []entity.CurrencyRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	for i := range rates {
+		if rates[i].ID == uuid.Nil {
+			rates[i].ID = uuid.New()
+		}
+	}
+
+	err := crp.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency_code"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "nominal", "source", "updated_at"}),
+	}).CreateInBatches(rates, 500).Error
+	if err != nil {
+		crp.logger.Error(ctx, "Failed to save currency rates", err, logrus.Fields{"count": len(rates)})
+		return apperror.DatabaseError("saving currency rates", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repositorypostgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+type esfApprovalPolicyRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfApprovalPolicyRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfApprovalPolicyRepository {
+	return &esfApprovalPolicyRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetApprovalPolicy возвращает политику согласования; если она не сохранялась - выключенную политику
+func (eaprp *esfApprovalPolicyRepositoryPostgres) GetApprovalPolicy(ctx context.Context, orgID uuid.UUID) (*entity.EsfApprovalPolicy, error) {
+	orgDB, err := eaprp.getOrgDB(ctx, orgID)
+	if err != nil {
+		eaprp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var policy entity.EsfApprovalPolicy
+	err = orgDB.WithContext(ctx).Where("id = ?", entity.ApprovalPolicyID).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		return &entity.EsfApprovalPolicy{ID: entity.ApprovalPolicyID, RequiredApprovals: 1}, nil
+	}
+	if err != nil {
+		eaprp.logger.Error(ctx, "Failed to fetch approval policy", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching approval policy", err)
+	}
+	return &policy, nil
+}
+
+// SaveApprovalPolicy сохраняет политику согласования; она применяется к несогласованным документам
+func (eaprp *esfApprovalPolicyRepositoryPostgres) SaveApprovalPolicy(ctx context.Context, orgID uuid.UUID, policy *entity.EsfApprovalPolicy) error {
+	eaprp.logger.Debug(ctx, "Saving approval policy", logrus.Fields{"org_id": orgID.String(), "enabled": policy.Enabled})
+
+	orgDB, err := eaprp.getOrgDB(ctx, orgID)
+	if err != nil {
+		eaprp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	policy.ID = entity.ApprovalPolicyID
+	err = orgDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "threshold_amount", "operation_type_codes", "required_approvals", "updated_at", "updated_by"}),
+	}).Create(policy).Error
+	if err != nil {
+		eaprp.logger.Error(ctx, "Failed to save approval policy", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("saving approval policy", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package repositorypostgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// GetDocumentApprovals возвращает решения по документу в порядке принятия
+func (edrp *esfDocumentRepositoryPostgres) GetDocumentApprovals(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentApproval, error) {
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var approvals []entity.EsfDocumentApproval
+	if err := orgDB.WithContext(ctx).Where("document_id = ?", documentID).Order("created_at, id").Find(&approvals).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to fetch document approvals", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.DatabaseError("fetching document approvals", err)
+	}
+	return approvals, nil
+}
+
+// DecideDocumentApproval сохраняет решение согласующего. Если to отличается от статуса документа,
+// документ переводится в статус to с причиной из решения (отклонение возвращает документ в черновик).
+// Решение не сохраняется, если документ изменился после чтения: согласование относится к прочитанному содержимому.
+func (edrp *esfDocumentRepositoryPostgres) DecideDocumentApproval(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, approval *entity.EsfDocumentApproval, to entity.DocumentStatus) error {
+	edrp.logger.Debug(ctx, "Recording approval decision", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "decision": approval.Decision})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if approval.ID == uuid.Nil {
+		approval.ID = uuid.New()
+	}
+	approval.DocumentID = doc.ID
+
+	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		unchanged := tx.Model(&entity.EsfDocument{}).Where("id = ? AND status = ? AND updated_at = ?", doc.ID, doc.Status, doc.UpdatedAt)
+		if to != doc.Status {
+			result := unchanged.Updates(map[string]interface{}{
+				"status":            to,
+				"status_reason":     approval.Reason,
+				"status_changed_at": time.Now(),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return apperror.New(apperror.ErrInvalidStatusTransition, "document has been changed by another request")
+			}
+			if err := edrp.writeRevision(ctx, tx, doc.ID, entity.RevisionActionStatus); err != nil {
+				return err
+			}
+		} else {
+			var locked []uuid.UUID
+			if err := unchanged.Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &locked).Error; err != nil {
+				return err
+			}
+			if len(locked) == 0 {
+				return apperror.New(apperror.ErrInvalidStatusTransition, "document has been changed by another request")
+			}
+		}
+		return tx.Create(approval).Error
+	})
+
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Document changed while approving", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+			return appErr
+		}
+		edrp.logger.Error(ctx, "Failed to record approval decision", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+		return apperror.DatabaseError("recording approval decision", err)
+	}
+	return nil
+}
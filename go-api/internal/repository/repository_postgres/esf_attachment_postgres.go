@@ -0,0 +1,99 @@
+package repositorypostgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+type esfAttachmentRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfAttachmentRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfAttachmentRepository {
+	return &esfAttachmentRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetAttachments возвращает вложения документа в порядке загрузки
+func (earp *esfAttachmentRepositoryPostgres) GetAttachments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfAttachment, error) {
+	orgDB, err := earp.getOrgDB(ctx, orgID)
+	if err != nil {
+		earp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var attachments []entity.EsfAttachment
+	if err := orgDB.WithContext(ctx).Where("document_id = ?", documentID).Order("created_at").Find(&attachments).Error; err != nil {
+		earp.logger.Error(ctx, "Failed to fetch attachments", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.DatabaseError("fetching attachments", err)
+	}
+	return attachments, nil
+}
+
+// GetAttachmentByID возвращает вложение документа
+func (earp *esfAttachmentRepositoryPostgres) GetAttachmentByID(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfAttachment, error) {
+	orgDB, err := earp.getOrgDB(ctx, orgID)
+	if err != nil {
+		earp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var attachment entity.EsfAttachment
+	if err := orgDB.WithContext(ctx).Where("id = ? AND document_id = ?", id, documentID).First(&attachment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("attachment")
+		}
+		earp.logger.Error(ctx, "Failed to fetch attachment", err, logrus.Fields{"org_id": orgID.String(), "attachment_id": id.String()})
+		return nil, apperror.DatabaseError("fetching attachment", err)
+	}
+	return &attachment, nil
+}
+
+// CreateAttachment сохраняет описание загруженного вложения
+func (earp *esfAttachmentRepositoryPostgres) CreateAttachment(ctx context.Context, orgID uuid.UUID, attachment *entity.EsfAttachment) error {
+	earp.logger.Debug(ctx, "Creating attachment", logrus.Fields{"org_id": orgID.String(), "doc_id": attachment.DocumentID.String(), "file_name": attachment.FileName})
+
+	orgDB, err := earp.getOrgDB(ctx, orgID)
+	if err != nil {
+		earp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if err := orgDB.WithContext(ctx).Create(attachment).Error; err != nil {
+		earp.logger.Error(ctx, "Failed to create attachment", err, logrus.Fields{"org_id": orgID.String(), "doc_id": attachment.DocumentID.String()})
+		return apperror.DatabaseError("creating attachment", err)
+	}
+	return nil
+}
+
+// DeleteAttachment удаляет описание вложения; содержимое удаляет сервис из хранилища вложений
+func (earp *esfAttachmentRepositoryPostgres) DeleteAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error {
+	earp.logger.Debug(ctx, "Deleting attachment", logrus.Fields{"org_id": orgID.String(), "attachment_id": id.String()})
+
+	orgDB, err := earp.getOrgDB(ctx, orgID)
+	if err != nil {
+		earp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	result := orgDB.WithContext(ctx).Where("id = ? AND document_id = ?", id, documentID).Delete(&entity.EsfAttachment{})
+	if result.Error != nil {
+		earp.logger.Error(ctx, "Failed to delete attachment", result.Error, logrus.Fields{"org_id": orgID.String(), "attachment_id": id.String()})
+		return apperror.DatabaseError("deleting attachment", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFoundError("attachment")
+	}
+	return nil
+}
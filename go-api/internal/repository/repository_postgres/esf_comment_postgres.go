@@ -0,0 +1,145 @@
+package repositorypostgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+type esfCommentRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfCommentRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfCommentRepository {
+	return &esfCommentRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetComments возвращает комментарии документа с упоминаниями в порядке создания
+func (ecmrp *esfCommentRepositoryPostgres) GetComments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentComment, error) {
+	orgDB, err := ecmrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecmrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var comments []entity.EsfDocumentComment
+	if err := orgDB.WithContext(ctx).Preload("Mentions").Where("document_id = ?", documentID).Order("created_at, id").Find(&comments).Error; err != nil {
+		ecmrp.logger.Error(ctx, "Failed to fetch comments", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.DatabaseError("fetching comments", err)
+	}
+	return comments, nil
+}
+
+// GetCommentByID возвращает комментарий документа
+func (ecmrp *esfCommentRepositoryPostgres) GetCommentByID(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfDocumentComment, error) {
+	orgDB, err := ecmrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecmrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var comment entity.EsfDocumentComment
+	if err := orgDB.WithContext(ctx).Preload("Mentions").Where("id = ? AND document_id = ?", id, documentID).First(&comment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("comment")
+		}
+		ecmrp.logger.Error(ctx, "Failed to fetch comment", err, logrus.Fields{"org_id": orgID.String(), "comment_id": id.String()})
+		return nil, apperror.DatabaseError("fetching comment", err)
+	}
+	return &comment, nil
+}
+
+// CreateComment сохраняет комментарий вместе с упоминаниями
+func (ecmrp *esfCommentRepositoryPostgres) CreateComment(ctx context.Context, orgID uuid.UUID, comment *entity.EsfDocumentComment) error {
+	ecmrp.logger.Debug(ctx, "Creating comment", logrus.Fields{"org_id": orgID.String(), "doc_id": comment.DocumentID.String()})
+
+	orgDB, err := ecmrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecmrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if comment.ID == uuid.Nil {
+		comment.ID = uuid.New()
+	}
+	if err := orgDB.WithContext(ctx).Create(comment).Error; err != nil {
+		ecmrp.logger.Error(ctx, "Failed to create comment", err, logrus.Fields{"org_id": orgID.String(), "doc_id": comment.DocumentID.String()})
+		return apperror.DatabaseError("creating comment", err)
+	}
+	return nil
+}
+
+// UpdateComment сохраняет текст комментария и заменяет упоминания в одной транзакции
+func (ecmrp *esfCommentRepositoryPostgres) UpdateComment(ctx context.Context, orgID uuid.UUID, comment *entity.EsfDocumentComment) error {
+	ecmrp.logger.Debug(ctx, "Updating comment", logrus.Fields{"org_id": orgID.String(), "comment_id": comment.ID.String()})
+
+	orgDB, err := ecmrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecmrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.EsfDocumentComment{}).
+			Where("id = ? AND document_id = ?", comment.ID, comment.DocumentID).
+			Updates(map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.NotFoundError("comment")
+		}
+
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&entity.EsfCommentMention{}).Error; err != nil {
+			return err
+		}
+		for i := range comment.Mentions {
+			comment.Mentions[i].CommentID = comment.ID
+		}
+		if len(comment.Mentions) > 0 {
+			return tx.Create(&comment.Mentions).Error
+		}
+		return nil
+	})
+
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			return appErr
+		}
+		ecmrp.logger.Error(ctx, "Failed to update comment", err, logrus.Fields{"org_id": orgID.String(), "comment_id": comment.ID.String()})
+		return apperror.DatabaseError("updating comment", err)
+	}
+	return nil
+}
+
+// DeleteComment помечает комментарий удаленным; ответы ветки сохраняются
+func (ecmrp *esfCommentRepositoryPostgres) DeleteComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error {
+	ecmrp.logger.Debug(ctx, "Deleting comment", logrus.Fields{"org_id": orgID.String(), "comment_id": id.String()})
+
+	orgDB, err := ecmrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecmrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	result := orgDB.WithContext(ctx).Where("id = ? AND document_id = ?", id, documentID).Delete(&entity.EsfDocumentComment{})
+	if result.Error != nil {
+		ecmrp.logger.Error(ctx, "Failed to delete comment", result.Error, logrus.Fields{"org_id": orgID.String(), "comment_id": id.String()})
+		return apperror.DatabaseError("deleting comment", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFoundError("comment")
+	}
+	return nil
+}
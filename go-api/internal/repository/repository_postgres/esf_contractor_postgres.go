@@ -0,0 +1,214 @@
+package repositorypostgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/transaction"
+)
+
+type esfContractorRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfContractorRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfContractorRepository {
+	return &esfContractorRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetContractors возвращает контрагентов организации по наименованию; query ищет по наименованию и ИНН
+func (ecrp *esfContractorRepositoryPostgres) GetContractors(ctx context.Context, orgID uuid.UUID, query string) ([]entity.EsfContractor, error) {
+	ecrp.logger.Debug(ctx, "Fetching contractors", logrus.Fields{"org_id": orgID.String(), "query": query})
+
+	orgDB, err := ecrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	db := orgDB.WithContext(ctx).Preload("BankAccounts")
+	if query = strings.TrimSpace(query); query != "" {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query) + "%"
+		db = db.Where("name ILIKE ? OR tin LIKE ?", pattern, pattern)
+	}
+
+	var contractors []entity.EsfContractor
+	if err := db.Order("name").Find(&contractors).Error; err != nil {
+		ecrp.logger.Error(ctx, "Failed to fetch contractors", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching contractors", err)
+	}
+	return contractors, nil
+}
+
+// GetContractorByID возвращает контрагента с банковскими счетами
+func (ecrp *esfContractorRepositoryPostgres) GetContractorByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfContractor, error) {
+	return ecrp.getContractor(ctx, orgID, "id = ?", id)
+}
+
+// GetContractorByTin возвращает контрагента по ИНН
+func (ecrp *esfContractorRepositoryPostgres) GetContractorByTin(ctx context.Context, orgID uuid.UUID, tin string) (*entity.EsfContractor, error) {
+	return ecrp.getContractor(ctx, orgID, "tin = ?", tin)
+}
+
+func (ecrp *esfContractorRepositoryPostgres) getContractor(ctx context.Context, orgID uuid.UUID, condition string, value interface{}) (*entity.EsfContractor, error) {
+	orgDB, err := ecrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var contractor entity.EsfContractor
+	if err := orgDB.WithContext(ctx).Preload("BankAccounts").Where(condition, value).First(&contractor).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("contractor")
+		}
+		ecrp.logger.Error(ctx, "Failed to fetch contractor", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching contractor", err)
+	}
+	return &contractor, nil
+}
+
+// CreateContractor создает контрагента вместе с банковскими счетами
+func (ecrp *esfContractorRepositoryPostgres) CreateContractor(ctx context.Context, orgID uuid.UUID, contractor *entity.EsfContractor) error {
+	ecrp.logger.Debug(ctx, "Creating contractor", logrus.Fields{"org_id": orgID.String(), "tin": contractor.Tin})
+
+	orgDB, err := ecrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if contractor.ID == uuid.Nil {
+		contractor.ID = uuid.New()
+	}
+	for i := range contractor.BankAccounts {
+		contractor.BankAccounts[i].ContractorID = contractor.ID
+	}
+
+	if err := orgDB.WithContext(ctx).Create(contractor).Error; err != nil {
+		ecrp.logger.Error(ctx, "Failed to create contractor", err, logrus.Fields{"org_id": orgID.String(), "tin": contractor.Tin})
+		return apperror.DatabaseError("creating contractor", err)
+	}
+	return nil
+}
+
+// UpdateContractor обновляет контрагента, заменяет его банковские счета и в той же транзакции
+// переносит ИНН, резидентство и код страны в черновики, которые ссылаются на контрагента.
+// Возвращает идентификаторы обновленных черновиков.
+func (ecrp *esfContractorRepositoryPostgres) UpdateContractor(ctx context.Context, orgID uuid.UUID, contractor *entity.EsfContractor) ([]uuid.UUID, error) {
+	ecrp.logger.Debug(ctx, "Updating contractor", logrus.Fields{"org_id": orgID.String(), "contractor_id": contractor.ID.String()})
+
+	orgDB, err := ecrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var drafts []uuid.UUID
+	err = transaction.Execute(ctx, orgDB, ecrp.logger.Raw(), func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.EsfContractor{}).Where("id = ?", contractor.ID).Updates(map[string]interface{}{
+			"name":           contractor.Name,
+			"tin":            contractor.Tin,
+			"is_resident":    contractor.IsResident,
+			"country_code":   contractor.CountryCode,
+			"contact_person": contractor.ContactPerson,
+			"phone":          contractor.Phone,
+			"email":          contractor.Email,
+			"address":        contractor.Address,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("contractor_id = ?", contractor.ID).Delete(&entity.EsfContractorBankAccount{}).Error; err != nil {
+			return err
+		}
+		for i := range contractor.BankAccounts {
+			contractor.BankAccounts[i].ID = uuid.New()
+			contractor.BankAccounts[i].ContractorID = contractor.ID
+		}
+		if len(contractor.BankAccounts) > 0 {
+			if err := tx.Create(&contractor.BankAccounts).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&entity.EsfDocument{}).
+			Where("contractor_id = ? AND status = ?", contractor.ID, entity.DocumentStatusDraft).
+			Pluck("id", &drafts).Error; err != nil {
+			return err
+		}
+		if len(drafts) == 0 {
+			return nil
+		}
+		if err := tx.Model(&entity.EsfDocument{}).Where("id IN ?", drafts).Updates(map[string]interface{}{
+			"contractor_tin": contractor.Tin,
+			"is_resident":    contractor.IsResident,
+			"country_code":   contractor.CountryCode,
+		}).Error; err != nil {
+			return err
+		}
+		for _, id := range drafts {
+			if err := ecrp.writeRevision(ctx, tx, id, entity.RevisionActionUpdate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to update contractor", err, logrus.Fields{"org_id": orgID.String(), "contractor_id": contractor.ID.String()})
+		return nil, apperror.DatabaseError("updating contractor", err)
+	}
+
+	ecrp.logger.Debug(ctx, "Contractor updated successfully", logrus.Fields{"org_id": orgID.String(), "contractor_id": contractor.ID.String(), "drafts": len(drafts)})
+	return drafts, nil
+}
+
+// DeleteContractor удаляет контрагента и снимает ссылку на него с черновиков.
+// Отправленные документы сохраняют ссылку и скопированные реквизиты.
+func (ecrp *esfContractorRepositoryPostgres) DeleteContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	ecrp.logger.Debug(ctx, "Deleting contractor", logrus.Fields{"org_id": orgID.String(), "contractor_id": id.String()})
+
+	orgDB, err := ecrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	err = transaction.Execute(ctx, orgDB, ecrp.logger.Raw(), func(tx *gorm.DB) error {
+		var drafts []uuid.UUID
+		if err := tx.Model(&entity.EsfDocument{}).
+			Where("contractor_id = ? AND status = ?", id, entity.DocumentStatusDraft).
+			Pluck("id", &drafts).Error; err != nil {
+			return err
+		}
+		if len(drafts) > 0 {
+			if err := tx.Model(&entity.EsfDocument{}).Where("id IN ?", drafts).Update("contractor_id", nil).Error; err != nil {
+				return err
+			}
+			for _, docID := range drafts {
+				if err := ecrp.writeRevision(ctx, tx, docID, entity.RevisionActionUpdate); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Where("id = ?", id).Delete(&entity.EsfContractor{}).Error
+	})
+
+	if err != nil {
+		ecrp.logger.Error(ctx, "Failed to delete contractor", err, logrus.Fields{"org_id": orgID.String(), "contractor_id": id.String()})
+		return apperror.DatabaseError("deleting contractor", err)
+	}
+	return nil
+}
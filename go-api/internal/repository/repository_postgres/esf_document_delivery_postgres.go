@@ -0,0 +1,70 @@
+package repositorypostgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// UpdateBuyerStatus сохраняет статус документа у покупателя. Статус меняется вне жизненного
+// цикла документа продавца, поэтому ревизия не записывается и статус документа не проверяется.
+func (edrp *esfDocumentRepositoryPostgres) UpdateBuyerStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, update repository.BuyerStatusUpdate) error {
+	edrp.logger.Debug(ctx, "Updating document buyer status", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "buyer_status": update.Status})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	columns := map[string]interface{}{
+		"buyer_status":            update.Status,
+		"buyer_status_reason":     update.Reason,
+		"buyer_status_changed_at": time.Now(),
+	}
+	if update.CounterpartyOrgID != nil {
+		columns["counterparty_org_id"] = *update.CounterpartyOrgID
+	}
+	if update.CounterpartyDocumentID != nil {
+		columns["counterparty_document_id"] = *update.CounterpartyDocumentID
+	}
+
+	result := orgDB.WithContext(ctx).Model(&entity.EsfDocument{}).Where("id = ?", id).Updates(columns)
+	if result.Error != nil {
+		edrp.logger.Error(ctx, "Failed to update document buyer status", result.Error, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return apperror.DatabaseError("updating document buyer status", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFoundError("document")
+	}
+	return nil
+}
+
+// GetDeliveredCopy возвращает входящую копию документа продавца в организации покупателя
+func (edrp *esfDocumentRepositoryPostgres) GetDeliveredCopy(ctx context.Context, orgID uuid.UUID, sellerOrgID uuid.UUID, sellerDocID uuid.UUID) (*entity.EsfDocument, error) {
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var document entity.EsfDocument
+	err = orgDB.WithContext(ctx).
+		Where("direction = ? AND counterparty_org_id = ? AND counterparty_document_id = ?", entity.DocumentDirectionIncoming, sellerOrgID, sellerDocID).
+		First(&document).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("delivered document")
+		}
+		edrp.logger.Error(ctx, "Failed to fetch delivered document", err, logrus.Fields{"org_id": orgID.String(), "seller_doc_id": sellerDocID.String()})
+		return nil, apperror.DatabaseError("fetching delivered document", err)
+	}
+	return &document, nil
+}
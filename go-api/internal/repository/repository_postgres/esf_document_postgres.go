@@ -2,13 +2,11 @@ package repositorypostgres
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"sync"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/rusgainew/tunduck-app/internal/repository"
@@ -16,20 +14,19 @@ import (
 	"github.com/rusgainew/tunduck-app/pkg/entity"
 	"github.com/rusgainew/tunduck-app/pkg/logger"
 	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/search"
+	"github.com/rusgainew/tunduck-app/pkg/transaction"
 )
 
 type esfDocumentRepositoryPostgres struct {
-	logger  *logger.Logger
-	baseDB  *gorm.DB
-	dbCache map[string]*gorm.DB
-	cacheMu sync.RWMutex
+	*organizationDatabases
+	logger *logger.Logger
 }
 
 func NewEsfDocumentRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfDocumentRepository {
 	return &esfDocumentRepositoryPostgres{
-		baseDB:  db,
-		logger:  logger.New(log),
-		dbCache: make(map[string]*gorm.DB),
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
 	}
 }
 
@@ -98,7 +95,12 @@ func (edrp *esfDocumentRepositoryPostgres) CreateDocument(ctx context.Context, o
 		return apperror.DatabaseError("getting organization database", err)
 	}
 
-	err = orgDB.WithContext(ctx).Create(doc).Error
+	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(doc).Error; err != nil {
+			return err
+		}
+		return edrp.writeRevision(ctx, tx, doc.ID, entity.RevisionActionCreate)
+	})
 
 	if err != nil {
 		edrp.logger.Error(ctx, "Failed to create document in database", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
@@ -109,7 +111,40 @@ func (edrp *esfDocumentRepositoryPostgres) CreateDocument(ctx context.Context, o
 	return nil
 }
 
-// UpdateDocument обновляет существующий документ ЭСФ
+// CreateDocuments создает несколько документов ЭСФ в одной транзакции БД организации:
+// при ошибке не создается ни один документ
+func (edrp *esfDocumentRepositoryPostgres) CreateDocuments(ctx context.Context, orgID uuid.UUID, docs []entity.EsfDocument) error {
+	edrp.logger.Debug(ctx, "Creating documents in organization database", logrus.Fields{"org_id": orgID.String(), "count": len(docs)})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	err = transaction.Execute(ctx, orgDB, edrp.logger.Raw(), func(tx *gorm.DB) error {
+		for i := range docs {
+			if err := tx.Create(&docs[i]).Error; err != nil {
+				return err
+			}
+			if err := edrp.writeRevision(ctx, tx, docs[i].ID, entity.RevisionActionCreate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to create documents in database", err, logrus.Fields{"org_id": orgID.String(), "count": len(docs)})
+		return apperror.DatabaseError("creating documents", err)
+	}
+
+	edrp.logger.Debug(ctx, "Documents created successfully", logrus.Fields{"org_id": orgID.String(), "count": len(docs)})
+	return nil
+}
+
+// UpdateDocument обновляет существующий документ ЭСФ, если он находится в статусе черновика.
+// Если документ уже покинул черновик, возвращает ErrDocumentLocked.
 func (edrp *esfDocumentRepositoryPostgres) UpdateDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) error {
 	edrp.logger.Debug(ctx, "Updating document in organization database", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
 
@@ -120,42 +155,108 @@ func (edrp *esfDocumentRepositoryPostgres) UpdateDocument(ctx context.Context, o
 	}
 
 	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// Обновляем основной документ
+		return edrp.updateDocument(ctx, tx, doc)
+	})
+
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Document left draft status concurrently", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+			return appErr
+		}
+		edrp.logger.Error(ctx, "Failed to update document in database (transaction failed)", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+		return apperror.DatabaseError("updating document", err)
+	}
+
+	edrp.logger.Debug(ctx, "Document updated successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+	return nil
+}
+
+// updateDocument обновляет черновик и заменяет его позиции в транзакции tx.
+// Обновление условное: если документ уже покинул черновик, возвращает ErrDocumentLocked.
+func (edrp *esfDocumentRepositoryPostgres) updateDocument(ctx context.Context, tx *gorm.DB, doc *entity.EsfDocument) error {
+	// Обновляем основной документ
+	result := tx.Model(&entity.EsfDocument{}).
+		Where("id = ? AND status = ?", doc.ID, entity.DocumentStatusDraft).
+		Updates(doc)
+	if result.Error != nil {
+		edrp.logger.Error(ctx, "Failed to update document model", result.Error, logrus.Fields{"doc_id": doc.ID.String()})
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.New(apperror.ErrDocumentLocked, "document can only be modified in draft status")
+	}
+
+	// Updates пропускает пустые поля, поэтому связь с контрагентом снимаем явно
+	if doc.ContractorID == nil {
 		if err := tx.Model(&entity.EsfDocument{}).
 			Where("id = ?", doc.ID).
-			Updates(doc).Error; err != nil {
-			edrp.logger.Error(ctx, "Failed to update document model", err, logrus.Fields{"doc_id": doc.ID.String()})
+			Update("contractor_id", nil).Error; err != nil {
+			edrp.logger.Error(ctx, "Failed to unlink document contractor", err, logrus.Fields{"doc_id": doc.ID.String()})
 			return err
 		}
+	}
 
-		// Удаляем старые записи CatalogEntries
-		if err := tx.Where("document_id = ?", doc.ID).
-			Delete(&entity.EsfEntries{}).Error; err != nil {
-			edrp.logger.Error(ctx, "Failed to delete old catalog entries", err, logrus.Fields{"doc_id": doc.ID.String()})
+	// Удаляем старые записи CatalogEntries
+	if err := tx.Where("document_id = ?", doc.ID).
+		Delete(&entity.EsfEntries{}).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to delete old catalog entries", err, logrus.Fields{"doc_id": doc.ID.String()})
+		return err
+	}
+
+	// Создаем новые записи CatalogEntries
+	if len(doc.CatalogEntries) > 0 {
+		if err := tx.Create(&doc.CatalogEntries).Error; err != nil {
+			edrp.logger.Error(ctx, "Failed to create new catalog entries", err, logrus.Fields{"doc_id": doc.ID.String()})
 			return err
 		}
+	}
 
-		// Создаем новые записи CatalogEntries
-		if len(doc.CatalogEntries) > 0 {
-			if err := tx.Create(&doc.CatalogEntries).Error; err != nil {
-				edrp.logger.Error(ctx, "Failed to create new catalog entries", err, logrus.Fields{"doc_id": doc.ID.String()})
+	return edrp.writeRevision(ctx, tx, doc.ID, entity.RevisionActionUpdate)
+}
+
+// SaveDocuments создает и обновляет документы ЭСФ в одной транзакции БД организации:
+// при ошибке не изменяется ни один документ
+func (edrp *esfDocumentRepositoryPostgres) SaveDocuments(ctx context.Context, orgID uuid.UUID, created, updated []entity.EsfDocument) error {
+	edrp.logger.Debug(ctx, "Saving document batch in organization database", logrus.Fields{"org_id": orgID.String(), "created": len(created), "updated": len(updated)})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	err = transaction.Execute(ctx, orgDB, edrp.logger.Raw(), func(tx *gorm.DB) error {
+		for i := range created {
+			if err := tx.Create(&created[i]).Error; err != nil {
+				return err
+			}
+			if err := edrp.writeRevision(ctx, tx, created[i].ID, entity.RevisionActionCreate); err != nil {
+				return err
+			}
+		}
+		for i := range updated {
+			if err := edrp.updateDocument(ctx, tx, &updated[i]); err != nil {
 				return err
 			}
 		}
-
 		return nil
 	})
 
 	if err != nil {
-		edrp.logger.Error(ctx, "Failed to update document in database (transaction failed)", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
-		return apperror.DatabaseError("updating document", err)
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Batch document left draft status concurrently", logrus.Fields{"org_id": orgID.String()})
+			return appErr
+		}
+		edrp.logger.Error(ctx, "Failed to save document batch in database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("saving documents", err)
 	}
 
-	edrp.logger.Debug(ctx, "Document updated successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+	edrp.logger.Debug(ctx, "Document batch saved successfully", logrus.Fields{"org_id": orgID.String(), "created": len(created), "updated": len(updated)})
 	return nil
 }
 
-// DeleteDocument удаляет документ ЭСФ (soft delete)
+// DeleteDocument удаляет черновик ЭСФ без номера (soft delete).
+// Если документ уже покинул черновик или получил номер, возвращает ErrDocumentLocked.
 func (edrp *esfDocumentRepositoryPostgres) DeleteDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
 	edrp.logger.Debug(ctx, "Deleting document from organization database", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
 
@@ -165,9 +266,23 @@ func (edrp *esfDocumentRepositoryPostgres) DeleteDocument(ctx context.Context, o
 		return apperror.DatabaseError("getting organization database", err)
 	}
 
-	err = orgDB.WithContext(ctx).Delete(&entity.EsfDocument{}, id).Error
+	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("status = ? AND number_seq = 0", entity.DocumentStatusDraft).
+			Delete(&entity.EsfDocument{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperror.New(apperror.ErrDocumentLocked, "only an unnumbered draft can be deleted")
+		}
+		return edrp.writeRevision(ctx, tx, id, entity.RevisionActionDelete)
+	})
 
 	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Document left draft status concurrently", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+			return appErr
+		}
 		edrp.logger.Error(ctx, "Failed to delete document from database", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
 		return apperror.DatabaseError("deleting document", err)
 	}
@@ -176,51 +291,103 @@ func (edrp *esfDocumentRepositoryPostgres) DeleteDocument(ctx context.Context, o
 	return nil
 }
 
-// getOrgDB возвращает подключение к БД организации по ее ID, кэшируя соединения.
-func (edrp *esfDocumentRepositoryPostgres) getOrgDB(ctx context.Context, orgID uuid.UUID) (*gorm.DB, error) {
-	if orgID == uuid.Nil {
-		return nil, fmt.Errorf("organization id is required")
+// UpdateDocumentStatus атомарно переводит документ из статуса from в статус to.
+// Если статус документа уже изменился, возвращает ErrInvalidStatusTransition.
+// Документ, покидающий черновик, получает номер в той же транзакции.
+func (edrp *esfDocumentRepositoryPostgres) UpdateDocumentStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from, to entity.DocumentStatus, reason string) error {
+	edrp.logger.Debug(ctx, "Updating document status", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "from": from, "to": to})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
 	}
 
-	edrp.cacheMu.RLock()
-	if cached, ok := edrp.dbCache[orgID.String()]; ok {
-		edrp.cacheMu.RUnlock()
-		return cached, nil
+	updates := map[string]interface{}{
+		"status":            to,
+		"status_reason":     reason,
+		"status_changed_at": time.Now(),
 	}
-	edrp.cacheMu.RUnlock()
+	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if from.IsEditable() && to == entity.DocumentStatusReady {
+			if err := edrp.assignNumber(ctx, tx, id, updates); err != nil {
+				return err
+			}
+		}
+		return edrp.updateStatus(ctx, tx, id, from, updates)
+	})
 
-	var org entity.EstOrganization
-	if err := edrp.baseDB.WithContext(ctx).Select("db_name").Where("id = ?", orgID).First(&org).Error; err != nil {
-		edrp.logger.Error(ctx, "Failed to fetch organization database name", err, logrus.Fields{"orgID": orgID.String()})
-		return nil, apperror.DatabaseError("fetching organization database name", err)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Document status changed concurrently", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "from": from})
+			return appErr
+		}
+		edrp.logger.Error(ctx, "Failed to update document status", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return apperror.DatabaseError("updating document status", err)
 	}
 
-	if org.DBName == "" {
-		edrp.logger.Error(ctx, "Organization has empty database name", nil, logrus.Fields{"orgID": orgID.String()})
-		return nil, fmt.Errorf("organization %s has empty database name", orgID)
+	edrp.logger.Debug(ctx, "Document status updated successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "status": to})
+	return nil
+}
+
+// MarkDocumentSubmitted атомарно переводит документ из статуса from в submitted
+// и сохраняет идентификаторы, полученные от налоговой службы.
+func (edrp *esfDocumentRepositoryPostgres) MarkDocumentSubmitted(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from entity.DocumentStatus, responseID, externalUUID string) error {
+	edrp.logger.Debug(ctx, "Marking document as submitted", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "external_uuid": externalUUID})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
 	}
 
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	sslmode := os.Getenv("DB_SSLMODE")
-	if sslmode == "" {
-		sslmode = "disable"
+	now := time.Now()
+	err = edrp.updateStatusWithRevision(ctx, orgDB, id, from, map[string]interface{}{
+		"status":                 entity.DocumentStatusSubmitted,
+		"status_reason":          "",
+		"status_changed_at":      now,
+		"submitted_at":           now,
+		"external_response_id":   responseID,
+		"external_document_uuid": externalUUID,
+	})
+
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Document status changed concurrently", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "from": from})
+			return appErr
+		}
+		edrp.logger.Error(ctx, "Failed to mark document as submitted", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return apperror.DatabaseError("marking document as submitted", err)
 	}
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s", host, user, password, org.DBName, port, sslmode)
-	orgDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	edrp.logger.Debug(ctx, "Document marked as submitted", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+	return nil
+}
+
+// GetDocumentCorrections возвращает корректировочные счета-фактуры исходного документа
+func (edrp *esfDocumentRepositoryPostgres) GetDocumentCorrections(ctx context.Context, orgID uuid.UUID, originalID uuid.UUID) ([]entity.EsfDocument, error) {
+	edrp.logger.Debug(ctx, "Fetching document corrections", logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
 	if err != nil {
-		edrp.logger.Error(ctx, "Failed to connect to organization database", err, logrus.Fields{"dbName": org.DBName})
-		return nil, apperror.DatabaseError("connecting to organization database", err)
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
 	}
 
-	edrp.cacheMu.Lock()
-	edrp.dbCache[orgID.String()] = orgDB
-	edrp.cacheMu.Unlock()
+	var corrections []entity.EsfDocument
+	err = orgDB.WithContext(ctx).
+		Preload("CatalogEntries").
+		Where("original_document_id = ?", originalID).
+		Order("created_at ASC").
+		Find(&corrections).Error
 
-	return orgDB, nil
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to fetch document corrections", err, logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+		return nil, apperror.DatabaseError("fetching document corrections", err)
+	}
+
+	edrp.logger.Debug(ctx, "Document corrections fetched successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String(), "count": len(corrections)})
+	return corrections, nil
 }
 
 // GetAllDocumentsPaginated возвращает документы ЭСФ с пагинацией и фильтрацией
@@ -253,7 +420,22 @@ func (edrp *esfDocumentRepositoryPostgres) GetAllDocumentsPaginated(ctx context.
 
 	if filters.Search != "" {
 		edrp.logger.Debug(ctx, "Applying search filter", logrus.Fields{"search": filters.Search})
-		query = query.Where("name ILIKE ? OR description ILIKE ?", "%"+filters.Search+"%", "%"+filters.Search+"%")
+		query = query.Where("id IN (SELECT d.id FROM esf_documents d WHERE "+searchCondition(edrp.searchSupport(orgID))+")", searchArgs(strings.TrimSpace(filters.Search)))
+	}
+
+	if filters.Number != "" {
+		edrp.logger.Debug(ctx, "Applying number filter", logrus.Fields{"number": filters.Number})
+		query = query.Where("number ILIKE ?", "%"+search.EscapeLike(strings.TrimSpace(filters.Number))+"%")
+	}
+
+	if filters.Direction != "" {
+		edrp.logger.Debug(ctx, "Applying direction filter", logrus.Fields{"direction": filters.Direction})
+		query = query.Where("direction = ?", filters.Direction)
+	}
+
+	if filters.SupplierTin != "" {
+		edrp.logger.Debug(ctx, "Applying supplier TIN filter", logrus.Fields{"supplier_tin": filters.SupplierTin})
+		query = query.Where("supplier_tin = ?", strings.TrimSpace(filters.SupplierTin))
 	}
 
 	if filters.CreatedAfter != "" {
@@ -275,7 +457,7 @@ func (edrp *esfDocumentRepositoryPostgres) GetAllDocumentsPaginated(ctx context.
 	// Применяем сортировку и пагинацию
 	if err := query.
 		Preload("CatalogEntries").
-		Order(params.Sort + " " + params.Order).
+		Order(documentOrder(params)).
 		Offset(params.GetOffset()).
 		Limit(params.GetLimit()).
 		Find(&documents).Error; err != nil {
@@ -296,3 +478,36 @@ func (edrp *esfDocumentRepositoryPostgres) GetAllDocumentsPaginated(ctx context.
 
 	return documents, totalCount, nil
 }
+
+// documentOrder возвращает порядок сортировки документов.
+// Номер сортируется по году и порядковому номеру, а не как строка:
+// префикс и число цифр могут меняться в настройках нумерации.
+// Входящие документы без порядкового номера сортируются по номеру поставщика.
+// Сортировка допускается только по колонкам из documentSortColumns,
+// остальные значения заменяются на created_at.
+func documentOrder(params pagination.PaginationParams) string {
+	order := "desc"
+	if params.Order == "asc" {
+		order = "asc"
+	}
+	if params.Sort == "number" {
+		return "number_year " + order + ", number_seq " + order + ", number " + order
+	}
+	column, ok := documentSortColumns[params.Sort]
+	if !ok {
+		column = "created_at"
+	}
+	return column + " " + order
+}
+
+// documentSortColumns колонки, по которым клиент может сортировать список документов
+var documentSortColumns = map[string]string{
+	"created_at":           "created_at",
+	"updated_at":           "updated_at",
+	"delivery_date":        "delivery_date",
+	"status":               "status",
+	"contractor_tin":       "contractor_tin",
+	"supplier_tin":         "supplier_tin",
+	"currency_code":        "currency_code",
+	"total_currency_value": "total_currency_value",
+}
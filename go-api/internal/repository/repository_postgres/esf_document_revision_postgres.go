@@ -0,0 +1,141 @@
+package repositorypostgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+// GetDocumentRevisions возвращает ревизии документа (без снимков) в порядке возрастания номера
+func (edrp *esfDocumentRepositoryPostgres) GetDocumentRevisions(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentRevision, error) {
+	edrp.logger.Debug(ctx, "Fetching document revisions", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var revisions []entity.EsfDocumentRevision
+	err = orgDB.WithContext(ctx).
+		Omit("snapshot").
+		Where("document_id = ?", documentID).
+		Order("revision ASC").
+		Find(&revisions).Error
+
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to fetch document revisions", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.DatabaseError("fetching document revisions", err)
+	}
+
+	edrp.logger.Debug(ctx, "Document revisions fetched successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "count": len(revisions)})
+	return revisions, nil
+}
+
+// GetDocumentRevision возвращает ревизию документа со снимком
+func (edrp *esfDocumentRepositoryPostgres) GetDocumentRevision(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, revision int) (*entity.EsfDocumentRevision, error) {
+	edrp.logger.Debug(ctx, "Fetching document revision", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "revision": revision})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var rev entity.EsfDocumentRevision
+	err = orgDB.WithContext(ctx).
+		Where("document_id = ? AND revision = ?", documentID, revision).
+		First(&rev).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			edrp.logger.Debug(ctx, "Document revision not found", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "revision": revision})
+			return nil, apperror.New(apperror.ErrNotFound, fmt.Sprintf("revision %d not found", revision))
+		}
+		edrp.logger.Error(ctx, "Failed to fetch document revision", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.DatabaseError("fetching document revision", err)
+	}
+
+	return &rev, nil
+}
+
+// updateStatusWithRevision атомарно обновляет поля документа, если он находится в статусе from,
+// и записывает ревизию. Если статус уже изменился, возвращает ErrInvalidStatusTransition.
+func (edrp *esfDocumentRepositoryPostgres) updateStatusWithRevision(ctx context.Context, orgDB *gorm.DB, id uuid.UUID, from entity.DocumentStatus, updates map[string]interface{}) error {
+	return orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return edrp.updateStatus(ctx, tx, id, from, updates)
+	})
+}
+
+// updateStatus выполняет условное обновление updateStatusWithRevision в транзакции tx
+func (edrp *esfDocumentRepositoryPostgres) updateStatus(ctx context.Context, tx *gorm.DB, id uuid.UUID, from entity.DocumentStatus, updates map[string]interface{}) error {
+	result := tx.Model(&entity.EsfDocument{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperror.New(apperror.ErrInvalidStatusTransition, "document status has been changed by another request")
+	}
+	return edrp.writeRevision(ctx, tx, id, entity.RevisionActionStatus)
+}
+
+// writeRevision сохраняет снимок текущего состояния документа в рамках транзакции tx.
+// Вызывается после изменения документа: строка документа уже заблокирована транзакцией,
+// поэтому номера ревизий выдаются последовательно.
+func (od *organizationDatabases) writeRevision(ctx context.Context, tx *gorm.DB, documentID uuid.UUID, action entity.RevisionAction) error {
+	// Документ читается без учета soft delete (ревизия удаления), позиции - только актуальные
+	var doc entity.EsfDocument
+	if err := tx.Unscoped().Where("id = ?", documentID).First(&doc).Error; err != nil {
+		od.logger.Error(ctx, "Failed to load document for revision", err, logrus.Fields{"doc_id": documentID.String()})
+		return err
+	}
+	if err := tx.Where("document_id = ?", documentID).Find(&doc.CatalogEntries).Error; err != nil {
+		od.logger.Error(ctx, "Failed to load document entries for revision", err, logrus.Fields{"doc_id": documentID.String()})
+		return err
+	}
+
+	snapshot, err := json.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("encoding document snapshot: %w", err)
+	}
+
+	var last int
+	if err := tx.Model(&entity.EsfDocumentRevision{}).
+		Where("document_id = ?", documentID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+
+	rev := entity.EsfDocumentRevision{
+		ID:         uuid.New(),
+		DocumentID: documentID,
+		Revision:   last + 1,
+		Action:     action,
+		Snapshot:   string(snapshot),
+	}
+	if userID, ok := logger.FromContext(ctx, logger.UserIDKey); ok {
+		rev.AuthorID = fmt.Sprint(userID)
+	}
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		rev.AuthorName = fmt.Sprint(username)
+	}
+
+	if err := tx.Create(&rev).Error; err != nil {
+		od.logger.Error(ctx, "Failed to write document revision", err, logrus.Fields{"doc_id": documentID.String(), "action": action})
+		return err
+	}
+
+	od.logger.Debug(ctx, "Document revision written", logrus.Fields{"doc_id": documentID.String(), "revision": rev.Revision, "action": action})
+	return nil
+}
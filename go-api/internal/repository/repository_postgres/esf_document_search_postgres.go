@@ -0,0 +1,212 @@
+package repositorypostgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/search"
+)
+
+// searchSupport возможности поиска, доступные в БД организации
+type searchSupport struct {
+	fullText bool // колонка search_vector создана
+	trigram  bool // расширение pg_trgm установлено
+}
+
+// fullTextStatements создают полнотекстовый индекс в БД организации.
+// Используется конфигурация 'simple': в документах смешаны русский, кыргызский текст и коды.
+var fullTextStatements = []string{
+	`ALTER TABLE esf_documents ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', coalesce(contractor_tin, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(foreign_name, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(supply_contract_number, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(personal_account_number, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(comment, '')), 'C')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_search_vector ON esf_documents USING GIN (search_vector)`,
+}
+
+// trigramStatements создают триграммные индексы; расширение pg_trgm может установить
+// только суперпользователь, поэтому без него поиск работает на ILIKE
+var trigramStatements = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_number_trgm ON esf_documents USING GIN (number gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_contractor_tin_trgm ON esf_documents USING GIN (contractor_tin gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_foreign_name_trgm ON esf_documents USING GIN (foreign_name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_supply_contract_number_trgm ON esf_documents USING GIN (supply_contract_number gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_comment_trgm ON esf_documents USING GIN (comment gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_personal_account_number_trgm ON esf_documents USING GIN (personal_account_number gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_supplier_tin_trgm ON esf_documents USING GIN (supplier_tin gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_documents_supplier_name_trgm ON esf_documents USING GIN (supplier_name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_esf_entries_sales_tax_code_trgm ON esf_entries USING GIN (sales_tax_code gin_trgm_ops)`,
+}
+
+// searchColumns поля документа, в которых ищется подстрока, и их ранжирование
+var searchColumns = []string{
+	"d.number",
+	"d.contractor_tin",
+	"coalesce(d.supplier_tin, '')",
+	"coalesce(d.supplier_name, '')",
+	"d.foreign_name",
+	"d.supply_contract_number",
+	"coalesce(d.personal_account_number, '')",
+}
+
+// searchCondition отбирает документы по полнотекстовому запросу или подстроке
+// в номере, искомых полях документа и кодах позиций
+func searchCondition(support searchSupport) string {
+	var conds []string
+	if support.fullText {
+		conds = append(conds, "d.search_vector @@ websearch_to_tsquery('simple', @query)")
+	}
+	for _, column := range searchColumns {
+		conds = append(conds, column+" ILIKE @pattern")
+	}
+	conds = append(conds, `d.comment ILIKE @pattern`, `EXISTS (
+		SELECT 1 FROM esf_entries e
+		WHERE e.document_id = d.id AND e.deleted_at IS NULL AND e.sales_tax_code ILIKE @pattern
+	)`)
+	return "(" + strings.Join(conds, "\n\tOR ") + ")"
+}
+
+// searchRank ранжирует документы: полнотекстовое совпадение весомее нечеткого.
+// Без pg_trgm нечеткое совпадение заменяется совпадением подстроки.
+func searchRank(support searchSupport) string {
+	fuzzy := make([]string, len(searchColumns))
+	for i, column := range searchColumns {
+		if support.trigram {
+			fuzzy[i] = "similarity(" + column + ", @query)"
+		} else {
+			fuzzy[i] = "(" + column + " ILIKE @pattern)::int"
+		}
+	}
+
+	text := "0"
+	if support.fullText {
+		text = "ts_rank(d.search_vector, websearch_to_tsquery('simple', @query)) * 2"
+	}
+	return "(" + text + " + GREATEST(" + strings.Join(fuzzy, ", ") + ")) AS rank"
+}
+
+// ensureSearchIndexes создает поисковые индексы и возвращает доступные возможности поиска.
+// Ошибки не фатальны для работы с документами: полнотекстовый и триграммный поиск независимы.
+func (od *organizationDatabases) ensureSearchIndexes(ctx context.Context, orgDB *gorm.DB) searchSupport {
+	var support searchSupport
+	if err := orgDB.WithContext(ctx).Exec(fullTextStatements[0]).Error; err != nil {
+		od.logger.Warn(ctx, "Failed to create search vector, full-text search is unavailable", logrus.Fields{"error": err.Error()})
+	} else {
+		support.fullText = true
+		od.execSearchStatements(ctx, orgDB, fullTextStatements[1:])
+	}
+
+	if err := orgDB.WithContext(ctx).Exec(trigramStatements[0]).Error; err != nil {
+		od.logger.Warn(ctx, "pg_trgm extension is unavailable, search falls back to substring ranking", logrus.Fields{"error": err.Error()})
+	} else {
+		support.trigram = true
+		od.execSearchStatements(ctx, orgDB, trigramStatements[1:])
+	}
+	return support
+}
+
+// execSearchStatements создает индексы; без индекса поиск работает медленнее, но корректно
+func (od *organizationDatabases) execSearchStatements(ctx context.Context, orgDB *gorm.DB, statements []string) {
+	for _, stmt := range statements {
+		if err := orgDB.WithContext(ctx).Exec(stmt).Error; err != nil {
+			od.logger.Warn(ctx, "Failed to create search index", logrus.Fields{"error": err.Error()})
+		}
+	}
+}
+
+// searchSupport возвращает возможности поиска, определенные при подключении к БД организации
+func (od *organizationDatabases) searchSupport(orgID uuid.UUID) searchSupport {
+	od.cacheMu.RLock()
+	defer od.cacheMu.RUnlock()
+	return od.searchCaps[orgID.String()]
+}
+
+// searchArgs возвращает именованные параметры для searchCondition и searchRank
+func searchArgs(query string) map[string]interface{} {
+	return map[string]interface{}{
+		"query":   query,
+		"pattern": "%" + search.EscapeLike(query) + "%",
+	}
+}
+
+// SearchDocuments выполняет полнотекстовый поиск документов с ранжированием
+func (edrp *esfDocumentRepositoryPostgres) SearchDocuments(ctx context.Context, orgID uuid.UUID, query string, params pagination.PaginationParams) ([]repository.DocumentSearchHit, int64, error) {
+	edrp.logger.Debug(ctx, "Searching documents", logrus.Fields{"org_id": orgID.String(), "query": query, "page": params.Page})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, 0, apperror.DatabaseError("getting organization database", err)
+	}
+
+	support := edrp.searchSupport(orgID)
+	args := searchArgs(query)
+	base := func() *gorm.DB {
+		return orgDB.WithContext(ctx).
+			Table("esf_documents AS d").
+			Where("d.deleted_at IS NULL").
+			Where(searchCondition(support), args)
+	}
+
+	var totalCount int64
+	if err := base().Count(&totalCount).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to count search results", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, 0, apperror.DatabaseError("counting search results", err)
+	}
+
+	var ranked []struct {
+		ID   uuid.UUID
+		Rank float64
+	}
+	if err := base().
+		Select("d.id, "+searchRank(support), args).
+		Order("rank DESC, d.created_at DESC").
+		Offset(params.GetOffset()).
+		Limit(params.GetLimit()).
+		Scan(&ranked).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to search documents", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, 0, apperror.DatabaseError("searching documents", err)
+	}
+
+	if len(ranked) == 0 {
+		return []repository.DocumentSearchHit{}, totalCount, nil
+	}
+
+	ids := make([]uuid.UUID, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+
+	var documents []entity.EsfDocument
+	if err := orgDB.WithContext(ctx).Preload("CatalogEntries").Where("id IN ?", ids).Find(&documents).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to load found documents", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, 0, apperror.DatabaseError("loading found documents", err)
+	}
+
+	byID := make(map[uuid.UUID]entity.EsfDocument, len(documents))
+	for _, doc := range documents {
+		byID[doc.ID] = doc
+	}
+
+	// Сохраняем порядок ранжирования
+	hits := make([]repository.DocumentSearchHit, 0, len(ranked))
+	for _, r := range ranked {
+		if doc, ok := byID[r.ID]; ok {
+			hits = append(hits, repository.DocumentSearchHit{Document: doc, Rank: r.Rank})
+		}
+	}
+
+	edrp.logger.Debug(ctx, "Document search completed", logrus.Fields{"org_id": orgID.String(), "count": len(hits), "total": totalCount})
+	return hits, totalCount, nil
+}
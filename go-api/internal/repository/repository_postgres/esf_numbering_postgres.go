@@ -0,0 +1,138 @@
+package repositorypostgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/numbering"
+)
+
+// nextNumberStatement увеличивает счетчик года и возвращает новый порядковый номер.
+// Строка счетчика остается заблокированной до конца транзакции смены статуса документа:
+// параллельные переводы ждут друг друга, а при откате транзакции номер не расходуется.
+const nextNumberStatement = `INSERT INTO esf_number_sequences (year, last_number, updated_at) VALUES (?, 1, NOW())
+	ON CONFLICT (year) DO UPDATE SET last_number = esf_number_sequences.last_number + 1, updated_at = NOW()
+	RETURNING last_number`
+
+type esfNumberingRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfNumberingRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfNumberingRepository {
+	return &esfNumberingRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetNumberingSettings возвращает настройки нумерации; если они не сохранялись - значения по умолчанию
+func (enrp *esfNumberingRepositoryPostgres) GetNumberingSettings(ctx context.Context, orgID uuid.UUID) (*entity.EsfNumberingSettings, error) {
+	orgDB, err := enrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		enrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	settings, err := numberingSettings(orgDB.WithContext(ctx))
+	if err != nil {
+		enrp.logger.Error(ctx, "Failed to fetch numbering settings", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching numbering settings", err)
+	}
+	return settings, nil
+}
+
+// SaveNumberingSettings сохраняет настройки нумерации; они применяются к следующим документам
+func (enrp *esfNumberingRepositoryPostgres) SaveNumberingSettings(ctx context.Context, orgID uuid.UUID, settings *entity.EsfNumberingSettings) error {
+	enrp.logger.Debug(ctx, "Saving numbering settings", logrus.Fields{"org_id": orgID.String(), "prefix": settings.Prefix})
+
+	orgDB, err := enrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		enrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	settings.ID = entity.NumberingSettingsID
+	err = orgDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"prefix", "width", "updated_at", "updated_by"}),
+	}).Create(settings).Error
+	if err != nil {
+		enrp.logger.Error(ctx, "Failed to save numbering settings", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("saving numbering settings", err)
+	}
+	return nil
+}
+
+// GetNumberSequences возвращает счетчики номеров по годам, начиная с последнего
+func (enrp *esfNumberingRepositoryPostgres) GetNumberSequences(ctx context.Context, orgID uuid.UUID) ([]entity.EsfNumberSequence, error) {
+	orgDB, err := enrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		enrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var sequences []entity.EsfNumberSequence
+	if err := orgDB.WithContext(ctx).Order("year DESC").Find(&sequences).Error; err != nil {
+		enrp.logger.Error(ctx, "Failed to fetch number sequences", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching number sequences", err)
+	}
+	return sequences, nil
+}
+
+// assignNumber присваивает номер документу id, покидающему черновик, и добавляет его в updates
+// условного обновления статуса. Номер выдается в транзакции tx смены статуса: черновики
+// номер не расходуют, а при откате транзакции (в том числе если статус уже изменился)
+// счетчик не увеличивается, поэтому номера идут без пропусков.
+// Документ, возвращенный в черновик, сохраняет номер; входящие документы сохраняют номер поставщика.
+func (edrp *esfDocumentRepositoryPostgres) assignNumber(ctx context.Context, tx *gorm.DB, id uuid.UUID, updates map[string]interface{}) error {
+	var doc entity.EsfDocument
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "direction", "number_seq").
+		Where("id = ?", id).
+		First(&doc).Error
+	if err != nil {
+		return err
+	}
+	if doc.IsIncoming() || doc.NumberSeq > 0 {
+		return nil
+	}
+
+	settings, err := numberingSettings(tx)
+	if err != nil {
+		return err
+	}
+
+	year := time.Now().Year()
+	var seq int64
+	if err := tx.Raw(nextNumberStatement, year).Scan(&seq).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to allocate document number", err, logrus.Fields{"year": year})
+		return err
+	}
+
+	updates["number_year"] = year
+	updates["number_seq"] = seq
+	updates["number"] = numbering.Format(settings.Prefix, year, seq, settings.Width)
+	return nil
+}
+
+func numberingSettings(db *gorm.DB) (*entity.EsfNumberingSettings, error) {
+	var settings entity.EsfNumberingSettings
+	err := db.Where("id = ?", entity.NumberingSettingsID).First(&settings).Error
+	if err == gorm.ErrRecordNotFound {
+		return &entity.EsfNumberingSettings{ID: entity.NumberingSettingsID, Prefix: numbering.DefaultPrefix, Width: numbering.DefaultWidth}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
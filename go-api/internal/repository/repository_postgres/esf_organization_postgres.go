@@ -64,6 +64,25 @@ func (eop *esfOrganizationPostgres) GetByID(ctx context.Context, id string) (*en
 	return &organization, nil
 }
 
+// GetByTin возвращает организацию по ИНН
+func (eop *esfOrganizationPostgres) GetByTin(ctx context.Context, tin string) (*entity.EstOrganization, error) {
+	eop.logger.Debug(ctx, "Fetching organization by TIN from database", logrus.Fields{"tin": tin})
+
+	var organization entity.EstOrganization
+
+	if err := eop.db.WithContext(ctx).Where("tin = ? AND deleted_at IS NULL", tin).First(&organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			eop.logger.Debug(ctx, "Organization not found", logrus.Fields{"tin": tin})
+			return nil, nil
+		}
+		eop.logger.Error(ctx, "Failed to fetch organization by TIN from database", err, logrus.Fields{"tin": tin})
+		return nil, apperror.DatabaseError("fetching organization by TIN", err)
+	}
+
+	eop.logger.Debug(ctx, "Organization fetched successfully", logrus.Fields{"id": organization.ID.String()})
+	return &organization, nil
+}
+
 // Insert создает новую организацию в БД
 func (eop *esfOrganizationPostgres) Insert(ctx context.Context, org *entity.EstOrganization) error {
 	eop.logger.Debug(ctx, "Inserting organization into database", logrus.Fields{"name": org.Name, "id": org.ID.String()})
@@ -143,8 +162,8 @@ func (eop *esfOrganizationPostgres) CreateDatabase(ctx context.Context, dbName s
 	}
 	// не прерываем, так как возможно миграции пройдут, если расширение уже есть/не требуется
 
-	// Применяем миграции для пустых таблиц EsfDocument и EsfEntries
-	if err := newDB.WithContext(ctx).AutoMigrate(&entity.EsfDocument{}, &entity.EsfEntries{}); err != nil {
+	// Применяем миграции для таблиц организации
+	if err := newDB.WithContext(ctx).AutoMigrate(entity.OrganizationModels()...); err != nil {
 		eop.logger.Error(ctx, "Failed to run migrations in new database", err, logrus.Fields{"dbName": dbName})
 		return apperror.DatabaseError("running migrations", err)
 	}
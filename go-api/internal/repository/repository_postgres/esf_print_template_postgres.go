@@ -0,0 +1,80 @@
+package repositorypostgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// GetPrintTemplate возвращает печатную форму организации по имени
+func (edrp *esfDocumentRepositoryPostgres) GetPrintTemplate(ctx context.Context, orgID uuid.UUID, name string) (*entity.EsfPrintTemplate, error) {
+	edrp.logger.Debug(ctx, "Fetching print template", logrus.Fields{"org_id": orgID.String(), "template": name})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var tpl entity.EsfPrintTemplate
+	if err := orgDB.WithContext(ctx).Where("name = ?", name).First(&tpl).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("print template")
+		}
+		edrp.logger.Error(ctx, "Failed to fetch print template", err, logrus.Fields{"org_id": orgID.String(), "template": name})
+		return nil, apperror.DatabaseError("fetching print template", err)
+	}
+
+	return &tpl, nil
+}
+
+// SavePrintTemplate создает или заменяет печатную форму с тем же именем
+func (edrp *esfDocumentRepositoryPostgres) SavePrintTemplate(ctx context.Context, orgID uuid.UUID, tpl *entity.EsfPrintTemplate) error {
+	edrp.logger.Debug(ctx, "Saving print template", logrus.Fields{"org_id": orgID.String(), "template": tpl.Name})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if tpl.ID == uuid.Nil {
+		tpl.ID = uuid.New()
+	}
+
+	err = orgDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content", "updated_at", "updated_by"}),
+	}).Create(tpl).Error
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to save print template", err, logrus.Fields{"org_id": orgID.String(), "template": tpl.Name})
+		return apperror.DatabaseError("saving print template", err)
+	}
+
+	edrp.logger.Debug(ctx, "Print template saved successfully", logrus.Fields{"org_id": orgID.String(), "template": tpl.Name})
+	return nil
+}
+
+// DeletePrintTemplate удаляет печатную форму организации; после этого используется форма по умолчанию
+func (edrp *esfDocumentRepositoryPostgres) DeletePrintTemplate(ctx context.Context, orgID uuid.UUID, name string) error {
+	edrp.logger.Debug(ctx, "Deleting print template", logrus.Fields{"org_id": orgID.String(), "template": name})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if err := orgDB.WithContext(ctx).Where("name = ?", name).Delete(&entity.EsfPrintTemplate{}).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to delete print template", err, logrus.Fields{"org_id": orgID.String(), "template": name})
+		return apperror.DatabaseError("deleting print template", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package repositorypostgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+type esfRecurringRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfRecurringRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfRecurringRepository {
+	return &esfRecurringRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetRecurringTemplates возвращает шаблоны повторяющихся документов по наименованию
+func (errcp *esfRecurringRepositoryPostgres) GetRecurringTemplates(ctx context.Context, orgID uuid.UUID) ([]entity.EsfRecurringTemplate, error) {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var templates []entity.EsfRecurringTemplate
+	if err := orgDB.WithContext(ctx).Order("name").Find(&templates).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to fetch recurring templates", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching recurring templates", err)
+	}
+	return templates, nil
+}
+
+// GetRecurringTemplateByID возвращает шаблон повторяющегося документа
+func (errcp *esfRecurringRepositoryPostgres) GetRecurringTemplateByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfRecurringTemplate, error) {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var template entity.EsfRecurringTemplate
+	if err := orgDB.WithContext(ctx).Where("id = ?", id).First(&template).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("recurring template")
+		}
+		errcp.logger.Error(ctx, "Failed to fetch recurring template", err, logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+		return nil, apperror.DatabaseError("fetching recurring template", err)
+	}
+	return &template, nil
+}
+
+// GetDueRecurringTemplates возвращает активные шаблоны, дата выпуска которых наступила к date
+func (errcp *esfRecurringRepositoryPostgres) GetDueRecurringTemplates(ctx context.Context, orgID uuid.UUID, date time.Time) ([]entity.EsfRecurringTemplate, error) {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var templates []entity.EsfRecurringTemplate
+	if err := orgDB.WithContext(ctx).
+		Where("is_active AND next_run_date IS NOT NULL AND next_run_date <= ?", date).
+		Order("next_run_date").
+		Find(&templates).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to fetch due recurring templates", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching due recurring templates", err)
+	}
+	return templates, nil
+}
+
+// CreateRecurringTemplate создает шаблон повторяющегося документа
+func (errcp *esfRecurringRepositoryPostgres) CreateRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate) error {
+	errcp.logger.Debug(ctx, "Creating recurring template", logrus.Fields{"org_id": orgID.String(), "name": template.Name})
+
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if template.ID == uuid.Nil {
+		template.ID = uuid.New()
+	}
+	if err := orgDB.WithContext(ctx).Create(template).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to create recurring template", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("creating recurring template", err)
+	}
+	return nil
+}
+
+// UpdateRecurringTemplate заменяет документ и правило повторения шаблона
+func (errcp *esfRecurringRepositoryPostgres) UpdateRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate) error {
+	errcp.logger.Debug(ctx, "Updating recurring template", logrus.Fields{"org_id": orgID.String(), "template_id": template.ID.String()})
+
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	result := orgDB.WithContext(ctx).Model(&entity.EsfRecurringTemplate{}).Where("id = ?", template.ID).Updates(map[string]interface{}{
+		"name":          template.Name,
+		"document":      template.Document,
+		"frequency":     template.Frequency,
+		"interval":      template.Interval,
+		"day_of_month":  template.DayOfMonth,
+		"start_date":    template.StartDate,
+		"end_date":      template.EndDate,
+		"is_active":     template.IsActive,
+		"next_run_date": template.NextRunDate,
+		"last_error":    template.LastError,
+		"updated_by":    template.UpdatedBy,
+	})
+	if result.Error != nil {
+		errcp.logger.Error(ctx, "Failed to update recurring template", result.Error, logrus.Fields{"org_id": orgID.String(), "template_id": template.ID.String()})
+		return apperror.DatabaseError("updating recurring template", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFoundError("recurring template")
+	}
+	return nil
+}
+
+// DeleteRecurringTemplate удаляет шаблон; созданные по нему документы сохраняются
+func (errcp *esfRecurringRepositoryPostgres) DeleteRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	errcp.logger.Debug(ctx, "Deleting recurring template", logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	result := orgDB.WithContext(ctx).Where("id = ?", id).Delete(&entity.EsfRecurringTemplate{})
+	if result.Error != nil {
+		errcp.logger.Error(ctx, "Failed to delete recurring template", result.Error, logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+		return apperror.DatabaseError("deleting recurring template", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFoundError("recurring template")
+	}
+	return nil
+}
+
+// SaveRecurringSchedule сохраняет дату следующего выпуска и ошибку последнего выпуска
+func (errcp *esfRecurringRepositoryPostgres) SaveRecurringSchedule(ctx context.Context, orgID uuid.UUID, id uuid.UUID, nextRunDate *time.Time, lastError string) error {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if err := orgDB.WithContext(ctx).Model(&entity.EsfRecurringTemplate{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"next_run_date": nextRunDate,
+		"last_error":    lastError,
+	}).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to save recurring schedule", err, logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+		return apperror.DatabaseError("saving recurring schedule", err)
+	}
+	return nil
+}
+
+// GetRecurringRuns возвращает выпуски документов по шаблону, начиная с последнего периода
+func (errcp *esfRecurringRepositoryPostgres) GetRecurringRuns(ctx context.Context, orgID uuid.UUID, templateID uuid.UUID) ([]entity.EsfRecurringRun, error) {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var runs []entity.EsfRecurringRun
+	if err := orgDB.WithContext(ctx).Where("template_id = ?", templateID).Order("period DESC").Find(&runs).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to fetch recurring runs", err, logrus.Fields{"org_id": orgID.String(), "template_id": templateID.String()})
+		return nil, apperror.DatabaseError("fetching recurring runs", err)
+	}
+	return runs, nil
+}
+
+// ReserveRecurringRun резервирует выпуск за период. Возвращает false, если выпуск
+// за этот период уже зарезервирован (документ создан или создается другим экземпляром).
+func (errcp *esfRecurringRepositoryPostgres) ReserveRecurringRun(ctx context.Context, orgID uuid.UUID, run *entity.EsfRecurringRun) (bool, error) {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return false, apperror.DatabaseError("getting organization database", err)
+	}
+
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	result := orgDB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "template_id"}, {Name: "period"}},
+		DoNothing: true,
+	}).Create(run)
+	if result.Error != nil {
+		errcp.logger.Error(ctx, "Failed to reserve recurring run", result.Error, logrus.Fields{"org_id": orgID.String(), "template_id": run.TemplateID.String(), "period": run.Period})
+		return false, apperror.DatabaseError("reserving recurring run", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CompleteRecurringRun связывает выпуск с созданным документом
+func (errcp *esfRecurringRepositoryPostgres) CompleteRecurringRun(ctx context.Context, orgID uuid.UUID, runID uuid.UUID, documentID uuid.UUID) error {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if err := orgDB.WithContext(ctx).Model(&entity.EsfRecurringRun{}).Where("id = ?", runID).Update("document_id", documentID).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to complete recurring run", err, logrus.Fields{"org_id": orgID.String(), "run_id": runID.String()})
+		return apperror.DatabaseError("completing recurring run", err)
+	}
+	return nil
+}
+
+// DeleteRecurringRun снимает резерв выпуска, документ по которому не удалось создать
+func (errcp *esfRecurringRepositoryPostgres) DeleteRecurringRun(ctx context.Context, orgID uuid.UUID, runID uuid.UUID) error {
+	orgDB, err := errcp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errcp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if err := orgDB.WithContext(ctx).Where("id = ?", runID).Delete(&entity.EsfRecurringRun{}).Error; err != nil {
+		errcp.logger.Error(ctx, "Failed to delete recurring run", err, logrus.Fields{"org_id": orgID.String(), "run_id": runID.String()})
+		return apperror.DatabaseError("deleting recurring run", err)
+	}
+	return nil
+}
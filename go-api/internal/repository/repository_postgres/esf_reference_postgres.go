@@ -0,0 +1,122 @@
+package repositorypostgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/transaction"
+)
+
+// esfReferencePostgres реализует интерфейс EsfReferenceRepository для PostgreSQL
+type esfReferencePostgres struct {
+	logger *logger.Logger
+	db     *gorm.DB
+}
+
+// NewEsfReferenceRepositoryPostgres создает репозиторий справочников кодов ЭСФ
+func NewEsfReferenceRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfReferenceRepository {
+	return &esfReferencePostgres{
+		logger: logger.New(log),
+		db:     db,
+	}
+}
+
+// GetActiveVersions возвращает активные версии всех справочников
+func (erp *esfReferencePostgres) GetActiveVersions(ctx context.Context) ([]entity.EsfReferenceVersion, error) {
+	var versions []entity.EsfReferenceVersion
+	if err := erp.db.WithContext(ctx).Where("active = ?", true).Order("catalog").Find(&versions).Error; err != nil {
+		erp.logger.Error(ctx, "Failed to fetch active reference versions", err, logrus.Fields{})
+		return nil, apperror.DatabaseError("fetching reference versions", err)
+	}
+	return versions, nil
+}
+
+// GetActiveVersion возвращает активную версию справочника
+func (erp *esfReferencePostgres) GetActiveVersion(ctx context.Context, catalog string) (*entity.EsfReferenceVersion, error) {
+	var version entity.EsfReferenceVersion
+	err := erp.db.WithContext(ctx).Where("catalog = ? AND active = ?", catalog, true).First(&version).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.NotFoundError("reference catalog")
+		}
+		erp.logger.Error(ctx, "Failed to fetch active reference version", err, logrus.Fields{"catalog": catalog})
+		return nil, apperror.DatabaseError("fetching reference version", err)
+	}
+	return &version, nil
+}
+
+// GetItems возвращает коды версий в порядке сортировки
+func (erp *esfReferencePostgres) GetItems(ctx context.Context, versionIDs ...uuid.UUID) ([]entity.EsfReferenceItem, error) {
+	var items []entity.EsfReferenceItem
+	if len(versionIDs) == 0 {
+		return items, nil
+	}
+
+	if err := erp.db.WithContext(ctx).
+		Where("version_id IN ?", versionIDs).
+		Order("version_id, sort_order, code").
+		Find(&items).Error; err != nil {
+		erp.logger.Error(ctx, "Failed to fetch reference items", err, logrus.Fields{"versions": len(versionIDs)})
+		return nil, apperror.DatabaseError("fetching reference items", err)
+	}
+	return items, nil
+}
+
+// VersionExists проверяет, загружена ли версия справочника
+func (erp *esfReferencePostgres) VersionExists(ctx context.Context, catalog, version string) (bool, error) {
+	var count int64
+	if err := erp.db.WithContext(ctx).Model(&entity.EsfReferenceVersion{}).
+		Where("catalog = ? AND version = ?", catalog, version).
+		Count(&count).Error; err != nil {
+		erp.logger.Error(ctx, "Failed to check reference version", err, logrus.Fields{"catalog": catalog, "version": version})
+		return false, apperror.DatabaseError("checking reference version", err)
+	}
+	return count > 0, nil
+}
+
+// CreateVersion атомарно создает версию справочника с кодами.
+// При activate остальные версии справочника деактивируются.
+func (erp *esfReferencePostgres) CreateVersion(ctx context.Context, version *entity.EsfReferenceVersion, items []entity.EsfReferenceItem, activate bool) error {
+	erp.logger.Debug(ctx, "Creating reference version", logrus.Fields{"catalog": version.Catalog, "version": version.Version, "items": len(items)})
+
+	if version.ID == uuid.Nil {
+		version.ID = uuid.New()
+	}
+	version.Active = activate
+
+	err := transaction.Execute(ctx, erp.db, erp.logger.Raw(), func(tx *gorm.DB) error {
+		if activate {
+			if err := tx.Model(&entity.EsfReferenceVersion{}).
+				Where("catalog = ? AND active = ?", version.Catalog, true).
+				Update("active", false).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		for i := range items {
+			items[i].VersionID = version.ID
+		}
+		if len(items) > 0 {
+			return tx.CreateInBatches(items, 500).Error
+		}
+		return nil
+	})
+
+	if err != nil {
+		erp.logger.Error(ctx, "Failed to create reference version", err, logrus.Fields{"catalog": version.Catalog, "version": version.Version})
+		return apperror.DatabaseError("creating reference version", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package repositorypostgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+type esfReportRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfReportRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfReportRepository {
+	return &esfReportRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// counterpartyTin ИНН контрагента документа: покупателя исходящего или поставщика входящего
+const counterpartyTin = "CASE WHEN d.direction = 'incoming' THEN d.supplier_tin ELSE d.contractor_tin END"
+
+// reportScope отбирает документы периода, статусов и направления фильтра
+func reportScope(filter repository.LedgerFilter) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("d.deleted_at IS NULL AND d.delivery_date >= ? AND d.delivery_date < ? AND d.status IN ?", filter.From, filter.To, filter.Statuses)
+		if filter.Direction != "" {
+			db = db.Where("d.direction = ?", filter.Direction)
+		}
+		return db
+	}
+}
+
+// GetLedgerDocuments возвращает документы периода с суммами позиций, упорядоченные
+// по ставке НДС, ИНН контрагента, дате поставки и номеру. Суммы складываются в numeric без потерь.
+func (errp *esfReportRepositoryPostgres) GetLedgerDocuments(ctx context.Context, orgID uuid.UUID, filter repository.LedgerFilter) ([]repository.LedgerDocument, error) {
+	orgDB, err := errp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var rows []repository.LedgerDocument
+	err = orgDB.WithContext(ctx).
+		Table("esf_documents AS d").
+		Select(`d.id, d.number, d.delivery_date, d.status, d.direction, d.tax_rate_vat_code,
+			` + counterpartyTin + ` AS contractor_tin, d.contractor_id, d.supplier_name,
+			d.original_document_id, d.currency_code,
+			COUNT(e.id) AS entries,
+			SUM(e.amount_without_taxes) AS amount_without_taxes,
+			SUM(e.vat_amount) AS vat_amount,
+			SUM(e.sales_tax_amount) AS sales_tax_amount,
+			SUM(e.total_amount) AS total_amount`).
+		Joins("JOIN esf_entries AS e ON e.document_id = d.id AND e.deleted_at IS NULL").
+		Scopes(reportScope(filter)).
+		Group("d.id").
+		Order("d.tax_rate_vat_code, contractor_tin, d.delivery_date, d.number_year, d.number_seq, d.number, d.id").
+		Scan(&rows).Error
+	if err != nil {
+		errp.logger.Error(ctx, "Failed to fetch ledger documents", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching ledger documents", err)
+	}
+	return rows, nil
+}
+
+// aggregateGroups выражения группировки и порядок групп; значения AggregateGroup не попадают в SQL напрямую
+var aggregateGroups = map[repository.AggregateGroup]struct{ key, order string }{
+	repository.AggregateTotal:        {key: "''"},
+	repository.AggregateByMonth:      {key: "to_char(d.delivery_date, 'YYYY-MM')", order: "key"},
+	repository.AggregateByContractor: {key: counterpartyTin, order: "total_amount DESC, key"},
+	repository.AggregateByVATCode:    {key: "d.tax_rate_vat_code", order: "key"},
+}
+
+// GetDocumentAggregates считает суммы позиций документов периода агрегатными запросами в БД.
+// Позиции сначала суммируются по документу, поэтому Documents - число документов, а не позиций.
+func (errp *esfReportRepositoryPostgres) GetDocumentAggregates(ctx context.Context, orgID uuid.UUID, filter repository.LedgerFilter, groupBy repository.AggregateGroup, limit int) ([]repository.AggregateBucket, error) {
+	group, ok := aggregateGroups[groupBy]
+	if !ok {
+		return nil, apperror.ValidationError("unknown aggregate group " + string(groupBy))
+	}
+
+	orgDB, err := errp.getOrgDB(ctx, orgID)
+	if err != nil {
+		errp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	entries := orgDB.Table("esf_entries").
+		Select(`document_id,
+			SUM(amount_without_taxes) AS amount_without_taxes,
+			SUM(vat_amount) AS vat_amount,
+			SUM(sales_tax_amount) AS sales_tax_amount,
+			SUM(total_amount) AS total_amount`).
+		Where("deleted_at IS NULL").
+		Group("document_id")
+
+	db := orgDB.WithContext(ctx).
+		Table("esf_documents AS d").
+		Select(group.key+` AS key,
+			COUNT(*) AS documents,
+			COALESCE(SUM(e.amount_without_taxes), 0) AS amount_without_taxes,
+			COALESCE(SUM(e.vat_amount), 0) AS vat_amount,
+			COALESCE(SUM(e.sales_tax_amount), 0) AS sales_tax_amount,
+			COALESCE(SUM(e.total_amount), 0) AS total_amount`).
+		Joins("LEFT JOIN (?) AS e ON e.document_id = d.id", entries).
+		Scopes(reportScope(filter))
+	// Итоги без группировки возвращают одну строку и для пустого периода
+	if groupBy != repository.AggregateTotal {
+		db = db.Group("key").Order(group.order)
+	}
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	var buckets []repository.AggregateBucket
+	if err := db.Scan(&buckets).Error; err != nil {
+		errp.logger.Error(ctx, "Failed to aggregate documents", err, logrus.Fields{"org_id": orgID.String(), "group_by": string(groupBy)})
+		return nil, apperror.DatabaseError("aggregating documents", err)
+	}
+	return buckets, nil
+}
@@ -0,0 +1,87 @@
+package repositorypostgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// GetDocumentSignatures возвращает подписи документа в порядке подписания
+func (edrp *esfDocumentRepositoryPostgres) GetDocumentSignatures(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentSignature, error) {
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var signatures []entity.EsfDocumentSignature
+	if err := orgDB.WithContext(ctx).Where("document_id = ?", documentID).Order("created_at").Find(&signatures).Error; err != nil {
+		edrp.logger.Error(ctx, "Failed to fetch document signatures", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.DatabaseError("fetching document signatures", err)
+	}
+	return signatures, nil
+}
+
+// SignDocument сохраняет подпись документа и переводит его в статус to.
+// Подпись сохраняется, только если документ не менялся после чтения (статус и updated_at совпадают с doc),
+// иначе подписанное содержимое могло устареть.
+func (edrp *esfDocumentRepositoryPostgres) SignDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, signature *entity.EsfDocumentSignature, to entity.DocumentStatus) error {
+	edrp.logger.Debug(ctx, "Signing document", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "key_id": signature.KeyID.String()})
+
+	orgDB, err := edrp.getOrgDB(ctx, orgID)
+	if err != nil {
+		edrp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if signature.ID == uuid.Nil {
+		signature.ID = uuid.New()
+	}
+	signature.DocumentID = doc.ID
+
+	err = orgDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		unchanged := tx.Model(&entity.EsfDocument{}).Where("id = ? AND status = ? AND updated_at = ?", doc.ID, doc.Status, doc.UpdatedAt)
+		if to != doc.Status {
+			result := unchanged.Updates(map[string]interface{}{
+				"status":            to,
+				"status_reason":     "",
+				"status_changed_at": time.Now(),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return apperror.New(apperror.ErrInvalidStatusTransition, "document has been changed by another request")
+			}
+			if err := edrp.writeRevision(ctx, tx, doc.ID, entity.RevisionActionStatus); err != nil {
+				return err
+			}
+		} else {
+			var locked []uuid.UUID
+			if err := unchanged.Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &locked).Error; err != nil {
+				return err
+			}
+			if len(locked) == 0 {
+				return apperror.New(apperror.ErrInvalidStatusTransition, "document has been changed by another request")
+			}
+		}
+		return tx.Create(signature).Error
+	})
+
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			edrp.logger.Warn(ctx, "Document changed while signing", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+			return appErr
+		}
+		edrp.logger.Error(ctx, "Failed to sign document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+		return apperror.DatabaseError("signing document", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repositorypostgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+type esfSigningKeyRepositoryPostgres struct {
+	*organizationDatabases
+	logger *logger.Logger
+}
+
+func NewEsfSigningKeyRepositoryPostgres(db *gorm.DB, log *logrus.Logger) repository.EsfSigningKeyRepository {
+	return &esfSigningKeyRepositoryPostgres{
+		organizationDatabases: sharedOrganizationDatabases(db, log),
+		logger:                logger.New(log),
+	}
+}
+
+// GetSigningKeys возвращает ключи подписи организации, включая отозванные
+func (eskp *esfSigningKeyRepositoryPostgres) GetSigningKeys(ctx context.Context, orgID uuid.UUID) ([]entity.EsfSigningKey, error) {
+	orgDB, err := eskp.getOrgDB(ctx, orgID)
+	if err != nil {
+		eskp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var keys []entity.EsfSigningKey
+	if err := orgDB.WithContext(ctx).Order("created_at").Find(&keys).Error; err != nil {
+		eskp.logger.Error(ctx, "Failed to fetch signing keys", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching signing keys", err)
+	}
+	return keys, nil
+}
+
+// GetSigningKeyByID возвращает ключ подписи по идентификатору
+func (eskp *esfSigningKeyRepositoryPostgres) GetSigningKeyByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfSigningKey, error) {
+	return eskp.getSigningKey(ctx, orgID, "id = ?", id)
+}
+
+// GetSigningKeyByFingerprint возвращает ключ подписи по отпечатку
+func (eskp *esfSigningKeyRepositoryPostgres) GetSigningKeyByFingerprint(ctx context.Context, orgID uuid.UUID, fingerprint string) (*entity.EsfSigningKey, error) {
+	return eskp.getSigningKey(ctx, orgID, "fingerprint = ?", fingerprint)
+}
+
+func (eskp *esfSigningKeyRepositoryPostgres) getSigningKey(ctx context.Context, orgID uuid.UUID, condition string, value interface{}) (*entity.EsfSigningKey, error) {
+	orgDB, err := eskp.getOrgDB(ctx, orgID)
+	if err != nil {
+		eskp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("getting organization database", err)
+	}
+
+	var key entity.EsfSigningKey
+	if err := orgDB.WithContext(ctx).Where(condition, value).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperror.NotFoundError("signing key")
+		}
+		eskp.logger.Error(ctx, "Failed to fetch signing key", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.DatabaseError("fetching signing key", err)
+	}
+	return &key, nil
+}
+
+// CreateSigningKey сохраняет открытый ключ подписи
+func (eskp *esfSigningKeyRepositoryPostgres) CreateSigningKey(ctx context.Context, orgID uuid.UUID, key *entity.EsfSigningKey) error {
+	eskp.logger.Debug(ctx, "Creating signing key", logrus.Fields{"org_id": orgID.String(), "fingerprint": key.Fingerprint})
+
+	orgDB, err := eskp.getOrgDB(ctx, orgID)
+	if err != nil {
+		eskp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	if err := orgDB.WithContext(ctx).Create(key).Error; err != nil {
+		eskp.logger.Error(ctx, "Failed to create signing key", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("creating signing key", err)
+	}
+	return nil
+}
+
+// RevokeSigningKey отзывает ключ подписи; повторный отзыв возвращает NotFound
+func (eskp *esfSigningKeyRepositoryPostgres) RevokeSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID, revokedAt time.Time) error {
+	eskp.logger.Debug(ctx, "Revoking signing key", logrus.Fields{"org_id": orgID.String(), "key_id": id.String()})
+
+	orgDB, err := eskp.getOrgDB(ctx, orgID)
+	if err != nil {
+		eskp.logger.Error(ctx, "Failed to get organization database", err, logrus.Fields{"org_id": orgID.String()})
+		return apperror.DatabaseError("getting organization database", err)
+	}
+
+	result := orgDB.WithContext(ctx).Model(&entity.EsfSigningKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		eskp.logger.Error(ctx, "Failed to revoke signing key", result.Error, logrus.Fields{"org_id": orgID.String(), "key_id": id.String()})
+		return apperror.DatabaseError("revoking signing key", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperror.NotFoundError("signing key")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package repositorypostgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+)
+
+// organizationDatabases подключения к БД организаций, открытые через основную БД baseDB.
+// Документы, контрагенты, нумерация и другие данные организации хранятся в одной БД,
+// поэтому репозитории, созданные от одной основной БД, используют общие подключения.
+type organizationDatabases struct {
+	logger  *logger.Logger
+	baseDB  *gorm.DB
+	dbCache map[string]*gorm.DB
+	// Возможности поиска в БД организации (зависят от прав на установку pg_trgm)
+	searchCaps map[string]searchSupport
+	cacheMu    sync.RWMutex
+}
+
+// organizationDatabasesByBase общие подключения к БД организаций по основной БД
+var organizationDatabasesByBase sync.Map
+
+// sharedOrganizationDatabases возвращает подключения к БД организаций, общие для репозиториев основной БД db
+func sharedOrganizationDatabases(db *gorm.DB, log *logrus.Logger) *organizationDatabases {
+	if existing, ok := organizationDatabasesByBase.Load(db); ok {
+		return existing.(*organizationDatabases)
+	}
+	created := &organizationDatabases{
+		baseDB:     db,
+		logger:     logger.New(log),
+		dbCache:    make(map[string]*gorm.DB),
+		searchCaps: make(map[string]searchSupport),
+	}
+	actual, _ := organizationDatabasesByBase.LoadOrStore(db, created)
+	return actual.(*organizationDatabases)
+}
+
+// getOrgDB возвращает подключение к БД организации по ее ID, кэшируя соединения.
+func (od *organizationDatabases) getOrgDB(ctx context.Context, orgID uuid.UUID) (*gorm.DB, error) {
+	if orgID == uuid.Nil {
+		return nil, fmt.Errorf("organization id is required")
+	}
+
+	od.cacheMu.RLock()
+	if cached, ok := od.dbCache[orgID.String()]; ok {
+		od.cacheMu.RUnlock()
+		return cached, nil
+	}
+	od.cacheMu.RUnlock()
+
+	var org entity.EstOrganization
+	if err := od.baseDB.WithContext(ctx).Select("db_name").Where("id = ?", orgID).First(&org).Error; err != nil {
+		od.logger.Error(ctx, "Failed to fetch organization database name", err, logrus.Fields{"orgID": orgID.String()})
+		return nil, apperror.DatabaseError("fetching organization database name", err)
+	}
+
+	if org.DBName == "" {
+		od.logger.Error(ctx, "Organization has empty database name", nil, logrus.Fields{"orgID": orgID.String()})
+		return nil, fmt.Errorf("organization %s has empty database name", orgID)
+	}
+
+	host := os.Getenv("DB_HOST")
+	port := os.Getenv("DB_PORT")
+	user := os.Getenv("DB_USER")
+	password := os.Getenv("DB_PASSWORD")
+	sslmode := os.Getenv("DB_SSLMODE")
+	if sslmode == "" {
+		sslmode = "disable"
+	}
+
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s", host, user, password, org.DBName, port, sslmode)
+	orgDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		od.logger.Error(ctx, "Failed to connect to organization database", err, logrus.Fields{"dbName": org.DBName})
+		return nil, apperror.DatabaseError("connecting to organization database", err)
+	}
+
+	// Приводим схему БД организации к актуальной версии (новые колонки и таблицы)
+	if err := orgDB.WithContext(ctx).AutoMigrate(entity.OrganizationModels()...); err != nil {
+		od.logger.Error(ctx, "Failed to migrate organization database", err, logrus.Fields{"dbName": org.DBName})
+		return nil, apperror.DatabaseError("migrating organization database", err)
+	}
+
+	support := od.ensureSearchIndexes(ctx, orgDB)
+
+	od.cacheMu.Lock()
+	od.dbCache[orgID.String()] = orgDB
+	od.searchCaps[orgID.String()] = support
+	od.cacheMu.Unlock()
+
+	return orgDB, nil
+}
@@ -0,0 +1,96 @@
+// Package scheduler запускает периодические задачи приложения.
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultRecurringInterval интервал запуска выпуска повторяющихся документов по умолчанию
+const DefaultRecurringInterval = time.Hour
+
+// RecurringRunner создает документы по наступившим шаблонам организации (EsfRecurringService)
+type RecurringRunner interface {
+	RunRecurringTemplates(ctx context.Context, orgID uuid.UUID, now time.Time) (*models.EsfRecurringRunReport, error)
+}
+
+// OrganizationLister возвращает организации, для которых выпускаются документы (EsfOrganizationRepository)
+type OrganizationLister interface {
+	GetAll(ctx context.Context) ([]*entity.EstOrganization, error)
+}
+
+// RecurringScheduler периодически создает черновики по шаблонам повторяющихся документов всех организаций.
+// Повторные запуски безопасны: документ за период создается не больше одного раза.
+type RecurringScheduler struct {
+	runner   RecurringRunner
+	orgs     OrganizationLister
+	interval time.Duration
+	logger   *logger.Logger
+	now      func() time.Time
+}
+
+// NewRecurringScheduler создает планировщик; interval <= 0 заменяется DefaultRecurringInterval
+func NewRecurringScheduler(runner RecurringRunner, orgs OrganizationLister, interval time.Duration, log *logrus.Logger) *RecurringScheduler {
+	if interval <= 0 {
+		interval = DefaultRecurringInterval
+	}
+	return &RecurringScheduler{
+		runner:   runner,
+		orgs:     orgs,
+		interval: interval,
+		logger:   logger.New(log),
+		now:      time.Now,
+	}
+}
+
+// Start запускает планировщик в отдельной горутине до отмены ctx; первый запуск выполняется сразу
+func (s *RecurringScheduler) Start(ctx context.Context) {
+	s.logger.Info(ctx, "Recurring document scheduler started", logrus.Fields{"interval": s.interval.String()})
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				s.logger.Info(context.Background(), "Recurring document scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunOnce обрабатывает шаблоны всех организаций. Ошибка одной организации
+// не останавливает обработку остальных. Возвращает число созданных документов.
+func (s *RecurringScheduler) RunOnce(ctx context.Context) int {
+	orgs, err := s.orgs.GetAll(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to list organizations for recurring documents", err)
+		return 0
+	}
+
+	now := s.now()
+	created := 0
+	for _, org := range orgs {
+		if ctx.Err() != nil {
+			break
+		}
+		report, err := s.runner.RunRecurringTemplates(ctx, org.ID, now)
+		if report != nil {
+			created += len(report.Created)
+		}
+		if err != nil {
+			s.logger.Error(ctx, "Failed to run recurring templates", err, logrus.Fields{"org_id": org.ID.String()})
+		}
+	}
+	return created
+}
@@ -0,0 +1,46 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRunner struct {
+	calls  []uuid.UUID
+	failed uuid.UUID
+}
+
+func (r *stubRunner) RunRecurringTemplates(ctx context.Context, orgID uuid.UUID, now time.Time) (*models.EsfRecurringRunReport, error) {
+	r.calls = append(r.calls, orgID)
+	if orgID == r.failed {
+		return nil, errors.New("database is unavailable")
+	}
+	return &models.EsfRecurringRunReport{Created: []models.EsfRecurringRunModel{{Period: "2026-03"}}}, nil
+}
+
+type stubOrgs []*entity.EstOrganization
+
+func (o stubOrgs) GetAll(ctx context.Context) ([]*entity.EstOrganization, error) {
+	return o, nil
+}
+
+// TestRecurringScheduler_RunOnce tests that a failing organization does not stop the others
+func TestRecurringScheduler_RunOnce(t *testing.T) {
+	orgs := stubOrgs{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	runner := &stubRunner{failed: orgs[1].ID}
+
+	s := NewRecurringScheduler(runner, orgs, 0, logrus.New())
+	assert.Equal(t, DefaultRecurringInterval, s.interval)
+
+	created := s.RunOnce(context.Background())
+	assert.Equal(t, 2, created)
+	assert.Equal(t, []uuid.UUID{orgs[0].ID, orgs[1].ID, orgs[2].ID}, runner.calls)
+}
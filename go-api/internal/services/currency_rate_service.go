@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+)
+
+// CurrencyRateService официальные курсы валют: загрузка из файлов и внешних источников,
+// выдача курса на дату документа
+type CurrencyRateService interface {
+	// ImportRates разбирает файл курсов (json, csv, xlsx) и сохраняет курсы
+	ImportRates(ctx context.Context, data []byte, format, source string) (*models.CurrencyRateImportResponse, error)
+	// FetchRates загружает курсы на дату из подключенного источника
+	FetchRates(ctx context.Context, date time.Time) (*models.CurrencyRateImportResponse, error)
+	// SetFetcher подключает источник курсов; name сохраняется как источник загруженных курсов
+	SetFetcher(fetcher currency.Fetcher, name string)
+
+	GetRates(ctx context.Context, currencyCode string, from, to time.Time) ([]models.CurrencyRateModel, error)
+	// RateOn возвращает последний курс валюты, установленный не позже date
+	RateOn(ctx context.Context, currencyCode string, date time.Time) (*models.CurrencyRateModel, error)
+}
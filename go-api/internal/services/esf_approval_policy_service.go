@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// EsfApprovalPolicyService политика согласования документов организации перед подписанием и отправкой
+type EsfApprovalPolicyService interface {
+	GetApprovalPolicy(ctx context.Context, orgID uuid.UUID) (*models.EsfApprovalPolicyModel, error)
+	// UpdateApprovalPolicy сохраняет политику; она применяется ко всем еще не подписанным документам
+	UpdateApprovalPolicy(ctx context.Context, orgID uuid.UUID, req *models.EsfApprovalPolicyRequest) (*models.EsfApprovalPolicyModel, error)
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/blobstore"
+)
+
+// EsfAttachmentService вложения документов (договоры, сканы); содержимое хранится в хранилище вложений
+type EsfAttachmentService interface {
+	GetAttachments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]models.EsfAttachmentModel, error)
+	UploadAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, upload *models.EsfAttachmentUpload) (*models.EsfAttachmentModel, error)
+	GetAttachmentContent(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*models.EsfAttachmentModel, []byte, error)
+	DeleteAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error
+	SetBlobStore(store blobstore.BlobStore)
+}
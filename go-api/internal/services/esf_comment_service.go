@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// EsfCommentService комментарии к документам с упоминаниями пользователей
+type EsfCommentService interface {
+	GetComments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]models.EsfCommentModel, error)
+	CreateComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, req *models.EsfCommentRequest) (*models.EsfCommentModel, error)
+	UpdateComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID, req *models.EsfCommentRequest) (*models.EsfCommentModel, error)
+	DeleteComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+)
+
+// EsfContractorService справочник контрагентов организации.
+// Реквизиты контрагента подставляются в документы, поэтому изменение переносится в черновики.
+type EsfContractorService interface {
+	// GetContractors возвращает контрагентов; query ищет по наименованию и ИНН
+	GetContractors(ctx context.Context, orgID uuid.UUID, query string) ([]models.EsfContractorModel, error)
+	GetContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfContractorModel, error)
+	CreateContractor(ctx context.Context, orgID uuid.UUID, req *models.EsfContractorModel) (*models.EsfContractorModel, error)
+	UpdateContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfContractorModel) (*models.EsfContractorModel, error)
+	DeleteContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
+
+	// SetCacheManager подключает кэш документов, чтобы сбрасывать измененные черновики
+	SetCacheManager(cache.CacheManager)
+}
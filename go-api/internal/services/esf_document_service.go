@@ -4,9 +4,13 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/gateway"
 	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
 	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
 	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/pdf"
 )
 
 type EsfDocumentService interface {
@@ -16,9 +20,82 @@ type EsfDocumentService interface {
 	UpdateDocument(ctx context.Context, orgID uuid.UUID, doc *models.EsfEditDocumentRequest) error
 	DeleteDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
 
+	// ValidateDocumentDraft проверяет документ так же, как при создании, но не сохраняет его;
+	// курс валюты не проверяется, так как шаблоны получают его на дату выпуска
+	ValidateDocumentDraft(ctx context.Context, orgID uuid.UUID, req *models.EsfCreateDocumentRequest) error
+
+	// Пакетное создание и изменение документов с результатом по каждому элементу
+	ProcessDocumentBatch(ctx context.Context, orgID uuid.UUID, req *models.EsfDocumentBatchRequest) (*models.EsfDocumentBatchResponse, error)
+
+	// Жизненный цикл документа
+	ChangeDocumentStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, status entity.DocumentStatus, reason string) (*models.EsfDocumentStatusResponse, error)
+
+	// Полнотекстовый поиск
+	SearchDocuments(ctx context.Context, orgID uuid.UUID, query string, params pagination.PaginationParams) ([]models.EsfDocumentSearchResult, int64, error)
+
 	// Пагіновані методи
 	GetAllDocumentsPaginated(ctx context.Context, orgID uuid.UUID, params pagination.PaginationParams, filters pagination.DocumentFilterParams) ([]models.EsfCreateDocumentRequest, int64, error)
 
+	// Корректировочные счета-фактуры
+	CreateCorrection(ctx context.Context, orgID uuid.UUID, originalID uuid.UUID, req *models.EsfCorrectionRequest) (*models.EsfCreateDocumentResponse, error)
+	GetCorrectionChain(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfCorrectionChainResponse, error)
+
+	// История ревизий
+	GetDocumentRevisions(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]models.EsfDocumentRevisionModel, error)
+	GetDocumentRevision(ctx context.Context, orgID uuid.UUID, id uuid.UUID, revision int) (*models.EsfDocumentRevisionModel, error)
+	DiffDocumentRevisions(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from, to int) (*models.EsfDocumentRevisionDiffResponse, error)
+
+	// Обмен XML файлами в формате налоговой службы
+	ExportDocumentXML(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]byte, error)
+	ImportDocumentsXML(ctx context.Context, orgID uuid.UUID, data []byte) (*models.EsfDocumentImportResponse, error)
+
+	// Массовый импорт из CSV/XLSX с проверкой без сохранения (dryRun)
+	ImportDocumentsTable(ctx context.Context, orgID uuid.UUID, data []byte, opts models.EsfTableImportOptions) (*models.EsfTableImportReport, error)
+
+	// Печатные формы и PDF
+	RenderDocumentPDF(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]byte, error)
+	GetPrintTemplate(ctx context.Context, orgID uuid.UUID) (*models.EsfPrintTemplateModel, error)
+	SavePrintTemplate(ctx context.Context, orgID uuid.UUID, content string) (*models.EsfPrintTemplateModel, error)
+	DeletePrintTemplate(ctx context.Context, orgID uuid.UUID) error
+	SetPrintRenderer(renderer *pdf.Renderer)
+
+	// Электронная подпись документов: подпись делает клиент ключом из SetSigningKeyRepository
+	GetSigningPayload(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfSigningPayload, error)
+	SignDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfSignRequest) (*models.EsfDocumentSignatureModel, error)
+	GetDocumentSignatures(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]models.EsfDocumentSignatureModel, error)
+	VerifyDocumentSignatures(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfSignatureVerification, error)
+
+	// Согласование документов по политике организации перед подписанием и отправкой
+	GetDocumentApproval(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfApprovalStatusModel, error)
+	DecideDocumentApproval(ctx context.Context, orgID uuid.UUID, id uuid.UUID, decision entity.ApprovalDecision, req *models.EsfApprovalDecisionRequest) (*models.EsfApprovalStatusModel, error)
+
+	// Копирование документа в новый черновик, в том числе в другую организацию
+	CloneDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfCloneRequest) (*models.EsfCloneResponse, error)
+
+	// Доставка исходящего документа покупателю - другой организации платформы
+	DeliverDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfDeliveryResponse, error)
+
+	// Проверка кодов по справочникам
+	SetReferenceService(references EsfReferenceService)
+
+	// Заполнение курса документов в иностранной валюте
+	SetCurrencyRates(rates CurrencyRateService)
+
+	// Справочник контрагентов для заполнения реквизитов документов
+	SetContractorRepository(contractors repository.EsfContractorRepository)
+
+	// Ключи подписи для подписания и проверки подписей документов
+	SetSigningKeyRepository(keys repository.EsfSigningKeyRepository)
+
+	// Политика согласования, которую проверяют подписание и отправка
+	SetApprovalPolicyRepository(policies repository.EsfApprovalPolicyRepository)
+
+	// Справочник организаций для операций между организациями
+	SetOrganizationRepository(orgRepo repository.EsfOrganizationRepository)
+
+	// Отправка документов в налоговую службу
+	SetSubmissionGateway(gw gateway.EsfGateway)
+
 	// Cache management
 	SetCacheManager(cache.CacheManager)
 	CacheWarmDocuments(ctx context.Context, orgID uuid.UUID, limit int) error
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// EsfNumberingService настройки нумерации документов организации
+type EsfNumberingService interface {
+	// GetNumbering возвращает настройки, счетчики по годам и следующий номер
+	GetNumbering(ctx context.Context, orgID uuid.UUID) (*models.EsfNumberingModel, error)
+	// UpdateNumbering сохраняет настройки для следующих документов; присвоенные номера не меняются
+	UpdateNumbering(ctx context.Context, orgID uuid.UUID, req *models.EsfNumberingRequest) (*models.EsfNumberingModel, error)
+}
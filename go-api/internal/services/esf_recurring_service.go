@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// EsfRecurringService шаблоны повторяющихся документов; RunRecurringTemplates вызывается планировщиком
+type EsfRecurringService interface {
+	GetRecurringTemplates(ctx context.Context, orgID uuid.UUID) ([]models.EsfRecurringTemplateModel, error)
+	GetRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfRecurringTemplateModel, error)
+	CreateRecurringTemplate(ctx context.Context, orgID uuid.UUID, req *models.EsfRecurringTemplateRequest) (*models.EsfRecurringTemplateModel, error)
+	UpdateRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfRecurringTemplateRequest) (*models.EsfRecurringTemplateModel, error)
+	DeleteRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
+	PreviewRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID, count int) ([]models.EsfRecurringPreviewItem, error)
+	GetRecurringRuns(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]models.EsfRecurringRunModel, error)
+	RunRecurringTemplates(ctx context.Context, orgID uuid.UUID, now time.Time) (*models.EsfRecurringRunReport, error)
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// EsfReferenceService справочники кодов ЭСФ: загрузка встроенных версий,
+// выдача для интерфейса и проверка кодов документов
+type EsfReferenceService interface {
+	// SeedBundled загружает встроенные справочники, которых еще нет в БД
+	SeedBundled(ctx context.Context) error
+
+	ListCatalogs(ctx context.Context) ([]models.EsfReferenceCatalogModel, error)
+	GetCatalog(ctx context.Context, catalog string) (*models.EsfReferenceCatalogModel, error)
+
+	// ValidateDocument проверяет коды документа и позиций по активным версиям справочников
+	ValidateDocument(ctx context.Context, doc *entity.EsfDocument) *apperror.AppError
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+)
+
+// EsfReportService отчеты по документам организации: книги продаж и покупок, зачет НДС и аналитика
+type EsfReportService interface {
+	// Книга продаж или покупок за период по ставкам НДС и контрагентам
+	GetVATLedger(ctx context.Context, orgID uuid.UUID, req *models.EsfVatLedgerRequest) (*models.EsfVatLedgerReport, error)
+	ExportVATLedgerCSV(ctx context.Context, orgID uuid.UUID, req *models.EsfVatLedgerRequest) ([]byte, error)
+	// Зачет входящего НДС поставщиков против начисленного НДС за период
+	GetVATBalance(ctx context.Context, orgID uuid.UUID, req *models.EsfVatBalanceRequest) (*models.EsfVatBalanceReport, error)
+
+	// Аналитика документов за период для панели администратора
+	GetAnalyticsSummary(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) (*models.EsfAnalyticsSummary, error)
+	GetMonthlyAnalytics(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) ([]models.EsfAnalyticsMonth, error)
+	GetTopContractors(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) ([]models.EsfAnalyticsContractor, error)
+	GetVATByRate(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) ([]models.EsfAnalyticsVatRate, error)
+
+	// SetCacheManager подключает общий кэш агрегатов аналитики
+	SetCacheManager(cache.CacheManager)
+}
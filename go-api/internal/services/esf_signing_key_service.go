@@ -0,0 +1,19 @@
+package services
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+)
+
+// EsfSigningKeyService ключи подписи документов организации.
+// Регистрируется только открытая часть ключа, документ подписывает клиент.
+type EsfSigningKeyService interface {
+	GetSigningKeys(ctx context.Context, orgID uuid.UUID) ([]models.EsfSigningKeyModel, error)
+	GetSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfSigningKeyModel, error)
+	// RegisterSigningKey регистрирует личный ключ текущего пользователя или ключ организации
+	RegisterSigningKey(ctx context.Context, orgID uuid.UUID, req *models.EsfSigningKeyRequest) (*models.EsfSigningKeyModel, error)
+	// RevokeSigningKey отзывает ключ; личный ключ может отозвать только его владелец
+	RevokeSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error
+}
@@ -0,0 +1,168 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// rateLookbackDays сколько дней назад ищется последний курс: Национальный банк
+// не устанавливает курсы в выходные и праздники, но курс старше этого срока не используется
+const rateLookbackDays = 10
+
+// maxRatesPeriod ограничивает период выдачи курсов
+const maxRatesPeriod = 366 * 24 * time.Hour
+
+type currencyRateService struct {
+	repo        repository.CurrencyRateRepository
+	logger      *logger.Logger
+	fetcher     currency.Fetcher
+	fetcherName string
+}
+
+// NewCurrencyRateService создает сервис курсов валют
+func NewCurrencyRateService(repo repository.CurrencyRateRepository, log *logrus.Logger) services.CurrencyRateService {
+	return &currencyRateService{
+		repo:   repo,
+		logger: logger.New(log),
+	}
+}
+
+// SetFetcher подключает источник курсов
+func (s *currencyRateService) SetFetcher(fetcher currency.Fetcher, name string) {
+	s.fetcher = fetcher
+	s.fetcherName = name
+}
+
+// ImportRates разбирает файл курсов и сохраняет курсы
+func (s *currencyRateService) ImportRates(ctx context.Context, data []byte, format, source string) (*models.CurrencyRateImportResponse, error) {
+	s.logger.Info(ctx, "Importing currency rates", logrus.Fields{"format": format, "source": source})
+
+	rates, err := currency.Parse(data, format)
+	if err != nil {
+		return nil, apperror.NewWithDetails(apperror.ErrValidation, "invalid rates file", err.Error())
+	}
+	return s.saveRates(ctx, rates, source)
+}
+
+// FetchRates загружает курсы на дату из подключенного источника
+func (s *currencyRateService) FetchRates(ctx context.Context, date time.Time) (*models.CurrencyRateImportResponse, error) {
+	if s.fetcher == nil {
+		return nil, apperror.New(apperror.ErrConfigError, "currency rates fetcher is not configured")
+	}
+
+	s.logger.Info(ctx, "Fetching currency rates", logrus.Fields{"date": date.Format(currency.DateLayout), "source": s.fetcherName})
+
+	rates, err := s.fetcher.Fetch(ctx, date)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch currency rates", err, logrus.Fields{"source": s.fetcherName})
+		return nil, apperror.New(apperror.ErrExternalService, "failed to fetch currency rates").WithError(err).WithDetails(err.Error())
+	}
+	if len(rates) == 0 {
+		return nil, apperror.NewWithDetails(apperror.ErrNotFound, "no currency rates for the date", date.Format(currency.DateLayout))
+	}
+	return s.saveRates(ctx, rates, s.fetcherName)
+}
+
+func (s *currencyRateService) saveRates(ctx context.Context, rates []currency.Rate, source string) (*models.CurrencyRateImportResponse, error) {
+	result := &models.CurrencyRateImportResponse{Imported: len(rates), Source: source}
+	records := make([]entity.CurrencyRate, len(rates))
+	currencies := make(map[string]bool)
+
+	for i, r := range rates {
+		records[i] = entity.CurrencyRate{
+			CurrencyCode: currency.Normalize(r.Currency),
+			Date:         r.Date,
+			Rate:         r.Rate,
+			Nominal:      r.Nominal,
+			Source:       source,
+		}
+		if records[i].Nominal == 0 {
+			records[i].Nominal = 1
+		}
+		currencies[records[i].CurrencyCode] = true
+
+		date := r.Date
+		if result.From == nil || date.Before(*result.From) {
+			result.From = &date
+		}
+		if result.To == nil || date.After(*result.To) {
+			result.To = &date
+		}
+	}
+
+	if err := s.repo.SaveRates(ctx, records); err != nil {
+		return nil, repositoryError("saving currency rates", err)
+	}
+
+	for code := range currencies {
+		result.Currencies = append(result.Currencies, code)
+	}
+	sort.Strings(result.Currencies)
+
+	s.logger.Info(ctx, "Currency rates imported", logrus.Fields{"count": result.Imported, "currencies": len(result.Currencies), "source": source})
+	return result, nil
+}
+
+// GetRates возвращает курсы за период
+func (s *currencyRateService) GetRates(ctx context.Context, currencyCode string, from, to time.Time) ([]models.CurrencyRateModel, error) {
+	if to.Before(from) {
+		return nil, apperror.ValidationError("period end must not be before its start")
+	}
+	if to.Sub(from) > maxRatesPeriod {
+		return nil, apperror.ValidationError("period must not exceed one year")
+	}
+
+	rates, err := s.repo.GetRates(ctx, currency.Normalize(currencyCode), from, to)
+	if err != nil {
+		return nil, repositoryError("fetching currency rates", err)
+	}
+
+	result := make([]models.CurrencyRateModel, len(rates))
+	for i := range rates {
+		result[i] = currencyRateToModel(&rates[i])
+	}
+	return result, nil
+}
+
+// RateOn возвращает последний курс валюты, установленный не позже date
+func (s *currencyRateService) RateOn(ctx context.Context, currencyCode string, date time.Time) (*models.CurrencyRateModel, error) {
+	code := currency.Normalize(currencyCode)
+	if currency.IsBase(code) {
+		return nil, apperror.ValidationError("rate of the base currency is always 1")
+	}
+
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	rate, err := s.repo.GetRate(ctx, code, day, day.AddDate(0, 0, -rateLookbackDays))
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+			return nil, apperror.NewWithDetails(apperror.ErrNotFound, "currency rate not found",
+				fmt.Sprintf("no official %s rate within %d days before %s", code, rateLookbackDays, day.Format(currency.DateLayout)))
+		}
+		return nil, repositoryError("fetching currency rate", err)
+	}
+
+	model := currencyRateToModel(rate)
+	return &model, nil
+}
+
+func currencyRateToModel(r *entity.CurrencyRate) models.CurrencyRateModel {
+	return models.CurrencyRateModel{
+		CurrencyCode: r.CurrencyCode,
+		Date:         r.Date,
+		Rate:         r.Rate,
+		Nominal:      r.Nominal,
+		UnitRate:     currency.RoundRate(r.PerUnit()),
+		Source:       r.Source,
+	}
+}
@@ -0,0 +1,200 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCurrencyRateRepository is a mock implementation of CurrencyRateRepository
+type MockCurrencyRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockCurrencyRateRepository) GetRate(ctx context.Context, currencyCode string, date, since time.Time) (*entity.CurrencyRate, error) {
+	args := m.Called(ctx, currencyCode, date, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CurrencyRate), args.Error(1)
+}
+
+func (m *MockCurrencyRateRepository) GetRates(ctx context.Context, currencyCode string, from, to time.Time) ([]entity.CurrencyRate, error) {
+	args := m.Called(ctx, currencyCode, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.CurrencyRate), args.Error(1)
+}
+
+func (m *MockCurrencyRateRepository) SaveRates(ctx context.Context, rates []entity.CurrencyRate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
+
+// ========== Currency Rate Tests ==========
+
+func TestCurrencyRate_ImportRates(t *testing.T) {
+	mockRepo := new(MockCurrencyRateRepository)
+	var saved []entity.CurrencyRate
+	mockRepo.On("SaveRates", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(1).([]entity.CurrencyRate)
+	}).Return(nil)
+
+	service := NewCurrencyRateService(mockRepo, logrus.New())
+	result, err := service.ImportRates(context.Background(),
+		[]byte("date,currency,rate,nominal\n2024-01-15,840,89.45,\n2024-01-16,KZT,19.5,100\n"), "csv", "upload")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, []string{"KZT", "USD"}, result.Currencies)
+	assert.Equal(t, "2024-01-15", result.From.Format("2006-01-02"))
+	assert.Equal(t, "2024-01-16", result.To.Format("2006-01-02"))
+	require.Len(t, saved, 2)
+	assert.Equal(t, "USD", saved[0].CurrencyCode)
+	assert.Equal(t, 1, saved[0].Nominal)
+	assert.Equal(t, "upload", saved[1].Source)
+}
+
+func TestCurrencyRate_ImportRejectsInvalidFile(t *testing.T) {
+	mockRepo := new(MockCurrencyRateRepository)
+	service := NewCurrencyRateService(mockRepo, logrus.New())
+
+	_, err := service.ImportRates(context.Background(), []byte("date,currency,rate\n2024-01-15,USD,-1\n"), "csv", "upload")
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+	mockRepo.AssertNotCalled(t, "SaveRates", mock.Anything, mock.Anything)
+}
+
+func TestCurrencyRate_FetchRequiresFetcher(t *testing.T) {
+	service := NewCurrencyRateService(new(MockCurrencyRateRepository), logrus.New())
+
+	_, err := service.FetchRates(context.Background(), time.Now())
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrConfigError, appErr.Code)
+}
+
+func TestCurrencyRate_RateOnLooksBack(t *testing.T) {
+	mockRepo := new(MockCurrencyRateRepository)
+	day := time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetRate", mock.Anything, "KZT", day, day.AddDate(0, 0, -rateLookbackDays)).
+		Return(&entity.CurrencyRate{CurrencyCode: "KZT", Date: day.AddDate(0, 0, -2), Rate: 19.5, Nominal: 100}, nil)
+	mockRepo.On("GetRate", mock.Anything, "USD", mock.Anything, mock.Anything).Return(nil, apperror.NotFoundError("currency rate"))
+
+	service := NewCurrencyRateService(mockRepo, logrus.New())
+
+	rate, err := service.RateOn(context.Background(), "398", day.Add(15*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0.195, rate.UnitRate)
+
+	_, err = service.RateOn(context.Background(), "USD", day)
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrNotFound, appErr.Code)
+	assert.Contains(t, appErr.Details, "within 10 days before 2024-01-14")
+}
+
+// ========== Document Currency Conversion Tests ==========
+
+func TestEsfDocumentCreate_FillsCurrencyRate(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	rateRepo := new(MockCurrencyRateRepository)
+	orgID := uuid.New()
+	delivery := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	rateRepo.On("GetRate", mock.Anything, "USD", delivery, mock.Anything).
+		Return(&entity.CurrencyRate{CurrencyCode: "USD", Date: delivery, Rate: 89.45, Nominal: 1}, nil)
+
+	var saved *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetCurrencyRates(NewCurrencyRateService(rateRepo, logrus.New()))
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ForeignName:         "Test",
+		CurrencyCode:        "840",
+		DeliveryDate:        &delivery,
+		TaxRateVATCode:      "1",
+		IsPriceWithoutTaxes: true,
+		CatalogEntries: []models.EsfEntriesModel{
+			{SalesTaxCode: "001", Quantity: 10, Price: money.MustParse("100")},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, 89.45, saved.CurrencyRate)
+	assert.Equal(t, money.MustParse("1120"), saved.CatalogEntries[0].TotalAmount)
+	assert.Equal(t, money.MustParse("12.52"), saved.TotalCurrencyValue)
+	assert.Equal(t, money.MustParse("11.18"), saved.TotalCurrencyValueWithoutTaxes)
+}
+
+func TestEsfDocumentCreate_KeepsManualCurrencyRate(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	rateRepo := new(MockCurrencyRateRepository)
+	orgID := uuid.New()
+	rate := 90.0
+
+	var saved *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetCurrencyRates(NewCurrencyRateService(rateRepo, logrus.New()))
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ForeignName:    "Test",
+		CurrencyCode:   "USD",
+		CurrencyRate:   &rate,
+		TaxRateVATCode: "1",
+		CatalogEntries: []models.EsfEntriesModel{
+			{SalesTaxCode: "001", Quantity: 1, Price: money.MustParse("900")},
+		},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, 90.0, saved.CurrencyRate)
+	assert.Equal(t, money.MustParse("10"), saved.TotalCurrencyValue)
+	rateRepo.AssertNotCalled(t, "GetRate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentCreate_RequiresCurrencyRate(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	rateRepo := new(MockCurrencyRateRepository)
+	rateRepo.On("GetRate", mock.Anything, "EUR", mock.Anything, mock.Anything).Return(nil, apperror.NotFoundError("currency rate"))
+	delivery := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetCurrencyRates(NewCurrencyRateService(rateRepo, logrus.New()))
+
+	for name, req := range map[string]*models.EsfCreateDocumentRequest{
+		"currencyRate": {ForeignName: "Test", CurrencyCode: "EUR", DeliveryDate: &delivery},
+		"deliveryDate": {ForeignName: "Test", CurrencyCode: "EUR"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := service.CreateDocument(context.Background(), uuid.New(), req)
+
+			appErr, ok := err.(*apperror.AppError)
+			require.True(t, ok)
+			require.Len(t, appErr.Fields, 1)
+			assert.Equal(t, name, appErr.Fields[0].Field)
+		})
+	}
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,219 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// analyticsCacheTTL время жизни агрегатов в кеше: данные панели могут отставать
+// от документов не более чем на этот срок
+const analyticsCacheTTL = 5 * time.Minute
+
+// maxAnalyticsPeriod ограничивает период аналитики тремя годами для сравнения по годам
+const maxAnalyticsPeriod = 3 * 366 * 24 * time.Hour
+
+const (
+	defaultAnalyticsLimit = 10
+	maxAnalyticsLimit     = 100
+)
+
+// GetAnalyticsSummary возвращает итоги документов за период и среднюю сумму документа
+func (s *esfReportService) GetAnalyticsSummary(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) (*models.EsfAnalyticsSummary, error) {
+	filter, _, err := analyticsFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.aggregateTotals(ctx, orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.EsfAnalyticsSummary{
+		From:                filter.From,
+		To:                  filter.To.AddDate(0, 0, -1),
+		Currency:            currency.Base,
+		Statuses:            make([]string, len(filter.Statuses)),
+		EsfAnalyticsAmounts: analyticsAmounts(totals),
+	}
+	for i, status := range filter.Statuses {
+		result.Statuses[i] = status.String()
+	}
+	return result, nil
+}
+
+// GetMonthlyAnalytics возвращает итоги по месяцам периода, включая месяцы без документов
+func (s *esfReportService) GetMonthlyAnalytics(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) ([]models.EsfAnalyticsMonth, error) {
+	filter, _, err := analyticsFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.aggregates(ctx, orgID, filter, repository.AggregateByMonth, 0)
+	if err != nil {
+		return nil, err
+	}
+	byMonth := make(map[string]repository.AggregateBucket, len(buckets))
+	for _, b := range buckets {
+		byMonth[b.Key] = b
+	}
+
+	var result []models.EsfAnalyticsMonth
+	last := filter.To.AddDate(0, 0, -1)
+	for month := time.Date(filter.From.Year(), filter.From.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(last); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		result = append(result, models.EsfAnalyticsMonth{Month: key, EsfAnalyticsAmounts: analyticsAmounts(byMonth[key])})
+	}
+	return result, nil
+}
+
+// GetTopContractors возвращает покупателей с наибольшей суммой документов за период
+func (s *esfReportService) GetTopContractors(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) ([]models.EsfAnalyticsContractor, error) {
+	filter, limit, err := analyticsFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.aggregates(ctx, orgID, filter, repository.AggregateByContractor, limit)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.aggregateTotals(ctx, orgID, filter)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.contractorNames(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.EsfAnalyticsContractor, len(buckets))
+	for i, b := range buckets {
+		result[i] = models.EsfAnalyticsContractor{
+			ContractorTin:       b.Key,
+			ContractorName:      names[b.Key],
+			EsfAnalyticsAmounts: analyticsAmounts(b),
+		}
+		if !totals.TotalAmount.IsZero() {
+			result[i].Share = math.Round(float64(b.TotalAmount)/float64(totals.TotalAmount)*10000) / 100
+		}
+	}
+	return result, nil
+}
+
+// GetVATByRate возвращает итоги документов за период по ставкам НДС
+func (s *esfReportService) GetVATByRate(ctx context.Context, orgID uuid.UUID, req *models.EsfAnalyticsRequest) ([]models.EsfAnalyticsVatRate, error) {
+	filter, _, err := analyticsFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.aggregates(ctx, orgID, filter, repository.AggregateByVATCode, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.EsfAnalyticsVatRate, len(buckets))
+	for i, b := range buckets {
+		result[i] = models.EsfAnalyticsVatRate{VatCode: b.Key, EsfAnalyticsAmounts: analyticsAmounts(b)}
+		if rate, err := s.taxCalculator.Rates().VAT(b.Key); err == nil {
+			result[i].VatName = rate.Name
+			result[i].VatPercent = rate.Percent
+		}
+	}
+	return result, nil
+}
+
+// analyticsFilter проверяет период, статусы и размер рейтинга.
+// Аналитика продаж строится по исходящим документам организации.
+func analyticsFilter(req *models.EsfAnalyticsRequest) (repository.LedgerFilter, int, error) {
+	filter, fields := periodFilter(req.From, req.To, req.Statuses, defaultLedgerStatuses, maxAnalyticsPeriod)
+	filter.Direction = entity.DocumentDirectionOutgoing
+
+	limit := req.Limit
+	switch {
+	case limit == 0:
+		limit = defaultAnalyticsLimit
+	case limit < 0 || limit > maxAnalyticsLimit:
+		fields = append(fields, apperror.FieldError{Field: "limit", Message: fmt.Sprintf("limit must be between 1 and %d", maxAnalyticsLimit)})
+	}
+
+	if len(fields) > 0 {
+		return repository.LedgerFilter{}, 0, apperror.FieldValidationError("invalid analytics parameters", fields)
+	}
+	return filter, limit, nil
+}
+
+// aggregateTotals возвращает итоги всех документов периода
+func (s *esfReportService) aggregateTotals(ctx context.Context, orgID uuid.UUID, filter repository.LedgerFilter) (repository.AggregateBucket, error) {
+	buckets, err := s.aggregates(ctx, orgID, filter, repository.AggregateTotal, 0)
+	if err != nil || len(buckets) == 0 {
+		return repository.AggregateBucket{}, err
+	}
+	return buckets[0], nil
+}
+
+// aggregates возвращает агрегаты из общего кеша или считает их в БД организации.
+// В кеш записывается JSON-строка, чтобы суммы читались обратно без потери точности.
+func (s *esfReportService) aggregates(ctx context.Context, orgID uuid.UUID, filter repository.LedgerFilter, groupBy repository.AggregateGroup, limit int) ([]repository.AggregateBucket, error) {
+	statuses := make([]string, len(filter.Statuses))
+	for i, status := range filter.Statuses {
+		statuses[i] = status.String()
+	}
+	cacheKey := fmt.Sprintf("analytics:%s:%s:%s:%s:%s:%s:%d", orgID, groupBy, filter.Direction,
+		filter.From.Format(currency.DateLayout), filter.To.Format(currency.DateLayout), strings.Join(statuses, ","), limit)
+
+	if s.cacheManager != nil {
+		if cached, _ := s.cacheManager.Generic().Get(ctx, cacheKey); cached != nil {
+			var buckets []repository.AggregateBucket
+			if raw, ok := cached.(string); ok && json.Unmarshal([]byte(raw), &buckets) == nil {
+				s.logger.Debug(ctx, "Analytics found in cache", logrus.Fields{"org_id": orgID.String(), "group_by": string(groupBy)})
+				return buckets, nil
+			}
+		}
+	}
+
+	buckets, err := s.repo.GetDocumentAggregates(ctx, orgID, filter, groupBy, limit)
+	if err != nil {
+		return nil, repositoryError("aggregating documents", err)
+	}
+
+	if s.cacheManager != nil {
+		if data, err := json.Marshal(buckets); err == nil {
+			_ = s.cacheManager.Generic().Set(ctx, cacheKey, string(data), analyticsCacheTTL)
+		}
+	}
+	return buckets, nil
+}
+
+func analyticsAmounts(b repository.AggregateBucket) models.EsfAnalyticsAmounts {
+	result := models.EsfAnalyticsAmounts{
+		Documents:      b.Documents,
+		TaxableBase:    b.AmountWithoutTaxes,
+		VatAmount:      b.VatAmount,
+		SalesTaxAmount: b.SalesTaxAmount,
+		TotalAmount:    b.TotalAmount,
+	}
+	if b.Documents > 0 {
+		// Среднее по модулю не больше итога, который уже прочитан как decimal(15,2),
+		// поэтому выход за диапазон означает ошибку в агрегации
+		avg, err := b.TotalAmount.Div(float64(b.Documents))
+		if err != nil {
+			panic(fmt.Sprintf("average amount of %s over %d documents: %v", b.TotalAmount, b.Documents, err))
+		}
+		result.AverageAmount = avg
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCache кеш в памяти; как и Redis, хранит значения в JSON
+type memoryCache struct {
+	values map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: make(map[string][]byte)}
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (interface{}, error) {
+	raw, ok := m.values[key]
+	if !ok {
+		return nil, nil
+	}
+	var value interface{}
+	err := json.Unmarshal(raw, &value)
+	return value, err
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	raw, err := json.Marshal(value)
+	m.values[key] = raw
+	return err
+}
+
+func (m *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := m.values[key]
+	return ok, nil
+}
+
+func (m *memoryCache) Clear(ctx context.Context, pattern string) error {
+	m.values = make(map[string][]byte)
+	return nil
+}
+
+func (m *memoryCache) GetMultiple(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (m *memoryCache) SetMultiple(ctx context.Context, data map[string]interface{}, ttl time.Duration) error {
+	return nil
+}
+
+// memoryCacheManager отдает один кеш в памяти для всех разделов
+type memoryCacheManager struct {
+	*memoryCache
+}
+
+func (m memoryCacheManager) User() cache.Cache               { return m.memoryCache }
+func (m memoryCacheManager) Organization() cache.Cache       { return m.memoryCache }
+func (m memoryCacheManager) Document() cache.Cache           { return m.memoryCache }
+func (m memoryCacheManager) Session() cache.Cache            { return m.memoryCache }
+func (m memoryCacheManager) Token() cache.Cache              { return m.memoryCache }
+func (m memoryCacheManager) Generic() cache.Cache            { return m.memoryCache }
+func (m memoryCacheManager) Flush(ctx context.Context) error { return m.Clear(ctx, "*") }
+
+func aggregateBucket(key string, documents int, base, vat string) repository.AggregateBucket {
+	b := repository.AggregateBucket{
+		Key:                key,
+		Documents:          documents,
+		AmountWithoutTaxes: money.MustParse(base),
+		VatAmount:          money.MustParse(vat),
+	}
+	b.TotalAmount = b.AmountWithoutTaxes.Add(b.VatAmount)
+	return b
+}
+
+func analyticsRequest() *models.EsfAnalyticsRequest {
+	return &models.EsfAnalyticsRequest{
+		From: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// ========== Analytics Tests ==========
+
+func TestEsfAnalytics_SummaryIsCached(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	orgID := uuid.New()
+	filter := repository.LedgerFilter{
+		From:      time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC),
+		Statuses:  defaultLedgerStatuses,
+		Direction: entity.DocumentDirectionOutgoing,
+	}
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, filter, repository.AggregateTotal, 0).
+		Return([]repository.AggregateBucket{aggregateBucket("", 3, "999999999999.99", "0.01")}, nil).Once()
+
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+	service.SetCacheManager(memoryCacheManager{newMemoryCache()})
+
+	for i := 0; i < 2; i++ {
+		summary, err := service.GetAnalyticsSummary(context.Background(), orgID, analyticsRequest())
+		require.NoError(t, err)
+		assert.Equal(t, 3, summary.Documents)
+		assert.Equal(t, "1000000000000.00", summary.TotalAmount.String())
+		assert.Equal(t, "333333333333.33", summary.AverageAmount.String())
+		assert.Equal(t, time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC), summary.To)
+	}
+	mockRepo.AssertNumberOfCalls(t, "GetDocumentAggregates", 1)
+}
+
+func TestEsfAnalytics_MonthlyFillsGaps(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	orgID := uuid.New()
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, mock.Anything, repository.AggregateByMonth, 0).
+		Return([]repository.AggregateBucket{
+			aggregateBucket("2024-01", 2, "1000", "120"),
+			aggregateBucket("2024-03", 1, "500", "60"),
+		}, nil)
+
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+	months, err := service.GetMonthlyAnalytics(context.Background(), orgID, analyticsRequest())
+	require.NoError(t, err)
+
+	require.Len(t, months, 4)
+	assert.Equal(t, []string{"2024-01", "2024-02", "2024-03", "2024-04"},
+		[]string{months[0].Month, months[1].Month, months[2].Month, months[3].Month})
+	assert.Equal(t, money.MustParse("560"), months[0].AverageAmount)
+	assert.Zero(t, months[1].Documents)
+	assert.True(t, months[3].TotalAmount.IsZero())
+}
+
+func TestEsfAnalytics_TopContractors(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	orgID := uuid.New()
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, mock.Anything, repository.AggregateByContractor, 2).
+		Return([]repository.AggregateBucket{
+			aggregateBucket("01503201910012", 2, "2000", "240"),
+			aggregateBucket("02201199900123", 1, "1000", "120"),
+		}, nil)
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, mock.Anything, repository.AggregateTotal, 0).
+		Return([]repository.AggregateBucket{aggregateBucket("", 4, "6000", "720")}, nil)
+	contractorRepo := new(MockContractorRepository)
+	contractorRepo.On("GetContractors", mock.Anything, orgID, "").Return([]entity.EsfContractor{*newContractor(uuid.New())}, nil)
+
+	service := NewEsfReportService(mockRepo, contractorRepo, logrus.New())
+	req := analyticsRequest()
+	req.Limit = 2
+	contractors, err := service.GetTopContractors(context.Background(), orgID, req)
+	require.NoError(t, err)
+
+	require.Len(t, contractors, 2)
+	assert.Equal(t, "ОсОО Ала-Тоо", contractors[0].ContractorName)
+	assert.Equal(t, 33.33, contractors[0].Share)
+	assert.Equal(t, 16.67, contractors[1].Share)
+}
+
+func TestEsfAnalytics_VATByRate(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	orgID := uuid.New()
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, mock.Anything, repository.AggregateByVATCode, 0).
+		Return([]repository.AggregateBucket{aggregateBucket("1", 2, "1000", "120"), aggregateBucket("3", 1, "50", "0")}, nil)
+
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+	rates, err := service.GetVATByRate(context.Background(), orgID, analyticsRequest())
+	require.NoError(t, err)
+
+	require.Len(t, rates, 2)
+	assert.Equal(t, "НДС 12%", rates[0].VatName)
+	assert.Equal(t, float64(12), rates[0].VatPercent)
+	assert.Equal(t, "Без НДС", rates[1].VatName)
+}
+
+func TestEsfAnalytics_ValidatesParameters(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+
+	_, err := service.GetTopContractors(context.Background(), uuid.New(), &models.EsfAnalyticsRequest{
+		From:  time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		Limit: 1000,
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 2)
+	assert.Equal(t, "to", appErr.Fields[0].Field)
+	assert.Equal(t, "limit", appErr.Fields[1].Field)
+	mockRepo.AssertNotCalled(t, "GetDocumentAggregates", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
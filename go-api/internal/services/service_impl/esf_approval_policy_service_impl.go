@@ -0,0 +1,117 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRequiredApprovals ограничивает число согласований, которое может требовать политика
+const maxRequiredApprovals = 10
+
+// maxOperationTypeCodeLength длина кода вида операции (колонка operation_type_code)
+const maxOperationTypeCodeLength = 20
+
+type esfApprovalPolicyService struct {
+	repo   repository.EsfApprovalPolicyRepository
+	logger *logger.Logger
+}
+
+func NewEsfApprovalPolicyService(repo repository.EsfApprovalPolicyRepository, log *logrus.Logger) services.EsfApprovalPolicyService {
+	return &esfApprovalPolicyService{
+		repo:   repo,
+		logger: logger.New(log),
+	}
+}
+
+// GetApprovalPolicy возвращает политику согласования организации
+func (s *esfApprovalPolicyService) GetApprovalPolicy(ctx context.Context, orgID uuid.UUID) (*models.EsfApprovalPolicyModel, error) {
+	policy, err := s.repo.GetApprovalPolicy(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching approval policy", err)
+	}
+	return approvalPolicyToModel(policy), nil
+}
+
+// UpdateApprovalPolicy проверяет и сохраняет политику согласования.
+// Новая политика применяется ко всем еще не подписанным документам, в том числе к уже согласованным.
+func (s *esfApprovalPolicyService) UpdateApprovalPolicy(ctx context.Context, orgID uuid.UUID, req *models.EsfApprovalPolicyRequest) (*models.EsfApprovalPolicyModel, error) {
+	s.logger.Info(ctx, "Updating approval policy", logrus.Fields{"org_id": orgID.String(), "enabled": req.Enabled, "required_approvals": req.RequiredApprovals})
+
+	var fields []apperror.FieldError
+	addField := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+
+	if req.RequiredApprovals < 1 || req.RequiredApprovals > maxRequiredApprovals {
+		addField("requiredApprovals", fmt.Sprintf("required approvals must be between 1 and %d", maxRequiredApprovals))
+	}
+	if req.ThresholdAmount < 0 {
+		addField("thresholdAmount", "threshold amount must not be negative")
+	}
+
+	codes := make([]string, 0, len(req.OperationTypeCodes))
+	seen := make(map[string]bool, len(req.OperationTypeCodes))
+	for _, code := range req.OperationTypeCodes {
+		code = strings.TrimSpace(code)
+		switch {
+		case code == "":
+			addField("operationTypeCodes", "operation type code must not be empty")
+		case len(code) > maxOperationTypeCodeLength || strings.Contains(code, ","):
+			addField("operationTypeCodes", fmt.Sprintf("invalid operation type code %q", code))
+		case !seen[code]:
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	policy := &entity.EsfApprovalPolicy{
+		Enabled:            req.Enabled,
+		ThresholdAmount:    req.ThresholdAmount,
+		OperationTypeCodes: strings.Join(codes, ","),
+		RequiredApprovals:  req.RequiredApprovals,
+	}
+	if len(policy.OperationTypeCodes) > 500 {
+		addField("operationTypeCodes", "too many operation type codes")
+	}
+	if len(fields) > 0 {
+		return nil, apperror.FieldValidationError("invalid approval policy", fields)
+	}
+
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		policy.UpdatedBy = fmt.Sprint(username)
+	}
+	if err := s.repo.SaveApprovalPolicy(ctx, orgID, policy); err != nil {
+		s.logger.Error(ctx, "Failed to save approval policy", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, repositoryError("saving approval policy", err)
+	}
+
+	s.logger.Info(ctx, "Approval policy saved successfully", logrus.Fields{"org_id": orgID.String()})
+	return s.GetApprovalPolicy(ctx, orgID)
+}
+
+func approvalPolicyToModel(p *entity.EsfApprovalPolicy) *models.EsfApprovalPolicyModel {
+	result := &models.EsfApprovalPolicyModel{
+		Enabled:            p.Enabled,
+		ThresholdAmount:    p.ThresholdAmount,
+		OperationTypeCodes: p.OperationTypes(),
+		RequiredApprovals:  p.RequiredApprovals,
+		UpdatedBy:          p.UpdatedBy,
+	}
+	if result.OperationTypeCodes == nil {
+		result.OperationTypeCodes = []string{}
+	}
+	if !p.UpdatedAt.IsZero() {
+		result.UpdatedAt = &p.UpdatedAt
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockApprovalPolicyRepository мок политики согласования
+type MockApprovalPolicyRepository struct {
+	mock.Mock
+}
+
+func (m *MockApprovalPolicyRepository) GetApprovalPolicy(ctx context.Context, orgID uuid.UUID) (*entity.EsfApprovalPolicy, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfApprovalPolicy), args.Error(1)
+}
+
+func (m *MockApprovalPolicyRepository) SaveApprovalPolicy(ctx context.Context, orgID uuid.UUID, policy *entity.EsfApprovalPolicy) error {
+	args := m.Called(ctx, orgID, policy)
+	return args.Error(0)
+}
+
+// ========== Approval Policy Tests ==========
+
+func TestEsfApprovalPolicy_Validation(t *testing.T) {
+	mockRepo := new(MockApprovalPolicyRepository)
+	orgID := uuid.New()
+	service := NewEsfApprovalPolicyService(mockRepo, logrus.New())
+
+	_, err := service.UpdateApprovalPolicy(context.Background(), orgID, &models.EsfApprovalPolicyRequest{
+		Enabled:            true,
+		ThresholdAmount:    money.MustParse("-1"),
+		OperationTypeCodes: []string{" "},
+		RequiredApprovals:  0,
+	})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	assert.Len(t, appErr.Fields, 3)
+	mockRepo.AssertNotCalled(t, "SaveApprovalPolicy", mock.Anything, mock.Anything, mock.Anything)
+
+	var saved *entity.EsfApprovalPolicy
+	mockRepo.On("SaveApprovalPolicy", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfApprovalPolicy)
+	}).Return(nil)
+	mockRepo.On("GetApprovalPolicy", mock.Anything, orgID).Return(approvalPolicy(), nil)
+
+	result, err := service.UpdateApprovalPolicy(approverContext("admin"), orgID, &models.EsfApprovalPolicyRequest{
+		Enabled:            true,
+		ThresholdAmount:    money.MustParse("1000"),
+		OperationTypeCodes: []string{"201", " 201 "},
+		RequiredApprovals:  2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "201", saved.OperationTypeCodes)
+	assert.Equal(t, "admin", saved.UpdatedBy)
+	assert.Equal(t, []string{"201"}, result.OperationTypeCodes)
+}
@@ -0,0 +1,296 @@
+package service_impl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/blobstore"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxAttachmentSize наибольший размер вложения в байтах
+const MaxAttachmentSize = 10 * 1024 * 1024
+
+// maxAttachmentsPerDocument ограничивает число вложений документа
+const maxAttachmentsPerDocument = 50
+
+// maxAttachmentNameLength наибольшая длина имени файла вложения
+const maxAttachmentNameLength = 255
+
+// attachmentTypes допустимые типы вложений. Тип определяется по содержимому файла,
+// а не по заявленному клиентом, поэтому исполняемые файлы под видом PDF не принимаются.
+var attachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+	"text/xml":        true,
+}
+
+// officeTypes документы Office и OpenDocument - ZIP архивы, тип которых определяется по расширению
+var officeTypes = map[string]string{
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+}
+
+type esfAttachmentService struct {
+	repo      repository.EsfAttachmentRepository
+	documents repository.EsfDocumentRepository
+	blobStore blobstore.BlobStore
+	logger    *logger.Logger
+}
+
+// NewEsfAttachmentService создает сервис вложений; documents проверяет, что документ существует.
+// Без хранилища (SetBlobStore) вложения можно только просматривать и удалять.
+func NewEsfAttachmentService(repo repository.EsfAttachmentRepository, documents repository.EsfDocumentRepository, log *logrus.Logger) services.EsfAttachmentService {
+	return &esfAttachmentService{
+		repo:      repo,
+		documents: documents,
+		logger:    logger.New(log),
+	}
+}
+
+// SetBlobStore подключает хранилище вложений документов
+func (s *esfAttachmentService) SetBlobStore(store blobstore.BlobStore) {
+	s.blobStore = store
+}
+
+// GetAttachments возвращает вложения документа
+func (s *esfAttachmentService) GetAttachments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]models.EsfAttachmentModel, error) {
+	if _, err := s.documents.GetDocumentByID(ctx, orgID, documentID); err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+
+	attachments, err := s.repo.GetAttachments(ctx, orgID, documentID)
+	if err != nil {
+		return nil, repositoryError("fetching attachments", err)
+	}
+
+	result := make([]models.EsfAttachmentModel, len(attachments))
+	for i := range attachments {
+		result[i] = attachmentToModel(&attachments[i])
+	}
+	return result, nil
+}
+
+// UploadAttachment проверяет тип, размер и контрольную сумму файла и прикладывает его к документу
+func (s *esfAttachmentService) UploadAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, upload *models.EsfAttachmentUpload) (*models.EsfAttachmentModel, error) {
+	s.logger.Info(ctx, "Uploading attachment", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "file_name": upload.FileName})
+
+	if s.blobStore == nil {
+		return nil, apperror.New(apperror.ErrConfigError, "attachments storage is not configured")
+	}
+
+	fileName := attachmentFileName(upload.FileName)
+	if fileName == "" {
+		return nil, attachmentError("fileName", "file name is required")
+	}
+	expected := strings.ToLower(strings.TrimSpace(upload.Checksum))
+	if expected != "" {
+		if decoded, err := hex.DecodeString(expected); err != nil || len(decoded) != sha256.Size {
+			return nil, attachmentError("checksum", "checksum must be a hex-encoded SHA-256 digest")
+		}
+	}
+
+	if _, err := s.documents.GetDocumentByID(ctx, orgID, documentID); err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+	existing, err := s.repo.GetAttachments(ctx, orgID, documentID)
+	if err != nil {
+		return nil, repositoryError("fetching attachments", err)
+	}
+	if len(existing) >= maxAttachmentsPerDocument {
+		return nil, apperror.ValidationError(fmt.Sprintf("document must not have more than %d attachments", maxAttachmentsPerDocument))
+	}
+
+	// Тип определяется по первым 512 байтам, остальное содержимое передается в хранилище потоком
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, apperror.New(apperror.ErrInvalidRequest, "failed to read uploaded file").WithError(err)
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, attachmentError("file", "file is empty")
+	}
+	contentType, ok := detectAttachmentType(head, fileName)
+	if !ok {
+		return nil, attachmentError("file", fmt.Sprintf("file type %s is not allowed", contentType))
+	}
+
+	attachment := &entity.EsfAttachment{
+		ID:          uuid.New(),
+		DocumentID:  documentID,
+		FileName:    fileName,
+		ContentType: contentType,
+	}
+	attachment.StorageKey = orgID.String() + "/" + documentID.String() + "/" + attachment.ID.String()
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		attachment.UploadedBy = fmt.Sprint(username)
+	}
+
+	hash := sha256.New()
+	content := io.TeeReader(io.LimitReader(io.MultiReader(bytes.NewReader(head), upload.Content), MaxAttachmentSize+1), hash)
+	size, err := s.blobStore.Put(ctx, attachment.StorageKey, content)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to store attachment", err, logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+		return nil, apperror.New(apperror.ErrInternal, "failed to store attachment").WithError(err)
+	}
+	attachment.Size = size
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	var rejected *apperror.AppError
+	switch {
+	case size > MaxAttachmentSize:
+		rejected = attachmentError("file", fmt.Sprintf("file must not exceed %d bytes", MaxAttachmentSize))
+	case expected != "" && expected != attachment.Checksum:
+		rejected = attachmentError("checksum", "checksum does not match the uploaded content")
+	}
+	if rejected != nil {
+		s.deleteBlob(ctx, attachment.StorageKey)
+		return nil, rejected
+	}
+
+	if err := s.repo.CreateAttachment(ctx, orgID, attachment); err != nil {
+		s.deleteBlob(ctx, attachment.StorageKey)
+		return nil, repositoryError("creating attachment", err)
+	}
+
+	s.logger.Info(ctx, "Attachment uploaded successfully", logrus.Fields{
+		"org_id":        orgID.String(),
+		"doc_id":        documentID.String(),
+		"attachment_id": attachment.ID.String(),
+		"size":          size,
+	})
+	result := attachmentToModel(attachment)
+	return &result, nil
+}
+
+// GetAttachmentContent возвращает вложение и его содержимое, проверив размер и контрольную сумму
+func (s *esfAttachmentService) GetAttachmentContent(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*models.EsfAttachmentModel, []byte, error) {
+	if s.blobStore == nil {
+		return nil, nil, apperror.New(apperror.ErrConfigError, "attachments storage is not configured")
+	}
+
+	attachment, err := s.repo.GetAttachmentByID(ctx, orgID, documentID, id)
+	if err != nil {
+		return nil, nil, repositoryError("fetching attachment", err)
+	}
+
+	r, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to open attachment", err, logrus.Fields{"org_id": orgID.String(), "attachment_id": id.String()})
+		if err == blobstore.ErrNotFound {
+			return nil, nil, apperror.New(apperror.ErrInternal, "attachment content is missing")
+		}
+		return nil, nil, apperror.New(apperror.ErrInternal, "failed to read attachment").WithError(err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(io.LimitReader(r, attachment.Size+1))
+	if err != nil {
+		return nil, nil, apperror.New(apperror.ErrInternal, "failed to read attachment").WithError(err)
+	}
+	sum := sha256.Sum256(content)
+	if int64(len(content)) != attachment.Size || hex.EncodeToString(sum[:]) != attachment.Checksum {
+		s.logger.Error(ctx, "Attachment checksum mismatch", fmt.Errorf("stored content does not match checksum %s", attachment.Checksum),
+			logrus.Fields{"org_id": orgID.String(), "attachment_id": id.String()})
+		return nil, nil, apperror.New(apperror.ErrInternal, "attachment content is corrupted")
+	}
+
+	result := attachmentToModel(attachment)
+	return &result, content, nil
+}
+
+// DeleteAttachment удаляет вложение документа
+func (s *esfAttachmentService) DeleteAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error {
+	s.logger.Info(ctx, "Deleting attachment", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "attachment_id": id.String()})
+
+	attachment, err := s.repo.GetAttachmentByID(ctx, orgID, documentID, id)
+	if err != nil {
+		return repositoryError("fetching attachment", err)
+	}
+	if err := s.repo.DeleteAttachment(ctx, orgID, documentID, id); err != nil {
+		return repositoryError("deleting attachment", err)
+	}
+	if s.blobStore != nil {
+		s.deleteBlob(ctx, attachment.StorageKey)
+	}
+	return nil
+}
+
+// deleteBlob удаляет содержимое вложения; ошибка только журналируется, так как описание вложения уже удалено
+func (s *esfAttachmentService) deleteBlob(ctx context.Context, key string) {
+	if err := s.blobStore.Delete(ctx, key); err != nil {
+		s.logger.Error(ctx, "Failed to delete attachment content", err, logrus.Fields{"storage_key": key})
+	}
+}
+
+// detectAttachmentType определяет тип файла по содержимому и сообщает, допустим ли он
+func detectAttachmentType(head []byte, fileName string) (string, bool) {
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "application/octet-stream", false
+	}
+	if contentType == "application/zip" {
+		if office, ok := officeTypes[strings.ToLower(filepath.Ext(fileName))]; ok {
+			return office, true
+		}
+	}
+	return contentType, attachmentTypes[contentType]
+}
+
+// attachmentFileName оставляет от имени файла только базовое имя без управляющих символов
+func attachmentFileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if runes := []rune(name); len(runes) > maxAttachmentNameLength {
+		name = string(runes[:maxAttachmentNameLength])
+	}
+	return name
+}
+
+func attachmentError(field, message string) *apperror.AppError {
+	return apperror.FieldValidationError("invalid attachment", []apperror.FieldError{{Field: field, Message: message}})
+}
+
+func attachmentToModel(a *entity.EsfAttachment) models.EsfAttachmentModel {
+	return models.EsfAttachmentModel{
+		ID:          a.ID,
+		DocumentID:  a.DocumentID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Checksum:    a.Checksum,
+		CreatedAt:   a.CreatedAt,
+		UploadedBy:  a.UploadedBy,
+	}
+}
@@ -0,0 +1,234 @@
+package service_impl
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/blobstore"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBlobStore хранилище вложений в памяти для тестов
+type memoryBlobStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{blobs: make(map[string][]byte)}
+}
+
+func (m *memoryBlobStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[key] = data
+	return int64(len(data)), nil
+}
+
+func (m *memoryBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.blobs[key]
+	if !ok {
+		return nil, blobstore.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memoryBlobStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blobs, key)
+	return nil
+}
+
+// pdfContent минимальное содержимое, которое распознается как PDF
+var pdfContent = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\ntrailer\n<<>>\n%%EOF\n")
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// MockAttachmentRepository мок описаний вложений документов
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) GetAttachments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfAttachment, error) {
+	args := m.Called(ctx, orgID, documentID)
+	return args.Get(0).([]entity.EsfAttachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) GetAttachmentByID(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfAttachment, error) {
+	args := m.Called(ctx, orgID, documentID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfAttachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) CreateAttachment(ctx context.Context, orgID uuid.UUID, attachment *entity.EsfAttachment) error {
+	args := m.Called(ctx, orgID, attachment)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) DeleteAttachment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, documentID, id)
+	return args.Error(0)
+}
+
+func newAttachmentService(mockRepo *MockAttachmentRepository, orgID, docID uuid.UUID) (services.EsfAttachmentService, *memoryBlobStore) {
+	documents := new(MockDocumentRepository)
+	documents.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID}, nil)
+	mockRepo.On("GetAttachments", mock.Anything, orgID, docID).Return([]entity.EsfAttachment{}, nil)
+
+	store := newMemoryBlobStore()
+	service := NewEsfAttachmentService(mockRepo, documents, logrus.New())
+	service.SetBlobStore(store)
+	return service, store
+}
+
+// ========== Attachment Tests ==========
+
+func TestEsfAttachment_Upload(t *testing.T) {
+	mockRepo := new(MockAttachmentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	service, store := newAttachmentService(mockRepo, orgID, docID)
+
+	var saved *entity.EsfAttachment
+	mockRepo.On("CreateAttachment", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfAttachment)
+	}).Return(nil)
+
+	result, err := service.UploadAttachment(context.Background(), orgID, docID, &models.EsfAttachmentUpload{
+		FileName: `C:\Users\buh\Договор №5.pdf`,
+		Content:  bytes.NewReader(pdfContent),
+		Checksum: strings.ToUpper(checksumOf(pdfContent)),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, "Договор №5.pdf", result.FileName)
+	assert.Equal(t, "application/pdf", result.ContentType)
+	assert.Equal(t, int64(len(pdfContent)), result.Size)
+	assert.Equal(t, checksumOf(pdfContent), result.Checksum)
+	assert.Equal(t, pdfContent, store.blobs[saved.StorageKey])
+}
+
+func TestEsfAttachment_UploadRejectsType(t *testing.T) {
+	mockRepo := new(MockAttachmentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	service, store := newAttachmentService(mockRepo, orgID, docID)
+
+	_, err := service.UploadAttachment(context.Background(), orgID, docID, &models.EsfAttachmentUpload{
+		FileName: "invoice.pdf",
+		Content:  bytes.NewReader([]byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00")),
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "file", appErr.Fields[0].Field)
+	assert.Empty(t, store.blobs)
+	mockRepo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfAttachment_UploadChecksumMismatch(t *testing.T) {
+	mockRepo := new(MockAttachmentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	service, store := newAttachmentService(mockRepo, orgID, docID)
+
+	_, err := service.UploadAttachment(context.Background(), orgID, docID, &models.EsfAttachmentUpload{
+		FileName: "invoice.pdf",
+		Content:  bytes.NewReader(pdfContent),
+		Checksum: checksumOf([]byte("other content")),
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "checksum", appErr.Fields[0].Field)
+	assert.Empty(t, store.blobs, "rejected content must be removed from the storage")
+	mockRepo.AssertNotCalled(t, "CreateAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfAttachment_UploadSizeLimit(t *testing.T) {
+	mockRepo := new(MockAttachmentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	service, store := newAttachmentService(mockRepo, orgID, docID)
+
+	content := io.MultiReader(bytes.NewReader(pdfContent), io.LimitReader(zeroReader{}, MaxAttachmentSize))
+	_, err := service.UploadAttachment(context.Background(), orgID, docID, &models.EsfAttachmentUpload{
+		FileName: "scan.pdf",
+		Content:  content,
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "file", appErr.Fields[0].Field)
+	assert.Empty(t, store.blobs)
+}
+
+func TestEsfAttachment_DownloadDetectsCorruption(t *testing.T) {
+	mockRepo := new(MockAttachmentRepository)
+	orgID, docID, id := uuid.New(), uuid.New(), uuid.New()
+	store := newMemoryBlobStore()
+	service := NewEsfAttachmentService(mockRepo, new(MockDocumentRepository), logrus.New())
+	service.SetBlobStore(store)
+
+	attachment := &entity.EsfAttachment{
+		ID:          id,
+		DocumentID:  docID,
+		FileName:    "invoice.pdf",
+		ContentType: "application/pdf",
+		Size:        int64(len(pdfContent)),
+		Checksum:    checksumOf(pdfContent),
+		StorageKey:  "key",
+	}
+	mockRepo.On("GetAttachmentByID", mock.Anything, orgID, docID, id).Return(attachment, nil)
+
+	store.blobs["key"] = pdfContent
+	_, content, err := service.GetAttachmentContent(context.Background(), orgID, docID, id)
+	require.NoError(t, err)
+	assert.Equal(t, pdfContent, content)
+
+	corrupted := append([]byte(nil), pdfContent...)
+	corrupted[10] = 'X'
+	store.blobs["key"] = corrupted
+	_, _, err = service.GetAttachmentContent(context.Background(), orgID, docID, id)
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInternal, appErr.Code)
+	assert.Equal(t, "attachment content is corrupted", appErr.Message)
+}
+
+// zeroReader бесконечный поток нулевых байтов
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
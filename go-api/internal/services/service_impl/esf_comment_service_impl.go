@@ -0,0 +1,264 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// maxCommentLength наибольшая длина текста комментария в символах
+const maxCommentLength = 5000
+
+// maxCommentMentions ограничивает число упоминаний в одном комментарии
+const maxCommentMentions = 20
+
+// mentionPattern упоминание @username в начале текста или после пробела и знаков препинания;
+// адреса электронной почты (user@example.kg) упоминаниями не считаются
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([\p{L}\p{N}_.-]{3,50})`)
+
+type esfCommentService struct {
+	repo      repository.EsfCommentRepository
+	documents repository.EsfDocumentRepository
+	users     repository.UserRepository
+	logger    *logger.Logger
+}
+
+// NewEsfCommentService создает сервис комментариев.
+// documents проверяет, что документ существует; без справочника пользователей (users == nil) упоминания не разбираются.
+func NewEsfCommentService(repo repository.EsfCommentRepository, documents repository.EsfDocumentRepository, users repository.UserRepository, log *logrus.Logger) services.EsfCommentService {
+	return &esfCommentService{
+		repo:      repo,
+		documents: documents,
+		users:     users,
+		logger:    logger.New(log),
+	}
+}
+
+// GetComments возвращает комментарии документа в порядке создания
+func (s *esfCommentService) GetComments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]models.EsfCommentModel, error) {
+	if _, err := s.documents.GetDocumentByID(ctx, orgID, documentID); err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+
+	comments, err := s.repo.GetComments(ctx, orgID, documentID)
+	if err != nil {
+		return nil, repositoryError("fetching comments", err)
+	}
+
+	result := make([]models.EsfCommentModel, len(comments))
+	for i := range comments {
+		result[i] = commentToModel(&comments[i])
+	}
+	return result, nil
+}
+
+// CreateComment добавляет комментарий к документу от имени пользователя из контекста запроса
+func (s *esfCommentService) CreateComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, req *models.EsfCommentRequest) (*models.EsfCommentModel, error) {
+	s.logger.Info(ctx, "Creating comment", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String()})
+
+	author := actorID(ctx)
+	if author == "" {
+		return nil, apperror.New(apperror.ErrUnauthorized, "comment author is unknown")
+	}
+	body, appErr := commentBody(req.Body)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if _, err := s.documents.GetDocumentByID(ctx, orgID, documentID); err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+
+	comment := &entity.EsfDocumentComment{
+		ID:         uuid.New(),
+		DocumentID: documentID,
+		AuthorID:   author,
+		AuthorName: actorName(ctx),
+		Body:       body,
+	}
+
+	// Ответ на ответ добавляется в ветку первого комментария
+	if req.ParentId != nil {
+		parent, err := s.repo.GetCommentByID(ctx, orgID, documentID, *req.ParentId)
+		if err != nil {
+			if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+				return nil, commentError("parentId", "parent comment not found")
+			}
+			return nil, repositoryError("fetching parent comment", err)
+		}
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	mentions, err := s.resolveMentions(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	comment.Mentions = mentions
+
+	if err := s.repo.CreateComment(ctx, orgID, comment); err != nil {
+		return nil, repositoryError("creating comment", err)
+	}
+
+	s.logger.Info(ctx, "Comment created successfully", logrus.Fields{
+		"org_id":     orgID.String(),
+		"doc_id":     documentID.String(),
+		"comment_id": comment.ID.String(),
+		"mentions":   len(mentions),
+	})
+	result := commentToModel(comment)
+	return &result, nil
+}
+
+// UpdateComment изменяет текст комментария; изменять комментарий может только его автор
+func (s *esfCommentService) UpdateComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID, req *models.EsfCommentRequest) (*models.EsfCommentModel, error) {
+	s.logger.Info(ctx, "Updating comment", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "comment_id": id.String()})
+
+	body, appErr := commentBody(req.Body)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	comment, err := s.ownComment(ctx, orgID, documentID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.resolveMentions(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	editedAt := time.Now()
+	comment.Body = body
+	comment.EditedAt = &editedAt
+	comment.Mentions = mentions
+
+	if err := s.repo.UpdateComment(ctx, orgID, comment); err != nil {
+		return nil, repositoryError("updating comment", err)
+	}
+
+	result := commentToModel(comment)
+	return &result, nil
+}
+
+// DeleteComment удаляет комментарий; удалять комментарий может только его автор
+func (s *esfCommentService) DeleteComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error {
+	s.logger.Info(ctx, "Deleting comment", logrus.Fields{"org_id": orgID.String(), "doc_id": documentID.String(), "comment_id": id.String()})
+
+	if _, err := s.ownComment(ctx, orgID, documentID, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteComment(ctx, orgID, documentID, id); err != nil {
+		return repositoryError("deleting comment", err)
+	}
+	return nil
+}
+
+// ownComment возвращает комментарий, если его автор - пользователь из контекста запроса
+func (s *esfCommentService) ownComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfDocumentComment, error) {
+	comment, err := s.repo.GetCommentByID(ctx, orgID, documentID, id)
+	if err != nil {
+		return nil, repositoryError("fetching comment", err)
+	}
+	if author := actorID(ctx); author == "" || author != comment.AuthorID {
+		s.logger.Warn(ctx, "Attempt to modify comment of another user", logrus.Fields{"comment_id": id.String(), "user_id": author})
+		return nil, apperror.New(apperror.ErrForbidden, "only the author can modify the comment")
+	}
+	return comment, nil
+}
+
+// resolveMentions находит пользователей, упомянутых через @username.
+// Неизвестные имена остаются обычным текстом; без справочника пользователей упоминания не разбираются.
+func (s *esfCommentService) resolveMentions(ctx context.Context, body string) ([]entity.EsfCommentMention, error) {
+	if s.users == nil {
+		return nil, nil
+	}
+
+	names := mentionedUsernames(body)
+	if len(names) > maxCommentMentions {
+		return nil, commentError("body", fmt.Sprintf("comment must not mention more than %d users", maxCommentMentions))
+	}
+
+	mentions := make([]entity.EsfCommentMention, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		user, err := s.users.GetByUsername(ctx, name)
+		if err != nil {
+			return nil, repositoryError("fetching mentioned user", err)
+		}
+		if user == nil || !user.IsActive || seen[user.ID.String()] {
+			continue
+		}
+		seen[user.ID.String()] = true
+		mentions = append(mentions, entity.EsfCommentMention{
+			ID:       uuid.New(),
+			UserID:   user.ID.String(),
+			Username: user.Username,
+		})
+	}
+	return mentions, nil
+}
+
+// mentionedUsernames возвращает имена из упоминаний @username без повторов в порядке появления.
+// Точка и дефис в конце имени считаются знаками препинания: "@aibek." упоминает aibek.
+func mentionedUsernames(body string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.TrimRight(m[1], ".-")
+		if utf8.RuneCountInString(name) < 3 || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// commentBody проверяет текст комментария
+func commentBody(raw string) (string, *apperror.AppError) {
+	body := strings.TrimSpace(raw)
+	if body == "" {
+		return "", commentError("body", "comment text is required")
+	}
+	if utf8.RuneCountInString(body) > maxCommentLength {
+		return "", commentError("body", fmt.Sprintf("comment must not exceed %d characters", maxCommentLength))
+	}
+	return body, nil
+}
+
+func commentError(field, message string) *apperror.AppError {
+	return apperror.FieldValidationError("invalid comment", []apperror.FieldError{{Field: field, Message: message}})
+}
+
+func commentToModel(c *entity.EsfDocumentComment) models.EsfCommentModel {
+	mentions := make([]models.EsfCommentMentionModel, len(c.Mentions))
+	for i, m := range c.Mentions {
+		mentions[i] = models.EsfCommentMentionModel{UserID: m.UserID, Username: m.Username}
+	}
+	return models.EsfCommentModel{
+		ID:         c.ID,
+		DocumentID: c.DocumentID,
+		ParentID:   c.ParentID,
+		AuthorID:   c.AuthorID,
+		AuthorName: c.AuthorName,
+		Body:       c.Body,
+		CreatedAt:  c.CreatedAt,
+		EditedAt:   c.EditedAt,
+		Mentions:   mentions,
+	}
+}
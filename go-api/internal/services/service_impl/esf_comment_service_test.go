@@ -0,0 +1,211 @@
+package service_impl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUserRepository ищет пользователей по имени без учета регистра
+type stubUserRepository struct {
+	users []*entity.User
+}
+
+func (r *stubUserRepository) Create(ctx context.Context, user *entity.User) error { return nil }
+
+func (r *stubUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	for _, u := range r.users {
+		if strings.EqualFold(u.Username, username) {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return nil, nil
+}
+
+func (r *stubUserRepository) GetAll(ctx context.Context, limit int) ([]*entity.User, error) {
+	return r.users, nil
+}
+
+func (r *stubUserRepository) Update(ctx context.Context, user *entity.User) error { return nil }
+
+func (r *stubUserRepository) Delete(ctx context.Context, id uuid.UUID) error { return nil }
+
+// MockCommentRepository мок комментариев к документам
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) GetComments(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentComment, error) {
+	args := m.Called(ctx, orgID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.EsfDocumentComment), args.Error(1)
+}
+
+func (m *MockCommentRepository) GetCommentByID(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) (*entity.EsfDocumentComment, error) {
+	args := m.Called(ctx, orgID, documentID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfDocumentComment), args.Error(1)
+}
+
+func (m *MockCommentRepository) CreateComment(ctx context.Context, orgID uuid.UUID, comment *entity.EsfDocumentComment) error {
+	args := m.Called(ctx, orgID, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, orgID uuid.UUID, comment *entity.EsfDocumentComment) error {
+	args := m.Called(ctx, orgID, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) DeleteComment(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, documentID, id)
+	return args.Error(0)
+}
+
+func commentContext(userID, username string) context.Context {
+	return logger.WithContext(logger.WithContext(context.Background(), logger.UserIDKey, userID), logger.UsernameKey, username)
+}
+
+// ========== Document Comment Tests ==========
+
+func TestEsfDocumentComment_CreateResolvesMentions(t *testing.T) {
+	mockRepo := new(MockCommentRepository)
+	documents := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	aigul := &entity.User{ID: uuid.New(), Username: "aigul.s", IsActive: true}
+	blocked := &entity.User{ID: uuid.New(), Username: "nurlan", IsActive: false}
+
+	documents.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID}, nil)
+	var created *entity.EsfDocumentComment
+	mockRepo.On("CreateComment", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*entity.EsfDocumentComment)
+	}).Return(nil)
+
+	users := &stubUserRepository{users: []*entity.User{aigul, blocked}}
+	service := NewEsfCommentService(mockRepo, documents, users, logrus.New())
+
+	result, err := service.CreateComment(commentContext("user-1", "aibek"), orgID, docID, &models.EsfCommentRequest{
+		Body: "  @Aigul.S, проверьте сумму. Копия @aigul.s и @nurlan, вопросы на info@example.kg или @unknown  ",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Equal(t, "user-1", created.AuthorID)
+	assert.Equal(t, "aibek", created.AuthorName)
+	assert.Equal(t, docID, created.DocumentID)
+	assert.Nil(t, created.ParentID)
+	assert.False(t, strings.HasPrefix(created.Body, " "))
+	assert.Equal(t, []models.EsfCommentMentionModel{{UserID: aigul.ID.String(), Username: "aigul.s"}}, result.Mentions)
+	mockRepo.AssertExpectations(t)
+	documents.AssertExpectations(t)
+}
+
+func TestEsfDocumentComment_Validation(t *testing.T) {
+	mockRepo := new(MockCommentRepository)
+	documents := new(MockDocumentRepository)
+	service := NewEsfCommentService(mockRepo, documents, nil, logrus.New())
+	orgID, docID := uuid.New(), uuid.New()
+
+	_, err := service.CreateComment(commentContext("user-1", "aibek"), orgID, docID, &models.EsfCommentRequest{Body: "   "})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+
+	_, err = service.CreateComment(commentContext("user-1", "aibek"), orgID, docID, &models.EsfCommentRequest{Body: strings.Repeat("я", maxCommentLength+1)})
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+
+	_, err = service.CreateComment(context.Background(), orgID, docID, &models.EsfCommentRequest{Body: "Без автора"})
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrUnauthorized, appErr.Code)
+
+	mockRepo.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentComment_ReplyAttachesToThreadRoot(t *testing.T) {
+	mockRepo := new(MockCommentRepository)
+	documents := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	rootID, replyID := uuid.New(), uuid.New()
+
+	documents.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID}, nil)
+	mockRepo.On("GetCommentByID", mock.Anything, orgID, docID, replyID).Return(&entity.EsfDocumentComment{ID: replyID, DocumentID: docID, ParentID: &rootID}, nil)
+	mockRepo.On("CreateComment", mock.Anything, orgID, mock.Anything).Return(nil)
+
+	service := NewEsfCommentService(mockRepo, documents, nil, logrus.New())
+	result, err := service.CreateComment(commentContext("user-2", "aigul.s"), orgID, docID, &models.EsfCommentRequest{Body: "Исправлено", ParentId: &replyID})
+	require.NoError(t, err)
+
+	require.NotNil(t, result.ParentID)
+	assert.Equal(t, rootID, *result.ParentID)
+	assert.Empty(t, result.Mentions)
+}
+
+func TestEsfDocumentComment_OnlyAuthorCanModify(t *testing.T) {
+	mockRepo := new(MockCommentRepository)
+	documents := new(MockDocumentRepository)
+	orgID, docID, commentID := uuid.New(), uuid.New(), uuid.New()
+	mockRepo.On("GetCommentByID", mock.Anything, orgID, docID, commentID).Return(&entity.EsfDocumentComment{
+		ID:         commentID,
+		DocumentID: docID,
+		AuthorID:   "user-1",
+		Body:       "Проверьте сумму",
+	}, nil)
+	mockRepo.On("UpdateComment", mock.Anything, orgID, mock.Anything).Return(nil)
+	mockRepo.On("DeleteComment", mock.Anything, orgID, docID, commentID).Return(nil)
+
+	service := NewEsfCommentService(mockRepo, documents, nil, logrus.New())
+
+	_, err := service.UpdateComment(commentContext("user-2", "aigul.s"), orgID, docID, commentID, &models.EsfCommentRequest{Body: "Чужая правка"})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrForbidden, appErr.Code)
+
+	err = service.DeleteComment(commentContext("user-2", "aigul.s"), orgID, docID, commentID)
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrForbidden, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "DeleteComment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	result, err := service.UpdateComment(commentContext("user-1", "aibek"), orgID, docID, commentID, &models.EsfCommentRequest{Body: "Проверьте сумму НДС"})
+	require.NoError(t, err)
+	assert.Equal(t, "Проверьте сумму НДС", result.Body)
+	assert.NotNil(t, result.EditedAt)
+
+	require.NoError(t, service.DeleteComment(commentContext("user-1", "aibek"), orgID, docID, commentID))
+}
+
+func TestMentionedUsernames(t *testing.T) {
+	assert.Equal(t, []string{"aibek", "aigul.s", "Нурлан"},
+		mentionedUsernames("@aibek, (@aigul.s) и @Нурлан. Повтор @AIBEK; почта a@mail.kg; @ab короткое"))
+}
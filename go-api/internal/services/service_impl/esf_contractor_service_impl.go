@@ -0,0 +1,244 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/tin"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ИНН нерезидента выдается иностранным государством, проверяется только формат
+	foreignTinPattern        = regexp.MustCompile(`^[A-Za-z0-9-]{1,14}$`)
+	contractorCountryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	contractorEmailPattern   = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+)
+
+type esfContractorService struct {
+	repo         repository.EsfContractorRepository
+	logger       *logger.Logger
+	cacheManager cache.CacheManager
+}
+
+func NewEsfContractorService(repo repository.EsfContractorRepository, log *logrus.Logger) services.EsfContractorService {
+	return &esfContractorService{
+		repo:   repo,
+		logger: logger.New(log),
+	}
+}
+
+// SetCacheManager подключает кэш документов: черновики с реквизитами контрагента сбрасываются при его изменении
+func (s *esfContractorService) SetCacheManager(cacheManager cache.CacheManager) {
+	s.cacheManager = cacheManager
+}
+
+// GetContractors возвращает контрагентов организации; query ищет по наименованию и ИНН
+func (s *esfContractorService) GetContractors(ctx context.Context, orgID uuid.UUID, query string) ([]models.EsfContractorModel, error) {
+	contractors, err := s.repo.GetContractors(ctx, orgID, query)
+	if err != nil {
+		return nil, repositoryError("fetching contractors", err)
+	}
+
+	result := make([]models.EsfContractorModel, len(contractors))
+	for i := range contractors {
+		result[i] = contractorToModel(&contractors[i])
+	}
+	return result, nil
+}
+
+// GetContractor возвращает контрагента по идентификатору
+func (s *esfContractorService) GetContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfContractorModel, error) {
+	contractor, err := s.repo.GetContractorByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching contractor", err)
+	}
+	model := contractorToModel(contractor)
+	return &model, nil
+}
+
+// CreateContractor добавляет контрагента в справочник организации
+func (s *esfContractorService) CreateContractor(ctx context.Context, orgID uuid.UUID, req *models.EsfContractorModel) (*models.EsfContractorModel, error) {
+	s.logger.Info(ctx, "Creating contractor", logrus.Fields{"org_id": orgID.String()})
+
+	contractor, err := s.contractorFromModel(ctx, orgID, uuid.Nil, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateContractor(ctx, orgID, contractor); err != nil {
+		return nil, repositoryError("creating contractor", err)
+	}
+
+	s.logger.Info(ctx, "Contractor created successfully", logrus.Fields{"org_id": orgID.String(), "contractor_id": contractor.ID.String()})
+	return s.GetContractor(ctx, orgID, contractor.ID)
+}
+
+// UpdateContractor изменяет контрагента; реквизиты переносятся в черновики, которые на него ссылаются
+func (s *esfContractorService) UpdateContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfContractorModel) (*models.EsfContractorModel, error) {
+	s.logger.Info(ctx, "Updating contractor", logrus.Fields{"org_id": orgID.String(), "contractor_id": id.String()})
+
+	if _, err := s.repo.GetContractorByID(ctx, orgID, id); err != nil {
+		return nil, repositoryError("fetching contractor", err)
+	}
+
+	contractor, err := s.contractorFromModel(ctx, orgID, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	drafts, err := s.repo.UpdateContractor(ctx, orgID, contractor)
+	if err != nil {
+		return nil, repositoryError("updating contractor", err)
+	}
+	for _, docID := range drafts {
+		deleteCachedDocument(ctx, s.cacheManager, orgID, docID)
+	}
+
+	s.logger.Info(ctx, "Contractor updated successfully", logrus.Fields{"org_id": orgID.String(), "contractor_id": id.String(), "drafts": len(drafts)})
+	return s.GetContractor(ctx, orgID, id)
+}
+
+// DeleteContractor удаляет контрагента из справочника
+func (s *esfContractorService) DeleteContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	s.logger.Info(ctx, "Deleting contractor", logrus.Fields{"org_id": orgID.String(), "contractor_id": id.String()})
+
+	if _, err := s.repo.GetContractorByID(ctx, orgID, id); err != nil {
+		return repositoryError("fetching contractor", err)
+	}
+	if err := s.repo.DeleteContractor(ctx, orgID, id); err != nil {
+		return repositoryError("deleting contractor", err)
+	}
+	return nil
+}
+
+// contractorFromModel проверяет данные контрагента и уникальность ИНН в организации
+func (s *esfContractorService) contractorFromModel(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfContractorModel) (*entity.EsfContractor, error) {
+	contractor := &entity.EsfContractor{
+		ID:            id,
+		Name:          strings.TrimSpace(req.Name),
+		Tin:           strings.TrimSpace(req.Tin),
+		IsResident:    req.IsResident,
+		CountryCode:   strings.TrimSpace(req.CountryCode),
+		ContactPerson: strings.TrimSpace(req.ContactPerson),
+		Phone:         strings.TrimSpace(req.Phone),
+		Email:         strings.TrimSpace(req.Email),
+		Address:       strings.TrimSpace(req.Address),
+	}
+
+	var fields []apperror.FieldError
+	addField := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+
+	if contractor.Name == "" {
+		addField("name", "name is required")
+	} else if len([]rune(contractor.Name)) > 255 {
+		addField("name", "name must not exceed 255 characters")
+	}
+
+	if contractor.IsResident {
+		if err := tin.Validate(contractor.Tin); err != nil {
+			addField("tin", err.Error())
+		}
+		if contractor.CountryCode == "" {
+			contractor.CountryCode = "KG"
+		}
+	} else {
+		if !foreignTinPattern.MatchString(contractor.Tin) {
+			addField("tin", "TIN of a non-resident must contain up to 14 letters or digits")
+		}
+		if contractor.CountryCode == "" {
+			addField("countryCode", "country code is required for non-residents")
+		}
+	}
+	if contractor.CountryCode != "" && !contractorCountryPattern.MatchString(contractor.CountryCode) {
+		addField("countryCode", "country code must contain 2 uppercase letters")
+	}
+	if contractor.Email != "" && !contractorEmailPattern.MatchString(contractor.Email) {
+		addField("email", "invalid email format")
+	}
+
+	seen := make(map[string]bool, len(req.BankAccounts))
+	hasDefault := false
+	for i, account := range req.BankAccounts {
+		number := strings.TrimSpace(account.Account)
+		field := fmt.Sprintf("bankAccounts[%d].account", i)
+		switch {
+		case number == "":
+			addField(field, "account number is required")
+		case len(number) > 50:
+			addField(field, "account number must not exceed 50 characters")
+		case seen[number]:
+			addField(field, "account number is duplicated")
+		}
+		seen[number] = true
+
+		if account.IsDefault {
+			if hasDefault {
+				addField(fmt.Sprintf("bankAccounts[%d].isDefault", i), "only one account can be the default")
+			}
+			hasDefault = true
+		}
+		contractor.BankAccounts = append(contractor.BankAccounts, entity.EsfContractorBankAccount{
+			Account:   number,
+			BankName:  strings.TrimSpace(account.BankName),
+			Bic:       strings.TrimSpace(account.Bic),
+			IsDefault: account.IsDefault,
+		})
+	}
+	// Единственный или первый счет становится основным, если основной не указан
+	if !hasDefault && len(contractor.BankAccounts) > 0 {
+		contractor.BankAccounts[0].IsDefault = true
+	}
+
+	if len(fields) > 0 {
+		return nil, apperror.FieldValidationError("invalid contractor", fields)
+	}
+
+	existing, err := s.repo.GetContractorByTin(ctx, orgID, contractor.Tin)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != apperror.ErrNotFound {
+			return nil, repositoryError("checking contractor TIN", err)
+		}
+	} else if existing.ID != id {
+		return nil, apperror.New(apperror.ErrAlreadyExists, "contractor with this TIN already exists").
+			WithFieldErrors([]apperror.FieldError{{Field: "tin", Message: "TIN is already used by contractor " + existing.Name}})
+	}
+
+	return contractor, nil
+}
+
+func contractorToModel(c *entity.EsfContractor) models.EsfContractorModel {
+	id := c.ID
+	createdAt, updatedAt := c.CreatedAt, c.UpdatedAt
+	accounts := make([]models.EsfContractorBankAccountModel, len(c.BankAccounts))
+	for i, a := range c.BankAccounts {
+		accounts[i] = models.EsfContractorBankAccountModel{Account: a.Account, BankName: a.BankName, Bic: a.Bic, IsDefault: a.IsDefault}
+	}
+
+	return models.EsfContractorModel{
+		ID:            &id,
+		Name:          c.Name,
+		Tin:           c.Tin,
+		IsResident:    c.IsResident,
+		CountryCode:   c.CountryCode,
+		ContactPerson: c.ContactPerson,
+		Phone:         c.Phone,
+		Email:         c.Email,
+		Address:       c.Address,
+		BankAccounts:  accounts,
+		CreatedAt:     &createdAt,
+		UpdatedAt:     &updatedAt,
+	}
+}
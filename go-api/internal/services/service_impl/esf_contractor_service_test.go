@@ -0,0 +1,238 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockContractorRepository мок справочника контрагентов
+type MockContractorRepository struct {
+	mock.Mock
+}
+
+func (m *MockContractorRepository) GetContractors(ctx context.Context, orgID uuid.UUID, query string) ([]entity.EsfContractor, error) {
+	args := m.Called(ctx, orgID, query)
+	return args.Get(0).([]entity.EsfContractor), args.Error(1)
+}
+
+func (m *MockContractorRepository) GetContractorByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfContractor, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfContractor), args.Error(1)
+}
+
+func (m *MockContractorRepository) GetContractorByTin(ctx context.Context, orgID uuid.UUID, tin string) (*entity.EsfContractor, error) {
+	args := m.Called(ctx, orgID, tin)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfContractor), args.Error(1)
+}
+
+func (m *MockContractorRepository) CreateContractor(ctx context.Context, orgID uuid.UUID, contractor *entity.EsfContractor) error {
+	args := m.Called(ctx, orgID, contractor)
+	return args.Error(0)
+}
+
+func (m *MockContractorRepository) UpdateContractor(ctx context.Context, orgID uuid.UUID, contractor *entity.EsfContractor) ([]uuid.UUID, error) {
+	args := m.Called(ctx, orgID, contractor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockContractorRepository) DeleteContractor(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, id)
+	return args.Error(0)
+}
+
+func newContractor(id uuid.UUID) *entity.EsfContractor {
+	return &entity.EsfContractor{
+		ID:          id,
+		Name:        "ОсОО Ала-Тоо",
+		Tin:         "01503201910012",
+		IsResident:  true,
+		CountryCode: "KG",
+		BankAccounts: []entity.EsfContractorBankAccount{
+			{ContractorID: id, Account: "1030120000000001"},
+			{ContractorID: id, Account: "1030120000000002", IsDefault: true},
+		},
+	}
+}
+
+// ========== Contractor Directory Tests ==========
+
+func TestEsfContractor_CreateValidatesTin(t *testing.T) {
+	mockRepo := new(MockContractorRepository)
+	service := NewEsfContractorService(mockRepo, logrus.New())
+
+	_, err := service.CreateContractor(context.Background(), uuid.New(), &models.EsfContractorModel{
+		Name:       "ОсОО Ала-Тоо",
+		Tin:        "01513201910012",
+		IsResident: true,
+		BankAccounts: []models.EsfContractorBankAccountModel{
+			{Account: "1030120000000001", IsDefault: true},
+			{Account: "1030120000000001", IsDefault: true},
+		},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	fields := make([]string, len(appErr.Fields))
+	for i, f := range appErr.Fields {
+		fields[i] = f.Field
+	}
+	assert.ElementsMatch(t, []string{"tin", "bankAccounts[1].account", "bankAccounts[1].isDefault"}, fields)
+	mockRepo.AssertNotCalled(t, "CreateContractor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfContractor_CreateRejectsDuplicateTin(t *testing.T) {
+	mockRepo := new(MockContractorRepository)
+	orgID := uuid.New()
+	mockRepo.On("GetContractorByTin", mock.Anything, orgID, "01503201910012").Return(newContractor(uuid.New()), nil)
+
+	service := NewEsfContractorService(mockRepo, logrus.New())
+	_, err := service.CreateContractor(context.Background(), orgID, &models.EsfContractorModel{
+		Name: "Ала-Тоо", Tin: "01503201910012", IsResident: true,
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrAlreadyExists, appErr.Code)
+	mockRepo.AssertNotCalled(t, "CreateContractor", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfContractor_CreateSetsDefaults(t *testing.T) {
+	mockRepo := new(MockContractorRepository)
+	orgID := uuid.New()
+
+	var saved *entity.EsfContractor
+	mockRepo.On("GetContractorByTin", mock.Anything, orgID, "01503201910012").Return(nil, apperror.NotFoundError("contractor"))
+	mockRepo.On("CreateContractor", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfContractor)
+		saved.ID = uuid.New()
+	}).Return(nil)
+	mockRepo.On("GetContractorByID", mock.Anything, orgID, mock.Anything).Return(newContractor(uuid.New()), nil)
+
+	service := NewEsfContractorService(mockRepo, logrus.New())
+	_, err := service.CreateContractor(context.Background(), orgID, &models.EsfContractorModel{
+		Name:         " Ала-Тоо ",
+		Tin:          "01503201910012",
+		IsResident:   true,
+		BankAccounts: []models.EsfContractorBankAccountModel{{Account: "1030120000000001"}, {Account: "1030120000000002"}},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, "Ала-Тоо", saved.Name)
+	assert.Equal(t, "KG", saved.CountryCode)
+	assert.True(t, saved.BankAccounts[0].IsDefault)
+	assert.False(t, saved.BankAccounts[1].IsDefault)
+}
+
+func TestEsfContractor_NonResidentRequiresCountry(t *testing.T) {
+	service := NewEsfContractorService(new(MockContractorRepository), logrus.New())
+
+	_, err := service.CreateContractor(context.Background(), uuid.New(), &models.EsfContractorModel{
+		Name: "Alatoo GmbH", Tin: "DE123456789",
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "countryCode", appErr.Fields[0].Field)
+}
+
+func TestEsfContractor_DocumentAutofill(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	contractors := new(MockContractorRepository)
+	orgID, contractorID := uuid.New(), uuid.New()
+	contractors.On("GetContractorByID", mock.Anything, orgID, contractorID).Return(newContractor(contractorID), nil)
+
+	var created *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetContractorRepository(contractors)
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ContractorId:   &contractorID,
+		TaxRateVATCode: "1",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Equal(t, "01503201910012", created.ContractorTin)
+	assert.True(t, created.IsResident)
+	assert.Equal(t, "KG", created.CountryCode)
+	assert.Equal(t, "1030120000000002", created.ContractorBankAccount)
+}
+
+func TestEsfContractor_DocumentMustMatchContractor(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	contractors := new(MockContractorRepository)
+	orgID, contractorID := uuid.New(), uuid.New()
+	contractors.On("GetContractorByID", mock.Anything, orgID, contractorID).Return(newContractor(contractorID), nil)
+	contractors.On("GetContractorByID", mock.Anything, orgID, mock.Anything).Return(nil, apperror.NotFoundError("contractor"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetContractorRepository(contractors)
+
+	t.Run("TIN and account differ", func(t *testing.T) {
+		_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+			ContractorId:          &contractorID,
+			ContractorTin:         "01234567890123",
+			ContractorBankAccount: "999",
+		})
+
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		require.Len(t, appErr.Fields, 2)
+		assert.Equal(t, "contractorTin", appErr.Fields[0].Field)
+		assert.Equal(t, "contractorBankAccount", appErr.Fields[1].Field)
+	})
+
+	t.Run("unknown contractor", func(t *testing.T) {
+		unknown := uuid.New()
+		_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{ContractorId: &unknown})
+
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		require.Len(t, appErr.Fields, 1)
+		assert.Equal(t, "contractorId", appErr.Fields[0].Field)
+	})
+
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfContractor_UpdateKeepsOwnTin(t *testing.T) {
+	mockRepo := new(MockContractorRepository)
+	orgID, contractorID := uuid.New(), uuid.New()
+	mockRepo.On("GetContractorByID", mock.Anything, orgID, contractorID).Return(newContractor(contractorID), nil)
+	mockRepo.On("GetContractorByTin", mock.Anything, orgID, "01503201910012").Return(newContractor(contractorID), nil)
+	mockRepo.On("UpdateContractor", mock.Anything, orgID, mock.Anything).Return([]uuid.UUID{uuid.New()}, nil)
+
+	service := NewEsfContractorService(mockRepo, logrus.New())
+	_, err := service.UpdateContractor(context.Background(), orgID, contractorID, &models.EsfContractorModel{
+		Name: "ОсОО Ала-Тоо Трейд", Tin: "01503201910012", IsResident: true,
+	})
+	require.NoError(t, err)
+
+	mockRepo.AssertCalled(t, "UpdateContractor", mock.Anything, orgID, mock.MatchedBy(func(c *entity.EsfContractor) bool {
+		return c.ID == contractorID && c.Name == "ОсОО Ала-Тоо Трейд"
+	}))
+}
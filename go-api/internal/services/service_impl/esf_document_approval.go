@@ -0,0 +1,304 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/signature"
+	"github.com/sirupsen/logrus"
+)
+
+// SetApprovalPolicyRepository подключает политику согласования организации.
+// Без политики подписание и отправка отклоняются: нельзя проверить, требуется ли согласование.
+func (s *esfDocumentService) SetApprovalPolicyRepository(policies repository.EsfApprovalPolicyRepository) {
+	s.policies = policies
+}
+
+// approvalPolicy возвращает политику согласования организации
+func (s *esfDocumentService) approvalPolicy(ctx context.Context, orgID uuid.UUID) (*entity.EsfApprovalPolicy, error) {
+	if s.policies == nil {
+		return nil, apperror.New(apperror.ErrConfigError, "approval policy is not configured")
+	}
+	return s.policies.GetApprovalPolicy(ctx, orgID)
+}
+
+// GetDocumentApproval возвращает итог согласования текущего содержимого документа и все решения по нему
+func (s *esfDocumentService) GetDocumentApproval(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfApprovalStatusModel, error) {
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+	policy, err := s.approvalPolicy(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching approval policy", err)
+	}
+	digest, err := documentDigest(doc)
+	if err != nil {
+		return nil, err
+	}
+	approvals, err := s.repo.GetDocumentApprovals(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document approvals", err)
+	}
+	return approvalStatus(doc, policy, approvals, digest), nil
+}
+
+// DecideDocumentApproval сохраняет решение согласующего по документу в статусе ready или signed.
+// Автор документа не может его согласовать; каждый согласующий принимает одно решение по содержимому.
+// Отклонение возвращает готовый документ в черновик с причиной отклонения.
+func (s *esfDocumentService) DecideDocumentApproval(ctx context.Context, orgID uuid.UUID, id uuid.UUID, decision entity.ApprovalDecision, req *models.EsfApprovalDecisionRequest) (*models.EsfApprovalStatusModel, error) {
+	s.logger.Info(ctx, "Recording approval decision", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "decision": decision})
+
+	if decision != entity.ApprovalDecisionApproved && decision != entity.ApprovalDecisionRejected {
+		return nil, apperror.ValidationError("unknown approval decision: " + decision.String())
+	}
+	approver := actorID(ctx)
+	if approver == "" {
+		return nil, apperror.New(apperror.ErrUnauthorized, "approver is unknown")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" && decision == entity.ApprovalDecisionRejected {
+		return nil, apperror.ValidationError("reason is required to reject a document")
+	}
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+	if doc.Status != entity.DocumentStatusReady && doc.Status != entity.DocumentStatusSigned {
+		current := doc.Status
+		if current == "" {
+			current = entity.DocumentStatusDraft
+		}
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidStatusTransition, "document must be ready to be approved",
+			fmt.Sprintf("cannot approve a document in status %s", current))
+	}
+
+	policy, err := s.approvalPolicy(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching approval policy", err)
+	}
+	if len(approvalReasons(policy, doc)) == 0 {
+		return nil, apperror.New(apperror.ErrInvalidRequest, "document does not require approval")
+	}
+
+	digest, err := documentDigest(doc)
+	if err != nil {
+		return nil, err
+	}
+	if req.Digest != "" && !strings.EqualFold(req.Digest, digest) {
+		return nil, apperror.NewWithDetails(apperror.ErrConflict, "document has changed since the approval status was requested",
+			"review the document again before deciding")
+	}
+
+	// Принцип четырех глаз: создатель и редакторы документа его не согласуют
+	revisions, err := s.repo.GetDocumentRevisions(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document revisions", err)
+	}
+	for _, rev := range revisions {
+		if rev.AuthorID == approver && (rev.Action == entity.RevisionActionCreate || rev.Action == entity.RevisionActionUpdate) {
+			s.logger.Warn(ctx, "Attempt to approve own document", logrus.Fields{"doc_id": id.String(), "user_id": approver})
+			return nil, apperror.ForbiddenError("document cannot be approved by its author")
+		}
+	}
+
+	approvals, err := s.repo.GetDocumentApprovals(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document approvals", err)
+	}
+	status := approvalStatus(doc, policy, approvals, digest)
+	if decision == entity.ApprovalDecisionApproved {
+		if status.Status == entity.ApprovalStatusApproved.String() {
+			return nil, apperror.New(apperror.ErrAlreadyExists, "document is already approved")
+		}
+		for _, d := range status.Decisions {
+			if d.Current && d.ApproverID == approver && d.Decision == entity.ApprovalDecisionApproved.String() {
+				return nil, apperror.New(apperror.ErrAlreadyExists, "document is already approved by this user")
+			}
+		}
+	}
+
+	record := &entity.EsfDocumentApproval{
+		ID:           uuid.New(),
+		Decision:     decision,
+		Reason:       reason,
+		Digest:       digest,
+		ApproverID:   approver,
+		ApproverName: actorName(ctx),
+	}
+	to := doc.Status
+	if decision == entity.ApprovalDecisionRejected {
+		record.Final = true
+		if doc.Status == entity.DocumentStatusReady {
+			to = entity.DocumentStatusDraft
+		}
+	} else {
+		record.Final = status.Approvals+1 >= policy.RequiredApprovals
+	}
+
+	if err := s.repo.DecideDocumentApproval(ctx, orgID, doc, record, to); err != nil {
+		return nil, repositoryError("recording approval decision", err)
+	}
+	s.invalidateDocumentCache(ctx, orgID, id)
+
+	s.logger.Info(ctx, "Approval decision recorded", logrus.Fields{
+		"org_id":   orgID.String(),
+		"doc_id":   id.String(),
+		"decision": decision,
+		"final":    record.Final,
+		"status":   to,
+	})
+	doc.Status = to
+	return approvalStatus(doc, policy, append(approvals, *record), digest), nil
+}
+
+// ensureApproved проверяет перед подписанием и отправкой, что документ согласован, если этого требует политика
+func (s *esfDocumentService) ensureApproved(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) error {
+	policy, err := s.approvalPolicy(ctx, orgID)
+	if err != nil {
+		return repositoryError("fetching approval policy", err)
+	}
+	reasons := approvalReasons(policy, doc)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	digest, err := documentDigest(doc)
+	if err != nil {
+		return err
+	}
+	approvals, err := s.repo.GetDocumentApprovals(ctx, orgID, doc.ID)
+	if err != nil {
+		return repositoryError("fetching document approvals", err)
+	}
+
+	status := approvalStatus(doc, policy, approvals, digest)
+	switch status.Status {
+	case entity.ApprovalStatusApproved.String():
+		return nil
+	case entity.ApprovalStatusRejected.String():
+		return apperror.NewWithDetails(apperror.ErrApprovalRequired, "document was rejected by the approver",
+			"edit the document and request approval again")
+	}
+	s.logger.Warn(ctx, "Document is not approved", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "approvals": status.Approvals})
+	return apperror.NewWithDetails(apperror.ErrApprovalRequired, "document requires approval",
+		fmt.Sprintf("%d of %d approvals received; %s", status.Approvals, status.RequiredApprovals, strings.Join(reasons, "; ")))
+}
+
+// approvalReasons возвращает причины, по которым политика требует согласования документа.
+// Политика без порога и видов операции распространяется на все исходящие документы;
+// входящие документы поставщиков не подписываются и не согласуются.
+func approvalReasons(policy *entity.EsfApprovalPolicy, doc *entity.EsfDocument) []string {
+	if !policy.Enabled || doc.IsIncoming() {
+		return nil
+	}
+
+	codes := policy.OperationTypes()
+	if policy.ThresholdAmount == 0 && len(codes) == 0 {
+		return []string{"all outgoing documents require approval"}
+	}
+
+	var reasons []string
+	if policy.ThresholdAmount > 0 {
+		if total := documentTotal(doc); total > policy.ThresholdAmount {
+			reasons = append(reasons, fmt.Sprintf("document total %s exceeds the approval threshold %s", total, policy.ThresholdAmount))
+		}
+	}
+	for _, code := range codes {
+		if code == doc.OperationTypeCode {
+			reasons = append(reasons, fmt.Sprintf("operation type %s requires approval", code))
+			break
+		}
+	}
+	return reasons
+}
+
+// documentTotal возвращает сумму документа с налогами в сомах; для корректировки - модуль изменения
+func documentTotal(doc *entity.EsfDocument) money.Amount {
+	amounts := make([]money.Amount, len(doc.CatalogEntries))
+	for i, e := range doc.CatalogEntries {
+		amounts[i] = e.TotalAmount
+	}
+	return money.Sum(amounts...).Abs()
+}
+
+// documentDigest возвращает SHA-256 канонического представления документа (как при подписании)
+func documentDigest(doc *entity.EsfDocument) (string, error) {
+	payload, err := canonicalDocument(doc)
+	if err != nil {
+		return "", apperror.New(apperror.ErrInternal, "failed to build document payload").WithError(err)
+	}
+	return signature.Digest(payload), nil
+}
+
+// approvalStatus подводит итог согласования текущего содержимого документа.
+// Учитываются согласования разных пользователей после последнего отклонения с тем же digest;
+// отклонение без последующих согласований означает отказ.
+func approvalStatus(doc *entity.EsfDocument, policy *entity.EsfApprovalPolicy, approvals []entity.EsfDocumentApproval, digest string) *models.EsfApprovalStatusModel {
+	result := &models.EsfApprovalStatusModel{
+		DocumentID:        doc.ID,
+		Status:            entity.ApprovalStatusNotRequired.String(),
+		Reasons:           approvalReasons(policy, doc),
+		RequiredApprovals: policy.RequiredApprovals,
+		Digest:            digest,
+		Decisions:         make([]models.EsfApprovalModel, len(approvals)),
+	}
+
+	roundStart := 0
+	for i, a := range approvals {
+		if a.Decision == entity.ApprovalDecisionRejected {
+			roundStart = i
+		}
+	}
+
+	approvers := make(map[string]bool)
+	rejected := false
+	for i := range approvals {
+		a := &approvals[i]
+		current := i >= roundStart && a.Digest == digest
+		if current {
+			switch a.Decision {
+			case entity.ApprovalDecisionRejected:
+				rejected = true
+			case entity.ApprovalDecisionApproved:
+				approvers[a.ApproverID] = true
+				rejected = false
+			}
+		}
+		result.Decisions[i] = approvalToModel(a, current)
+	}
+	result.Approvals = len(approvers)
+
+	switch {
+	case len(result.Reasons) == 0:
+	case result.Approvals >= policy.RequiredApprovals:
+		result.Status = entity.ApprovalStatusApproved.String()
+	case rejected:
+		result.Status = entity.ApprovalStatusRejected.String()
+	default:
+		result.Status = entity.ApprovalStatusPending.String()
+	}
+	return result
+}
+
+func approvalToModel(a *entity.EsfDocumentApproval, current bool) models.EsfApprovalModel {
+	return models.EsfApprovalModel{
+		ID:           a.ID,
+		Decision:     a.Decision.String(),
+		Reason:       a.Reason,
+		Digest:       a.Digest,
+		Final:        a.Final,
+		ApproverID:   a.ApproverID,
+		ApproverName: a.ApproverName,
+		CreatedAt:    a.CreatedAt,
+		Current:      current,
+	}
+}
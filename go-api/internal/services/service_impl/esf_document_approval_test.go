@@ -0,0 +1,202 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// approvalPolicy возвращает политику: документы дороже 1000 сомов требуют двух согласований
+func approvalPolicy() *entity.EsfApprovalPolicy {
+	return &entity.EsfApprovalPolicy{
+		ID:                 entity.ApprovalPolicyID,
+		Enabled:            true,
+		ThresholdAmount:    money.MustParse("1000"),
+		OperationTypeCodes: "201",
+		RequiredApprovals:  2,
+	}
+}
+
+func approverContext(userID string) context.Context {
+	return logger.WithContext(logger.WithContext(context.Background(), logger.UserIDKey, userID), logger.UsernameKey, userID)
+}
+
+// ========== Document Approval Tests ==========
+
+func TestEsfApproval_Reasons(t *testing.T) {
+	doc := newTestDocument(uuid.New(), withStatus(entity.DocumentStatusReady))
+	doc.OperationTypeCode = "101"
+	doc.CatalogEntries[0].TotalAmount = money.MustParse("1000.01")
+
+	policy := approvalPolicy()
+	assert.Len(t, approvalReasons(policy, doc), 1)
+
+	doc.OperationTypeCode = "201"
+	assert.Len(t, approvalReasons(policy, doc), 2)
+
+	doc.CatalogEntries[0].TotalAmount = money.MustParse("1000")
+	doc.OperationTypeCode = "101"
+	assert.Empty(t, approvalReasons(policy, doc))
+
+	// Политика без критериев распространяется на все исходящие документы
+	assert.Len(t, approvalReasons(&entity.EsfApprovalPolicy{Enabled: true, RequiredApprovals: 1}, doc), 1)
+
+	doc.Direction = entity.DocumentDirectionIncoming
+	assert.Empty(t, approvalReasons(&entity.EsfApprovalPolicy{Enabled: true, RequiredApprovals: 1}, doc))
+
+	policy.Enabled = false
+	doc.Direction = entity.DocumentDirectionOutgoing
+	assert.Empty(t, approvalReasons(policy, doc))
+}
+
+func TestEsfApproval_StatusCountsCurrentRound(t *testing.T) {
+	doc := newTestDocument(uuid.New(), withStatus(entity.DocumentStatusReady))
+	doc.OperationTypeCode = "201"
+	digest, err := documentDigest(doc)
+	require.NoError(t, err)
+	policy := approvalPolicy()
+
+	approve := func(user, digest string) entity.EsfDocumentApproval {
+		return entity.EsfDocumentApproval{ID: uuid.New(), Decision: entity.ApprovalDecisionApproved, ApproverID: user, Digest: digest}
+	}
+	reject := func(user, digest string) entity.EsfDocumentApproval {
+		return entity.EsfDocumentApproval{ID: uuid.New(), Decision: entity.ApprovalDecisionRejected, ApproverID: user, Digest: digest, Reason: "Цена завышена"}
+	}
+
+	// Согласование прежнего содержимого и повторное согласование того же пользователя не учитываются
+	status := approvalStatus(doc, policy, []entity.EsfDocumentApproval{approve("user-2", "stale"), approve("user-3", digest), approve("user-3", digest)}, digest)
+	assert.Equal(t, "pending", status.Status)
+	assert.Equal(t, 1, status.Approvals)
+	assert.False(t, status.Decisions[0].Current)
+
+	status = approvalStatus(doc, policy, []entity.EsfDocumentApproval{approve("user-2", digest), approve("user-3", digest)}, digest)
+	assert.Equal(t, "approved", status.Status)
+
+	// Отклонение начинает согласование заново
+	status = approvalStatus(doc, policy, []entity.EsfDocumentApproval{approve("user-2", digest), reject("user-3", digest)}, digest)
+	assert.Equal(t, "rejected", status.Status)
+	assert.Equal(t, 0, status.Approvals)
+
+	status = approvalStatus(doc, policy, []entity.EsfDocumentApproval{approve("user-2", digest), reject("user-3", digest), approve("user-2", digest)}, digest)
+	assert.Equal(t, "pending", status.Status)
+	assert.Equal(t, 1, status.Approvals)
+}
+
+func TestEsfApproval_DecisionRules(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	policies := new(MockApprovalPolicyRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	doc.OperationTypeCode = "201"
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	policies.On("GetApprovalPolicy", mock.Anything, orgID).Return(approvalPolicy(), nil)
+	mockRepo.On("GetDocumentRevisions", mock.Anything, orgID, docID).Return([]entity.EsfDocumentRevision{
+		{DocumentID: docID, Revision: 1, Action: entity.RevisionActionCreate, AuthorID: "user-1"},
+		{DocumentID: docID, Revision: 2, Action: entity.RevisionActionStatus, AuthorID: "user-2"},
+	}, nil)
+	mockRepo.On("GetDocumentApprovals", mock.Anything, orgID, docID).Return([]entity.EsfDocumentApproval{}, nil)
+	mockRepo.On("DecideDocumentApproval", mock.Anything, orgID, doc, mock.Anything, mock.Anything).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetApprovalPolicyRepository(policies)
+
+	// Автор документа не согласует его сам
+	_, err := service.DecideDocumentApproval(approverContext("user-1"), orgID, docID, entity.ApprovalDecisionApproved, &models.EsfApprovalDecisionRequest{})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrForbidden, appErr.Code)
+
+	_, err = service.DecideDocumentApproval(approverContext("user-2"), orgID, docID, entity.ApprovalDecisionRejected, &models.EsfApprovalDecisionRequest{Reason: " "})
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+
+	_, err = service.DecideDocumentApproval(approverContext("user-2"), orgID, docID, entity.ApprovalDecisionApproved, &models.EsfApprovalDecisionRequest{Digest: "0000"})
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrConflict, appErr.Code)
+
+	result, err := service.DecideDocumentApproval(approverContext("user-2"), orgID, docID, entity.ApprovalDecisionApproved, &models.EsfApprovalDecisionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "pending", result.Status)
+	assert.Equal(t, 1, result.Approvals)
+	mockRepo.AssertCalled(t, "DecideDocumentApproval", mock.Anything, orgID, doc, mock.MatchedBy(func(a *entity.EsfDocumentApproval) bool {
+		return a.ApproverID == "user-2" && a.Decision == entity.ApprovalDecisionApproved && !a.Final && a.Digest == result.Digest
+	}), entity.DocumentStatusReady)
+}
+
+func TestEsfApproval_RejectReturnsDocumentToDraft(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	policies := new(MockApprovalPolicyRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	doc.OperationTypeCode = "201"
+	digest, err := documentDigest(doc)
+	require.NoError(t, err)
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	policies.On("GetApprovalPolicy", mock.Anything, orgID).Return(approvalPolicy(), nil)
+	mockRepo.On("GetDocumentRevisions", mock.Anything, orgID, docID).Return([]entity.EsfDocumentRevision{}, nil)
+	mockRepo.On("GetDocumentApprovals", mock.Anything, orgID, docID).Return([]entity.EsfDocumentApproval{
+		{ID: uuid.New(), Decision: entity.ApprovalDecisionApproved, ApproverID: "user-2", Digest: digest},
+	}, nil)
+	mockRepo.On("DecideDocumentApproval", mock.Anything, orgID, doc, mock.MatchedBy(func(a *entity.EsfDocumentApproval) bool {
+		return a.Decision == entity.ApprovalDecisionRejected && a.Final && a.Reason == "Цена завышена"
+	}), entity.DocumentStatusDraft).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetApprovalPolicyRepository(policies)
+	result, err := service.DecideDocumentApproval(approverContext("user-3"), orgID, docID, entity.ApprovalDecisionRejected, &models.EsfApprovalDecisionRequest{Reason: " Цена завышена "})
+	require.NoError(t, err)
+
+	assert.Equal(t, "rejected", result.Status)
+	assert.Equal(t, 0, result.Approvals)
+	require.Len(t, result.Decisions, 2)
+	assert.True(t, result.Decisions[1].Final)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfApproval_BlocksSigningUntilApproved(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	policies := new(MockApprovalPolicyRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	doc.OperationTypeCode = "201"
+	digest, err := documentDigest(doc)
+	require.NoError(t, err)
+
+	approvals := []entity.EsfDocumentApproval{{ID: uuid.New(), Decision: entity.ApprovalDecisionApproved, ApproverID: "user-2", Digest: digest}}
+	policies.On("GetApprovalPolicy", mock.Anything, orgID).Return(approvalPolicy(), nil)
+	mockRepo.On("GetDocumentApprovals", mock.Anything, orgID, docID).Return(approvals, nil).Once()
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New()).(*esfDocumentService)
+	service.SetApprovalPolicyRepository(policies)
+	err = service.ensureApproved(context.Background(), orgID, doc)
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrApprovalRequired, appErr.Code)
+	assert.Contains(t, appErr.Details, "1 of 2 approvals received")
+
+	approvals = append(approvals, entity.EsfDocumentApproval{ID: uuid.New(), Decision: entity.ApprovalDecisionApproved, ApproverID: "user-3", Digest: digest})
+	mockRepo.On("GetDocumentApprovals", mock.Anything, orgID, docID).Return(approvals, nil).Once()
+	require.NoError(t, service.ensureApproved(context.Background(), orgID, doc))
+
+	// Изменение документа после согласования требует нового согласования
+	doc.CatalogEntries[0].Price = money.MustParse("100.01")
+	mockRepo.On("GetDocumentApprovals", mock.Anything, orgID, docID).Return(approvals, nil).Once()
+	err = service.ensureApproved(context.Background(), orgID, doc)
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrApprovalRequired, appErr.Code)
+}
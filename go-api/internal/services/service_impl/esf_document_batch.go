@@ -0,0 +1,172 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// maxBatchItems ограничивает количество элементов в одном пакете
+const maxBatchItems = 500
+
+// ProcessDocumentBatch создает и изменяет документы пакетом.
+// В режиме atomic пакет проверяется целиком и сохраняется в одной транзакции;
+// в режиме bestEffort каждый элемент обрабатывается независимо.
+func (s *esfDocumentService) ProcessDocumentBatch(ctx context.Context, orgID uuid.UUID, req *models.EsfDocumentBatchRequest) (*models.EsfDocumentBatchResponse, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = models.BatchModeAtomic
+	}
+
+	s.logger.Info(ctx, "Processing document batch", logrus.Fields{"org_id": orgID.String(), "mode": mode, "items": len(req.Items)})
+
+	if mode != models.BatchModeAtomic && mode != models.BatchModeBestEffort {
+		return nil, apperror.NewWithDetails(apperror.ErrValidation, "invalid batch mode",
+			fmt.Sprintf("expected %s or %s", models.BatchModeAtomic, models.BatchModeBestEffort))
+	}
+	if len(req.Items) == 0 {
+		return nil, apperror.ValidationError("batch must contain at least one item")
+	}
+	if len(req.Items) > maxBatchItems {
+		return nil, apperror.ValidationError(fmt.Sprintf("batch must not contain more than %d items", maxBatchItems))
+	}
+
+	result := &models.EsfDocumentBatchResponse{
+		Mode:  mode,
+		Total: len(req.Items),
+		Items: make([]models.EsfDocumentBatchItemResult, len(req.Items)),
+	}
+	for i := range req.Items {
+		result.Items[i] = models.EsfDocumentBatchItemResult{Index: i, OwnedCrmReceiptCode: req.Items[i].OwnedCrmReceiptCode}
+	}
+
+	var err error
+	if mode == models.BatchModeAtomic {
+		err = s.processAtomicBatch(ctx, orgID, req.Items, result)
+	} else {
+		s.processBestEffortBatch(ctx, orgID, req.Items, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range result.Items {
+		switch item.Status {
+		case models.BatchItemCreated, models.BatchItemUpdated:
+			result.Succeeded++
+		case models.BatchItemFailed:
+			result.Failed++
+		}
+	}
+
+	s.logger.Info(ctx, "Document batch processed", logrus.Fields{"org_id": orgID.String(), "mode": mode, "succeeded": result.Succeeded, "failed": result.Failed})
+	return result, nil
+}
+
+// processAtomicBatch проверяет все элементы и сохраняет их одной транзакцией.
+// Если хотя бы один элемент некорректен, остальные помечаются как skipped и ничего не сохраняется.
+func (s *esfDocumentService) processAtomicBatch(ctx context.Context, orgID uuid.UUID, items []models.EsfEditDocumentRequest, result *models.EsfDocumentBatchResponse) error {
+	var created, updated []entity.EsfDocument
+	var createdIndex, updatedIndex []int
+	seen := make(map[uuid.UUID]int)
+	failed := false
+
+	for i := range items {
+		item := &items[i]
+
+		if item.ID == uuid.Nil {
+			doc := s.toEntity(&item.EsfCreateDocumentRequest)
+			if appErr := s.prepareNewDocument(ctx, orgID, &doc); appErr != nil {
+				failBatchItem(&result.Items[i], appErr)
+				failed = true
+				continue
+			}
+			created = append(created, doc)
+			createdIndex = append(createdIndex, i)
+			continue
+		}
+
+		if first, ok := seen[item.ID]; ok {
+			failBatchItem(&result.Items[i], apperror.NewWithDetails(apperror.ErrInvalidRequest, "document is updated more than once in the batch",
+				fmt.Sprintf("same document as item %d", first)))
+			failed = true
+			continue
+		}
+		seen[item.ID] = i
+
+		doc, err := s.prepareDocumentUpdate(ctx, orgID, item)
+		if err != nil {
+			failBatchItem(&result.Items[i], err)
+			failed = true
+			continue
+		}
+		updated = append(updated, *doc)
+		updatedIndex = append(updatedIndex, i)
+	}
+
+	if failed {
+		for i := range result.Items {
+			if result.Items[i].Status == "" {
+				result.Items[i].Status = models.BatchItemSkipped
+			}
+		}
+		s.logger.Warn(ctx, "Document batch rejected", logrus.Fields{"org_id": orgID.String()})
+		return nil
+	}
+
+	if err := s.repo.SaveDocuments(ctx, orgID, created, updated); err != nil {
+		s.logger.Error(ctx, "Failed to save document batch", err, logrus.Fields{"org_id": orgID.String()})
+		return repositoryError("saving document batch", err)
+	}
+
+	for n, i := range createdIndex {
+		result.Items[i].Status = models.BatchItemCreated
+		result.Items[i].DocumentUuid = created[n].ID.String()
+	}
+	for n, i := range updatedIndex {
+		result.Items[i].Status = models.BatchItemUpdated
+		result.Items[i].DocumentUuid = updated[n].ID.String()
+		s.invalidateDocumentCache(ctx, orgID, updated[n].ID)
+	}
+	return nil
+}
+
+// processBestEffortBatch применяет элементы по одному; ошибка элемента не влияет на остальные
+func (s *esfDocumentService) processBestEffortBatch(ctx context.Context, orgID uuid.UUID, items []models.EsfEditDocumentRequest, result *models.EsfDocumentBatchResponse) {
+	for i := range items {
+		item := &items[i]
+
+		if item.ID == uuid.Nil {
+			resp, err := s.CreateDocument(ctx, orgID, &item.EsfCreateDocumentRequest)
+			if err != nil {
+				failBatchItem(&result.Items[i], err)
+				continue
+			}
+			result.Items[i].Status = models.BatchItemCreated
+			result.Items[i].DocumentUuid = resp.DocumentUuid
+			continue
+		}
+
+		if err := s.UpdateDocument(ctx, orgID, item); err != nil {
+			failBatchItem(&result.Items[i], err)
+			continue
+		}
+		result.Items[i].Status = models.BatchItemUpdated
+		result.Items[i].DocumentUuid = item.ID.String()
+	}
+}
+
+// failBatchItem записывает ошибку элемента пакета в формате ответа API
+func failBatchItem(item *models.EsfDocumentBatchItemResult, err error) {
+	item.Status = models.BatchItemFailed
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		appErr = apperror.New(apperror.ErrInternal, "internal error")
+	}
+	item.Error = appErr.ToResponse()
+}
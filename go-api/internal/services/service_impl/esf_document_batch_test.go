@@ -0,0 +1,135 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchItem(id uuid.UUID, crmCode string, total money.Amount) models.EsfEditDocumentRequest {
+	return models.EsfEditDocumentRequest{
+		ID: id,
+		EsfCreateDocumentRequest: models.EsfCreateDocumentRequest{
+			OwnedCrmReceiptCode: crmCode,
+			ContractorTin:       "01234567890123",
+			TaxRateVATCode:      "1",
+			IsPriceWithoutTaxes: true,
+			CatalogEntries: []models.EsfEntriesModel{{
+				SalesTaxCode: "001",
+				Quantity:     10,
+				Price:        money.MustParse("100"),
+				TotalAmount:  total,
+			}},
+		},
+	}
+}
+
+// ========== Batch Tests ==========
+
+func TestEsfDocumentBatch_AtomicSavesAllItems(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, draftID := uuid.New(), uuid.New()
+
+	var created, updated []entity.EsfDocument
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, draftID).Return(newTestDocument(draftID), nil)
+	mockRepo.On("SaveDocuments", mock.Anything, orgID, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).([]entity.EsfDocument)
+		updated = args.Get(3).([]entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ProcessDocumentBatch(context.Background(), orgID, &models.EsfDocumentBatchRequest{
+		Items: []models.EsfEditDocumentRequest{
+			newBatchItem(uuid.Nil, "CRM-1", 0),
+			newBatchItem(draftID, "CRM-2", money.MustParse("1120")),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.BatchModeAtomic, result.Mode)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 0, result.Failed)
+
+	require.Len(t, created, 1)
+	require.Len(t, updated, 1)
+	assert.Equal(t, models.BatchItemCreated, result.Items[0].Status)
+	assert.Equal(t, created[0].ID.String(), result.Items[0].DocumentUuid)
+	assert.Equal(t, "CRM-1", result.Items[0].OwnedCrmReceiptCode)
+	assert.Equal(t, models.BatchItemUpdated, result.Items[1].Status)
+	assert.Equal(t, draftID.String(), result.Items[1].DocumentUuid)
+}
+
+func TestEsfDocumentBatch_AtomicRejectsWholeBatch(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, lockedID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, lockedID).Return(newTestDocument(lockedID, withStatus(entity.DocumentStatusAccepted)), nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ProcessDocumentBatch(context.Background(), orgID, &models.EsfDocumentBatchRequest{
+		Mode: models.BatchModeAtomic,
+		Items: []models.EsfEditDocumentRequest{
+			newBatchItem(uuid.Nil, "CRM-1", 0),
+			newBatchItem(uuid.Nil, "CRM-2", money.MustParse("999")),
+			newBatchItem(lockedID, "CRM-3", 0),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.Succeeded)
+	assert.Equal(t, 2, result.Failed)
+	assert.Equal(t, models.BatchItemSkipped, result.Items[0].Status)
+	assert.Equal(t, models.BatchItemFailed, result.Items[1].Status)
+	assert.Equal(t, string(apperror.ErrFieldValidation), result.Items[1].Error.Code)
+	assert.Equal(t, models.BatchItemFailed, result.Items[2].Status)
+	assert.Equal(t, string(apperror.ErrDocumentLocked), result.Items[2].Error.Code)
+	mockRepo.AssertNotCalled(t, "SaveDocuments", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentBatch_BestEffortAppliesValidItems(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, missingID := uuid.New(), uuid.New()
+
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Return(nil)
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, missingID).Return(nil, apperror.NotFoundError("document"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ProcessDocumentBatch(context.Background(), orgID, &models.EsfDocumentBatchRequest{
+		Mode: models.BatchModeBestEffort,
+		Items: []models.EsfEditDocumentRequest{
+			newBatchItem(uuid.Nil, "CRM-1", 0),
+			newBatchItem(missingID, "CRM-2", 0),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Succeeded)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, models.BatchItemCreated, result.Items[0].Status)
+	assert.NotEmpty(t, result.Items[0].DocumentUuid)
+	assert.Equal(t, string(apperror.ErrNotFound), result.Items[1].Error.Code)
+}
+
+func TestEsfDocumentBatch_ValidatesRequest(t *testing.T) {
+	service := NewEsfDocumentService(new(MockDocumentRepository), nil, logrus.New())
+
+	_, err := service.ProcessDocumentBatch(context.Background(), uuid.New(), &models.EsfDocumentBatchRequest{})
+	assert.Error(t, err)
+
+	_, err = service.ProcessDocumentBatch(context.Background(), uuid.New(), &models.EsfDocumentBatchRequest{
+		Mode:  "sometimes",
+		Items: []models.EsfEditDocumentRequest{newBatchItem(uuid.Nil, "", 0)},
+	})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+}
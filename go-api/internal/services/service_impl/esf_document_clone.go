@@ -0,0 +1,107 @@
+package service_impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/sirupsen/logrus"
+)
+
+// SetOrganizationRepository подключает справочник организаций для операций между организациями
+func (s *esfDocumentService) SetOrganizationRepository(orgRepo repository.EsfOrganizationRepository) {
+	s.orgRepo = orgRepo
+}
+
+// CloneDocument копирует реквизиты и позиции документа в новый черновик текущей или другой организации.
+// Номер, дата поставки, курс валюты и суммы не копируются: номер присваивается заново,
+// дата поставки - сегодня, курс определяется по новой дате, налоги рассчитываются заново.
+func (s *esfDocumentService) CloneDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfCloneRequest) (*models.EsfCloneResponse, error) {
+	targetOrgID := orgID
+	if req.TargetOrgId != nil && *req.TargetOrgId != uuid.Nil {
+		targetOrgID = *req.TargetOrgId
+	}
+
+	s.logger.Info(ctx, "Cloning document", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "target_org_id": targetOrgID.String()})
+
+	source, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+	if source.IsCorrection() {
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidRequest, "correction documents cannot be cloned",
+			"clone the original document: "+source.OriginalDocumentID.String())
+	}
+
+	if targetOrgID != orgID {
+		if _, err := s.organization(ctx, targetOrgID); err != nil {
+			if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+				return nil, apperror.FieldValidationError("invalid target organization", []apperror.FieldError{
+					{Field: "targetOrgId", Message: "organization not found"},
+				})
+			}
+			return nil, err
+		}
+	}
+
+	doc := s.toModel(source)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	doc.DeliveryDate = &today
+	doc.OwnedCrmReceiptCode = ""
+	doc.CurrencyRate = nil
+	doc.TotalCurrencyValue = nil
+	doc.TotalCurrencyValueWithoutTaxes = nil
+	// Справочник контрагентов ведется в каждой организации отдельно; реквизиты покупателя остаются в документе
+	if targetOrgID != orgID {
+		doc.ContractorId = nil
+	}
+	for i := range doc.CatalogEntries {
+		doc.CatalogEntries[i] = models.EsfEntriesModel{
+			UnitClassificationCode: doc.CatalogEntries[i].UnitClassificationCode,
+			SalesTaxCode:           doc.CatalogEntries[i].SalesTaxCode,
+			CustomsAuthorityCode:   doc.CatalogEntries[i].CustomsAuthorityCode,
+			Quantity:               doc.CatalogEntries[i].Quantity,
+			Price:                  doc.CatalogEntries[i].Price,
+		}
+	}
+
+	// Номер входящего документа присваивает поставщик, поэтому его можно указать в overrides
+	doc.Number = ""
+	if len(bytes.TrimSpace(req.Overrides)) > 0 {
+		// Позиции из overrides заменяют скопированные целиком, а не дополняют их поля
+		entries := doc.CatalogEntries
+		doc.CatalogEntries = nil
+		decoder := json.NewDecoder(bytes.NewReader(req.Overrides))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, apperror.NewWithDetails(apperror.ErrValidation, "invalid document overrides", err.Error())
+		}
+		if doc.CatalogEntries == nil {
+			doc.CatalogEntries = entries
+		}
+	}
+	doc.ID = nil
+	doc.Status = ""
+	doc.ExternalDocumentUuid = ""
+	doc.OriginalDocumentId = nil
+	doc.CorrectionReason = ""
+
+	resp, err := s.CreateDocument(ctx, targetOrgID, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Document cloned successfully", logrus.Fields{
+		"org_id":        orgID.String(),
+		"doc_id":        id.String(),
+		"target_org_id": targetOrgID.String(),
+		"clone_id":      resp.DocumentUuid,
+	})
+	return &models.EsfCloneResponse{DocumentUuid: resp.DocumentUuid, OrgId: targetOrgID, SourceDocumentUuid: id.String()}, nil
+}
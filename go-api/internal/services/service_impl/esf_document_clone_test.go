@@ -0,0 +1,161 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Document Clone Tests ==========
+
+func TestEsfClone_CopiesIntoNewDraft(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	source := newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))
+	source.ExternalDocumentUUID = "ext-1"
+	source.OwnedCrmReceiptCode = "CRM-17"
+	source.CatalogEntries[0].VatAmount = money.MustParse("1")
+	source.CatalogEntries[0].TotalAmount = money.MustParse("1")
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(source, nil)
+
+	var created *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.CloneDocument(context.Background(), orgID, docID, &models.EsfCloneRequest{})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Equal(t, created.ID.String(), result.DocumentUuid)
+	assert.Equal(t, orgID, result.OrgId)
+	assert.NotEqual(t, docID, created.ID)
+	assert.Equal(t, entity.DocumentStatusDraft, created.Status)
+	assert.Empty(t, created.Number)
+	assert.Empty(t, created.ExternalDocumentUUID)
+	assert.Empty(t, created.OwnedCrmReceiptCode)
+	assert.Equal(t, "Д-42", created.SupplyContractNumber)
+	assert.Equal(t, time.Now().UTC().Truncate(24*time.Hour), created.DeliveryDate)
+
+	require.Len(t, created.CatalogEntries, 1)
+	for _, entry := range created.CatalogEntries {
+		assert.Equal(t, uuid.Nil, entry.ID)
+		assert.Equal(t, uuid.Nil, entry.DocumentID)
+	}
+	// Налоги рассчитаны заново, а не скопированы
+	assert.Equal(t, money.MustParse("120"), created.CatalogEntries[0].VatAmount)
+	assert.Equal(t, money.MustParse("1120"), created.CatalogEntries[0].TotalAmount)
+}
+
+func TestEsfClone_AppliesOverrides(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted)), nil)
+
+	var created *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CloneDocument(context.Background(), orgID, docID, &models.EsfCloneRequest{
+		Overrides: json.RawMessage(`{
+			"number": "INV-1999-000001",
+			"deliveryDate": "2026-05-04T00:00:00Z",
+			"comment": "Май",
+			"catalogEntries": [{"salesTaxCode": "001", "quantity": 3, "price": "10"}]
+		}`),
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Empty(t, created.Number)
+	assert.Equal(t, time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), created.DeliveryDate)
+	assert.Equal(t, "Май", created.Comment)
+	require.Len(t, created.CatalogEntries, 1)
+	assert.Equal(t, float64(3), created.CatalogEntries[0].Quantity)
+	assert.Empty(t, created.CatalogEntries[0].UnitClassificationCode)
+}
+
+func TestEsfClone_RejectsUnknownOverride(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted)), nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CloneDocument(context.Background(), orgID, docID, &models.EsfCloneRequest{
+		Overrides: json.RawMessage(`{"buyerTin": "01503201910012"}`),
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfClone_IntoAnotherOrganization(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	contractors := new(MockContractorRepository)
+	orgID, targetOrgID, docID, contractorID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	source := newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))
+	source.ContractorID = &contractorID
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(source, nil)
+
+	var created *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, targetOrgID, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetOrganizationRepository(&stubOrganizationRepository{org: &entity.EstOrganization{ID: targetOrgID}})
+	service.SetContractorRepository(contractors)
+
+	t.Run("target exists", func(t *testing.T) {
+		result, err := service.CloneDocument(context.Background(), orgID, docID, &models.EsfCloneRequest{TargetOrgId: &targetOrgID})
+		require.NoError(t, err)
+
+		assert.Equal(t, targetOrgID, result.OrgId)
+		require.NotNil(t, created)
+		assert.Nil(t, created.ContractorID)
+		assert.Equal(t, "01234567890123", created.ContractorTin)
+		contractors.AssertNotCalled(t, "GetContractorByID", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		unknown := uuid.New()
+		_, err := service.CloneDocument(context.Background(), orgID, docID, &models.EsfCloneRequest{TargetOrgId: &unknown})
+
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		require.Len(t, appErr.Fields, 1)
+		assert.Equal(t, "targetOrgId", appErr.Fields[0].Field)
+	})
+}
+
+func TestEsfClone_RejectsCorrection(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, originalID := uuid.New(), uuid.New(), uuid.New()
+	correction := newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))
+	correction.OriginalDocumentID = &originalID
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(correction, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CloneDocument(context.Background(), orgID, docID, &models.EsfCloneRequest{})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidRequest, appErr.Code)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
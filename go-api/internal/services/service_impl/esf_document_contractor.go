@@ -0,0 +1,73 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+)
+
+// SetContractorRepository подключает справочник контрагентов.
+// Без справочника документы с contractorId отклоняются, а доставленные копии не связываются с контрагентом.
+func (s *esfDocumentService) SetContractorRepository(contractors repository.EsfContractorRepository) {
+	s.contractors = contractors
+}
+
+// applyContractor заполняет реквизиты контрагента из справочника контрагентов: покупателя
+// исходящего документа или поставщика входящего.
+// Переданный ИНН должен совпадать с ИНН контрагента, а счет - быть одним из его счетов;
+// если счет не указан, подставляется основной счет контрагента.
+func (s *esfDocumentService) applyContractor(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) *apperror.AppError {
+	if doc.ContractorID == nil {
+		return nil
+	}
+
+	if s.contractors == nil {
+		return apperror.New(apperror.ErrConfigError, "contractor directory is not configured")
+	}
+
+	contractor, err := s.contractors.GetContractorByID(ctx, orgID, *doc.ContractorID)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+			return apperror.FieldValidationError("invalid contractor", []apperror.FieldError{
+				{Field: "contractorId", Message: "contractor not found"},
+			})
+		}
+		return repositoryError("fetching contractor", err)
+	}
+
+	tinField, tin, accountField, account := "contractorTin", &doc.ContractorTin, "contractorBankAccount", &doc.ContractorBankAccount
+	if doc.IsIncoming() {
+		tinField, tin, accountField, account = "supplierTin", &doc.SupplierTin, "supplierBankAccount", &doc.SupplierBankAccount
+	}
+
+	var fields []apperror.FieldError
+	if *tin != "" && *tin != contractor.Tin {
+		fields = append(fields, apperror.FieldError{
+			Field:   tinField,
+			Message: fmt.Sprintf("TIN does not match contractor TIN %s", contractor.Tin),
+		})
+	}
+	if *account == "" {
+		*account = contractor.DefaultBankAccount()
+	} else if !contractor.HasBankAccount(*account) {
+		fields = append(fields, apperror.FieldError{
+			Field:   accountField,
+			Message: "account is not registered for the contractor",
+		})
+	}
+	if len(fields) > 0 {
+		return apperror.FieldValidationError("document does not match the contractor", fields)
+	}
+
+	*tin = contractor.Tin
+	if doc.IsIncoming() && doc.SupplierName == "" {
+		doc.SupplierName = contractor.Name
+	}
+	doc.IsResident = contractor.IsResident
+	doc.CountryCode = contractor.CountryCode
+	return nil
+}
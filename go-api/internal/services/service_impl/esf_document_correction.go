@@ -0,0 +1,304 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/tax"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateCorrection создает корректировочную счет-фактуру к принятому документу.
+// Корректировка создается в статусе черновика и проходит обычный жизненный цикл.
+func (s *esfDocumentService) CreateCorrection(ctx context.Context, orgID uuid.UUID, originalID uuid.UUID, req *models.EsfCorrectionRequest) (*models.EsfCreateDocumentResponse, error) {
+	s.logger.Info(ctx, "Creating correction document", logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+
+	original, err := s.repo.GetDocumentByID(ctx, orgID, originalID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch original document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+
+	if original.IsCorrection() {
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidRequest, "corrections must reference the original document",
+			"original document: "+original.OriginalDocumentID.String())
+	}
+	if original.IsIncoming() {
+		return nil, apperror.New(apperror.ErrInvalidRequest, "incoming documents are corrected by the supplier, register the supplier correction as a new incoming document")
+	}
+	if original.Status != entity.DocumentStatusAccepted {
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidRequest, "only accepted documents can be corrected",
+			"current status: "+original.Status.String())
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, apperror.ValidationError("reason is required to correct a document")
+	}
+	if len(req.Entries) == 0 {
+		return nil, apperror.ValidationError("correction must contain at least one entry")
+	}
+
+	corrections, err := s.repo.GetDocumentCorrections(ctx, orgID, originalID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document corrections", err, logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+		return nil, repositoryError("fetching document corrections", err)
+	}
+
+	current := netEntries(original, corrections)
+	pending := pendingCorrections(corrections)
+
+	correction := entity.EsfDocument{
+		ID:                    uuid.New(),
+		Status:                entity.DocumentStatusDraft,
+		OriginalDocumentID:    &original.ID,
+		CorrectionReason:      reason,
+		ForeignName:           original.ForeignName,
+		IsBranchDataSent:      original.IsBranchDataSent,
+		IsPriceWithoutTaxes:   original.IsPriceWithoutTaxes,
+		AffiliateTin:          original.AffiliateTin,
+		IsIndustry:            original.IsIndustry,
+		OperationTypeCode:     original.OperationTypeCode,
+		DeliveryDate:          original.DeliveryDate,
+		DeliveryTypeCode:      original.DeliveryTypeCode,
+		IsResident:            original.IsResident,
+		ContractorTin:         original.ContractorTin,
+		SupplierBankAccount:   original.SupplierBankAccount,
+		ContractorBankAccount: original.ContractorBankAccount,
+		CurrencyCode:          original.CurrencyCode,
+		CountryCode:           original.CountryCode,
+		CurrencyRate:          original.CurrencyRate,
+		SupplyContractNumber:  original.SupplyContractNumber,
+		ContractStartDate:     original.ContractStartDate,
+		DeliveryCode:          original.DeliveryCode,
+		PaymentCode:           original.PaymentCode,
+		TaxRateVATCode:        original.TaxRateVATCode,
+		CatalogEntries:        make([]entity.EsfEntries, 0, len(req.Entries)),
+	}
+
+	var fields []apperror.FieldError
+	seen := make(map[uuid.UUID]bool, len(req.Entries))
+	var totalWithoutTaxes, total money.Amount
+
+	for i, delta := range req.Entries {
+		state, ok := current[delta.OriginalEntryId]
+		switch {
+		case !ok:
+			fields = append(fields, apperror.FieldError{Field: correctionFieldName(i, "originalEntryId"), Message: "entry does not belong to the original document"})
+			continue
+		case seen[delta.OriginalEntryId]:
+			fields = append(fields, apperror.FieldError{Field: correctionFieldName(i, "originalEntryId"), Message: "entry is corrected more than once"})
+			continue
+		case pending[delta.OriginalEntryId] != uuid.Nil:
+			// Изменение считается от итогов с учетом только отправленных корректировок,
+			// поэтому две неотправленные корректировки одной позиции не должны суммироваться
+			fields = append(fields, apperror.FieldError{Field: correctionFieldName(i, "originalEntryId"),
+				Message: "entry has pending correction " + pending[delta.OriginalEntryId].String() + ", submit or cancel it first"})
+			continue
+		case delta.QuantityDelta == 0 && delta.PriceDelta.IsZero():
+			fields = append(fields, apperror.FieldError{Field: correctionFieldName(i, "quantityDelta"), Message: "quantity or price delta is required"})
+			continue
+		}
+		seen[delta.OriginalEntryId] = true
+
+		after := tax.LineInput{
+			Quantity:     state.Quantity + delta.QuantityDelta,
+			Price:        state.Price.Add(delta.PriceDelta),
+			SalesTaxCode: state.SalesTaxCode,
+		}
+		amounts, err := s.taxCalculator.CalculateAdjustment(original.TaxRateVATCode, original.IsPriceWithoutTaxes,
+			tax.LineInput{Quantity: state.Quantity, Price: state.Price, SalesTaxCode: state.SalesTaxCode}, after)
+		if err != nil {
+			if calcErr, ok := err.(*tax.CalculationError); ok {
+				for _, issue := range calcErr.Issues {
+					field := issue.Field
+					if issue.Line >= 0 {
+						field = correctionFieldName(i, issue.Field+"Delta")
+					}
+					fields = append(fields, apperror.FieldError{Field: field, Message: issue.Message})
+				}
+			}
+			continue
+		}
+
+		entryID := delta.OriginalEntryId
+		correction.CatalogEntries = append(correction.CatalogEntries, entity.EsfEntries{
+			UnitClassificationCode: state.UnitClassificationCode,
+			SalesTaxCode:           state.SalesTaxCode,
+			CustomsAuthorityCode:   state.CustomsAuthorityCode,
+			Quantity:               after.Quantity,
+			Price:                  after.Price,
+			VatAmount:              amounts.VatAmount,
+			SalesTaxAmount:         amounts.SalesTaxAmount,
+			AmountWithoutTaxes:     amounts.AmountWithoutTaxes,
+			TotalAmount:            amounts.TotalAmount,
+			OriginalEntryID:        &entryID,
+			QuantityDelta:          delta.QuantityDelta,
+			PriceDelta:             delta.PriceDelta,
+		})
+		totalWithoutTaxes += amounts.AmountWithoutTaxes
+		total += amounts.TotalAmount
+	}
+
+	if correction.TotalCurrencyValueWithoutTaxes, err = currencyTotal(&correction, totalWithoutTaxes); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "totalCurrencyValueWithoutTaxes", Message: "amount in document currency is out of range"})
+	}
+	if correction.TotalCurrencyValue, err = currencyTotal(&correction, total); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "totalCurrencyValue", Message: "amount in document currency is out of range"})
+	}
+
+	if len(fields) > 0 {
+		s.logger.Warn(ctx, "Correction validation failed", logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String(), "issues": len(fields)})
+		return nil, apperror.FieldValidationError("invalid correction entries", fields)
+	}
+
+	if err := s.repo.CreateDocument(ctx, orgID, &correction); err != nil {
+		s.logger.Error(ctx, "Failed to create correction document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+		return nil, repositoryError("creating correction document", err)
+	}
+
+	s.logger.Info(ctx, "Correction document created successfully", logrus.Fields{
+		"org_id":        orgID.String(),
+		"doc_id":        originalID.String(),
+		"correction_id": correction.ID.String(),
+	})
+
+	return &models.EsfCreateDocumentResponse{
+		ResponseId:   "success",
+		DocumentUuid: correction.ID.String(),
+	}, nil
+}
+
+// GetCorrectionChain возвращает исходный документ со всеми корректировками и итоговыми суммами.
+// id может указывать как на исходный документ, так и на любую его корректировку.
+func (s *esfDocumentService) GetCorrectionChain(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfCorrectionChainResponse, error) {
+	s.logger.Info(ctx, "Fetching correction chain", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+
+	original, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+
+	if original.IsCorrection() {
+		originalID := *original.OriginalDocumentID
+		if original, err = s.repo.GetDocumentByID(ctx, orgID, originalID); err != nil {
+			s.logger.Error(ctx, "Failed to fetch original document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": originalID.String()})
+			return nil, repositoryError("fetching original document", err)
+		}
+	}
+
+	corrections, err := s.repo.GetDocumentCorrections(ctx, orgID, original.ID)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document corrections", err, logrus.Fields{"org_id": orgID.String(), "doc_id": original.ID.String()})
+		return nil, repositoryError("fetching document corrections", err)
+	}
+
+	net := netEntries(original, corrections)
+
+	resp := &models.EsfCorrectionChainResponse{
+		Original:             s.toModel(original),
+		Corrections:          make([]models.EsfCreateDocumentRequest, len(corrections)),
+		NetEntries:           make([]models.EsfNetEntryModel, 0, len(original.CatalogEntries)),
+		PendingCorrectionIds: []uuid.UUID{},
+	}
+	for i := range corrections {
+		resp.Corrections[i] = s.toModel(&corrections[i])
+		if isPendingCorrection(&corrections[i]) {
+			resp.PendingCorrectionIds = append(resp.PendingCorrectionIds, corrections[i].ID)
+			for _, delta := range corrections[i].CatalogEntries {
+				resp.PendingTotals.AmountWithoutTaxes += delta.AmountWithoutTaxes
+				resp.PendingTotals.VatAmount += delta.VatAmount
+				resp.PendingTotals.SalesTaxAmount += delta.SalesTaxAmount
+				resp.PendingTotals.TotalAmount += delta.TotalAmount
+			}
+		}
+	}
+
+	// Позиции выводятся в порядке исходного документа
+	for _, entry := range original.CatalogEntries {
+		state := net[entry.ID]
+		resp.NetEntries = append(resp.NetEntries, models.EsfNetEntryModel{
+			EntryId:                entry.ID,
+			UnitClassificationCode: state.UnitClassificationCode,
+			SalesTaxCode:           state.SalesTaxCode,
+			Quantity:               state.Quantity,
+			Price:                  state.Price,
+			VatAmount:              state.VatAmount,
+			SalesTaxAmount:         state.SalesTaxAmount,
+			AmountWithoutTaxes:     state.AmountWithoutTaxes,
+			TotalAmount:            state.TotalAmount,
+		})
+		resp.NetTotals.AmountWithoutTaxes += state.AmountWithoutTaxes
+		resp.NetTotals.VatAmount += state.VatAmount
+		resp.NetTotals.SalesTaxAmount += state.SalesTaxAmount
+		resp.NetTotals.TotalAmount += state.TotalAmount
+	}
+
+	return resp, nil
+}
+
+// netEntries применяет к позициям исходного документа корректировки, отправленные в налоговую службу
+// или принятые ею. Неотправленные, отклоненные и аннулированные корректировки пропускаются.
+func netEntries(original *entity.EsfDocument, corrections []entity.EsfDocument) map[uuid.UUID]entity.EsfEntries {
+	result := make(map[uuid.UUID]entity.EsfEntries, len(original.CatalogEntries))
+	for _, entry := range original.CatalogEntries {
+		result[entry.ID] = entry
+	}
+
+	for _, correction := range corrections {
+		if !correction.Status.IsRegistered() {
+			continue
+		}
+		for _, delta := range correction.CatalogEntries {
+			if delta.OriginalEntryID == nil {
+				continue
+			}
+			state, ok := result[*delta.OriginalEntryID]
+			if !ok {
+				continue
+			}
+			state.Quantity += delta.QuantityDelta
+			state.Price = state.Price.Add(delta.PriceDelta)
+			state.VatAmount = state.VatAmount.Add(delta.VatAmount)
+			state.SalesTaxAmount = state.SalesTaxAmount.Add(delta.SalesTaxAmount)
+			state.AmountWithoutTaxes = state.AmountWithoutTaxes.Add(delta.AmountWithoutTaxes)
+			state.TotalAmount = state.TotalAmount.Add(delta.TotalAmount)
+			result[*delta.OriginalEntryID] = state
+		}
+	}
+
+	return result
+}
+
+// isPendingCorrection проверяет, что корректировка еще готовится и не учитывается в итогах цепочки
+func isPendingCorrection(correction *entity.EsfDocument) bool {
+	return !correction.Status.IsRegistered() && !correction.Status.IsVoid()
+}
+
+// pendingCorrections возвращает для позиций исходного документа неотправленные корректировки, которые их изменяют
+func pendingCorrections(corrections []entity.EsfDocument) map[uuid.UUID]uuid.UUID {
+	result := make(map[uuid.UUID]uuid.UUID)
+	for i := range corrections {
+		if !isPendingCorrection(&corrections[i]) {
+			continue
+		}
+		for _, delta := range corrections[i].CatalogEntries {
+			if delta.OriginalEntryID != nil {
+				result[*delta.OriginalEntryID] = corrections[i].ID
+			}
+		}
+	}
+	return result
+}
+
+// correctionFieldName формирует имя поля запроса корректировки для ошибок валидации
+func correctionFieldName(line int, field string) string {
+	return fmt.Sprintf("entries[%d].%s", line, field)
+}
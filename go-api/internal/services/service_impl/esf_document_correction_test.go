@@ -0,0 +1,198 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Correction Document Tests ==========
+
+func TestEsfDocumentCorrection_CreatesDeltaDocument(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, entryID := uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted), withEntries(testEntry(entryID, 10, money.MustParse("100")))), nil)
+	mockRepo.On("GetDocumentCorrections", mock.Anything, orgID, docID).Return([]entity.EsfDocument{}, nil)
+
+	var saved *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateCorrection(context.Background(), orgID, docID, &models.EsfCorrectionRequest{
+		Reason:  "returned goods",
+		Entries: []models.EsfCorrectionEntryRequest{{OriginalEntryId: entryID, QuantityDelta: -2}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+
+	assert.Equal(t, entity.DocumentStatusDraft, saved.Status)
+	require.NotNil(t, saved.OriginalDocumentID)
+	assert.Equal(t, docID, *saved.OriginalDocumentID)
+
+	require.Len(t, saved.CatalogEntries, 1)
+	entry := saved.CatalogEntries[0]
+	assert.Equal(t, 8.0, entry.Quantity)
+	assert.Equal(t, -2.0, entry.QuantityDelta)
+	assert.Equal(t, money.MustParse("-200"), entry.AmountWithoutTaxes)
+	assert.Equal(t, money.MustParse("-24"), entry.VatAmount)
+	assert.Equal(t, money.MustParse("-224"), saved.TotalCurrencyValue)
+}
+
+func TestEsfDocumentCorrection_RequiresAcceptedOriginal(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, entryID := uuid.New(), uuid.New(), uuid.New()
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusSubmitted), withEntries(testEntry(entryID, 10, money.MustParse("100"))))
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateCorrection(context.Background(), orgID, docID, &models.EsfCorrectionRequest{
+		Reason:  "price change",
+		Entries: []models.EsfCorrectionEntryRequest{{OriginalEntryId: entryID, PriceDelta: money.MustParse("-10")}},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidRequest, appErr.Code)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentCorrection_RejectsNegativeResult(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, entryID := uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted), withEntries(testEntry(entryID, 10, money.MustParse("100")))), nil)
+	mockRepo.On("GetDocumentCorrections", mock.Anything, orgID, docID).Return([]entity.EsfDocument{}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateCorrection(context.Background(), orgID, docID, &models.EsfCorrectionRequest{
+		Reason: "invalid",
+		Entries: []models.EsfCorrectionEntryRequest{
+			{OriginalEntryId: entryID, QuantityDelta: -11},
+			{OriginalEntryId: uuid.New(), QuantityDelta: 1},
+		},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 2)
+	assert.Equal(t, "entries[0].quantityDelta", appErr.Fields[0].Field)
+	assert.Equal(t, "entries[1].originalEntryId", appErr.Fields[1].Field)
+}
+
+func TestEsfDocumentCorrection_ChainNetTotals(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, entryID := uuid.New(), uuid.New(), uuid.New()
+	correctionID, pendingID := uuid.New(), uuid.New()
+
+	corrections := []entity.EsfDocument{
+		{
+			ID:                 correctionID,
+			Status:             entity.DocumentStatusAccepted,
+			OriginalDocumentID: &docID,
+			CatalogEntries: []entity.EsfEntries{{
+				OriginalEntryID: &entryID, Quantity: 8, Price: money.MustParse("100"), QuantityDelta: -2,
+				AmountWithoutTaxes: money.MustParse("-200"), VatAmount: money.MustParse("-24"), TotalAmount: money.MustParse("-224"),
+			}},
+		},
+		{
+			ID:                 uuid.New(),
+			Status:             entity.DocumentStatusCancelled,
+			OriginalDocumentID: &docID,
+			CatalogEntries: []entity.EsfEntries{{
+				OriginalEntryID: &entryID, Quantity: 7, Price: money.MustParse("100"), QuantityDelta: -1,
+				AmountWithoutTaxes: money.MustParse("-100"), VatAmount: money.MustParse("-12"), TotalAmount: money.MustParse("-112"),
+			}},
+		},
+		{
+			ID:                 pendingID,
+			Status:             entity.DocumentStatusReady,
+			OriginalDocumentID: &docID,
+			CatalogEntries: []entity.EsfEntries{{
+				OriginalEntryID: &entryID, Quantity: 5, Price: money.MustParse("100"), QuantityDelta: -3,
+				AmountWithoutTaxes: money.MustParse("-300"), VatAmount: money.MustParse("-36"), TotalAmount: money.MustParse("-336"),
+			}},
+		},
+	}
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, correctionID).Return(&corrections[0], nil)
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted), withEntries(testEntry(entryID, 10, money.MustParse("100")))), nil)
+	mockRepo.On("GetDocumentCorrections", mock.Anything, orgID, docID).Return(corrections, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	chain, err := service.GetCorrectionChain(context.Background(), orgID, correctionID)
+	require.NoError(t, err)
+
+	require.NotNil(t, chain.Original.ID)
+	assert.Equal(t, docID, *chain.Original.ID)
+	assert.Len(t, chain.Corrections, 3)
+
+	// Неотправленная корректировка в итоги не входит и выводится отдельно
+	require.Len(t, chain.NetEntries, 1)
+	assert.Equal(t, 8.0, chain.NetEntries[0].Quantity)
+	assert.Equal(t, money.MustParse("800"), chain.NetTotals.AmountWithoutTaxes)
+	assert.Equal(t, money.MustParse("96"), chain.NetTotals.VatAmount)
+	assert.Equal(t, money.MustParse("896"), chain.NetTotals.TotalAmount)
+	assert.Equal(t, []uuid.UUID{pendingID}, chain.PendingCorrectionIds)
+	assert.Equal(t, money.MustParse("-336"), chain.PendingTotals.TotalAmount)
+}
+
+func TestEsfDocumentCorrection_RejectsEntryWithPendingCorrection(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, entryID := uuid.New(), uuid.New(), uuid.New()
+	pendingID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted), withEntries(testEntry(entryID, 10, money.MustParse("100")))), nil)
+	mockRepo.On("GetDocumentCorrections", mock.Anything, orgID, docID).Return([]entity.EsfDocument{{
+		ID:                 pendingID,
+		Status:             entity.DocumentStatusDraft,
+		OriginalDocumentID: &docID,
+		CatalogEntries:     []entity.EsfEntries{{OriginalEntryID: &entryID, Quantity: 1, Price: money.MustParse("100"), QuantityDelta: -9}},
+	}}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateCorrection(context.Background(), orgID, docID, &models.EsfCorrectionRequest{
+		Reason:  "returned goods",
+		Entries: []models.EsfCorrectionEntryRequest{{OriginalEntryId: entryID, QuantityDelta: -9}},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "entries[0].originalEntryId", appErr.Fields[0].Field)
+	assert.Contains(t, appErr.Fields[0].Message, pendingID.String())
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentUpdate_CorrectionIsNotEditable(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID, originalID := uuid.New(), uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{
+		ID:                 docID,
+		Status:             entity.DocumentStatusDraft,
+		OriginalDocumentID: &originalID,
+	}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.UpdateDocument(context.Background(), orgID, &models.EsfEditDocumentRequest{ID: docID})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidRequest, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
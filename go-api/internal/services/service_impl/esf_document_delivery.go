@@ -0,0 +1,239 @@
+package service_impl
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// DeliverDocument доставляет отправленный исходящий документ покупателю, если ИНН покупателя
+// принадлежит другой организации платформы. Повторная доставка возвращает ранее созданную копию.
+func (s *esfDocumentService) DeliverDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfDeliveryResponse, error) {
+	s.logger.Info(ctx, "Delivering document to buyer", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+	if doc.IsIncoming() {
+		return nil, apperror.New(apperror.ErrInvalidRequest, "only outgoing documents can be delivered to the buyer")
+	}
+	if doc.Status != entity.DocumentStatusSubmitted && doc.Status != entity.DocumentStatusAccepted {
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidRequest, "only submitted documents can be delivered to the buyer",
+			"current status: "+doc.Status.String())
+	}
+
+	return s.deliver(ctx, orgID, doc)
+}
+
+// deliver создает входящую копию документа в организации покупателя и связывает ее с документом продавца.
+// Копия сохраняет номер, реквизиты и суммы документа продавца и не редактируется покупателем.
+func (s *esfDocumentService) deliver(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) (*models.EsfDeliveryResponse, error) {
+	if doc.CounterpartyOrgID != nil && doc.CounterpartyDocumentID != nil {
+		return deliveryResponse(doc.ID, *doc.CounterpartyOrgID, *doc.CounterpartyDocumentID, doc.BuyerStatus), nil
+	}
+
+	seller, err := s.organization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if seller.Tin == "" {
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidRequest, "organization TIN is not set",
+			"set the organization TIN to deliver documents to buyers")
+	}
+
+	buyer, err := s.orgRepo.GetByTin(ctx, doc.ContractorTin)
+	if err != nil {
+		return nil, repositoryError("fetching buyer organization", err)
+	}
+	if buyer == nil || buyer.ID == orgID {
+		return nil, apperror.NewWithDetails(apperror.ErrNotFound, "buyer is not an organization on the platform",
+			"contractor TIN: "+doc.ContractorTin)
+	}
+
+	// Копия могла остаться от доставки, которая не успела связать документ продавца,
+	// или быть создана параллельной доставкой: вторая копия не создается
+	delivered, err := s.repo.GetDeliveredCopy(ctx, buyer.ID, orgID, doc.ID)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != apperror.ErrNotFound {
+			return nil, repositoryError("fetching delivered document", err)
+		}
+		if delivered, err = s.createDeliveredCopy(ctx, orgID, doc, seller, buyer.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Ранее созданную копию покупатель мог уже принять или оспорить
+	buyerStatus := entity.BuyerStatusFor(delivered.Status)
+	if err := s.repo.UpdateBuyerStatus(ctx, orgID, doc.ID, repository.BuyerStatusUpdate{
+		Status:                 buyerStatus,
+		CounterpartyOrgID:      &buyer.ID,
+		CounterpartyDocumentID: &delivered.ID,
+	}); err != nil {
+		s.logger.Error(ctx, "Failed to link delivered document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "buyer_doc_id": delivered.ID.String()})
+		return nil, repositoryError("linking delivered document", err)
+	}
+	s.invalidateDocumentCache(ctx, orgID, doc.ID)
+
+	s.logger.Info(ctx, "Document delivered to buyer", logrus.Fields{
+		"org_id":       orgID.String(),
+		"doc_id":       doc.ID.String(),
+		"buyer_org_id": buyer.ID.String(),
+		"buyer_doc_id": delivered.ID.String(),
+	})
+	return deliveryResponse(doc.ID, buyer.ID, delivered.ID, buyerStatus), nil
+}
+
+// createDeliveredCopy создает входящую копию документа продавца в организации покупателя
+func (s *esfDocumentService) createDeliveredCopy(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, seller *entity.EstOrganization, buyerOrgID uuid.UUID) (*entity.EsfDocument, error) {
+	delivered := deliveredCopy(doc, seller)
+	delivered.CounterpartyOrgID = &orgID
+	delivered.CounterpartyDocumentID = &doc.ID
+
+	// Корректировка доставляется как корректировка копии исходного документа у того же покупателя
+	if doc.IsCorrection() {
+		original, err := s.repo.GetDocumentByID(ctx, orgID, *doc.OriginalDocumentID)
+		if err != nil {
+			return nil, repositoryError("fetching original document", err)
+		}
+		if original.CounterpartyOrgID == nil || *original.CounterpartyOrgID != buyerOrgID || original.CounterpartyDocumentID == nil {
+			return nil, apperror.NewWithDetails(apperror.ErrInvalidRequest, "correction cannot be delivered",
+				"original document was not delivered to the buyer")
+		}
+		delivered.OriginalDocumentID = original.CounterpartyDocumentID
+	}
+
+	// Продавец связывается с контрагентом покупателя, если он есть в справочнике покупателя
+	if s.contractors != nil {
+		if contractor, err := s.contractors.GetContractorByTin(ctx, buyerOrgID, seller.Tin); err == nil {
+			delivered.ContractorID = &contractor.ID
+		} else if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != apperror.ErrNotFound {
+			return nil, repositoryError("fetching buyer contractor", err)
+		}
+	}
+
+	if err := s.repo.CreateDocument(ctx, buyerOrgID, delivered); err != nil {
+		// Уникальный индекс не дает создать вторую копию; если копию создала параллельная доставка, используется она
+		if existing, getErr := s.repo.GetDeliveredCopy(ctx, buyerOrgID, orgID, doc.ID); getErr == nil {
+			return existing, nil
+		}
+		s.logger.Error(ctx, "Failed to create delivered document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "buyer_org_id": buyerOrgID.String()})
+		return nil, repositoryError("creating delivered document", err)
+	}
+	return delivered, nil
+}
+
+// deliveredCopy возвращает входящую копию документа продавца для организации покупателя.
+// Идентификаторы налоговой службы не копируются: аннулирование копии не должно аннулировать документ продавца.
+func deliveredCopy(doc *entity.EsfDocument, seller *entity.EstOrganization) *entity.EsfDocument {
+	delivered := *doc
+	delivered.ID = uuid.New()
+	delivered.CreatedAt, delivered.UpdatedAt = time.Time{}, time.Time{}
+	delivered.NumberYear, delivered.NumberSeq = 0, 0
+	delivered.Status = entity.DocumentStatusDraft
+	delivered.StatusChangedAt = nil
+	delivered.StatusReason = ""
+	delivered.ExternalResponseID = ""
+	delivered.ExternalDocumentUUID = ""
+	delivered.SubmittedAt = nil
+	delivered.Direction = entity.DocumentDirectionIncoming
+	delivered.SupplierTin = seller.Tin
+	delivered.SupplierName = seller.Name
+	delivered.BuyerStatus = ""
+	delivered.BuyerStatusReason = ""
+	delivered.BuyerStatusChangedAt = nil
+	delivered.OriginalDocumentID = nil
+	delivered.ContractorID = nil
+
+	delivered.CatalogEntries = make([]entity.EsfEntries, len(doc.CatalogEntries))
+	for i, ent := range doc.CatalogEntries {
+		ent.ID = uuid.Nil
+		ent.DocumentID = delivered.ID
+		ent.OriginalEntryID = nil
+		delivered.CatalogEntries[i] = ent
+	}
+	return &delivered
+}
+
+func deliveryResponse(id, buyerOrgID, buyerDocID uuid.UUID, status entity.BuyerStatus) *models.EsfDeliveryResponse {
+	return &models.EsfDeliveryResponse{
+		DocumentUuid:      id.String(),
+		BuyerOrgId:        buyerOrgID,
+		BuyerDocumentUuid: buyerDocID.String(),
+		BuyerStatus:       status.String(),
+	}
+}
+
+// autoDeliver доставляет отправленный документ покупателю на платформе. Доставка не влияет
+// на результат отправки: ошибки записываются в журнал, документ можно доставить повторно вручную.
+func (s *esfDocumentService) autoDeliver(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) string {
+	if s.orgRepo == nil || doc.IsIncoming() {
+		return ""
+	}
+
+	resp, err := s.deliver(ctx, orgID, doc)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok && (appErr.Code == apperror.ErrNotFound || appErr.Code == apperror.ErrInvalidRequest) {
+			s.logger.Debug(ctx, "Document is not delivered to buyer", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "reason": appErr.Message})
+			return ""
+		}
+		s.logger.Warn(ctx, "Failed to deliver document to buyer", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "error": err.Error()})
+		return ""
+	}
+	return resp.BuyerDocumentUuid
+}
+
+// reflectBuyerStatus передает продавцу решение покупателя по доставленной копии документа
+func (s *esfDocumentService) reflectBuyerStatus(ctx context.Context, doc *entity.EsfDocument, status entity.DocumentStatus, reason string) {
+	if doc.CounterpartyOrgID == nil || doc.CounterpartyDocumentID == nil {
+		return
+	}
+	sellerOrgID, sellerDocID := *doc.CounterpartyOrgID, *doc.CounterpartyDocumentID
+
+	buyerStatus := entity.BuyerStatusFor(status)
+	if err := s.repo.UpdateBuyerStatus(ctx, sellerOrgID, sellerDocID, repository.BuyerStatusUpdate{Status: buyerStatus, Reason: reason}); err != nil {
+		s.logger.Warn(ctx, "Failed to update buyer status of supplier document", logrus.Fields{
+			"org_id": sellerOrgID.String(),
+			"doc_id": sellerDocID.String(),
+			"error":  err.Error(),
+		})
+		return
+	}
+	s.invalidateDocumentCache(ctx, sellerOrgID, sellerDocID)
+}
+
+// withdrawDelivery аннулирует копию документа у покупателя при аннулировании документа продавцом
+func (s *esfDocumentService) withdrawDelivery(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, reason string) {
+	if doc.CounterpartyOrgID == nil || doc.CounterpartyDocumentID == nil {
+		return
+	}
+	buyerOrgID, buyerDocID := *doc.CounterpartyOrgID, *doc.CounterpartyDocumentID
+	fields := logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "buyer_org_id": buyerOrgID.String(), "buyer_doc_id": buyerDocID.String()}
+
+	delivered, err := s.repo.GetDocumentByID(ctx, buyerOrgID, buyerDocID)
+	if err != nil {
+		s.logger.Warn(ctx, "Failed to fetch delivered document", fields)
+		return
+	}
+	if delivered.Status != entity.DocumentStatusCancelled {
+		if err := s.repo.UpdateDocumentStatus(ctx, buyerOrgID, buyerDocID, delivered.Status, entity.DocumentStatusCancelled, reason); err != nil {
+			s.logger.Warn(ctx, "Failed to cancel delivered document", fields)
+			return
+		}
+		s.invalidateDocumentCache(ctx, buyerOrgID, buyerDocID)
+	}
+
+	if err := s.repo.UpdateBuyerStatus(ctx, orgID, doc.ID, repository.BuyerStatusUpdate{Status: entity.BuyerStatusCancelled, Reason: reason}); err != nil {
+		s.logger.Warn(ctx, "Failed to update buyer status of document", fields)
+		return
+	}
+	s.invalidateDocumentCache(ctx, orgID, doc.ID)
+	s.logger.Info(ctx, "Delivered document cancelled", fields)
+}
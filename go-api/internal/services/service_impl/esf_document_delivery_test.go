@@ -0,0 +1,241 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/gateway"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// deliveryOrganizations возвращает продавца и покупателя - организации платформы
+func deliveryOrganizations() (*entity.EstOrganization, *entity.EstOrganization) {
+	seller := &entity.EstOrganization{ID: uuid.New(), Name: "ОсОО Ала-Тоо", Tin: "01503201910012", Token: "seller-token"}
+	buyer := &entity.EstOrganization{ID: uuid.New(), Name: "ОсОО Ысык-Көл", Tin: "01234567890123"}
+	return seller, buyer
+}
+
+// deliveredFrom делает документ копией документа продавца в организации покупателя
+func deliveredFrom(seller *entity.EstOrganization, sellerDocID uuid.UUID) documentOption {
+	return func(doc *entity.EsfDocument) {
+		doc.Direction = entity.DocumentDirectionIncoming
+		doc.SupplierTin = seller.Tin
+		doc.CounterpartyOrgID = &seller.ID
+		doc.CounterpartyDocumentID = &sellerDocID
+	}
+}
+
+// ========== Document Delivery Tests ==========
+
+func TestEsfDocumentDelivery_SubmitDeliversToBuyer(t *testing.T) {
+	server := gateway.NewMockEsfServer()
+	defer server.Close()
+
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	docID := uuid.New()
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusSigned))
+	mockRepo.On("GetDocumentByID", mock.Anything, seller.ID, docID).Return(doc, nil)
+	signed := mockSignedDocument(t, mockRepo, seller.ID, doc)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, seller.ID, docID, entity.DocumentStatusSigned, entity.DocumentStatusSubmitting, "").Return(nil)
+	mockRepo.On("MarkDocumentSubmitted", mock.Anything, seller.ID, docID, entity.DocumentStatusSubmitting, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("GetDeliveredCopy", mock.Anything, buyer.ID, seller.ID, docID).Return(nil, apperror.NotFoundError("delivered document"))
+	contractors := new(MockContractorRepository)
+	contractors.On("GetContractorByTin", mock.Anything, buyer.ID, seller.Tin).Return(nil, apperror.NotFoundError("contractor"))
+
+	var delivered *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, buyer.ID, mock.Anything).Run(func(args mock.Arguments) {
+		delivered = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+	mockRepo.On("UpdateBuyerStatus", mock.Anything, seller.ID, docID, mock.MatchedBy(func(u repository.BuyerStatusUpdate) bool {
+		return u.Status == entity.BuyerStatusDelivered && *u.CounterpartyOrgID == buyer.ID && *u.CounterpartyDocumentID == delivered.ID
+	})).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	signed.wire(service)
+	service.SetOrganizationRepository(&stubOrganizationRepository{org: seller, others: []*entity.EstOrganization{buyer}})
+	service.SetContractorRepository(contractors)
+	service.SetSubmissionGateway(gateway.NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New()))
+
+	resp, err := service.ChangeDocumentStatus(context.Background(), seller.ID, docID, entity.DocumentStatusSubmitted, "")
+	require.NoError(t, err)
+
+	require.NotNil(t, delivered)
+	assert.Equal(t, delivered.ID.String(), resp.DeliveredDocumentUuid)
+	assert.Equal(t, entity.DocumentDirectionIncoming, delivered.Direction)
+	assert.Equal(t, entity.DocumentStatusDraft, delivered.Status)
+	assert.Equal(t, "INV-2026-000007", delivered.Number)
+	assert.Equal(t, seller.Tin, delivered.SupplierTin)
+	assert.Equal(t, seller.Name, delivered.SupplierName)
+	assert.Empty(t, delivered.ExternalDocumentUUID)
+	assert.True(t, delivered.IsDelivered())
+	require.Len(t, delivered.CatalogEntries, 1)
+	assert.Equal(t, uuid.Nil, delivered.CatalogEntries[0].ID)
+	assert.Equal(t, "120.00", delivered.CatalogEntries[0].VatAmount.String())
+	// Документ продавца не изменяется при создании копии
+	assert.Equal(t, entity.DocumentDirection(""), doc.Direction)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentDelivery_ManualDelivery(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	draftID, deliveredID := uuid.New(), uuid.New()
+	buyerDocID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, seller.ID, draftID).Return(newTestDocument(draftID, withStatus(entity.DocumentStatusDraft)), nil)
+	delivered := newTestDocument(deliveredID, withStatus(entity.DocumentStatusAccepted))
+	delivered.CounterpartyOrgID, delivered.CounterpartyDocumentID = &buyer.ID, &buyerDocID
+	delivered.BuyerStatus = entity.BuyerStatusAccepted
+	mockRepo.On("GetDocumentByID", mock.Anything, seller.ID, deliveredID).Return(delivered, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetOrganizationRepository(&stubOrganizationRepository{org: seller, others: []*entity.EstOrganization{buyer}})
+
+	_, err := service.DeliverDocument(context.Background(), seller.ID, draftID)
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidRequest, appErr.Code)
+
+	// Повторная доставка возвращает ранее созданную копию
+	resp, err := service.DeliverDocument(context.Background(), seller.ID, deliveredID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.EsfDeliveryResponse{
+		DocumentUuid:      deliveredID.String(),
+		BuyerOrgId:        buyer.ID,
+		BuyerDocumentUuid: buyerDocID.String(),
+		BuyerStatus:       "accepted",
+	}, resp)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestEsfDocumentDelivery_ExistingCopyIsLinked tests that a copy left by an interrupted delivery
+// is linked to the seller document instead of creating a second copy
+func TestEsfDocumentDelivery_ExistingCopyIsLinked(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	docID, buyerDocID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, seller.ID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusSubmitted)), nil)
+	existing := newTestDocument(buyerDocID, withStatus(entity.DocumentStatusAccepted), deliveredFrom(seller, docID))
+	mockRepo.On("GetDeliveredCopy", mock.Anything, buyer.ID, seller.ID, docID).Return(existing, nil)
+	mockRepo.On("UpdateBuyerStatus", mock.Anything, seller.ID, docID, repository.BuyerStatusUpdate{
+		Status:                 entity.BuyerStatusAccepted,
+		CounterpartyOrgID:      &buyer.ID,
+		CounterpartyDocumentID: &buyerDocID,
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetOrganizationRepository(&stubOrganizationRepository{org: seller, others: []*entity.EstOrganization{buyer}})
+
+	resp, err := service.DeliverDocument(context.Background(), seller.ID, docID)
+	require.NoError(t, err)
+	assert.Equal(t, buyerDocID.String(), resp.BuyerDocumentUuid)
+	assert.Equal(t, "accepted", resp.BuyerStatus)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestEsfDocumentDelivery_ConcurrentDeliveryUsesExistingCopy tests that a delivery losing the race
+// for the unique copy links the copy created by the other delivery
+func TestEsfDocumentDelivery_ConcurrentDeliveryUsesExistingCopy(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	docID, buyerDocID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, seller.ID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusSubmitted)), nil)
+	mockRepo.On("GetDeliveredCopy", mock.Anything, buyer.ID, seller.ID, docID).Return(nil, apperror.NotFoundError("delivered document")).Once()
+	contractors := new(MockContractorRepository)
+	contractors.On("GetContractorByTin", mock.Anything, buyer.ID, seller.Tin).Return(nil, apperror.NotFoundError("contractor"))
+	mockRepo.On("CreateDocument", mock.Anything, buyer.ID, mock.Anything).Return(apperror.DatabaseError("creating document", assert.AnError))
+	existing := newTestDocument(buyerDocID, deliveredFrom(seller, docID))
+	mockRepo.On("GetDeliveredCopy", mock.Anything, buyer.ID, seller.ID, docID).Return(existing, nil).Once()
+	mockRepo.On("UpdateBuyerStatus", mock.Anything, seller.ID, docID, mock.MatchedBy(func(u repository.BuyerStatusUpdate) bool {
+		return u.Status == entity.BuyerStatusDelivered && *u.CounterpartyDocumentID == buyerDocID
+	})).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetOrganizationRepository(&stubOrganizationRepository{org: seller, others: []*entity.EstOrganization{buyer}})
+	service.SetContractorRepository(contractors)
+
+	resp, err := service.DeliverDocument(context.Background(), seller.ID, docID)
+	require.NoError(t, err)
+	assert.Equal(t, buyerDocID.String(), resp.BuyerDocumentUuid)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentDelivery_BuyerDisputeIsReflected(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	docID, sellerDocID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, buyer.ID, docID).Return(newTestDocument(docID, deliveredFrom(seller, sellerDocID)), nil)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, buyer.ID, docID, entity.DocumentStatusDraft, entity.DocumentStatusRejected, "Цена не согласована").Return(nil)
+	mockRepo.On("UpdateBuyerStatus", mock.Anything, seller.ID, sellerDocID, repository.BuyerStatusUpdate{
+		Status: entity.BuyerStatusDisputed,
+		Reason: "Цена не согласована",
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ChangeDocumentStatus(context.Background(), buyer.ID, docID, entity.DocumentStatusRejected, " Цена не согласована ")
+	require.NoError(t, err)
+
+	assert.Equal(t, "rejected", result.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentDelivery_DeliveredCopyIsReadOnly(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	docID := uuid.New()
+	mockRepo.On("GetDocumentByID", mock.Anything, buyer.ID, docID).Return(newTestDocument(docID, deliveredFrom(seller, uuid.New())), nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.UpdateDocument(context.Background(), buyer.ID, &models.EsfEditDocumentRequest{
+		ID:                       docID,
+		EsfCreateDocumentRequest: models.EsfCreateDocumentRequest{Direction: "incoming", Number: "INV-2026-000008"},
+	})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+
+	err = service.DeleteDocument(context.Background(), buyer.ID, docID)
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+
+	mockRepo.AssertNotCalled(t, "UpdateDocument", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "DeleteDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentDelivery_SellerCancelWithdrawsCopy(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	seller, buyer := deliveryOrganizations()
+	docID, buyerDocID := uuid.New(), uuid.New()
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))
+	doc.CounterpartyOrgID, doc.CounterpartyDocumentID = &buyer.ID, &buyerDocID
+	mockRepo.On("GetDocumentByID", mock.Anything, seller.ID, docID).Return(doc, nil)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, seller.ID, docID, entity.DocumentStatusAccepted, entity.DocumentStatusCancelled, "Ошибка в сумме").Return(nil)
+	mockRepo.On("GetDocumentByID", mock.Anything, buyer.ID, buyerDocID).Return(newTestDocument(buyerDocID, deliveredFrom(seller, docID)), nil)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, buyer.ID, buyerDocID, entity.DocumentStatusDraft, entity.DocumentStatusCancelled, "Ошибка в сумме").Return(nil)
+	mockRepo.On("UpdateBuyerStatus", mock.Anything, seller.ID, docID, repository.BuyerStatusUpdate{
+		Status: entity.BuyerStatusCancelled,
+		Reason: "Ошибка в сумме",
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.ChangeDocumentStatus(context.Background(), seller.ID, docID, entity.DocumentStatusCancelled, "Ошибка в сумме")
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,157 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Document Direction Tests ==========
+
+func TestDocumentDirectionTransitions(t *testing.T) {
+	incoming := entity.DocumentDirectionIncoming
+	assert.True(t, incoming.CanTransition(entity.DocumentStatusDraft, entity.DocumentStatusAccepted))
+	assert.True(t, incoming.CanTransition(entity.DocumentStatusDraft, entity.DocumentStatusRejected))
+	assert.True(t, incoming.CanTransition(entity.DocumentStatusRejected, entity.DocumentStatusDraft))
+	assert.False(t, incoming.CanTransition(entity.DocumentStatusDraft, entity.DocumentStatusReady))
+	assert.False(t, incoming.CanTransition(entity.DocumentStatusDraft, entity.DocumentStatusSubmitted))
+
+	// Пустое направление - исходящий документ с обычным жизненным циклом
+	assert.True(t, entity.DocumentDirection("").CanTransition(entity.DocumentStatusDraft, entity.DocumentStatusReady))
+	assert.False(t, entity.DocumentDirectionOutgoing.CanTransition(entity.DocumentStatusDraft, entity.DocumentStatusAccepted))
+	assert.False(t, entity.DocumentDirection("inbound").IsValid())
+}
+
+func TestEsfDocumentDirection_IncomingIsAccepted(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{
+		ID: docID, Status: entity.DocumentStatusDraft, Direction: entity.DocumentDirectionIncoming,
+	}, nil)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusDraft, entity.DocumentStatusAccepted, "").Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusAccepted, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "accepted", result.Status)
+	assert.Equal(t, []string{"cancelled"}, result.AllowedTransitions)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentDirection_IncomingCannotBeSubmitted(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{
+		ID: docID, Status: entity.DocumentStatusDraft, Direction: entity.DocumentDirectionIncoming,
+	}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusReady, "")
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidStatusTransition, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateDocumentStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentDirection_CreateValidates(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+
+	fieldsOf := func(err error) []string {
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		fields := make([]string, len(appErr.Fields))
+		for i, f := range appErr.Fields {
+			fields[i] = f.Field
+		}
+		return fields
+	}
+
+	_, err := service.CreateDocument(context.Background(), uuid.New(), &models.EsfCreateDocumentRequest{Direction: "incoming", IsResident: true})
+	assert.Equal(t, []string{"number", "supplierTin"}, fieldsOf(err))
+
+	_, err = service.CreateDocument(context.Background(), uuid.New(), &models.EsfCreateDocumentRequest{SupplierTin: "01503201910012"})
+	assert.Equal(t, []string{"supplierTin"}, fieldsOf(err))
+
+	_, err = service.CreateDocument(context.Background(), uuid.New(), &models.EsfCreateDocumentRequest{Direction: "inbound"})
+	assert.Equal(t, []string{"direction"}, fieldsOf(err))
+
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentDirection_IncomingFromContractor(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	contractors := new(MockContractorRepository)
+	orgID, contractorID := uuid.New(), uuid.New()
+	contractors.On("GetContractorByID", mock.Anything, orgID, contractorID).Return(newContractor(contractorID), nil)
+
+	var created *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetContractorRepository(contractors)
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		Direction:      "incoming",
+		Number:         " А-0017 ",
+		ContractorId:   &contractorID,
+		TaxRateVATCode: "1",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, created)
+	assert.Equal(t, entity.DocumentDirectionIncoming, created.Direction)
+	assert.Equal(t, "А-0017", created.Number)
+	assert.Equal(t, "01503201910012", created.SupplierTin)
+	assert.Equal(t, "ОсОО Ала-Тоо", created.SupplierName)
+	assert.Equal(t, "1030120000000002", created.SupplierBankAccount)
+	assert.Empty(t, created.ContractorTin)
+	assert.Empty(t, created.ContractorBankAccount)
+}
+
+func TestEsfDocumentDirection_UpdateKeepsDirection(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.UpdateDocument(context.Background(), orgID, &models.EsfEditDocumentRequest{
+		ID: docID,
+		EsfCreateDocumentRequest: models.EsfCreateDocumentRequest{
+			Direction: "incoming", Number: "А-0017", SupplierTin: "01503201910012", IsResident: true,
+		},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "direction", appErr.Fields[0].Field)
+	mockRepo.AssertNotCalled(t, "UpdateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentDirection_ListRejectsUnknownDirection(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+
+	_, _, err := service.GetAllDocumentsPaginated(context.Background(), uuid.New(),
+		pagination.PaginationParams{Page: 1, PageSize: 20}, pagination.DocumentFilterParams{Direction: "inbound"})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+	mockRepo.AssertNotCalled(t, "GetAllDocumentsPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,348 @@
+package service_impl
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/pdf"
+	"github.com/rusgainew/tunduck-app/pkg/spell"
+	"github.com/sirupsen/logrus"
+)
+
+// maxPrintTemplateSize ограничивает размер загружаемой печатной формы
+const maxPrintTemplateSize = 256 * 1024
+
+//go:embed templates/esf_invoice.html
+var defaultInvoiceTemplate string
+
+// printFuncs функции, доступные в печатных формах
+var printFuncs = template.FuncMap{
+	"money":         formatMoney,
+	"quantity":      formatQuantity,
+	"date":          formatDate,
+	"amountInWords": formatAmountInWords,
+	"inc":           func(i int) int { return i + 1 },
+}
+
+// SetPrintRenderer подключает рендерер PDF для печатных форм.
+// Реквизиты организации выводятся из справочника SetOrganizationRepository.
+func (s *esfDocumentService) SetPrintRenderer(renderer *pdf.Renderer) {
+	s.printRenderer = renderer
+}
+
+// RenderDocumentPDF формирует PDF документа по печатной форме организации
+func (s *esfDocumentService) RenderDocumentPDF(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]byte, error) {
+	s.logger.Info(ctx, "Rendering document PDF", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+
+	if s.printRenderer == nil {
+		return nil, apperror.New(apperror.ErrConfigError, "PDF rendering is not configured")
+	}
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+
+	org, err := s.organization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, err := s.printTemplate(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := parsePrintTemplate(content)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to parse print template", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, apperror.New(apperror.ErrInternal, "invalid print template").WithError(err)
+	}
+
+	out, err := s.renderPrintTemplate(tmpl, s.printData(doc, org))
+	if err != nil {
+		s.logger.Error(ctx, "Failed to render document PDF", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, apperror.New(apperror.ErrInternal, "failed to render document").WithError(err)
+	}
+
+	s.logger.Info(ctx, "Document PDF rendered successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "size": len(out)})
+	return out, nil
+}
+
+// GetPrintTemplate возвращает печатную форму организации или форму по умолчанию
+func (s *esfDocumentService) GetPrintTemplate(ctx context.Context, orgID uuid.UUID) (*models.EsfPrintTemplateModel, error) {
+	content, tpl, err := s.printTemplate(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.EsfPrintTemplateModel{
+		Name:      entity.PrintTemplateInvoice,
+		Content:   content,
+		IsDefault: tpl == nil,
+	}
+	if tpl != nil {
+		result.UpdatedAt = &tpl.UpdatedAt
+		result.UpdatedBy = tpl.UpdatedBy
+	}
+	return result, nil
+}
+
+// SavePrintTemplate проверяет и сохраняет печатную форму организации.
+// Форма проверяется пробным формированием PDF на примере документа.
+func (s *esfDocumentService) SavePrintTemplate(ctx context.Context, orgID uuid.UUID, content string) (*models.EsfPrintTemplateModel, error) {
+	s.logger.Info(ctx, "Saving print template", logrus.Fields{"org_id": orgID.String(), "size": len(content)})
+
+	if strings.TrimSpace(content) == "" {
+		return nil, apperror.ValidationError("print template content is required")
+	}
+	if len(content) > maxPrintTemplateSize {
+		return nil, apperror.ValidationError(fmt.Sprintf("print template must not exceed %d bytes", maxPrintTemplateSize))
+	}
+
+	tmpl, err := parsePrintTemplate(content)
+	if err != nil {
+		return nil, apperror.NewWithDetails(apperror.ErrValidation, "invalid print template", err.Error())
+	}
+	if _, err := s.renderPrintTemplate(tmpl, samplePrintData()); err != nil {
+		return nil, apperror.NewWithDetails(apperror.ErrValidation, "print template failed to render", err.Error())
+	}
+
+	tpl := &entity.EsfPrintTemplate{
+		Name:    entity.PrintTemplateInvoice,
+		Content: content,
+	}
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		tpl.UpdatedBy = fmt.Sprint(username)
+	}
+
+	if err := s.repo.SavePrintTemplate(ctx, orgID, tpl); err != nil {
+		s.logger.Error(ctx, "Failed to save print template", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, repositoryError("saving print template", err)
+	}
+
+	s.logger.Info(ctx, "Print template saved successfully", logrus.Fields{"org_id": orgID.String()})
+	return &models.EsfPrintTemplateModel{
+		Name:      tpl.Name,
+		Content:   tpl.Content,
+		UpdatedAt: &tpl.UpdatedAt,
+		UpdatedBy: tpl.UpdatedBy,
+	}, nil
+}
+
+// DeletePrintTemplate удаляет печатную форму организации, возвращая форму по умолчанию
+func (s *esfDocumentService) DeletePrintTemplate(ctx context.Context, orgID uuid.UUID) error {
+	s.logger.Info(ctx, "Resetting print template", logrus.Fields{"org_id": orgID.String()})
+
+	if err := s.repo.DeletePrintTemplate(ctx, orgID, entity.PrintTemplateInvoice); err != nil {
+		s.logger.Error(ctx, "Failed to delete print template", err, logrus.Fields{"org_id": orgID.String()})
+		return repositoryError("deleting print template", err)
+	}
+	return nil
+}
+
+// printTemplate возвращает текст печатной формы и саму форму организации (nil для формы по умолчанию)
+func (s *esfDocumentService) printTemplate(ctx context.Context, orgID uuid.UUID) (string, *entity.EsfPrintTemplate, error) {
+	tpl, err := s.repo.GetPrintTemplate(ctx, orgID, entity.PrintTemplateInvoice)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+			return defaultInvoiceTemplate, nil, nil
+		}
+		s.logger.Error(ctx, "Failed to fetch print template", err, logrus.Fields{"org_id": orgID.String()})
+		return "", nil, repositoryError("fetching print template", err)
+	}
+	return tpl.Content, tpl, nil
+}
+
+// renderPrintTemplate выполняет шаблон и формирует PDF
+func (s *esfDocumentService) renderPrintTemplate(tmpl *template.Template, data *models.EsfPrintData) ([]byte, error) {
+	var source bytes.Buffer
+	if err := tmpl.Execute(&source, data); err != nil {
+		return nil, err
+	}
+
+	// Без рендерера проверяется только выполнение шаблона
+	if s.printRenderer == nil {
+		return source.Bytes(), nil
+	}
+
+	var out bytes.Buffer
+	if err := s.printRenderer.Render(&out, source.String()); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// printData собирает данные печатной формы документа
+func (s *esfDocumentService) printData(doc *entity.EsfDocument, org *entity.EstOrganization) *models.EsfPrintData {
+	data := &models.EsfPrintData{
+		Document: s.toModel(doc),
+		Organization: models.EsfPrintOrganization{
+			ID:          org.ID.String(),
+			Name:        org.Name,
+			Description: org.Description,
+		},
+		PrintedAt: time.Now(),
+	}
+
+	for _, entry := range doc.CatalogEntries {
+		data.Totals.AmountWithoutTaxes += entry.AmountWithoutTaxes
+		data.Totals.VatAmount += entry.VatAmount
+		data.Totals.SalesTaxAmount += entry.SalesTaxAmount
+		data.Totals.TotalAmount += entry.TotalAmount
+	}
+	// Суммы позиций указаны в сомах, в том числе у документов в иностранной валюте
+	data.TotalInWords = spell.Amount(data.Totals.TotalAmount, currency.Base)
+
+	return data
+}
+
+// samplePrintData пример документа для проверки загружаемой печатной формы
+func samplePrintData() *models.EsfPrintData {
+	id := uuid.New()
+	now := time.Now()
+	rate := 1.0
+	return &models.EsfPrintData{
+		Document: models.EsfCreateDocumentRequest{
+			ID:                  &id,
+			Number:              "INV-2026-000001",
+			Status:              entity.DocumentStatusDraft.String(),
+			OwnedCrmReceiptCode: "0001",
+			DeliveryDate:        &now,
+			ContractorTin:       "01234567890123",
+			CurrencyCode:        "KGS",
+			CurrencyRate:        &rate,
+			TaxRateVATCode:      "1",
+			CatalogEntries: []models.EsfEntriesModel{{
+				ID:                     1,
+				UnitClassificationCode: "796",
+				SalesTaxCode:           "001",
+				Quantity:               1,
+				Price:                  money.MustParse("100"),
+				AmountWithoutTaxes:     money.MustParse("100"),
+				VatAmount:              money.MustParse("12"),
+				TotalAmount:            money.MustParse("112"),
+			}},
+		},
+		Organization: models.EsfPrintOrganization{ID: uuid.New().String(), Name: "Sample organization"},
+		Totals: models.EsfPrintTotals{
+			AmountWithoutTaxes: money.MustParse("100"),
+			VatAmount:          money.MustParse("12"),
+			TotalAmount:        money.MustParse("112"),
+		},
+		TotalInWords: spell.Amount(money.MustParse("112"), "KGS"),
+		PrintedAt:    now,
+	}
+}
+
+func parsePrintTemplate(content string) (*template.Template, error) {
+	return template.New(entity.PrintTemplateInvoice).Funcs(printFuncs).Parse(content)
+}
+
+// formatMoney форматирует сумму с разделителем разрядов: 1 120,00
+func formatMoney(value interface{}) string {
+	v, ok := printAmount(value)
+	if !ok {
+		return ""
+	}
+
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	cents := v.Abs().Minor()
+	integer := strconv.FormatInt(cents/100, 10)
+
+	var b strings.Builder
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return fmt.Sprintf("%s%s,%02d", sign, b.String(), cents%100)
+}
+
+// formatQuantity форматирует количество без лишних нулей: 1,5
+func formatQuantity(value interface{}) string {
+	v, ok := printNumber(value)
+	if !ok {
+		return ""
+	}
+	return strings.Replace(strconv.FormatFloat(v, 'f', -1, 64), ".", ",", 1)
+}
+
+// formatDate форматирует дату как 02.01.2006; пустая дата выводится пустой строкой
+func formatDate(value interface{}) string {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		t = *v
+	default:
+		return ""
+	}
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("02.01.2006")
+}
+
+// formatAmountInWords выводит сумму прописью
+func formatAmountInWords(value interface{}, currencyCode string) string {
+	v, ok := printAmount(value)
+	if !ok {
+		return ""
+	}
+	return spell.Amount(v, currencyCode)
+}
+
+// printAmount приводит денежное значение шаблона к money.Amount; числа округляются до тыйынов
+func printAmount(value interface{}) (money.Amount, bool) {
+	switch v := value.(type) {
+	case money.Amount:
+		return v, true
+	case *money.Amount:
+		if v == nil {
+			return 0, false
+		}
+		return *v, true
+	default:
+		f, ok := printNumber(value)
+		return money.FromFloat(f), ok
+	}
+}
+
+func printNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case *float64:
+		if v == nil {
+			return 0, false
+		}
+		return *v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,95 @@
+package service_impl
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/pdf"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newPrintRenderer(t *testing.T) *pdf.Renderer {
+	t.Helper()
+	renderer, err := pdf.NewRenderer("")
+	if err != nil {
+		t.Skipf("pdf fonts are not installed: %v", err)
+	}
+	return renderer
+}
+
+// ========== Print Template Tests ==========
+
+func TestEsfDocumentPrint_RendersDefaultTemplate(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))
+	doc.CurrencyCode = "KGS"
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	mockRepo.On("GetPrintTemplate", mock.Anything, orgID, entity.PrintTemplateInvoice).Return(nil, apperror.NotFoundError("print template"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetOrganizationRepository(&stubOrganizationRepository{
+		org: &entity.EstOrganization{ID: orgID, Name: "ОсОО «Тундук»"},
+	})
+	service.SetPrintRenderer(newPrintRenderer(t))
+
+	content, err := service.RenderDocumentPDF(context.Background(), orgID, docID)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(content, []byte("%PDF-")))
+}
+
+func TestEsfDocumentPrint_RequiresRenderer(t *testing.T) {
+	service := NewEsfDocumentService(new(MockDocumentRepository), nil, logrus.New())
+
+	_, err := service.RenderDocumentPDF(context.Background(), uuid.New(), uuid.New())
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrConfigError, appErr.Code)
+}
+
+func TestEsfDocumentPrint_SaveValidatesTemplate(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+
+	for _, content := range []string{
+		"",
+		"<p>{{.Document.ContractorTin</p>",
+		"<p>{{.Document.UnknownField}}</p>",
+	} {
+		_, err := service.SavePrintTemplate(context.Background(), orgID, content)
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok, "content %q", content)
+		assert.Equal(t, apperror.ErrValidation, appErr.Code)
+	}
+	mockRepo.AssertNotCalled(t, "SavePrintTemplate", mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.On("SavePrintTemplate", mock.Anything, orgID, mock.MatchedBy(func(tpl *entity.EsfPrintTemplate) bool {
+		return tpl.Name == entity.PrintTemplateInvoice
+	})).Return(nil)
+
+	result, err := service.SavePrintTemplate(context.Background(), orgID, `<h1>{{.Organization.Name}}</h1><p>{{money .Totals.TotalAmount}}</p>`)
+	require.NoError(t, err)
+	assert.False(t, result.IsDefault)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentPrint_Formatting(t *testing.T) {
+	total := 1234567.891
+	assert.Equal(t, "1 234 567,89", formatMoney(total))
+	assert.Equal(t, "1 234 567,89", formatMoney(&total))
+	assert.Equal(t, "-224,00", formatMoney(-224.0))
+	assert.Equal(t, "0,50", formatMoney(0.5))
+	assert.Equal(t, "", formatMoney((*float64)(nil)))
+	assert.Equal(t, "1,5", formatQuantity(1.5))
+	assert.Equal(t, "10", formatQuantity(10.0))
+}
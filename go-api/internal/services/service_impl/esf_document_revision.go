@@ -0,0 +1,108 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/diff"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// GetDocumentRevisions возвращает список ревизий документа без снимков
+func (s *esfDocumentService) GetDocumentRevisions(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]models.EsfDocumentRevisionModel, error) {
+	s.logger.Info(ctx, "Fetching document revisions", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+
+	revisions, err := s.repo.GetDocumentRevisions(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document revisions", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document revisions", err)
+	}
+	if len(revisions) == 0 {
+		return nil, apperror.New(apperror.ErrDocumentNotFound, "document has no revisions")
+	}
+
+	result := make([]models.EsfDocumentRevisionModel, len(revisions))
+	for i := range revisions {
+		result[i] = revisionToModel(&revisions[i])
+	}
+	return result, nil
+}
+
+// GetDocumentRevision возвращает ревизию документа со снимком
+func (s *esfDocumentService) GetDocumentRevision(ctx context.Context, orgID uuid.UUID, id uuid.UUID, revision int) (*models.EsfDocumentRevisionModel, error) {
+	s.logger.Info(ctx, "Fetching document revision", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "revision": revision})
+
+	rev, doc, err := s.loadRevision(ctx, orgID, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := revisionToModel(rev)
+	result.Document = doc
+	return &result, nil
+}
+
+// DiffDocumentRevisions возвращает пополевую разницу между ревизиями from и to
+func (s *esfDocumentService) DiffDocumentRevisions(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from, to int) (*models.EsfDocumentRevisionDiffResponse, error) {
+	s.logger.Info(ctx, "Comparing document revisions", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "from": from, "to": to})
+
+	_, fromDoc, err := s.loadRevision(ctx, orgID, id, from)
+	if err != nil {
+		return nil, err
+	}
+	_, toDoc, err := s.loadRevision(ctx, orgID, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := diff.Compare(fromDoc, toDoc)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to compare document revisions", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, apperror.New(apperror.ErrInternal, "failed to compare revisions").WithError(err)
+	}
+
+	return &models.EsfDocumentRevisionDiffResponse{
+		DocumentUuid: id.String(),
+		FromRevision: from,
+		ToRevision:   to,
+		Changes:      changes,
+	}, nil
+}
+
+// loadRevision загружает ревизию и восстанавливает документ из снимка
+func (s *esfDocumentService) loadRevision(ctx context.Context, orgID uuid.UUID, id uuid.UUID, revision int) (*entity.EsfDocumentRevision, *models.EsfCreateDocumentRequest, error) {
+	if revision < 1 {
+		return nil, nil, apperror.ValidationError(fmt.Sprintf("invalid revision number: %d", revision))
+	}
+
+	rev, err := s.repo.GetDocumentRevision(ctx, orgID, id, revision)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document revision", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "revision": revision})
+		return nil, nil, repositoryError("fetching document revision", err)
+	}
+
+	var snapshot entity.EsfDocument
+	if err := json.Unmarshal([]byte(rev.Snapshot), &snapshot); err != nil {
+		s.logger.Error(ctx, "Failed to decode revision snapshot", err, logrus.Fields{"doc_id": id.String(), "revision": revision})
+		return nil, nil, apperror.New(apperror.ErrInternal, "failed to decode revision snapshot").WithError(err)
+	}
+
+	doc := s.toModel(&snapshot)
+	return rev, &doc, nil
+}
+
+// revisionToModel преобразует ревизию в модель ответа без снимка
+func revisionToModel(rev *entity.EsfDocumentRevision) models.EsfDocumentRevisionModel {
+	return models.EsfDocumentRevisionModel{
+		Revision:   rev.Revision,
+		Action:     string(rev.Action),
+		AuthorId:   rev.AuthorID,
+		AuthorName: rev.AuthorName,
+		CreatedAt:  rev.CreatedAt,
+	}
+}
@@ -0,0 +1,92 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newRevision(t *testing.T, docID uuid.UUID, revision int, action entity.RevisionAction, doc entity.EsfDocument) *entity.EsfDocumentRevision {
+	snapshot, err := json.Marshal(&doc)
+	require.NoError(t, err)
+	return &entity.EsfDocumentRevision{
+		ID:         uuid.New(),
+		DocumentID: docID,
+		Revision:   revision,
+		Action:     action,
+		AuthorID:   "user-1",
+		Snapshot:   string(snapshot),
+	}
+}
+
+// ========== Document Revision Tests ==========
+
+func TestEsfDocumentRevision_GetRestoresSnapshot(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+
+	rev := newRevision(t, docID, 1, entity.RevisionActionCreate, entity.EsfDocument{
+		ID:            docID,
+		Status:        entity.DocumentStatusDraft,
+		ContractorTin: "01234567890123",
+	})
+	mockRepo.On("GetDocumentRevision", mock.Anything, orgID, docID, 1).Return(rev, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.GetDocumentRevision(context.Background(), orgID, docID, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Revision)
+	assert.Equal(t, "create", result.Action)
+	assert.Equal(t, "user-1", result.AuthorId)
+	require.NotNil(t, result.Document)
+	assert.Equal(t, "01234567890123", result.Document.ContractorTin)
+}
+
+func TestEsfDocumentRevision_Diff(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+
+	before := entity.EsfDocument{
+		ID:             docID,
+		Status:         entity.DocumentStatusDraft,
+		ContractorTin:  "01234567890123",
+		CatalogEntries: []entity.EsfEntries{{ID: uuid.New(), Quantity: 1, Price: money.MustParse("100")}},
+	}
+	after := before
+	after.Comment = "updated"
+	after.CatalogEntries = []entity.EsfEntries{{ID: uuid.New(), Quantity: 2, Price: money.MustParse("100")}}
+
+	mockRepo.On("GetDocumentRevision", mock.Anything, orgID, docID, 1).Return(newRevision(t, docID, 1, entity.RevisionActionCreate, before), nil)
+	mockRepo.On("GetDocumentRevision", mock.Anything, orgID, docID, 2).Return(newRevision(t, docID, 2, entity.RevisionActionUpdate, after), nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.DiffDocumentRevisions(context.Background(), orgID, docID, 1, 2)
+	require.NoError(t, err)
+
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, "catalogEntries[0].quantity", result.Changes[0].Field)
+	assert.Equal(t, 1.0, result.Changes[0].From)
+	assert.Equal(t, 2.0, result.Changes[0].To)
+	assert.Equal(t, "comment", result.Changes[1].Field)
+}
+
+func TestEsfDocumentRevision_InvalidNumber(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.GetDocumentRevision(context.Background(), uuid.New(), uuid.New(), 0)
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+}
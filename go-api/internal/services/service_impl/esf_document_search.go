@@ -0,0 +1,71 @@
+package service_impl
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/search"
+	"github.com/sirupsen/logrus"
+)
+
+// maxSearchQueryLength ограничивает длину поисковой строки
+const maxSearchQueryLength = 200
+
+// SearchDocuments выполняет полнотекстовый поиск документов и подсвечивает совпадения
+func (s *esfDocumentService) SearchDocuments(ctx context.Context, orgID uuid.UUID, query string, params pagination.PaginationParams) ([]models.EsfDocumentSearchResult, int64, error) {
+	query = strings.TrimSpace(query)
+	s.logger.Info(ctx, "Searching documents", logrus.Fields{"org_id": orgID.String(), "query": query, "page": params.Page})
+
+	if query == "" {
+		return nil, 0, apperror.ValidationError("search query is required")
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, 0, apperror.ValidationError("search query is too long")
+	}
+
+	hits, total, err := s.repo.SearchDocuments(ctx, orgID, query, params)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to search documents", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, 0, repositoryError("searching documents", err)
+	}
+
+	terms := search.Terms(query)
+	results := make([]models.EsfDocumentSearchResult, len(hits))
+	for i := range hits {
+		results[i] = models.EsfDocumentSearchResult{
+			Document:   s.toModel(&hits[i].Document),
+			Rank:       hits[i].Rank,
+			Highlights: documentHighlights(&hits[i].Document, terms),
+		}
+	}
+
+	s.logger.Debug(ctx, "Document search completed", logrus.Fields{"org_id": orgID.String(), "count": len(results), "total": total})
+	return results, total, nil
+}
+
+// documentHighlights подсвечивает совпадения в искомых полях документа
+func documentHighlights(doc *entity.EsfDocument, terms []string) map[string]string {
+	highlights := make(map[string]string)
+	add := func(field, value string) {
+		if marked, ok := search.Highlight(value, terms); ok {
+			highlights[field] = marked
+		}
+	}
+
+	add("contractorTin", doc.ContractorTin)
+	add("foreignName", doc.ForeignName)
+	add("supplyContractNumber", doc.SupplyContractNumber)
+	add("comment", doc.Comment)
+	add("personalAccountNumber", doc.PersonalAccountNumber)
+	for i, entry := range doc.CatalogEntries {
+		add(entryFieldName(i, "salesTaxCode"), entry.SalesTaxCode)
+	}
+
+	return highlights
+}
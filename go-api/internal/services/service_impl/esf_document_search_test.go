@@ -0,0 +1,59 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Document Search Tests ==========
+
+func TestEsfDocumentSearch_HighlightsMatches(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	params := pagination.PaginationParams{Page: 1, PageSize: 10}
+
+	mockRepo.On("SearchDocuments", mock.Anything, orgID, "ромашка 0123", params).Return([]repository.DocumentSearchHit{{
+		Document: entity.EsfDocument{
+			ID:            uuid.New(),
+			ContractorTin: "01234567890123",
+			ForeignName:   "ОсОО Ромашка",
+			Comment:       "без совпадений",
+			CatalogEntries: []entity.EsfEntries{
+				{SalesTaxCode: "A-0123"},
+			},
+		},
+		Rank: 0.75,
+	}}, int64(1), nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	results, total, err := service.SearchDocuments(context.Background(), orgID, "  ромашка 0123 ", params)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), total)
+	require.Len(t, results, 1)
+	assert.Equal(t, 0.75, results[0].Rank)
+	assert.Equal(t, map[string]string{
+		"contractorTin":                  "<mark>0123</mark>456789<mark>0123</mark>",
+		"foreignName":                    "ОсОО <mark>Ромашка</mark>",
+		"catalogEntries[0].salesTaxCode": "A-<mark>0123</mark>",
+	}, results[0].Highlights)
+}
+
+func TestEsfDocumentSearch_EmptyQuery(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, _, err := service.SearchDocuments(context.Background(), uuid.New(), "   ", pagination.PaginationParams{Page: 1, PageSize: 10})
+
+	require.Error(t, err)
+	mockRepo.AssertNotCalled(t, "SearchDocuments", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
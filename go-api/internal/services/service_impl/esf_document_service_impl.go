@@ -2,42 +2,77 @@ package service_impl
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/gateway"
 	models "github.com/rusgainew/tunduck-app/internal/models"
 	"github.com/rusgainew/tunduck-app/internal/repository"
 	"github.com/rusgainew/tunduck-app/internal/services"
 	"github.com/rusgainew/tunduck-app/pkg/apperror"
 	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
 	"github.com/rusgainew/tunduck-app/pkg/entity"
 	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/money"
 	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/pdf"
+	"github.com/rusgainew/tunduck-app/pkg/tax"
+	"github.com/rusgainew/tunduck-app/pkg/tin"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type esfDocumentService struct {
-	repo         repository.EsfDocumentRepository
-	db           *gorm.DB
-	logger       *logger.Logger
-	cacheManager cache.CacheManager
+	repo          repository.EsfDocumentRepository
+	db            *gorm.DB
+	logger        *logger.Logger
+	cacheManager  cache.CacheManager
+	taxCalculator *tax.Calculator
+	gateway       gateway.EsfGateway
+	orgRepo       repository.EsfOrganizationRepository
+	contractors   repository.EsfContractorRepository
+	signingKeys   repository.EsfSigningKeyRepository
+	policies      repository.EsfApprovalPolicyRepository
+	printRenderer *pdf.Renderer
+	references    services.EsfReferenceService
+	currencyRates services.CurrencyRateService
 }
 
-// NewEsfDocumentService создает новый document service с обязательными зависимостями
+// NewEsfDocumentService создает новый document service.
+// Документы хранятся в БД организаций через repo, поэтому db может быть nil.
 func NewEsfDocumentService(repo repository.EsfDocumentRepository, db *gorm.DB, log *logrus.Logger) services.EsfDocumentService {
-	if db == nil {
-		log.Fatal("database connection is required for EsfDocumentService")
-		return nil
-	}
 	return &esfDocumentService{
-		repo:         repo,
-		db:           db,
-		logger:       logger.New(log),
-		cacheManager: nil,
+		repo:          repo,
+		db:            db,
+		logger:        logger.New(log),
+		cacheManager:  nil,
+		taxCalculator: tax.NewCalculator(tax.DefaultRateTable()),
 	}
 }
 
+// SetSubmissionGateway подключает шлюз налоговой службы.
+// Без шлюза документы переводятся в статус submitted без фактической отправки.
+// Токен организации для отправки берется из справочника SetOrganizationRepository.
+func (s *esfDocumentService) SetSubmissionGateway(gw gateway.EsfGateway) {
+	s.gateway = gw
+}
+
+// SetReferenceService подключает проверку кодов документов по справочникам.
+// Без сервиса справочников коды не проверяются.
+func (s *esfDocumentService) SetReferenceService(references services.EsfReferenceService) {
+	s.references = references
+}
+
+// SetCurrencyRates подключает курсы валют: курс документа в валюте заполняется по дате поставки.
+// Без сервиса курсов курс документа в валюте указывается вручную.
+func (s *esfDocumentService) SetCurrencyRates(rates services.CurrencyRateService) {
+	s.currencyRates = rates
+}
+
 // SetCacheManager injects the cache manager into the service
 func (s *esfDocumentService) SetCacheManager(cacheManager cache.CacheManager) {
 	s.cacheManager = cacheManager
@@ -49,7 +84,7 @@ func (s *esfDocumentService) GetAllDocuments(ctx context.Context, orgID uuid.UUI
 	docs, err := s.repo.GetAllDocuments(ctx, orgID)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to fetch documents", err, logrus.Fields{"org_id": orgID.String()})
-		return nil, apperror.DatabaseError("fetching documents", err)
+		return nil, repositoryError("fetching documents", err)
 	}
 
 	s.logger.Debug(ctx, "Documents fetched successfully", logrus.Fields{"org_id": orgID.String(), "count": len(docs)})
@@ -66,18 +101,21 @@ func (s *esfDocumentService) GetDocumentByID(ctx context.Context, orgID uuid.UUI
 
 	// Try to get from cache first
 	if s.cacheManager != nil {
-		cacheKey := "doc:id:" + id.String()
+		cacheKey := documentCacheKey(orgID, id)
+		// Кеш возвращает значения, разобранные из JSON, поэтому документ хранится строкой
 		if cached, _ := s.cacheManager.Document().Get(ctx, cacheKey); cached != nil {
-			s.logger.Debug(ctx, "Document found in cache", logrus.Fields{"doc_id": id.String()})
-			doc := cached.(*models.EsfCreateDocumentRequest)
-			return doc, nil
+			var doc models.EsfCreateDocumentRequest
+			if raw, ok := cached.(string); ok && json.Unmarshal([]byte(raw), &doc) == nil {
+				s.logger.Debug(ctx, "Document found in cache", logrus.Fields{"doc_id": id.String()})
+				return &doc, nil
+			}
 		}
 	}
 
 	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
-		return nil, apperror.DatabaseError("fetching document", err)
+		return nil, repositoryError("fetching document", err)
 	}
 
 	if doc == nil {
@@ -91,8 +129,9 @@ func (s *esfDocumentService) GetDocumentByID(ctx context.Context, orgID uuid.UUI
 
 	// Cache the document (30 minutes TTL)
 	if s.cacheManager != nil {
-		cacheKey := "doc:id:" + id.String()
-		_ = s.cacheManager.Document().Set(ctx, cacheKey, &model, 30*time.Minute)
+		if data, err := json.Marshal(&model); err == nil {
+			_ = s.cacheManager.Document().Set(ctx, documentCacheKey(orgID, id), string(data), 30*time.Minute)
+		}
 	}
 
 	return &model, nil
@@ -103,10 +142,16 @@ func (s *esfDocumentService) CreateDocument(ctx context.Context, orgID uuid.UUID
 
 	doc := s.toEntity(req)
 	doc.ID = uuid.New()
+	doc.Status = entity.DocumentStatusDraft
+
+	if err := s.validateDocument(ctx, orgID, &doc); err != nil {
+		s.logger.Warn(ctx, "Document validation failed", logrus.Fields{"org_id": orgID.String(), "error": err.Error()})
+		return nil, err
+	}
 
 	if err := s.repo.CreateDocument(ctx, orgID, &doc); err != nil {
 		s.logger.Error(ctx, "Failed to create document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
-		return nil, apperror.DatabaseError("creating document", err)
+		return nil, repositoryError("creating document", err)
 	}
 
 	s.logger.Info(ctx, "Document created successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
@@ -120,17 +165,19 @@ func (s *esfDocumentService) CreateDocument(ctx context.Context, orgID uuid.UUID
 func (s *esfDocumentService) UpdateDocument(ctx context.Context, orgID uuid.UUID, req *models.EsfEditDocumentRequest) error {
 	s.logger.Info(ctx, "Updating document", logrus.Fields{"org_id": orgID.String(), "doc_id": req.ID.String()})
 
-	doc := s.toEntity(&req.EsfCreateDocumentRequest)
-	doc.ID = req.ID
+	doc, err := s.prepareDocumentUpdate(ctx, orgID, req)
+	if err != nil {
+		return err
+	}
 
-	if err := s.repo.UpdateDocument(ctx, orgID, &doc); err != nil {
+	if err := s.repo.UpdateDocument(ctx, orgID, doc); err != nil {
 		s.logger.Error(ctx, "Failed to update document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": req.ID.String()})
-		return apperror.DatabaseError("updating document", err)
+		return repositoryError("updating document", err)
 	}
 
 	// Invalidate cache
 	if s.cacheManager != nil {
-		cacheKey := "doc:id:" + req.ID.String()
+		cacheKey := documentCacheKey(orgID, req.ID)
 		_ = s.cacheManager.Document().Delete(ctx, cacheKey)
 	}
 
@@ -138,17 +185,60 @@ func (s *esfDocumentService) UpdateDocument(ctx context.Context, orgID uuid.UUID
 	return nil
 }
 
+// prepareDocumentUpdate проверяет, что документ можно изменить, и рассчитывает налоги новой версии
+func (s *esfDocumentService) prepareDocumentUpdate(ctx context.Context, orgID uuid.UUID, req *models.EsfEditDocumentRequest) (*entity.EsfDocument, error) {
+	existing, err := s.ensureEditable(ctx, orgID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsCorrection() {
+		return nil, apperror.New(apperror.ErrInvalidRequest, "correction documents cannot be edited, delete the draft and create a new correction")
+	}
+
+	doc := s.toEntity(&req.EsfCreateDocumentRequest)
+	doc.ID = req.ID
+	direction := existing.Direction
+	if direction == "" {
+		direction = entity.DocumentDirectionOutgoing
+	}
+	if doc.Direction == "" {
+		doc.Direction = direction
+	}
+	if doc.Direction != direction {
+		return nil, apperror.FieldValidationError("document direction cannot be changed", []apperror.FieldError{
+			{Field: "direction", Message: "direction cannot be changed after the document is created"},
+		})
+	}
+
+	if err := s.validateDocument(ctx, orgID, &doc); err != nil {
+		s.logger.Warn(ctx, "Document validation failed", logrus.Fields{"org_id": orgID.String(), "doc_id": req.ID.String(), "error": err.Error()})
+		return nil, err
+	}
+	return &doc, nil
+}
+
 func (s *esfDocumentService) DeleteDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
 	s.logger.Info(ctx, "Deleting document", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
 
+	doc, err := s.ensureEditable(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	// Номер уже выдан документу, возвращенному в черновик: удаление оставило бы пропуск в нумерации
+	if doc.NumberSeq > 0 {
+		s.logger.Warn(ctx, "Attempt to delete numbered document", logrus.Fields{"doc_id": id.String(), "number": doc.Number})
+		return apperror.NewWithDetails(apperror.ErrDocumentLocked, "numbered document cannot be deleted",
+			"cancel the document instead")
+	}
+
 	if err := s.repo.DeleteDocument(ctx, orgID, id); err != nil {
 		s.logger.Error(ctx, "Failed to delete document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
-		return apperror.DatabaseError("deleting document", err)
+		return repositoryError("deleting document", err)
 	}
 
 	// Invalidate cache
 	if s.cacheManager != nil {
-		cacheKey := "doc:id:" + id.String()
+		cacheKey := documentCacheKey(orgID, id)
 		_ = s.cacheManager.Document().Delete(ctx, cacheKey)
 	}
 
@@ -156,6 +246,512 @@ func (s *esfDocumentService) DeleteDocument(ctx context.Context, orgID uuid.UUID
 	return nil
 }
 
+// ChangeDocumentStatus переводит документ в новый статус жизненного цикла
+func (s *esfDocumentService) ChangeDocumentStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, status entity.DocumentStatus, reason string) (*models.EsfDocumentStatusResponse, error) {
+	s.logger.Info(ctx, "Changing document status", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "status": status})
+
+	if !status.IsValid() {
+		return nil, apperror.ValidationError("unknown document status: " + status.String())
+	}
+	if status == entity.DocumentStatusSigned {
+		return nil, apperror.New(apperror.ErrInvalidRequest, "document is signed by submitting a signature made with a registered key")
+	}
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+
+	current := doc.Status
+	if current == "" {
+		current = entity.DocumentStatusDraft
+	}
+
+	// Входящий документ поставщика принимается к учету или отклоняется без подписания и отправки
+	if !doc.Direction.CanTransition(current, status) {
+		s.logger.Warn(ctx, "Invalid document status transition", logrus.Fields{"doc_id": id.String(), "from": current, "to": status})
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidStatusTransition, "invalid document status transition",
+			fmt.Sprintf("cannot change status from %s to %s", current, status))
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" && (status == entity.DocumentStatusRejected || status == entity.DocumentStatusCancelled) {
+		return nil, apperror.ValidationError("reason is required to " + status.String() + " a document")
+	}
+
+	// В налоговую службу отправляется только согласованный документ, не изменявшийся после подписания
+	if status == entity.DocumentStatusSubmitted {
+		if err := s.ensureSigned(ctx, orgID, doc); err != nil {
+			return nil, err
+		}
+		if err := s.ensureApproved(ctx, orgID, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var submission *models.EsfCreateDocumentResponse
+	if s.gateway != nil {
+		switch {
+		case status == entity.DocumentStatusSubmitted:
+			if submission, err = s.submitDocument(ctx, orgID, doc); err != nil {
+				return nil, err
+			}
+		case status == entity.DocumentStatusCancelled && doc.ExternalDocumentUUID != "":
+			if err := s.cancelSubmittedDocument(ctx, orgID, doc, reason); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if submission != nil {
+		err = s.repo.MarkDocumentSubmitted(ctx, orgID, id, entity.DocumentStatusSubmitting, submission.ResponseId, submission.DocumentUuid)
+		if err != nil {
+			// Документ уже зарегистрирован в налоговой службе и остается в статусе submitting:
+			// повторная отправка невозможна, идентификаторы нужны для ручной сверки
+			s.logger.Error(ctx, "Document registered in ESF but not marked as submitted", err, logrus.Fields{
+				"org_id":        orgID.String(),
+				"doc_id":        id.String(),
+				"response_id":   submission.ResponseId,
+				"external_uuid": submission.DocumentUuid,
+			})
+		}
+	} else {
+		err = s.repo.UpdateDocumentStatus(ctx, orgID, id, doc.Status, status, reason)
+	}
+	if err != nil {
+		s.logger.Error(ctx, "Failed to update document status", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("updating document status", err)
+	}
+
+	s.invalidateDocumentCache(ctx, orgID, id)
+
+	// Решения по документам между организациями платформы передаются контрагенту
+	var deliveredID string
+	switch {
+	case doc.IsDelivered():
+		s.reflectBuyerStatus(ctx, doc, status, reason)
+	case status == entity.DocumentStatusSubmitted:
+		doc.Status = status
+		deliveredID = s.autoDeliver(ctx, orgID, doc)
+	case status == entity.DocumentStatusCancelled && !doc.IsIncoming():
+		s.withdrawDelivery(ctx, orgID, doc, reason)
+	}
+
+	allowed := doc.Direction.AllowedTransitions(status)
+	allowedNames := make([]string, len(allowed))
+	for i, st := range allowed {
+		allowedNames[i] = st.String()
+	}
+
+	s.logger.Info(ctx, "Document status changed successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "from": current, "to": status})
+
+	resp := &models.EsfDocumentStatusResponse{
+		DocumentUuid:          id.String(),
+		PreviousStatus:        current.String(),
+		Status:                status.String(),
+		AllowedTransitions:    allowedNames,
+		ExternalDocumentUuid:  doc.ExternalDocumentUUID,
+		DeliveredDocumentUuid: deliveredID,
+	}
+	if submission != nil {
+		resp.ExternalResponseId = submission.ResponseId
+		resp.ExternalDocumentUuid = submission.DocumentUuid
+	}
+	return resp, nil
+}
+
+// submitDocument отправляет документ в налоговую службу.
+// Ранее отправленный документ (например, после отклонения) редактируется, а не создается заново.
+func (s *esfDocumentService) submitDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) (*models.EsfCreateDocumentResponse, error) {
+	token, err := s.organizationToken(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Документ занимается до обращения к налоговой службе: параллельная или повторная
+	// отправка получит ErrInvalidStatusTransition и не зарегистрирует документ дважды
+	if err := s.repo.UpdateDocumentStatus(ctx, orgID, doc.ID, doc.Status, entity.DocumentStatusSubmitting, ""); err != nil {
+		s.logger.Warn(ctx, "Failed to claim document for submission", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "error": err.Error()})
+		return nil, repositoryError("claiming document for submission", err)
+	}
+
+	resp, err := s.sendToGateway(ctx, token, doc)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to submit document to ESF", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+		// Отправка не состоялась: документ возвращается в прежний статус, чтобы его можно было отправить снова
+		if rbErr := s.repo.UpdateDocumentStatus(ctx, orgID, doc.ID, entity.DocumentStatusSubmitting, doc.Status, ""); rbErr != nil {
+			s.logger.Error(ctx, "Failed to release document after rejected submission", rbErr, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+		}
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Document submitted to ESF", logrus.Fields{
+		"org_id":        orgID.String(),
+		"doc_id":        doc.ID.String(),
+		"response_id":   resp.ResponseId,
+		"external_uuid": resp.DocumentUuid,
+	})
+	return resp, nil
+}
+
+// sendToGateway регистрирует документ в налоговой службе или изменяет ранее зарегистрированный
+func (s *esfDocumentService) sendToGateway(ctx context.Context, token string, doc *entity.EsfDocument) (*models.EsfCreateDocumentResponse, error) {
+	payload := s.gatewayPayload(doc)
+
+	var (
+		resp *models.EsfCreateDocumentResponse
+		err  error
+	)
+	if doc.ExternalDocumentUUID != "" {
+		resp, err = s.gateway.EditInvoice(ctx, token, doc.ExternalDocumentUUID, payload)
+	} else {
+		resp, err = s.gateway.CreateInvoice(ctx, token, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.DocumentUuid == "" {
+		resp.DocumentUuid = doc.ExternalDocumentUUID
+	}
+	return resp, nil
+}
+
+// gatewayPayload формирует запрос к налоговой службе без внутренних полей документа
+func (s *esfDocumentService) gatewayPayload(doc *entity.EsfDocument) *models.EsfCreateDocumentRequest {
+	payload := s.toModel(doc)
+	payload.ID = nil
+	payload.Status = ""
+	payload.ExternalDocumentUuid = ""
+	payload.OriginalDocumentId = nil
+	payload.Direction = ""
+	payload.CounterpartyOrgId = nil
+	payload.CounterpartyDocumentId = nil
+	payload.BuyerStatus = ""
+	payload.BuyerStatusReason = ""
+	for i := range payload.CatalogEntries {
+		payload.CatalogEntries[i].OriginalEntryId = nil
+	}
+	return &payload
+}
+
+// cancelSubmittedDocument аннулирует документ в налоговой службе
+func (s *esfDocumentService) cancelSubmittedDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, reason string) error {
+	token, err := s.organizationToken(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.gateway.CancelInvoice(ctx, token, doc.ExternalDocumentUUID, reason); err != nil {
+		s.logger.Error(ctx, "Failed to cancel document in ESF", err, logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+		return err
+	}
+
+	s.logger.Info(ctx, "Document cancelled in ESF", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String()})
+	return nil
+}
+
+// organizationToken возвращает токен организации для доступа к API налоговой службы
+func (s *esfDocumentService) organizationToken(ctx context.Context, orgID uuid.UUID) (string, error) {
+	org, err := s.organization(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+	if org.Token == "" {
+		return "", apperror.New(apperror.ErrInvalidRequest, "organization has no ESF token configured")
+	}
+
+	return org.Token, nil
+}
+
+// organization возвращает организацию из основной БД
+func (s *esfDocumentService) organization(ctx context.Context, orgID uuid.UUID) (*entity.EstOrganization, error) {
+	if s.orgRepo == nil {
+		return nil, apperror.New(apperror.ErrInternal, "organization repository is not configured")
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, orgID.String())
+	if err != nil {
+		return nil, repositoryError("fetching organization", err)
+	}
+	if org == nil {
+		return nil, apperror.NotFoundError("organization")
+	}
+
+	return org, nil
+}
+
+// ValidateDocumentDraft проверяет документ без сохранения: реквизиты контрагента, коды по справочникам и налоги.
+// Курс валюты не заполняется - шаблоны повторяющихся документов получают его на дату выпуска.
+func (s *esfDocumentService) ValidateDocumentDraft(ctx context.Context, orgID uuid.UUID, req *models.EsfCreateDocumentRequest) error {
+	doc := s.toEntity(req)
+	if err := s.applyContractor(ctx, orgID, &doc); err != nil {
+		return err
+	}
+	if s.references != nil {
+		if err := s.references.ValidateDocument(ctx, &doc); err != nil {
+			return err
+		}
+	}
+	if err := s.applyTaxes(&doc); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateDocument заполняет реквизиты покупателя из справочника контрагентов,
+// проверяет коды документа по справочникам, заполняет курс валюты и рассчитывает налоги
+func (s *esfDocumentService) validateDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) *apperror.AppError {
+	if err := s.applyContractor(ctx, orgID, doc); err != nil {
+		return err
+	}
+	if err := validateDirection(doc); err != nil {
+		return err
+	}
+	if s.references != nil {
+		if err := s.references.ValidateDocument(ctx, doc); err != nil {
+			return err
+		}
+	}
+	if err := s.applyCurrencyRate(ctx, doc); err != nil {
+		return err
+	}
+	return s.applyTaxes(doc)
+}
+
+// validateDirection проверяет направление документа и реквизиты поставщика.
+// Без направления документ считается исходящим; у входящего обязательны номер и ИНН поставщика,
+// исходящий документ выписывает сама организация, поэтому поставщик в нем не указывается.
+func validateDirection(doc *entity.EsfDocument) *apperror.AppError {
+	if doc.Direction == "" {
+		doc.Direction = entity.DocumentDirectionOutgoing
+	}
+	doc.SupplierTin = strings.TrimSpace(doc.SupplierTin)
+	doc.SupplierName = strings.TrimSpace(doc.SupplierName)
+
+	var fields []apperror.FieldError
+	addField := func(field, message string) {
+		fields = append(fields, apperror.FieldError{Field: field, Message: message})
+	}
+
+	switch {
+	case !doc.Direction.IsValid():
+		addField("direction", "direction must be outgoing or incoming")
+	case doc.IsIncoming():
+		if doc.Number == "" {
+			addField("number", "supplier document number is required for incoming documents")
+		} else if len(doc.Number) > 50 {
+			addField("number", "number must not exceed 50 characters")
+		}
+		switch {
+		case doc.SupplierTin == "":
+			addField("supplierTin", "supplier TIN is required for incoming documents")
+		case doc.IsResident:
+			if err := tin.Validate(doc.SupplierTin); err != nil {
+				addField("supplierTin", err.Error())
+			}
+		case !foreignTinPattern.MatchString(doc.SupplierTin):
+			addField("supplierTin", "TIN of a non-resident must contain up to 14 letters or digits")
+		}
+		if len([]rune(doc.SupplierName)) > 255 {
+			addField("supplierName", "name must not exceed 255 characters")
+		}
+	default:
+		if doc.SupplierTin != "" {
+			addField("supplierTin", "supplier is specified only for incoming documents")
+		}
+		if doc.SupplierName != "" {
+			addField("supplierName", "supplier is specified only for incoming documents")
+		}
+	}
+
+	if len(fields) > 0 {
+		return apperror.FieldValidationError("invalid document direction", fields)
+	}
+	return nil
+}
+
+// applyCurrencyRate заполняет курс документа в иностранной валюте официальным курсом на дату поставки.
+// Курс, указанный клиентом, не заменяется.
+func (s *esfDocumentService) applyCurrencyRate(ctx context.Context, doc *entity.EsfDocument) *apperror.AppError {
+	if currency.IsBase(doc.CurrencyCode) || doc.CurrencyRate > 0 {
+		return nil
+	}
+
+	fieldError := func(field, message string) *apperror.AppError {
+		return apperror.FieldValidationError("currency rate is not available", []apperror.FieldError{{Field: field, Message: message}})
+	}
+	if s.currencyRates == nil {
+		return fieldError("currencyRate", "currency rate is required for documents in foreign currency")
+	}
+	if doc.DeliveryDate.IsZero() {
+		return fieldError("deliveryDate", "delivery date is required to determine the currency rate")
+	}
+
+	rate, err := s.currencyRates.RateOn(ctx, doc.CurrencyCode, doc.DeliveryDate)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+			return fieldError("currencyRate", appErr.Details)
+		}
+		return repositoryError("fetching currency rate", err)
+	}
+	doc.CurrencyRate = rate.UnitRate
+	return nil
+}
+
+// currencyTotal пересчитывает итог документа в сомах в валюту документа
+func currencyTotal(doc *entity.EsfDocument, amount money.Amount) (money.Amount, error) {
+	if currency.IsBase(doc.CurrencyCode) || doc.CurrencyRate <= 0 {
+		return amount, nil
+	}
+	return currency.ToCurrency(amount, doc.CurrencyRate)
+}
+
+// applyTaxes рассчитывает суммы позиций и итоги документа.
+// Суммы позиций указываются в сомах; итоги в валюте пересчитываются по курсу документа.
+// Незаполненные суммы заполняются расчетными, а переданные клиентом сверяются с расчетом.
+func (s *esfDocumentService) applyTaxes(doc *entity.EsfDocument) *apperror.AppError {
+	// Итоги документа без позиций нулевые: ненулевые итоги клиента не с чем сверить
+	if len(doc.CatalogEntries) == 0 {
+		var fields []apperror.FieldError
+		if !doc.TotalCurrencyValueWithoutTaxes.IsZero() {
+			fields = append(fields, apperror.FieldError{Field: "totalCurrencyValueWithoutTaxes", Message: "document without entries must have zero total"})
+		}
+		if !doc.TotalCurrencyValue.IsZero() {
+			fields = append(fields, apperror.FieldError{Field: "totalCurrencyValue", Message: "document without entries must have zero total"})
+		}
+		if len(fields) > 0 {
+			return apperror.FieldValidationError("document amounts do not match calculated values", fields)
+		}
+		return nil
+	}
+
+	input := tax.DocumentInput{
+		VATCode:           doc.TaxRateVATCode,
+		PriceWithoutTaxes: doc.IsPriceWithoutTaxes,
+		Lines:             make([]tax.LineInput, len(doc.CatalogEntries)),
+	}
+	for i, entry := range doc.CatalogEntries {
+		input.Lines[i] = tax.LineInput{
+			Quantity:     entry.Quantity,
+			Price:        entry.Price,
+			SalesTaxCode: entry.SalesTaxCode,
+		}
+	}
+
+	result, err := s.taxCalculator.CalculateDocument(input)
+	if err != nil {
+		var fields []apperror.FieldError
+		if calcErr, ok := err.(*tax.CalculationError); ok {
+			for _, issue := range calcErr.Issues {
+				fields = append(fields, apperror.FieldError{Field: entryFieldName(issue.Line, issue.Field), Message: issue.Message})
+			}
+		}
+		return apperror.FieldValidationError("invalid document amounts", fields).WithError(err)
+	}
+
+	var fields []apperror.FieldError
+	reconcile := func(field string, provided *money.Amount, calculated money.Amount) {
+		if provided.IsZero() {
+			*provided = calculated
+			return
+		}
+		if *provided != calculated {
+			fields = append(fields, apperror.FieldError{
+				Field:   field,
+				Message: fmt.Sprintf("expected %s, got %s", calculated, *provided),
+			})
+		}
+	}
+
+	for i := range doc.CatalogEntries {
+		entry := &doc.CatalogEntries[i]
+		line := result.Lines[i]
+		reconcile(entryFieldName(i, "amountWithoutTaxes"), &entry.AmountWithoutTaxes, line.AmountWithoutTaxes)
+		reconcile(entryFieldName(i, "vatAmount"), &entry.VatAmount, line.VatAmount)
+		reconcile(entryFieldName(i, "salesTaxAmount"), &entry.SalesTaxAmount, line.SalesTaxAmount)
+		reconcile(entryFieldName(i, "totalAmount"), &entry.TotalAmount, line.TotalAmount)
+	}
+
+	reconcileCurrency := func(field string, provided *money.Amount, amount money.Amount) {
+		calculated, err := currencyTotal(doc, amount)
+		if err != nil {
+			fields = append(fields, apperror.FieldError{Field: field, Message: "amount in document currency is out of range"})
+			return
+		}
+		reconcile(field, provided, calculated)
+	}
+
+	reconcileCurrency("totalCurrencyValueWithoutTaxes", &doc.TotalCurrencyValueWithoutTaxes, result.AmountWithoutTaxes)
+	reconcileCurrency("totalCurrencyValue", &doc.TotalCurrencyValue, result.TotalAmount)
+
+	if len(fields) > 0 {
+		return apperror.FieldValidationError("document amounts do not match calculated values", fields)
+	}
+
+	return nil
+}
+
+// entryFieldName формирует имя поля в запросе для ошибок валидации
+func entryFieldName(line int, field string) string {
+	if line < 0 {
+		return field
+	}
+	return fmt.Sprintf("catalogEntries[%d].%s", line, field)
+}
+
+// ensureEditable проверяет, что документ существует и находится в статусе черновика, и возвращает его
+func (s *esfDocumentService) ensureEditable(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfDocument, error) {
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+
+	if !doc.Status.IsEditable() {
+		s.logger.Warn(ctx, "Attempt to modify locked document", logrus.Fields{"doc_id": id.String(), "status": doc.Status})
+		return nil, apperror.NewWithDetails(apperror.ErrDocumentLocked, "document can only be modified in draft status",
+			"current status: "+doc.Status.String())
+	}
+	// Копию, доставленную продавцом, покупатель только принимает или оспаривает
+	if doc.IsDelivered() {
+		s.logger.Warn(ctx, "Attempt to modify delivered document", logrus.Fields{"doc_id": id.String()})
+		return nil, apperror.NewWithDetails(apperror.ErrDocumentLocked, "document delivered by the supplier is read-only",
+			"accept or reject the document instead")
+	}
+
+	return doc, nil
+}
+
+// documentCacheKey возвращает ключ документа в кеше. Документы организаций
+// хранятся в разных базах, поэтому ключ включает организацию: иначе документ
+// из кеша можно было бы получить, указав чужую организацию.
+func documentCacheKey(orgID, id uuid.UUID) string {
+	return "doc:" + orgID.String() + ":" + id.String()
+}
+
+// invalidateDocumentCache удаляет документ из кеша
+func (s *esfDocumentService) invalidateDocumentCache(ctx context.Context, orgID, id uuid.UUID) {
+	deleteCachedDocument(ctx, s.cacheManager, orgID, id)
+}
+
+// deleteCachedDocument удаляет документ из кеша; используется и сервисами, которые меняют документы косвенно
+func deleteCachedDocument(ctx context.Context, cacheManager cache.CacheManager, orgID, id uuid.UUID) {
+	if cacheManager != nil {
+		_ = cacheManager.Document().Delete(ctx, documentCacheKey(orgID, id))
+	}
+}
+
+// repositoryError сохраняет ошибки приложения из репозитория (например, not found)
+// и оборачивает остальные в ошибку БД
+func repositoryError(operation string, err error) *apperror.AppError {
+	if appErr, ok := err.(*apperror.AppError); ok && appErr.Code != apperror.ErrDatabase {
+		return appErr
+	}
+	return apperror.DatabaseError(operation, err)
+}
+
 // CacheWarmDocuments preloads frequently accessed documents
 func (s *esfDocumentService) CacheWarmDocuments(ctx context.Context, orgID uuid.UUID, limit int) error {
 	if s.cacheManager == nil {
@@ -179,8 +775,9 @@ func (s *esfDocumentService) CacheWarmDocuments(ctx context.Context, orgID uuid.
 	batchData := make(map[string]interface{})
 	for _, doc := range docs {
 		model := s.toModel(&doc)
-		cacheKey := "doc:id:" + doc.ID.String()
-		batchData[cacheKey] = &model
+		if data, err := json.Marshal(&model); err == nil {
+			batchData[documentCacheKey(orgID, doc.ID)] = string(data)
+		}
 	}
 
 	if len(batchData) > 0 {
@@ -220,6 +817,13 @@ func (s *esfDocumentService) toEntity(m *models.EsfCreateDocumentRequest) entity
 		return *p
 	}
 
+	derefAmount := func(p *money.Amount) money.Amount {
+		if p == nil {
+			return 0
+		}
+		return *p
+	}
+
 	derefTime := func(p *time.Time) time.Time {
 		if p == nil {
 			return time.Time{}
@@ -227,7 +831,10 @@ func (s *esfDocumentService) toEntity(m *models.EsfCreateDocumentRequest) entity
 		return *p
 	}
 
-	return entity.EsfDocument{
+	doc := entity.EsfDocument{
+		Direction:                      entity.DocumentDirection(m.Direction),
+		SupplierTin:                    m.SupplierTin,
+		SupplierName:                   m.SupplierName,
 		ForeignName:                    m.ForeignName,
 		IsBranchDataSent:               m.IsBranchDataSent,
 		IsPriceWithoutTaxes:            m.IsPriceWithoutTaxes,
@@ -238,14 +845,15 @@ func (s *esfDocumentService) toEntity(m *models.EsfCreateDocumentRequest) entity
 		DeliveryDate:                   derefTime(m.DeliveryDate),
 		DeliveryTypeCode:               m.DeliveryTypeCode,
 		IsResident:                     m.IsResident,
+		ContractorID:                   m.ContractorId,
 		ContractorTin:                  m.ContractorTin,
 		SupplierBankAccount:            m.SupplierBankAccount,
 		ContractorBankAccount:          m.ContractorBankAccount,
 		CurrencyCode:                   m.CurrencyCode,
 		CountryCode:                    m.CountryCode,
 		CurrencyRate:                   derefFloat64(m.CurrencyRate),
-		TotalCurrencyValue:             derefFloat64(m.TotalCurrencyValue),
-		TotalCurrencyValueWithoutTaxes: derefFloat64(m.TotalCurrencyValueWithoutTaxes),
+		TotalCurrencyValue:             derefAmount(m.TotalCurrencyValue),
+		TotalCurrencyValueWithoutTaxes: derefAmount(m.TotalCurrencyValueWithoutTaxes),
 		SupplyContractNumber:           m.SupplyContractNumber,
 		ContractStartDate:              derefTime(m.ContractStartDate),
 		Comment:                        m.Comment,
@@ -253,15 +861,20 @@ func (s *esfDocumentService) toEntity(m *models.EsfCreateDocumentRequest) entity
 		PaymentCode:                    m.PaymentCode,
 		TaxRateVATCode:                 m.TaxRateVATCode,
 		CatalogEntries:                 entries,
-		OpeningBalances:                derefFloat64(m.OpeningBalances),
-		AssessedContributionsAmount:    derefFloat64(m.AssessedContributionsAmount),
-		PaidAmount:                     derefFloat64(m.PaidAmount),
-		PenaltiesAmount:                derefFloat64(m.PenaltiesAmount),
-		FinesAmount:                    derefFloat64(m.FinesAmount),
-		ClosingBalances:                derefFloat64(m.ClosingBalances),
-		AmountToBePaid:                 derefFloat64(m.AmountToBePaid),
+		OpeningBalances:                derefAmount(m.OpeningBalances),
+		AssessedContributionsAmount:    derefAmount(m.AssessedContributionsAmount),
+		PaidAmount:                     derefAmount(m.PaidAmount),
+		PenaltiesAmount:                derefAmount(m.PenaltiesAmount),
+		FinesAmount:                    derefAmount(m.FinesAmount),
+		ClosingBalances:                derefAmount(m.ClosingBalances),
+		AmountToBePaid:                 derefAmount(m.AmountToBePaid),
 		PersonalAccountNumber:          m.PersonalAccountNumber,
 	}
+	// Номер входящего документа присвоен поставщиком; исходящим номер присваивается при создании
+	if doc.IsIncoming() {
+		doc.Number = strings.TrimSpace(m.Number)
+	}
+	return doc
 }
 
 func (s *esfDocumentService) toModel(e *entity.EsfDocument) models.EsfCreateDocumentRequest {
@@ -277,6 +890,9 @@ func (s *esfDocumentService) toModel(e *entity.EsfDocument) models.EsfCreateDocu
 			SalesTaxAmount:         ent.SalesTaxAmount,
 			AmountWithoutTaxes:     ent.AmountWithoutTaxes,
 			TotalAmount:            ent.TotalAmount,
+			OriginalEntryId:        ent.OriginalEntryID,
+			QuantityDelta:          ent.QuantityDelta,
+			PriceDelta:             ent.PriceDelta,
 		}
 	}
 
@@ -295,7 +911,33 @@ func (s *esfDocumentService) toModel(e *entity.EsfDocument) models.EsfCreateDocu
 		return &f
 	}
 
+	amountPtr := func(a money.Amount) *money.Amount {
+		if a.IsZero() {
+			return nil
+		}
+		return &a
+	}
+
+	var id *uuid.UUID
+	if e.ID != uuid.Nil {
+		docID := e.ID
+		id = &docID
+	}
+
 	return models.EsfCreateDocumentRequest{
+		ID:                             id,
+		Number:                         e.Number,
+		Status:                         e.Status.String(),
+		ExternalDocumentUuid:           e.ExternalDocumentUUID,
+		OriginalDocumentId:             e.OriginalDocumentID,
+		CorrectionReason:               e.CorrectionReason,
+		Direction:                      e.Direction.String(),
+		SupplierTin:                    e.SupplierTin,
+		SupplierName:                   e.SupplierName,
+		CounterpartyOrgId:              e.CounterpartyOrgID,
+		CounterpartyDocumentId:         e.CounterpartyDocumentID,
+		BuyerStatus:                    e.BuyerStatus.String(),
+		BuyerStatusReason:              e.BuyerStatusReason,
 		ForeignName:                    e.ForeignName,
 		IsBranchDataSent:               e.IsBranchDataSent,
 		IsPriceWithoutTaxes:            e.IsPriceWithoutTaxes,
@@ -306,14 +948,15 @@ func (s *esfDocumentService) toModel(e *entity.EsfDocument) models.EsfCreateDocu
 		DeliveryDate:                   timePtr(e.DeliveryDate),
 		DeliveryTypeCode:               e.DeliveryTypeCode,
 		IsResident:                     e.IsResident,
+		ContractorId:                   e.ContractorID,
 		ContractorTin:                  e.ContractorTin,
 		SupplierBankAccount:            e.SupplierBankAccount,
 		ContractorBankAccount:          e.ContractorBankAccount,
 		CurrencyCode:                   e.CurrencyCode,
 		CountryCode:                    e.CountryCode,
 		CurrencyRate:                   float64Ptr(e.CurrencyRate),
-		TotalCurrencyValue:             float64Ptr(e.TotalCurrencyValue),
-		TotalCurrencyValueWithoutTaxes: float64Ptr(e.TotalCurrencyValueWithoutTaxes),
+		TotalCurrencyValue:             amountPtr(e.TotalCurrencyValue),
+		TotalCurrencyValueWithoutTaxes: amountPtr(e.TotalCurrencyValueWithoutTaxes),
 		SupplyContractNumber:           e.SupplyContractNumber,
 		ContractStartDate:              timePtr(e.ContractStartDate),
 		Comment:                        e.Comment,
@@ -321,13 +964,13 @@ func (s *esfDocumentService) toModel(e *entity.EsfDocument) models.EsfCreateDocu
 		PaymentCode:                    e.PaymentCode,
 		TaxRateVATCode:                 e.TaxRateVATCode,
 		CatalogEntries:                 entries,
-		OpeningBalances:                float64Ptr(e.OpeningBalances),
-		AssessedContributionsAmount:    float64Ptr(e.AssessedContributionsAmount),
-		PaidAmount:                     float64Ptr(e.PaidAmount),
-		PenaltiesAmount:                float64Ptr(e.PenaltiesAmount),
-		FinesAmount:                    float64Ptr(e.FinesAmount),
-		ClosingBalances:                float64Ptr(e.ClosingBalances),
-		AmountToBePaid:                 float64Ptr(e.AmountToBePaid),
+		OpeningBalances:                amountPtr(e.OpeningBalances),
+		AssessedContributionsAmount:    amountPtr(e.AssessedContributionsAmount),
+		PaidAmount:                     amountPtr(e.PaidAmount),
+		PenaltiesAmount:                amountPtr(e.PenaltiesAmount),
+		FinesAmount:                    amountPtr(e.FinesAmount),
+		ClosingBalances:                amountPtr(e.ClosingBalances),
+		AmountToBePaid:                 amountPtr(e.AmountToBePaid),
 		PersonalAccountNumber:          e.PersonalAccountNumber,
 	}
 }
@@ -340,6 +983,10 @@ func (s *esfDocumentService) GetAllDocumentsPaginated(ctx context.Context, orgID
 		"page_size": params.PageSize,
 	})
 
+	if filters.Direction != "" && !entity.DocumentDirection(filters.Direction).IsValid() {
+		return nil, 0, apperror.ValidationError("direction must be outgoing or incoming")
+	}
+
 	docs, totalCount, err := s.repo.GetAllDocumentsPaginated(ctx, orgID, params, filters)
 	if err != nil {
 		s.logger.Error(ctx, "Failed to fetch paginated documents", err, logrus.Fields{"org_id": orgID.String()})
@@ -0,0 +1,464 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/diff"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/signature"
+	"github.com/sirupsen/logrus"
+)
+
+// signaturePayloadFormat версия канонического представления документа для подписи.
+// Состав полей фиксирован: новые поля документа не меняют представление
+// и не делают ранее сделанные подписи недействительными.
+const signaturePayloadFormat = "esf-document/v1"
+
+// signedDocument каноническое представление документа: реквизиты и позиции без служебных полей
+// (статус, даты изменения, идентификаторы налоговой службы), которые меняются после подписания
+type signedDocument struct {
+	Format                         string        `json:"format"`
+	ID                             uuid.UUID     `json:"id"`
+	Number                         string        `json:"number"`
+	OriginalDocumentID             *uuid.UUID    `json:"originalDocumentId"`
+	CorrectionReason               string        `json:"correctionReason"`
+	ForeignName                    string        `json:"foreignName"`
+	IsBranchDataSent               bool          `json:"isBranchDataSent"`
+	IsPriceWithoutTaxes            bool          `json:"isPriceWithoutTaxes"`
+	AffiliateTin                   string        `json:"affiliateTin"`
+	IsIndustry                     bool          `json:"isIndustry"`
+	OwnedCrmReceiptCode            string        `json:"ownedCrmReceiptCode"`
+	OperationTypeCode              string        `json:"operationTypeCode"`
+	DeliveryDate                   string        `json:"deliveryDate"`
+	DeliveryTypeCode               string        `json:"deliveryTypeCode"`
+	IsResident                     bool          `json:"isResident"`
+	ContractorTin                  string        `json:"contractorTin"`
+	SupplierBankAccount            string        `json:"supplierBankAccount"`
+	ContractorBankAccount          string        `json:"contractorBankAccount"`
+	CurrencyCode                   string        `json:"currencyCode"`
+	CountryCode                    string        `json:"countryCode"`
+	CurrencyRate                   float64       `json:"currencyRate"`
+	TotalCurrencyValue             money.Amount  `json:"totalCurrencyValue"`
+	TotalCurrencyValueWithoutTaxes money.Amount  `json:"totalCurrencyValueWithoutTaxes"`
+	SupplyContractNumber           string        `json:"supplyContractNumber"`
+	ContractStartDate              string        `json:"contractStartDate"`
+	Comment                        string        `json:"comment"`
+	DeliveryCode                   string        `json:"deliveryCode"`
+	PaymentCode                    string        `json:"paymentCode"`
+	TaxRateVATCode                 string        `json:"taxRateVATCode"`
+	OpeningBalances                money.Amount  `json:"openingBalances"`
+	AssessedContributionsAmount    money.Amount  `json:"assessedContributionsAmount"`
+	PaidAmount                     money.Amount  `json:"paidAmount"`
+	PenaltiesAmount                money.Amount  `json:"penaltiesAmount"`
+	FinesAmount                    money.Amount  `json:"finesAmount"`
+	ClosingBalances                money.Amount  `json:"closingBalances"`
+	AmountToBePaid                 money.Amount  `json:"amountToBePaid"`
+	PersonalAccountNumber          string        `json:"personalAccountNumber"`
+	CatalogEntries                 []signedEntry `json:"catalogEntries"`
+}
+
+type signedEntry struct {
+	ID                     uuid.UUID    `json:"id"`
+	UnitClassificationCode string       `json:"unitClassificationCode"`
+	SalesTaxCode           string       `json:"salesTaxCode"`
+	CustomsAuthorityCode   string       `json:"customsAuthorityCode"`
+	Quantity               float64      `json:"quantity"`
+	Price                  money.Amount `json:"price"`
+	VatAmount              money.Amount `json:"vatAmount"`
+	SalesTaxAmount         money.Amount `json:"salesTaxAmount"`
+	AmountWithoutTaxes     money.Amount `json:"amountWithoutTaxes"`
+	TotalAmount            money.Amount `json:"totalAmount"`
+	OriginalEntryID        *uuid.UUID   `json:"originalEntryId"`
+	QuantityDelta          float64      `json:"quantityDelta"`
+	PriceDelta             money.Amount `json:"priceDelta"`
+}
+
+// SetSigningKeyRepository подключает ключи подписи организации.
+// Без ключей документ нельзя подписать, а сделанные подписи - проверить.
+func (s *esfDocumentService) SetSigningKeyRepository(keys repository.EsfSigningKeyRepository) {
+	s.signingKeys = keys
+}
+
+// GetSigningPayload возвращает каноническое представление документа для подписи на стороне клиента
+func (s *esfDocumentService) GetSigningPayload(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfSigningPayload, error) {
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+
+	payload, err := canonicalDocument(doc)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "failed to build signing payload").WithError(err)
+	}
+
+	return &models.EsfSigningPayload{
+		DocumentID: id,
+		Format:     signaturePayloadFormat,
+		Payload:    string(payload),
+		Digest:     signature.Digest(payload),
+	}, nil
+}
+
+// SignDocument проверяет подпись канонического представления документа и сохраняет ее.
+// Подписывается документ в статусе ready (переходит в signed); подписанный документ
+// можно подписать повторно другим ключом (например, руководитель и главный бухгалтер).
+func (s *esfDocumentService) SignDocument(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfSignRequest) (*models.EsfDocumentSignatureModel, error) {
+	s.logger.Info(ctx, "Signing document", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "key_id": req.KeyID.String()})
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+	if doc.Status != entity.DocumentStatusReady && doc.Status != entity.DocumentStatusSigned {
+		current := doc.Status
+		if current == "" {
+			current = entity.DocumentStatusDraft
+		}
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidStatusTransition, "document must be ready to be signed",
+			fmt.Sprintf("cannot sign a document in status %s", current))
+	}
+
+	sig, err := signature.DecodeSignature(req.Signature)
+	if err != nil {
+		return nil, apperror.FieldValidationError("invalid signature", []apperror.FieldError{{Field: "signature", Message: err.Error()}})
+	}
+
+	if s.signingKeys == nil {
+		return nil, apperror.New(apperror.ErrConfigError, "signing keys are not configured")
+	}
+	key, err := s.signingKeys.GetSigningKeyByID(ctx, orgID, req.KeyID)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrNotFound {
+			return nil, apperror.FieldValidationError("invalid signature", []apperror.FieldError{{Field: "keyId", Message: "signing key not found"}})
+		}
+		return nil, repositoryError("fetching signing key", err)
+	}
+	if key.IsRevoked() {
+		return nil, apperror.FieldValidationError("invalid signature", []apperror.FieldError{{Field: "keyId", Message: "signing key is revoked"}})
+	}
+	signerID := actorID(ctx)
+	if key.Scope == entity.SigningKeyScopeUser && key.UserID != signerID {
+		return nil, apperror.ForbiddenError("personal signing key belongs to another user")
+	}
+
+	payload, err := canonicalDocument(doc)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "failed to build signing payload").WithError(err)
+	}
+	digest := signature.Digest(payload)
+	if req.Digest != "" && !strings.EqualFold(req.Digest, digest) {
+		return nil, apperror.NewWithDetails(apperror.ErrConflict, "document has changed since the signing payload was requested",
+			"request a new signing payload and sign it again")
+	}
+
+	publicKey, err := signature.ParsePublicKey(key.PublicKey)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "stored signing key is invalid").WithError(err)
+	}
+	if err := publicKey.Verify(payload, sig); err != nil {
+		s.logger.Warn(ctx, "Document signature verification failed", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "key_id": key.ID.String()})
+		return nil, apperror.FieldValidationError("invalid signature", []apperror.FieldError{{Field: "signature", Message: "signature does not match the document content and key"}})
+	}
+
+	// Первая подпись переводит документ в signed: до нее документ должен пройти согласование
+	if doc.Status == entity.DocumentStatusReady {
+		if err := s.ensureApproved(ctx, orgID, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	signatures, err := s.repo.GetDocumentSignatures(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document signatures", err)
+	}
+	for _, existing := range signatures {
+		if existing.KeyID == key.ID && existing.Digest == digest {
+			return nil, apperror.New(apperror.ErrAlreadyExists, "document is already signed with this key")
+		}
+	}
+
+	record := &entity.EsfDocumentSignature{
+		ID:          uuid.New(),
+		KeyID:       key.ID,
+		Algorithm:   key.Algorithm,
+		Fingerprint: key.Fingerprint,
+		Payload:     string(payload),
+		Digest:      digest,
+		Signature:   signature.EncodeSignature(sig),
+		SignerID:    signerID,
+		SignerName:  actorName(ctx),
+	}
+	if err := s.repo.SignDocument(ctx, orgID, doc, record, entity.DocumentStatusSigned); err != nil {
+		return nil, repositoryError("signing document", err)
+	}
+
+	s.invalidateDocumentCache(ctx, orgID, id)
+
+	s.logger.Info(ctx, "Document signed successfully", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String(), "key_id": key.ID.String(), "digest": digest})
+	model := signatureToModel(record, key)
+	return &model, nil
+}
+
+// GetDocumentSignatures возвращает подписи документа
+func (s *esfDocumentService) GetDocumentSignatures(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]models.EsfDocumentSignatureModel, error) {
+	if _, err := s.repo.GetDocumentByID(ctx, orgID, id); err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+
+	signatures, keys, err := s.loadSignatures(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.EsfDocumentSignatureModel, len(signatures))
+	for i := range signatures {
+		result[i] = signatureToModel(&signatures[i], keys[signatures[i].KeyID])
+	}
+	return result, nil
+}
+
+// VerifyDocumentSignatures проверяет подписи документа и сообщает об изменениях после подписания
+func (s *esfDocumentService) VerifyDocumentSignatures(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfSignatureVerification, error) {
+	s.logger.Info(ctx, "Verifying document signatures", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching document", err)
+	}
+	return s.verifySignatures(ctx, orgID, doc)
+}
+
+func (s *esfDocumentService) verifySignatures(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) (*models.EsfSignatureVerification, error) {
+	payload, err := canonicalDocument(doc)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "failed to build signing payload").WithError(err)
+	}
+
+	signatures, keys, err := s.loadSignatures(ctx, orgID, doc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.EsfSignatureVerification{
+		DocumentID: doc.ID,
+		Digest:     signature.Digest(payload),
+		Signed:     len(signatures) > 0,
+		Signatures: make([]models.EsfSignatureCheck, len(signatures)),
+	}
+	allValid := true
+	for i := range signatures {
+		check := checkSignature(&signatures[i], keys[signatures[i].KeyID], payload, result.Digest)
+		allValid = allValid && check.SignatureValid
+		result.Signatures[i] = check
+	}
+	if result.Signed {
+		latest := result.Signatures[len(result.Signatures)-1]
+		result.Modified = !latest.ContentMatches
+		result.Valid = allValid && latest.ContentMatches
+	}
+
+	if result.Modified || !allValid {
+		s.logger.Warn(ctx, "Document signatures are not valid", logrus.Fields{"org_id": orgID.String(), "doc_id": doc.ID.String(), "modified": result.Modified})
+	}
+	return result, nil
+}
+
+// ensureSigned проверяет перед отправкой, что документ подписан и не изменялся после подписания
+func (s *esfDocumentService) ensureSigned(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) error {
+	verification, err := s.verifySignatures(ctx, orgID, doc)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !verification.Signed:
+		return apperror.ValidationError("document must be signed before submission")
+	case verification.Modified:
+		return apperror.NewWithDetails(apperror.ErrValidation, "document signature is not valid", "document has been modified after signing")
+	case !verification.Valid:
+		return apperror.NewWithDetails(apperror.ErrValidation, "document signature is not valid", "stored signature does not match its payload")
+	}
+	return nil
+}
+
+// loadSignatures загружает подписи документа и ключи, которыми они сделаны
+func (s *esfDocumentService) loadSignatures(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentSignature, map[uuid.UUID]*entity.EsfSigningKey, error) {
+	signatures, err := s.repo.GetDocumentSignatures(ctx, orgID, documentID)
+	if err != nil {
+		return nil, nil, repositoryError("fetching document signatures", err)
+	}
+	if len(signatures) == 0 {
+		return signatures, nil, nil
+	}
+
+	if s.signingKeys == nil {
+		return nil, nil, apperror.New(apperror.ErrConfigError, "signing keys are not configured")
+	}
+	keys, err := s.signingKeys.GetSigningKeys(ctx, orgID)
+	if err != nil {
+		return nil, nil, repositoryError("fetching signing keys", err)
+	}
+	byID := make(map[uuid.UUID]*entity.EsfSigningKey, len(keys))
+	for i := range keys {
+		byID[keys[i].ID] = &keys[i]
+	}
+	return signatures, byID, nil
+}
+
+// checkSignature проверяет подпись сохраненного представления и сравнивает его с текущим документом
+func checkSignature(sig *entity.EsfDocumentSignature, key *entity.EsfSigningKey, current []byte, digest string) models.EsfSignatureCheck {
+	check := models.EsfSignatureCheck{
+		EsfDocumentSignatureModel: signatureToModel(sig, key),
+		ContentMatches:            sig.Digest == digest && sig.Payload == string(current),
+	}
+	if !check.ContentMatches {
+		if changes, err := diff.Compare(json.RawMessage(sig.Payload), json.RawMessage(current)); err == nil {
+			check.Changes = changes
+		}
+	}
+
+	switch {
+	case key == nil:
+		check.Error = "signing key not found"
+		return check
+	case key.Fingerprint != sig.Fingerprint:
+		check.Error = "signing key does not match the signature fingerprint"
+		return check
+	case signature.Digest([]byte(sig.Payload)) != sig.Digest:
+		check.Error = "signed payload does not match its digest"
+		return check
+	}
+	check.KeyRevoked = key.IsRevoked()
+
+	publicKey, err := signature.ParsePublicKey(key.PublicKey)
+	if err != nil {
+		check.Error = "stored signing key is invalid"
+		return check
+	}
+	raw, err := signature.DecodeSignature(sig.Signature)
+	if err == nil {
+		err = publicKey.Verify([]byte(sig.Payload), raw)
+	}
+	if err != nil {
+		check.Error = "signature does not match the signed payload"
+		return check
+	}
+	check.SignatureValid = true
+	return check
+}
+
+// canonicalDocument формирует каноническое представление документа для подписи.
+// Позиции упорядочиваются по идентификатору, даты приводятся к UTC.
+func canonicalDocument(doc *entity.EsfDocument) ([]byte, error) {
+	entries := make([]signedEntry, len(doc.CatalogEntries))
+	for i, e := range doc.CatalogEntries {
+		entries[i] = signedEntry{
+			ID:                     e.ID,
+			UnitClassificationCode: e.UnitClassificationCode,
+			SalesTaxCode:           e.SalesTaxCode,
+			CustomsAuthorityCode:   e.CustomsAuthorityCode,
+			Quantity:               e.Quantity,
+			Price:                  e.Price,
+			VatAmount:              e.VatAmount,
+			SalesTaxAmount:         e.SalesTaxAmount,
+			AmountWithoutTaxes:     e.AmountWithoutTaxes,
+			TotalAmount:            e.TotalAmount,
+			OriginalEntryID:        e.OriginalEntryID,
+			QuantityDelta:          e.QuantityDelta,
+			PriceDelta:             e.PriceDelta,
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID.String() < entries[j].ID.String() })
+
+	return json.Marshal(signedDocument{
+		Format:                         signaturePayloadFormat,
+		ID:                             doc.ID,
+		Number:                         doc.Number,
+		OriginalDocumentID:             doc.OriginalDocumentID,
+		CorrectionReason:               doc.CorrectionReason,
+		ForeignName:                    doc.ForeignName,
+		IsBranchDataSent:               doc.IsBranchDataSent,
+		IsPriceWithoutTaxes:            doc.IsPriceWithoutTaxes,
+		AffiliateTin:                   doc.AffiliateTin,
+		IsIndustry:                     doc.IsIndustry,
+		OwnedCrmReceiptCode:            doc.OwnedCrmReceiptCode,
+		OperationTypeCode:              doc.OperationTypeCode,
+		DeliveryDate:                   signedTime(doc.DeliveryDate),
+		DeliveryTypeCode:               doc.DeliveryTypeCode,
+		IsResident:                     doc.IsResident,
+		ContractorTin:                  doc.ContractorTin,
+		SupplierBankAccount:            doc.SupplierBankAccount,
+		ContractorBankAccount:          doc.ContractorBankAccount,
+		CurrencyCode:                   doc.CurrencyCode,
+		CountryCode:                    doc.CountryCode,
+		CurrencyRate:                   doc.CurrencyRate,
+		TotalCurrencyValue:             doc.TotalCurrencyValue,
+		TotalCurrencyValueWithoutTaxes: doc.TotalCurrencyValueWithoutTaxes,
+		SupplyContractNumber:           doc.SupplyContractNumber,
+		ContractStartDate:              signedTime(doc.ContractStartDate),
+		Comment:                        doc.Comment,
+		DeliveryCode:                   doc.DeliveryCode,
+		PaymentCode:                    doc.PaymentCode,
+		TaxRateVATCode:                 doc.TaxRateVATCode,
+		OpeningBalances:                doc.OpeningBalances,
+		AssessedContributionsAmount:    doc.AssessedContributionsAmount,
+		PaidAmount:                     doc.PaidAmount,
+		PenaltiesAmount:                doc.PenaltiesAmount,
+		FinesAmount:                    doc.FinesAmount,
+		ClosingBalances:                doc.ClosingBalances,
+		AmountToBePaid:                 doc.AmountToBePaid,
+		PersonalAccountNumber:          doc.PersonalAccountNumber,
+		CatalogEntries:                 entries,
+	})
+}
+
+func signedTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// actorID возвращает идентификатор пользователя из контекста запроса
+func actorID(ctx context.Context) string {
+	if userID, ok := logger.FromContext(ctx, logger.UserIDKey); ok {
+		return fmt.Sprint(userID)
+	}
+	return ""
+}
+
+// actorName возвращает имя пользователя из контекста запроса
+func actorName(ctx context.Context) string {
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		return fmt.Sprint(username)
+	}
+	return ""
+}
+
+func signatureToModel(sig *entity.EsfDocumentSignature, key *entity.EsfSigningKey) models.EsfDocumentSignatureModel {
+	model := models.EsfDocumentSignatureModel{
+		ID:          sig.ID,
+		KeyID:       sig.KeyID,
+		Algorithm:   sig.Algorithm,
+		Fingerprint: sig.Fingerprint,
+		Digest:      sig.Digest,
+		Signature:   sig.Signature,
+		SignerID:    sig.SignerID,
+		SignerName:  sig.SignerName,
+		SignedAt:    sig.CreatedAt,
+	}
+	if key != nil {
+		model.KeyName = key.Name
+	}
+	return model
+}
@@ -0,0 +1,264 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/signature"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newSigningKey создает ключ подписи и возвращает его запись и закрытый ключ
+func newSigningKey(t *testing.T, algorithm signature.Algorithm, scope entity.SigningKeyScope, userID string) (*entity.EsfSigningKey, []byte) {
+	privatePEM, publicPEM, err := signature.GenerateKey(algorithm)
+	require.NoError(t, err)
+	pub, err := signature.ParsePublicKey(string(publicPEM))
+	require.NoError(t, err)
+
+	return &entity.EsfSigningKey{
+		ID:          uuid.New(),
+		Name:        "Директор",
+		Scope:       scope,
+		UserID:      userID,
+		Algorithm:   string(pub.Algorithm),
+		PublicKey:   pub.PEM(),
+		Fingerprint: pub.Fingerprint,
+	}, privatePEM
+}
+
+// signPayload подписывает каноническое представление документа закрытым ключом
+func signPayload(t *testing.T, doc *entity.EsfDocument, privatePEM []byte) (payload []byte, sig string) {
+	payload, err := canonicalDocument(doc)
+	require.NoError(t, err)
+	priv, err := signature.ParsePrivateKey(privatePEM)
+	require.NoError(t, err)
+	raw, err := signature.Sign(priv, payload)
+	require.NoError(t, err)
+	return payload, signature.EncodeSignature(raw)
+}
+
+// signedDocumentMocks репозитории ключа подписи и политики согласования подписанного документа
+type signedDocumentMocks struct {
+	keys     *MockSigningKeyRepository
+	policies *MockApprovalPolicyRepository
+}
+
+// wire подключает репозитории к сервису документов
+func (m *signedDocumentMocks) wire(service services.EsfDocumentService) {
+	service.SetSigningKeyRepository(m.keys)
+	service.SetApprovalPolicyRepository(m.policies)
+}
+
+// mockSignedDocument подписывает документ ключом организации и настраивает репозитории на выдачу подписи и ключа;
+// политика согласования выключена
+func mockSignedDocument(t *testing.T, mockRepo *MockDocumentRepository, orgID uuid.UUID, doc *entity.EsfDocument) *signedDocumentMocks {
+	key, privatePEM := newSigningKey(t, signature.Ed25519, entity.SigningKeyScopeOrganization, "")
+	payload, sig := signPayload(t, doc, privatePEM)
+
+	keys := new(MockSigningKeyRepository)
+	keys.On("GetSigningKeys", mock.Anything, orgID).Return([]entity.EsfSigningKey{*key}, nil)
+	mockRepo.On("GetDocumentSignatures", mock.Anything, orgID, doc.ID).Return([]entity.EsfDocumentSignature{{
+		ID:          uuid.New(),
+		DocumentID:  doc.ID,
+		KeyID:       key.ID,
+		Algorithm:   key.Algorithm,
+		Fingerprint: key.Fingerprint,
+		Payload:     string(payload),
+		Digest:      signature.Digest(payload),
+		Signature:   sig,
+	}}, nil)
+	policies := new(MockApprovalPolicyRepository)
+	policies.On("GetApprovalPolicy", mock.Anything, orgID).Return(&entity.EsfApprovalPolicy{RequiredApprovals: 1}, nil).Maybe()
+	return &signedDocumentMocks{keys: keys, policies: policies}
+}
+
+// ========== Document Signing Tests ==========
+
+func TestCanonicalDocument_IgnoresServiceFields(t *testing.T) {
+	entries := withEntries(
+		testEntry(uuid.MustParse("b0000000-0000-0000-0000-000000000002"), 2, money.MustParse("150.50")),
+		testEntry(uuid.MustParse("a0000000-0000-0000-0000-000000000001"), 1, money.MustParse("100")),
+	)
+	doc := newTestDocument(uuid.New(), withStatus(entity.DocumentStatusReady), entries)
+	payload, err := canonicalDocument(doc)
+	require.NoError(t, err)
+
+	changed := newTestDocument(doc.ID, withStatus(entity.DocumentStatusReady), entries)
+	changed.Status = entity.DocumentStatusSubmitted
+	changed.UpdatedAt = time.Now()
+	changed.ExternalDocumentUUID = "ext-1"
+	changed.DeliveryDate = doc.DeliveryDate.UTC()
+	changed.CatalogEntries[0], changed.CatalogEntries[1] = changed.CatalogEntries[1], changed.CatalogEntries[0]
+	same, err := canonicalDocument(changed)
+	require.NoError(t, err)
+	assert.Equal(t, string(payload), string(same))
+	assert.Contains(t, string(payload), `"format":"esf-document/v1"`)
+
+	changed.CatalogEntries[0].Price = money.MustParse("100.01")
+	modified, err := canonicalDocument(changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, string(payload), string(modified))
+}
+
+func TestEsfSigning_SignDocument(t *testing.T) {
+	for _, algorithm := range []signature.Algorithm{signature.Ed25519, signature.ECDSAP256} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			mockRepo := new(MockDocumentRepository)
+			policies := new(MockApprovalPolicyRepository)
+			keys := new(MockSigningKeyRepository)
+			orgID, docID := uuid.New(), uuid.New()
+			doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+			key, privatePEM := newSigningKey(t, algorithm, entity.SigningKeyScopeUser, "user-1")
+			_, sig := signPayload(t, doc, privatePEM)
+
+			mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+			keys.On("GetSigningKeyByID", mock.Anything, orgID, key.ID).Return(key, nil)
+			mockRepo.On("GetDocumentSignatures", mock.Anything, orgID, docID).Return([]entity.EsfDocumentSignature{}, nil)
+			policies.On("GetApprovalPolicy", mock.Anything, orgID).Return(&entity.EsfApprovalPolicy{RequiredApprovals: 1}, nil)
+			mockRepo.On("SignDocument", mock.Anything, orgID, doc, mock.Anything, entity.DocumentStatusSigned).Return(nil)
+
+			ctx := logger.WithContext(logger.WithContext(context.Background(), logger.UserIDKey, "user-1"), logger.UsernameKey, "aibek")
+			service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+			service.SetApprovalPolicyRepository(policies)
+			service.SetSigningKeyRepository(keys)
+			result, err := service.SignDocument(ctx, orgID, docID, &models.EsfSignRequest{KeyID: key.ID, Signature: sig})
+			require.NoError(t, err)
+
+			assert.Equal(t, "aibek", result.SignerName)
+			assert.Equal(t, key.Fingerprint, result.Fingerprint)
+			mockRepo.AssertCalled(t, "SignDocument", mock.Anything, orgID, doc, mock.MatchedBy(func(s *entity.EsfDocumentSignature) bool {
+				return s.KeyID == key.ID && s.SignerID == "user-1" && s.Digest == signature.Digest([]byte(s.Payload))
+			}), entity.DocumentStatusSigned)
+		})
+	}
+}
+
+func TestEsfSigning_RejectsInvalidSignature(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	keys := new(MockSigningKeyRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	key, privatePEM := newSigningKey(t, signature.Ed25519, entity.SigningKeyScopeOrganization, "")
+
+	// Подпись сделана до изменения цены
+	_, sig := signPayload(t, doc, privatePEM)
+	doc.CatalogEntries[0].Price = money.MustParse("999")
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	keys.On("GetSigningKeyByID", mock.Anything, orgID, key.ID).Return(key, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetSigningKeyRepository(keys)
+	_, err := service.SignDocument(context.Background(), orgID, docID, &models.EsfSignRequest{KeyID: key.ID, Signature: sig})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "signature", appErr.Fields[0].Field)
+	mockRepo.AssertNotCalled(t, "SignDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfSigning_PersonalKeyOfAnotherUser(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	keys := new(MockSigningKeyRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	key, privatePEM := newSigningKey(t, signature.Ed25519, entity.SigningKeyScopeUser, "user-1")
+	_, sig := signPayload(t, doc, privatePEM)
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	keys.On("GetSigningKeyByID", mock.Anything, orgID, key.ID).Return(key, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetSigningKeyRepository(keys)
+	_, err := service.SignDocument(logger.WithContext(context.Background(), logger.UserIDKey, "user-2"), orgID, docID, &models.EsfSignRequest{KeyID: key.ID, Signature: sig})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrForbidden, appErr.Code)
+}
+
+func TestEsfSigning_VerifyDetectsModification(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	signed := mockSignedDocument(t, mockRepo, orgID, doc)
+
+	modified := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	modified.Status = entity.DocumentStatusSigned
+	modified.ContractorTin = "01503201910012"
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(modified, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	signed.wire(service)
+	result, err := service.VerifyDocumentSignatures(context.Background(), orgID, docID)
+	require.NoError(t, err)
+
+	assert.True(t, result.Signed)
+	assert.True(t, result.Modified)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Signatures, 1)
+	assert.True(t, result.Signatures[0].SignatureValid)
+	require.Len(t, result.Signatures[0].Changes, 1)
+	assert.Equal(t, "contractorTin", result.Signatures[0].Changes[0].Field)
+
+	_, err = service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusSubmitted, "")
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateDocumentStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfSigning_VerifyDetectsForgedPayload(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	keys := new(MockSigningKeyRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusReady))
+	key, privatePEM := newSigningKey(t, signature.Ed25519, entity.SigningKeyScopeOrganization, "")
+	_, sig := signPayload(t, doc, privatePEM)
+
+	// Документ и сохраненное представление изменены в БД согласованно, подпись осталась прежней
+	doc.Comment = "изменено в обход API"
+	forged, err := canonicalDocument(doc)
+	require.NoError(t, err)
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	keys.On("GetSigningKeys", mock.Anything, orgID).Return([]entity.EsfSigningKey{*key}, nil)
+	mockRepo.On("GetDocumentSignatures", mock.Anything, orgID, docID).Return([]entity.EsfDocumentSignature{{
+		KeyID: key.ID, Fingerprint: key.Fingerprint, Payload: string(forged), Digest: signature.Digest(forged), Signature: sig,
+	}}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetSigningKeyRepository(keys)
+	result, err := service.VerifyDocumentSignatures(context.Background(), orgID, docID)
+	require.NoError(t, err)
+
+	assert.False(t, result.Modified)
+	assert.False(t, result.Valid)
+	assert.False(t, result.Signatures[0].SignatureValid)
+}
+
+func TestEsfSigning_SubmitRequiresSignature(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusSigned)), nil)
+	mockRepo.On("GetDocumentSignatures", mock.Anything, orgID, docID).Return([]entity.EsfDocumentSignature{}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusSubmitted, "")
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, "document must be signed before submission", appErr.Message)
+}
@@ -0,0 +1,169 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ========== Document Lifecycle Tests ==========
+
+func TestDocumentStatusTransitions(t *testing.T) {
+	assert.True(t, entity.DocumentStatusDraft.CanTransitionTo(entity.DocumentStatusReady))
+	assert.True(t, entity.DocumentStatusReady.CanTransitionTo(entity.DocumentStatusSigned))
+	assert.True(t, entity.DocumentStatusSigned.CanTransitionTo(entity.DocumentStatusSubmitted))
+	assert.True(t, entity.DocumentStatusSubmitted.CanTransitionTo(entity.DocumentStatusAccepted))
+	assert.True(t, entity.DocumentStatusSubmitted.CanTransitionTo(entity.DocumentStatusRejected))
+	assert.True(t, entity.DocumentStatusRejected.CanTransitionTo(entity.DocumentStatusDraft))
+
+	assert.False(t, entity.DocumentStatusDraft.CanTransitionTo(entity.DocumentStatusSigned))
+	assert.False(t, entity.DocumentStatusAccepted.CanTransitionTo(entity.DocumentStatusDraft))
+	assert.False(t, entity.DocumentStatusCancelled.CanTransitionTo(entity.DocumentStatusDraft))
+
+	assert.True(t, entity.DocumentStatusDraft.IsEditable())
+	assert.False(t, entity.DocumentStatusReady.IsEditable())
+	assert.False(t, entity.DocumentStatus("archived").IsValid())
+}
+
+func TestEsfDocumentChangeStatus_Success(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusDraft, entity.DocumentStatusReady, "").Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusReady, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", result.PreviousStatus)
+	assert.Equal(t, "ready", result.Status)
+	assert.Contains(t, result.AllowedTransitions, "signed")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentChangeStatus_InvalidTransition(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusAccepted, "")
+
+	assert.Nil(t, result)
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidStatusTransition, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateDocumentStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentChangeStatus_RejectRequiresReason(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusSubmitted}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusRejected, "  ")
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrValidation, appErr.Code)
+}
+
+func TestEsfDocumentUpdate_LockedDocument(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusSigned}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.UpdateDocument(context.Background(), orgID, &models.EsfEditDocumentRequest{ID: docID})
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+	mockRepo.AssertNotCalled(t, "UpdateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentDelete_LockedDocument(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusAccepted}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.DeleteDocument(context.Background(), orgID, docID)
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+	mockRepo.AssertNotCalled(t, "DeleteDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentDelete_NumberedDraft(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	// Документ возвращен в черновик после выдачи номера
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{
+		ID: docID, Status: entity.DocumentStatusDraft, Number: "INV-2026-000012", NumberYear: 2026, NumberSeq: 12,
+	}, nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.DeleteDocument(context.Background(), orgID, docID)
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+	mockRepo.AssertNotCalled(t, "DeleteDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentUpdate_LeftDraftConcurrently(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	// Документ был черновиком при проверке, но параллельный запрос перевел его в ready
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
+	mockRepo.On("UpdateDocument", mock.Anything, orgID, mock.Anything).
+		Return(apperror.New(apperror.ErrDocumentLocked, "document can only be modified in draft status"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.UpdateDocument(context.Background(), orgID, &models.EsfEditDocumentRequest{ID: docID})
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+}
+
+func TestEsfDocumentDelete_LeftDraftConcurrently(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
+	mockRepo.On("DeleteDocument", mock.Anything, orgID, docID).
+		Return(apperror.New(apperror.ErrDocumentLocked, "only an unnumbered draft can be deleted"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	err := service.DeleteDocument(context.Background(), orgID, docID)
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrDocumentLocked, appErr.Code)
+}
@@ -0,0 +1,192 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/gateway"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubOrganizationRepository возвращает организацию с токеном и, при необходимости,
+// другие организации платформы (покупателей)
+type stubOrganizationRepository struct {
+	org    *entity.EstOrganization
+	others []*entity.EstOrganization
+}
+
+func (r *stubOrganizationRepository) GetAll(ctx context.Context) ([]*entity.EstOrganization, error) {
+	return append([]*entity.EstOrganization{r.org}, r.others...), nil
+}
+
+func (r *stubOrganizationRepository) GetByID(ctx context.Context, id string) (*entity.EstOrganization, error) {
+	orgs, _ := r.GetAll(ctx)
+	for _, org := range orgs {
+		if org != nil && org.ID.String() == id {
+			return org, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubOrganizationRepository) GetByTin(ctx context.Context, tin string) (*entity.EstOrganization, error) {
+	orgs, _ := r.GetAll(ctx)
+	for _, org := range orgs {
+		if org != nil && org.Tin != "" && org.Tin == tin {
+			return org, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *stubOrganizationRepository) Insert(ctx context.Context, org *entity.EstOrganization) error {
+	return nil
+}
+
+func (r *stubOrganizationRepository) Update(ctx context.Context, org *entity.EstOrganization) error {
+	return nil
+}
+
+func (r *stubOrganizationRepository) Delete(ctx context.Context, id string) error { return nil }
+
+func (r *stubOrganizationRepository) CreateDatabase(ctx context.Context, dbName string) error {
+	return nil
+}
+
+func (r *stubOrganizationRepository) GetAllPaginated(ctx context.Context, params pagination.PaginationParams, filters pagination.OrganizationFilterParams) ([]*entity.EstOrganization, int64, error) {
+	return nil, 0, nil
+}
+
+// ========== Document Submission Tests ==========
+
+func TestEsfDocumentSubmit_SendsToGatewayAndStoresIDs(t *testing.T) {
+	server := gateway.NewMockEsfServer()
+	defer server.Close()
+
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+	orgRepo := &stubOrganizationRepository{org: &entity.EstOrganization{ID: orgID, Token: "org-token-123"}}
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusSigned))
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	signed := mockSignedDocument(t, mockRepo, orgID, doc)
+
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusSigned, entity.DocumentStatusSubmitting, "").Return(nil).Once()
+
+	var externalUUID string
+	mockRepo.On("MarkDocumentSubmitted", mock.Anything, orgID, docID, entity.DocumentStatusSubmitting, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			externalUUID = args.String(5)
+		}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	signed.wire(service)
+	service.SetOrganizationRepository(orgRepo)
+	service.SetSubmissionGateway(gateway.NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New()))
+
+	resp, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusSubmitted, "")
+	require.NoError(t, err)
+	assert.Equal(t, "submitted", resp.Status)
+	assert.NotEmpty(t, resp.ExternalResponseId)
+	assert.Equal(t, externalUUID, resp.ExternalDocumentUuid)
+
+	inv, ok := server.Invoice(externalUUID)
+	require.True(t, ok)
+	assert.Equal(t, "org-token-123", inv.Token)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentSubmit_GatewayRejectionKeepsStatus(t *testing.T) {
+	server := gateway.NewMockEsfServer()
+	defer server.Close()
+
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+	orgRepo := &stubOrganizationRepository{org: &entity.EstOrganization{ID: orgID, Token: "org-token-123"}}
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusSigned))
+	doc.ContractorTin = ""
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	signed := mockSignedDocument(t, mockRepo, orgID, doc)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusSigned, entity.DocumentStatusSubmitting, "").Return(nil).Once()
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusSubmitting, entity.DocumentStatusSigned, "").Return(nil).Once()
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	signed.wire(service)
+	service.SetOrganizationRepository(orgRepo)
+	service.SetSubmissionGateway(gateway.NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New()))
+
+	_, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusSubmitted, "")
+	require.Error(t, err)
+	mockRepo.AssertNotCalled(t, "MarkDocumentSubmitted", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfDocumentSubmit_ConcurrentSubmissionIsNotSent(t *testing.T) {
+	server := gateway.NewMockEsfServer()
+	defer server.Close()
+
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+	orgRepo := &stubOrganizationRepository{org: &entity.EstOrganization{ID: orgID, Token: "org-token-123"}}
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusSigned))
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	signed := mockSignedDocument(t, mockRepo, orgID, doc)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusSigned, entity.DocumentStatusSubmitting, "").
+		Return(apperror.New(apperror.ErrInvalidStatusTransition, "document status has changed"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	signed.wire(service)
+	service.SetOrganizationRepository(orgRepo)
+	service.SetSubmissionGateway(gateway.NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New()))
+
+	_, err := service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusSubmitted, "")
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrInvalidStatusTransition, appErr.Code)
+	assert.Equal(t, 0, server.InvoiceCount())
+	mockRepo.AssertNotCalled(t, "MarkDocumentSubmitted", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentCancel_CancelsInGateway(t *testing.T) {
+	server := gateway.NewMockEsfServer()
+	defer server.Close()
+
+	client := gateway.NewEsfHTTPClient(server.URL(), 5*time.Second, logrus.New())
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	docID := uuid.New()
+	orgRepo := &stubOrganizationRepository{org: &entity.EstOrganization{ID: orgID, Token: "org-token-123"}}
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetOrganizationRepository(orgRepo)
+	service.SetSubmissionGateway(client)
+
+	created, err := client.CreateInvoice(context.Background(), "org-token-123", service.(*esfDocumentService).gatewayPayload(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))))
+	require.NoError(t, err)
+
+	doc := newTestDocument(docID, withStatus(entity.DocumentStatusAccepted))
+	doc.ExternalDocumentUUID = created.DocumentUuid
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil)
+	mockRepo.On("UpdateDocumentStatus", mock.Anything, orgID, docID, entity.DocumentStatusAccepted, entity.DocumentStatusCancelled, "duplicate").Return(nil)
+
+	_, err = service.ChangeDocumentStatus(context.Background(), orgID, docID, entity.DocumentStatusCancelled, "duplicate")
+	require.NoError(t, err)
+
+	inv, ok := server.Invoice(created.DocumentUuid)
+	require.True(t, ok)
+	assert.True(t, inv.Cancelled)
+	assert.Equal(t, "duplicate", inv.CancelReason)
+}
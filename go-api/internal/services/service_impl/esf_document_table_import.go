@@ -0,0 +1,464 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/tabular"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+)
+
+// maxImportRows ограничивает количество строк в файле импорта
+const maxImportRows = 10000
+
+// documentKeyField поле, по которому строки объединяются в документ
+const documentKeyField = "documentKey"
+
+var (
+	importTinPattern      = regexp.MustCompile(`^[0-9]{14}$`)
+	importCurrencyPattern = regexp.MustCompile(`^[A-Z0-9]{3}$`)
+	importCountryPattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// importField поле документа или позиции, которое заполняется из колонки файла
+type importField struct {
+	name     string
+	entry    bool
+	required bool
+	apply    func(doc *entity.EsfDocument, entry *entity.EsfEntries, value string) error
+}
+
+// importFields поля, доступные для сопоставления; имена совпадают с полями API
+var importFields = []importField{
+	docText("foreignName", false, 255, nil, func(d *entity.EsfDocument, v string) { d.ForeignName = v }),
+	docBool("isBranchDataSent", false, func(d *entity.EsfDocument, v bool) { d.IsBranchDataSent = v }),
+	docBool("isPriceWithoutTaxes", true, func(d *entity.EsfDocument, v bool) { d.IsPriceWithoutTaxes = v }),
+	docText("affiliateTin", false, 14, importTinPattern, func(d *entity.EsfDocument, v string) { d.AffiliateTin = v }),
+	docBool("isIndustry", false, func(d *entity.EsfDocument, v bool) { d.IsIndustry = v }),
+	docText("ownedCrmReceiptCode", false, 100, nil, func(d *entity.EsfDocument, v string) { d.OwnedCrmReceiptCode = v }),
+	docText("operationTypeCode", true, 20, nil, func(d *entity.EsfDocument, v string) { d.OperationTypeCode = v }),
+	docDate("deliveryDate", true, func(d *entity.EsfDocument, v time.Time) { d.DeliveryDate = v }),
+	docText("deliveryTypeCode", true, 20, nil, func(d *entity.EsfDocument, v string) { d.DeliveryTypeCode = v }),
+	docBool("isResident", false, func(d *entity.EsfDocument, v bool) { d.IsResident = v }),
+	docText("contractorTin", true, 14, importTinPattern, func(d *entity.EsfDocument, v string) { d.ContractorTin = v }),
+	docText("supplierBankAccount", false, 50, nil, func(d *entity.EsfDocument, v string) { d.SupplierBankAccount = v }),
+	docText("contractorBankAccount", false, 50, nil, func(d *entity.EsfDocument, v string) { d.ContractorBankAccount = v }),
+	docText("currencyCode", true, 3, importCurrencyPattern, func(d *entity.EsfDocument, v string) { d.CurrencyCode = v }),
+	docText("countryCode", false, 2, importCountryPattern, func(d *entity.EsfDocument, v string) { d.CountryCode = v }),
+	docDecimal("currencyRate", false, func(d *entity.EsfDocument, v float64) { d.CurrencyRate = v }),
+	docText("supplyContractNumber", false, 100, nil, func(d *entity.EsfDocument, v string) { d.SupplyContractNumber = v }),
+	docDate("contractStartDate", false, func(d *entity.EsfDocument, v time.Time) { d.ContractStartDate = v }),
+	docText("comment", false, 0, nil, func(d *entity.EsfDocument, v string) { d.Comment = v }),
+	docText("deliveryCode", false, 20, nil, func(d *entity.EsfDocument, v string) { d.DeliveryCode = v }),
+	docText("paymentCode", true, 20, nil, func(d *entity.EsfDocument, v string) { d.PaymentCode = v }),
+	docText("taxRateVATCode", true, 20, nil, func(d *entity.EsfDocument, v string) { d.TaxRateVATCode = v }),
+	docText("personalAccountNumber", false, 50, nil, func(d *entity.EsfDocument, v string) { d.PersonalAccountNumber = v }),
+
+	entryText("unitClassificationCode", true, 20, nil, func(e *entity.EsfEntries, v string) { e.UnitClassificationCode = v }),
+	entryText("salesTaxCode", true, 50, nil, func(e *entity.EsfEntries, v string) { e.SalesTaxCode = v }),
+	entryText("customsAuthorityCode", false, 50, nil, func(e *entity.EsfEntries, v string) { e.CustomsAuthorityCode = v }),
+	entryDecimal("quantity", true, func(e *entity.EsfEntries, v float64) { e.Quantity = v }),
+	entryAmount("price", true, func(e *entity.EsfEntries, v money.Amount) { e.Price = v }),
+	entryAmount("vatAmount", false, func(e *entity.EsfEntries, v money.Amount) { e.VatAmount = v }),
+	entryAmount("salesTaxAmount", false, func(e *entity.EsfEntries, v money.Amount) { e.SalesTaxAmount = v }),
+	entryAmount("amountWithoutTaxes", false, func(e *entity.EsfEntries, v money.Amount) { e.AmountWithoutTaxes = v }),
+	entryAmount("totalAmount", false, func(e *entity.EsfEntries, v money.Amount) { e.TotalAmount = v }),
+}
+
+// importColumn поле, сопоставленное колонке файла
+type importColumn struct {
+	field  importField
+	header string
+	index  int
+}
+
+// importGroup строки файла, составляющие один документ
+type importGroup struct {
+	key  string
+	rows []tabular.Row
+}
+
+// ImportDocumentsTable импортирует документы из CSV или XLSX.
+// В режиме dryRun документы только проверяются; иначе создаются в одной транзакции,
+// если в файле нет ни одной ошибки.
+func (s *esfDocumentService) ImportDocumentsTable(ctx context.Context, orgID uuid.UUID, data []byte, opts models.EsfTableImportOptions) (*models.EsfTableImportReport, error) {
+	s.logger.Info(ctx, "Importing documents from table", logrus.Fields{"org_id": orgID.String(), "format": opts.Format, "dry_run": opts.DryRun})
+
+	readOpts := tabular.Options{Sheet: opts.Sheet}
+	if opts.Delimiter != "" {
+		delimiter, size := utf8.DecodeRuneInString(opts.Delimiter)
+		if size != len(opts.Delimiter) {
+			return nil, apperror.ValidationError("delimiter must be a single character")
+		}
+		readOpts.Delimiter = delimiter
+	}
+
+	table, err := tabular.Read(data, opts.Format, readOpts)
+	if err != nil {
+		return nil, apperror.NewWithDetails(apperror.ErrValidation, "failed to read import file", err.Error())
+	}
+	if len(table.Rows) > maxImportRows {
+		return nil, apperror.ValidationError(fmt.Sprintf("import file must not contain more than %d rows", maxImportRows))
+	}
+
+	report := &models.EsfTableImportReport{
+		DryRun: opts.DryRun,
+		Rows:   len(table.Rows),
+		Errors: []models.EsfImportRowError{},
+	}
+
+	columns, keyColumn, err := resolveImportColumns(table, opts.Mapping, report)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []entity.EsfDocument
+	if len(report.Errors) == 0 {
+		groups := groupImportRows(table, keyColumn, report)
+		if len(groups) > maxImportDocuments {
+			return nil, apperror.ValidationError(fmt.Sprintf("import file must not contain more than %d documents", maxImportDocuments))
+		}
+
+		for _, group := range groups {
+			doc, ok := s.buildImportedDocument(ctx, orgID, group, columns, report)
+			if ok {
+				docs = append(docs, doc)
+			}
+		}
+		report.Documents = len(groups)
+	}
+
+	sort.SliceStable(report.Errors, func(i, j int) bool { return report.Errors[i].Row < report.Errors[j].Row })
+	report.Valid = len(report.Errors) == 0
+
+	if !report.Valid || opts.DryRun {
+		s.logger.Info(ctx, "Table import checked", logrus.Fields{"org_id": orgID.String(), "rows": report.Rows, "errors": len(report.Errors)})
+		return report, nil
+	}
+
+	result, err := s.createImportedDocuments(ctx, orgID, docs)
+	if err != nil {
+		return nil, err
+	}
+	report.Imported = result.Documents
+	return report, nil
+}
+
+// resolveImportColumns находит колонки файла для полей документа.
+// Ошибки сопоставления относятся к строке заголовков.
+func resolveImportColumns(table *tabular.Table, mapping map[string]string, report *models.EsfTableImportReport) ([]importColumn, int, error) {
+	known := map[string]bool{documentKeyField: true}
+	for _, f := range importFields {
+		known[f.name] = true
+	}
+	for field := range mapping {
+		if !known[field] {
+			return nil, -1, apperror.NewWithDetails(apperror.ErrValidation, "unknown field in column mapping", field)
+		}
+	}
+
+	find := func(field string) (string, int) {
+		header, mapped := mapping[field]
+		if !mapped {
+			header = field
+		}
+		index := table.Column(header)
+		if index < 0 && mapped {
+			report.Errors = append(report.Errors, models.EsfImportRowError{
+				Row: 1, Column: header, Field: field, Message: "mapped column not found in file",
+			})
+		}
+		return header, index
+	}
+
+	_, keyColumn := find(documentKeyField)
+
+	var columns []importColumn
+	for _, f := range importFields {
+		header, index := find(f.name)
+		if index < 0 {
+			if f.required {
+				if _, mapped := mapping[f.name]; !mapped {
+					report.Errors = append(report.Errors, models.EsfImportRowError{
+						Row: 1, Column: header, Field: f.name, Message: "required column is missing",
+					})
+				}
+			}
+			continue
+		}
+		columns = append(columns, importColumn{field: f, header: header, index: index})
+	}
+
+	return columns, keyColumn, nil
+}
+
+// groupImportRows объединяет строки с одинаковым ключом документа в порядке первого появления.
+// Без колонки ключа каждая строка - отдельный документ.
+func groupImportRows(table *tabular.Table, keyColumn int, report *models.EsfTableImportReport) []importGroup {
+	var groups []importGroup
+	index := make(map[string]int)
+
+	for _, row := range table.Rows {
+		if keyColumn < 0 {
+			groups = append(groups, importGroup{rows: []tabular.Row{row}})
+			continue
+		}
+
+		key := row.Value(keyColumn)
+		if key == "" {
+			report.Errors = append(report.Errors, models.EsfImportRowError{
+				Row: row.Number, Column: table.Header[keyColumn], Field: documentKeyField, Message: "document key is required",
+			})
+			continue
+		}
+
+		if i, ok := index[key]; ok {
+			groups[i].rows = append(groups[i].rows, row)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, importGroup{key: key, rows: []tabular.Row{row}})
+	}
+
+	return groups
+}
+
+// buildImportedDocument собирает документ из строк группы и проверяет его.
+// Поля документа берутся из первой строки; в остальных строках они должны быть пустыми или совпадать.
+func (s *esfDocumentService) buildImportedDocument(ctx context.Context, orgID uuid.UUID, group importGroup, columns []importColumn, report *models.EsfTableImportReport) (entity.EsfDocument, bool) {
+	errorsBefore := len(report.Errors)
+	fail := func(row int, column importColumn, message string) {
+		report.Errors = append(report.Errors, models.EsfImportRowError{
+			Row: row, Column: column.header, Field: column.field.name, Message: message,
+		})
+	}
+
+	doc := entity.EsfDocument{CatalogEntries: make([]entity.EsfEntries, len(group.rows))}
+	first := group.rows[0]
+
+	for _, column := range columns {
+		if column.field.entry {
+			continue
+		}
+
+		value := first.Value(column.index)
+		if value == "" {
+			if column.field.required {
+				fail(first.Number, column, "value is required")
+			}
+		} else if err := column.field.apply(&doc, nil, value); err != nil {
+			fail(first.Number, column, err.Error())
+		}
+
+		for _, row := range group.rows[1:] {
+			if other := row.Value(column.index); other != "" && other != value {
+				fail(row.Number, column, fmt.Sprintf("value %q differs from %q in row %d of the same document", other, value, first.Number))
+			}
+		}
+	}
+
+	for i, row := range group.rows {
+		for _, column := range columns {
+			if !column.field.entry {
+				continue
+			}
+
+			value := row.Value(column.index)
+			if value == "" {
+				if column.field.required {
+					fail(row.Number, column, "value is required")
+				}
+				continue
+			}
+			if err := column.field.apply(nil, &doc.CatalogEntries[i], value); err != nil {
+				fail(row.Number, column, err.Error())
+			}
+		}
+	}
+
+	if len(report.Errors) > errorsBefore {
+		return doc, false
+	}
+
+	if appErr := s.prepareNewDocument(ctx, orgID, &doc); appErr != nil {
+		if len(appErr.Fields) == 0 {
+			report.Errors = append(report.Errors, models.EsfImportRowError{Row: first.Number, Message: appErr.Message})
+		}
+		for _, field := range appErr.Fields {
+			row, name := first.Number, field.Field
+			var line int
+			var entryField string
+			if n, _ := fmt.Sscanf(field.Field, "catalogEntries[%d].%s", &line, &entryField); n == 2 && line < len(group.rows) {
+				row, name = group.rows[line].Number, entryField
+			}
+			report.Errors = append(report.Errors, models.EsfImportRowError{Row: row, Column: columnHeader(columns, name), Field: name, Message: field.Message})
+		}
+		return doc, false
+	}
+
+	return doc, true
+}
+
+func columnHeader(columns []importColumn, field string) string {
+	for _, column := range columns {
+		if column.field.name == field {
+			return column.header
+		}
+	}
+	return ""
+}
+
+func docText(name string, required bool, maxLength int, pattern *regexp.Regexp, set func(*entity.EsfDocument, string)) importField {
+	return importField{name: name, required: required, apply: func(d *entity.EsfDocument, _ *entity.EsfEntries, v string) error {
+		if err := checkText(v, maxLength, pattern); err != nil {
+			return err
+		}
+		set(d, v)
+		return nil
+	}}
+}
+
+func docBool(name string, required bool, set func(*entity.EsfDocument, bool)) importField {
+	return importField{name: name, required: required, apply: func(d *entity.EsfDocument, _ *entity.EsfEntries, v string) error {
+		b, err := parseImportBool(v)
+		if err != nil {
+			return err
+		}
+		set(d, b)
+		return nil
+	}}
+}
+
+func docDecimal(name string, required bool, set func(*entity.EsfDocument, float64)) importField {
+	return importField{name: name, required: required, apply: func(d *entity.EsfDocument, _ *entity.EsfEntries, v string) error {
+		f, err := parseImportDecimal(v)
+		if err != nil {
+			return err
+		}
+		set(d, f)
+		return nil
+	}}
+}
+
+func docDate(name string, required bool, set func(*entity.EsfDocument, time.Time)) importField {
+	return importField{name: name, required: required, apply: func(d *entity.EsfDocument, _ *entity.EsfEntries, v string) error {
+		t, err := parseImportDate(v)
+		if err != nil {
+			return err
+		}
+		set(d, t)
+		return nil
+	}}
+}
+
+func entryText(name string, required bool, maxLength int, pattern *regexp.Regexp, set func(*entity.EsfEntries, string)) importField {
+	return importField{name: name, entry: true, required: required, apply: func(_ *entity.EsfDocument, e *entity.EsfEntries, v string) error {
+		if err := checkText(v, maxLength, pattern); err != nil {
+			return err
+		}
+		set(e, v)
+		return nil
+	}}
+}
+
+func entryDecimal(name string, required bool, set func(*entity.EsfEntries, float64)) importField {
+	return importField{name: name, entry: true, required: required, apply: func(_ *entity.EsfDocument, e *entity.EsfEntries, v string) error {
+		f, err := parseImportDecimal(v)
+		if err != nil {
+			return err
+		}
+		set(e, f)
+		return nil
+	}}
+}
+
+func entryAmount(name string, required bool, set func(*entity.EsfEntries, money.Amount)) importField {
+	return importField{name: name, entry: true, required: required, apply: func(_ *entity.EsfDocument, e *entity.EsfEntries, v string) error {
+		a, err := parseImportAmount(v)
+		if err != nil {
+			return err
+		}
+		set(e, a)
+		return nil
+	}}
+}
+
+// checkText проверяет длину (0 - без ограничения) и шаблон строкового значения
+func checkText(value string, maxLength int, pattern *regexp.Regexp) error {
+	if n := utf8.RuneCountInString(value); maxLength > 0 && n > maxLength {
+		return fmt.Errorf("value length %d exceeds maximum %d", n, maxLength)
+	}
+	if pattern != nil && !pattern.MatchString(value) {
+		return fmt.Errorf("value %q does not match pattern %s", value, strings.Trim(pattern.String(), "^$"))
+	}
+	return nil
+}
+
+// parseImportBool принимает true/false, 1/0, да/нет, yes/no
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "1", "да", "yes", "y":
+		return true, nil
+	case "false", "0", "нет", "no", "n":
+		return false, nil
+	default:
+		return false, fmt.Errorf("value %q is not a boolean", value)
+	}
+}
+
+// importNumberSpaces пробелы-разделители разрядов, которые ставят табличные редакторы
+var importNumberSpaces = regexp.MustCompile(`[\s\x{00A0}\x{202F}]`)
+
+// parseImportDecimal принимает числа с точкой или запятой и пробелами между разрядами: 1 120,50
+func parseImportDecimal(value string) (float64, error) {
+	normalized := strings.Replace(importNumberSpaces.ReplaceAllString(value, ""), ",", ".", 1)
+	f, err := strconv.ParseFloat(normalized, 64)
+	if err != nil || strings.ContainsAny(normalized, "eEnN") {
+		return 0, fmt.Errorf("value %q is not a number", value)
+	}
+	return f, nil
+}
+
+// parseImportAmount принимает суммы в том же виде, что и parseImportDecimal, без потери точности
+func parseImportAmount(value string) (money.Amount, error) {
+	a, err := money.Parse(importNumberSpaces.ReplaceAllString(value, ""))
+	if err == money.ErrPrecision || err == money.ErrRange {
+		return 0, fmt.Errorf("value %q: %w", value, err)
+	}
+	if err != nil || strings.ContainsAny(value, "eE") {
+		return 0, fmt.Errorf("value %q is not a number", value)
+	}
+	return a, nil
+}
+
+// importDateLayouts поддерживаемые форматы дат
+var importDateLayouts = []string{"2006-01-02", "02.01.2006", "02/01/2006"}
+
+// parseImportDate принимает даты в форматах importDateLayouts и серийные номера дат Excel
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("value %q is not a date (expected YYYY-MM-DD or DD.MM.YYYY)", value)
+}
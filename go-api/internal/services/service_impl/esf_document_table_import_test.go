@@ -0,0 +1,115 @@
+package service_impl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const importTableHeader = "Номер;isPriceWithoutTaxes;operationTypeCode;Дата;deliveryTypeCode;ИНН;currencyCode;paymentCode;taxRateVATCode;unitClassificationCode;salesTaxCode;quantity;Цена\n"
+
+var importTableMapping = map[string]string{
+	"documentKey":   "Номер",
+	"deliveryDate":  "Дата",
+	"contractorTin": "ИНН",
+	"price":         "Цена",
+}
+
+// ========== Table Import Tests ==========
+
+func TestEsfDocumentTableImport_CommitGroupsRows(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+
+	var saved []entity.EsfDocument
+	mockRepo.On("CreateDocuments", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).([]entity.EsfDocument)
+	}).Return(nil)
+
+	data := importTableHeader +
+		"A-1;да;10;15.03.2024;1;01234567890123;KGS;1;1;796;001;10;100\n" +
+		"A-1;;;;;;;;;796;001;2;1 000,50\n" +
+		"B-7;true;10;2024-03-16;1;11111111111111;KGS;1;1;796;001;1;50\n"
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	report, err := service.ImportDocumentsTable(context.Background(), orgID, []byte(data),
+		models.EsfTableImportOptions{Format: "csv", Mapping: importTableMapping})
+	require.NoError(t, err)
+
+	assert.True(t, report.Valid)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 3, report.Rows)
+	assert.Equal(t, 2, report.Documents)
+	require.Len(t, report.Imported, 2)
+
+	require.Len(t, saved, 2)
+	require.Len(t, saved[0].CatalogEntries, 2)
+	assert.Equal(t, "01234567890123", saved[0].ContractorTin)
+	assert.Equal(t, 2024, saved[0].DeliveryDate.Year())
+	assert.Equal(t, money.MustParse("1000.5"), saved[0].CatalogEntries[1].Price)
+	assert.Equal(t, entity.DocumentStatusDraft, saved[0].Status)
+	assert.Len(t, saved[1].CatalogEntries, 1)
+}
+
+func TestEsfDocumentTableImport_DryRunReportsRowErrors(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+
+	data := importTableHeader +
+		"A-1;да;10;15.03.2024;1;01234567890123;KGS;1;1;796;001;10;100\n" +
+		"A-1;;;;;99999999999999;;;;796;001;abc;100\n" +
+		"B-7;true;10;позавчера;1;11111111111111;KGS;1;1;796;001;1;50\n"
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	report, err := service.ImportDocumentsTable(context.Background(), uuid.New(), []byte(data),
+		models.EsfTableImportOptions{Format: "csv", Mapping: importTableMapping, DryRun: true})
+	require.NoError(t, err)
+
+	assert.False(t, report.Valid)
+	assert.True(t, report.DryRun)
+	require.Len(t, report.Errors, 3)
+	assert.Equal(t, models.EsfImportRowError{Row: 3, Column: "ИНН", Field: "contractorTin",
+		Message: `value "99999999999999" differs from "01234567890123" in row 2 of the same document`}, report.Errors[0])
+	assert.Equal(t, 3, report.Errors[1].Row)
+	assert.Equal(t, "quantity", report.Errors[1].Field)
+	assert.Equal(t, 4, report.Errors[2].Row)
+	assert.Equal(t, "Дата", report.Errors[2].Column)
+	mockRepo.AssertNotCalled(t, "CreateDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentTableImport_ReportsMissingColumnsAndTaxErrors(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+
+	report, err := service.ImportDocumentsTable(context.Background(), uuid.New(),
+		[]byte("documentKey,price\n1,100\n"), models.EsfTableImportOptions{Format: "csv", DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	for _, e := range report.Errors {
+		assert.Equal(t, 1, e.Row)
+		assert.Equal(t, "required column is missing", e.Message)
+	}
+
+	data := strings.TrimSuffix(importTableHeader, "\n") + ";Итого\n" +
+		"A-1;true;10;2024-03-15;1;01234567890123;KGS;1;1;796;001;10;100;999\n"
+	mapping := map[string]string{"totalAmount": "Итого"}
+	for k, v := range importTableMapping {
+		mapping[k] = v
+	}
+	report, err = service.ImportDocumentsTable(context.Background(), uuid.New(), []byte(data),
+		models.EsfTableImportOptions{Format: "csv", Mapping: mapping})
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, 2, report.Errors[0].Row)
+	assert.Equal(t, "Итого", report.Errors[0].Column)
+	assert.Equal(t, "totalAmount", report.Errors[0].Field)
+	mockRepo.AssertNotCalled(t, "CreateDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
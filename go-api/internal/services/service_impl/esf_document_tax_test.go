@@ -0,0 +1,119 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Document Tax Calculation Tests ==========
+
+func TestEsfDocumentCreate_FillsCalculatedAmounts(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+
+	var saved *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ForeignName:         "Test",
+		TaxRateVATCode:      "1",
+		IsPriceWithoutTaxes: true,
+		CatalogEntries: []models.EsfEntriesModel{
+			{SalesTaxCode: "001", Quantity: 2, Price: money.MustParse("50")},
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, money.MustParse("100"), saved.CatalogEntries[0].AmountWithoutTaxes)
+	assert.Equal(t, money.MustParse("12"), saved.CatalogEntries[0].VatAmount)
+	assert.Equal(t, money.MustParse("112"), saved.CatalogEntries[0].TotalAmount)
+	assert.Equal(t, money.MustParse("112"), saved.TotalCurrencyValue)
+	assert.Equal(t, money.MustParse("100"), saved.TotalCurrencyValueWithoutTaxes)
+}
+
+func TestEsfDocumentCreate_AcceptsSalesTax(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	total := money.MustParse("114")
+
+	var saved *entity.EsfDocument
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ForeignName:         "Test",
+		TaxRateVATCode:      "1",
+		IsPriceWithoutTaxes: true,
+		TotalCurrencyValue:  &total,
+		CatalogEntries: []models.EsfEntriesModel{
+			{SalesTaxCode: "004", Quantity: 2, Price: money.MustParse("50"), VatAmount: money.MustParse("12"), SalesTaxAmount: money.MustParse("2")},
+		},
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, money.MustParse("2"), saved.CatalogEntries[0].SalesTaxAmount)
+	assert.Equal(t, money.MustParse("114"), saved.CatalogEntries[0].TotalAmount)
+	assert.Equal(t, money.MustParse("114"), saved.TotalCurrencyValue)
+}
+
+func TestEsfDocumentCreate_RejectsMismatchedAmounts(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	total := money.MustParse("500")
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ForeignName:         "Test",
+		TaxRateVATCode:      "1",
+		IsPriceWithoutTaxes: true,
+		TotalCurrencyValue:  &total,
+		CatalogEntries: []models.EsfEntriesModel{
+			{SalesTaxCode: "001", Quantity: 2, Price: money.MustParse("50"), VatAmount: money.MustParse("10")},
+		},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 2)
+	assert.Equal(t, "catalogEntries[0].vatAmount", appErr.Fields[0].Field)
+	assert.Equal(t, "totalCurrencyValue", appErr.Fields[1].Field)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentCreate_RejectsTotalsWithoutEntries(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+	total := money.MustParse("500")
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{
+		ForeignName:        "Test",
+		TaxRateVATCode:     "1",
+		TotalCurrencyValue: &total,
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "totalCurrencyValue", appErr.Fields[0].Field)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
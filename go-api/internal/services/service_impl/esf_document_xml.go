@@ -0,0 +1,113 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/esfxml"
+	"github.com/sirupsen/logrus"
+)
+
+// maxImportDocuments ограничивает количество документов в одном файле импорта
+const maxImportDocuments = 500
+
+// ExportDocumentXML возвращает документ в XML формате обмена налоговой службы
+func (s *esfDocumentService) ExportDocumentXML(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]byte, error) {
+	s.logger.Info(ctx, "Exporting document to XML", logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+
+	doc, err := s.repo.GetDocumentByID(ctx, orgID, id)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to fetch document", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, repositoryError("fetching document", err)
+	}
+
+	out, err := esfxml.Marshal(*doc)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to encode document to XML", err, logrus.Fields{"org_id": orgID.String(), "doc_id": id.String()})
+		return nil, apperror.New(apperror.ErrInternal, "failed to encode document").WithError(err)
+	}
+	return out, nil
+}
+
+// ImportDocumentsXML создает черновики документов из XML файла обмена (receipt или receipts).
+// Файл проверяется по схеме и налоговым расчетам целиком до создания документов.
+func (s *esfDocumentService) ImportDocumentsXML(ctx context.Context, orgID uuid.UUID, data []byte) (*models.EsfDocumentImportResponse, error) {
+	s.logger.Info(ctx, "Importing documents from XML", logrus.Fields{"org_id": orgID.String(), "size": len(data)})
+
+	docs, err := esfxml.Unmarshal(data)
+	if err != nil {
+		fields := []apperror.FieldError{{Field: "receipts", Message: err.Error()}}
+		if schemaErr, ok := err.(*esfxml.SchemaError); ok {
+			fields = fields[:0]
+			for _, issue := range schemaErr.Issues {
+				fields = append(fields, apperror.FieldError{Field: receiptFieldName(issue.Receipt, issue.Field), Message: issue.Message})
+			}
+		}
+		s.logger.Warn(ctx, "XML import failed schema validation", logrus.Fields{"org_id": orgID.String(), "issues": len(fields)})
+		return nil, apperror.FieldValidationError("xml document does not match the ESF schema", fields)
+	}
+
+	return s.importDocuments(ctx, orgID, docs, receiptFieldName)
+}
+
+// importDocuments рассчитывает налоги и атомарно создает импортированные документы.
+// fieldName формирует имя поля ошибки с учетом номера документа в файле.
+func (s *esfDocumentService) importDocuments(ctx context.Context, orgID uuid.UUID, docs []entity.EsfDocument, fieldName func(doc int, field string) string) (*models.EsfDocumentImportResponse, error) {
+	if len(docs) > maxImportDocuments {
+		return nil, apperror.ValidationError(fmt.Sprintf("import file must not contain more than %d documents", maxImportDocuments))
+	}
+
+	var fields []apperror.FieldError
+	for i := range docs {
+		if appErr := s.prepareNewDocument(ctx, orgID, &docs[i]); appErr != nil {
+			for _, field := range appErr.Fields {
+				fields = append(fields, apperror.FieldError{Field: fieldName(i, field.Field), Message: field.Message})
+			}
+		}
+	}
+	if len(fields) > 0 {
+		s.logger.Warn(ctx, "Imported documents validation failed", logrus.Fields{"org_id": orgID.String(), "issues": len(fields)})
+		return nil, apperror.FieldValidationError("imported documents failed validation", fields)
+	}
+
+	return s.createImportedDocuments(ctx, orgID, docs)
+}
+
+// prepareNewDocument готовит новый документ к созданию: присваивает идентификатор,
+// статус черновика, проверяет коды и рассчитывает налоги
+func (s *esfDocumentService) prepareNewDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) *apperror.AppError {
+	doc.ID = uuid.New()
+	doc.Status = entity.DocumentStatusDraft
+	return s.validateDocument(ctx, orgID, doc)
+}
+
+// createImportedDocuments атомарно сохраняет проверенные документы
+func (s *esfDocumentService) createImportedDocuments(ctx context.Context, orgID uuid.UUID, docs []entity.EsfDocument) (*models.EsfDocumentImportResponse, error) {
+	if err := s.repo.CreateDocuments(ctx, orgID, docs); err != nil {
+		s.logger.Error(ctx, "Failed to create imported documents", err, logrus.Fields{"org_id": orgID.String(), "count": len(docs)})
+		return nil, repositoryError("creating imported documents", err)
+	}
+
+	result := &models.EsfDocumentImportResponse{
+		Imported:  len(docs),
+		Documents: make([]models.EsfCreateDocumentResponse, len(docs)),
+	}
+	for i := range docs {
+		result.Documents[i] = models.EsfCreateDocumentResponse{ResponseId: "success", DocumentUuid: docs[i].ID.String()}
+	}
+
+	s.logger.Info(ctx, "Documents imported successfully", logrus.Fields{"org_id": orgID.String(), "count": len(docs)})
+	return result, nil
+}
+
+// receiptFieldName формирует имя поля документа из XML файла для ошибок валидации
+func receiptFieldName(receipt int, field string) string {
+	if receipt < 0 {
+		return field
+	}
+	return fmt.Sprintf("receipts[%d].%s", receipt, field)
+}
@@ -0,0 +1,120 @@
+package service_impl
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const importReceipt = `<receipt>
+    <isBranchDataSent>false</isBranchDataSent>
+    <isPriceWithoutTaxes>true</isPriceWithoutTaxes>
+    <operationTypeCode>10</operationTypeCode>
+    <deliveryDate>2024-03-15</deliveryDate>
+    <deliveryTypeCode>1</deliveryTypeCode>
+    <isResident>true</isResident>
+    <contractorTin>{{tin}}</contractorTin>
+    <currencyCode>KGS</currencyCode>
+    <paymentCode>1</paymentCode>
+    <taxRateVATCode>1</taxRateVATCode>
+    <catalogEntries>
+      <catalogEntry>
+        <unitClassificationCode>796</unitClassificationCode>
+        <salesTaxCode>001</salesTaxCode>
+        <quantity>10</quantity>
+        <price>100</price>
+        <totalAmount>{{total}}</totalAmount>
+      </catalogEntry>
+    </catalogEntries>
+  </receipt>`
+
+func importXML(receipts ...[2]string) []byte {
+	var b strings.Builder
+	b.WriteString("<receipts>")
+	for _, r := range receipts {
+		b.WriteString(strings.NewReplacer("{{tin}}", r[0], "{{total}}", r[1]).Replace(importReceipt))
+	}
+	b.WriteString("</receipts>")
+	return []byte(b.String())
+}
+
+// ========== XML Exchange Tests ==========
+
+func TestEsfDocumentXML_ImportCreatesDrafts(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+
+	var saved []entity.EsfDocument
+	mockRepo.On("CreateDocuments", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).([]entity.EsfDocument)
+	}).Return(nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	result, err := service.ImportDocumentsXML(context.Background(), orgID,
+		importXML([2]string{"01234567890123", ""}, [2]string{"11111111111111", "1120"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Imported)
+	require.Len(t, saved, 2)
+	for i, doc := range saved {
+		assert.Equal(t, entity.DocumentStatusDraft, doc.Status)
+		assert.NotEqual(t, uuid.Nil, doc.ID)
+		assert.Equal(t, doc.ID.String(), result.Documents[i].DocumentUuid)
+		assert.Equal(t, money.MustParse("120"), doc.CatalogEntries[0].VatAmount)
+		assert.Equal(t, money.MustParse("1120"), doc.TotalCurrencyValue)
+	}
+}
+
+func TestEsfDocumentXML_ImportMapsSchemaErrors(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+
+	_, err := service.ImportDocumentsXML(context.Background(), uuid.New(),
+		importXML([2]string{"01234567890123", ""}, [2]string{"bad", "abc"}))
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 2)
+	assert.Equal(t, "receipts[1].contractorTin", appErr.Fields[0].Field)
+	assert.Equal(t, "receipts[1].catalogEntries[0].totalAmount", appErr.Fields[1].Field)
+	mockRepo.AssertNotCalled(t, "CreateDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentXML_ImportMapsTaxErrors(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+
+	_, err := service.ImportDocumentsXML(context.Background(), uuid.New(),
+		importXML([2]string{"01234567890123", "1120"}, [2]string{"01234567890123", "999"}))
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "receipts[1].catalogEntries[0].totalAmount", appErr.Fields[0].Field)
+	mockRepo.AssertNotCalled(t, "CreateDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfDocumentXML_Export(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, docID := uuid.New(), uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(newTestDocument(docID, withStatus(entity.DocumentStatusAccepted)), nil)
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	out, err := service.ExportDocumentXML(context.Background(), orgID, docID)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(out), "<?xml"))
+	assert.Contains(t, string(out), "<contractorTin>01234567890123</contractorTin>")
+	assert.Contains(t, string(out), "<totalAmount>1120.00</totalAmount>")
+}
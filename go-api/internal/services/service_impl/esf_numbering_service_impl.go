@@ -0,0 +1,99 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/numbering"
+	"github.com/sirupsen/logrus"
+)
+
+type esfNumberingService struct {
+	repo   repository.EsfNumberingRepository
+	logger *logger.Logger
+}
+
+func NewEsfNumberingService(repo repository.EsfNumberingRepository, log *logrus.Logger) services.EsfNumberingService {
+	return &esfNumberingService{
+		repo:   repo,
+		logger: logger.New(log),
+	}
+}
+
+// GetNumbering возвращает настройки нумерации и счетчики по годам
+func (s *esfNumberingService) GetNumbering(ctx context.Context, orgID uuid.UUID) (*models.EsfNumberingModel, error) {
+	settings, err := s.repo.GetNumberingSettings(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching numbering settings", err)
+	}
+	sequences, err := s.repo.GetNumberSequences(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching number sequences", err)
+	}
+	return numberingToModel(settings, sequences), nil
+}
+
+// UpdateNumbering проверяет и сохраняет настройки нумерации.
+// Новые настройки применяются к следующим документам; присвоенные номера не меняются.
+func (s *esfNumberingService) UpdateNumbering(ctx context.Context, orgID uuid.UUID, req *models.EsfNumberingRequest) (*models.EsfNumberingModel, error) {
+	s.logger.Info(ctx, "Updating numbering settings", logrus.Fields{"org_id": orgID.String(), "prefix": req.Prefix, "width": req.Width})
+
+	settings := &entity.EsfNumberingSettings{
+		Prefix: strings.TrimSpace(req.Prefix),
+		Width:  req.Width,
+	}
+
+	var fields []apperror.FieldError
+	if err := numbering.ValidatePrefix(settings.Prefix); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "prefix", Message: err.Error()})
+	}
+	if err := numbering.ValidateWidth(settings.Width); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "width", Message: err.Error()})
+	}
+	if len(fields) > 0 {
+		return nil, apperror.FieldValidationError("invalid numbering settings", fields)
+	}
+
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		settings.UpdatedBy = fmt.Sprint(username)
+	}
+	if err := s.repo.SaveNumberingSettings(ctx, orgID, settings); err != nil {
+		s.logger.Error(ctx, "Failed to save numbering settings", err, logrus.Fields{"org_id": orgID.String()})
+		return nil, repositoryError("saving numbering settings", err)
+	}
+
+	s.logger.Info(ctx, "Numbering settings saved successfully", logrus.Fields{"org_id": orgID.String()})
+	return s.GetNumbering(ctx, orgID)
+}
+
+func numberingToModel(settings *entity.EsfNumberingSettings, sequences []entity.EsfNumberSequence) *models.EsfNumberingModel {
+	result := &models.EsfNumberingModel{
+		Prefix:    settings.Prefix,
+		Width:     settings.Width,
+		Sequences: make([]models.EsfNumberSequenceModel, len(sequences)),
+		UpdatedBy: settings.UpdatedBy,
+	}
+	if !settings.UpdatedAt.IsZero() {
+		result.UpdatedAt = &settings.UpdatedAt
+	}
+
+	year := time.Now().Year()
+	var next int64 = 1
+	for i, seq := range sequences {
+		result.Sequences[i] = models.EsfNumberSequenceModel{Year: seq.Year, LastNumber: seq.LastNumber}
+		if seq.Year == year {
+			next = seq.LastNumber + 1
+		}
+	}
+	result.NextNumber = numbering.Format(settings.Prefix, year, next, settings.Width)
+	return result
+}
@@ -0,0 +1,113 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockNumberingRepository мок настроек нумерации
+type MockNumberingRepository struct {
+	mock.Mock
+}
+
+func (m *MockNumberingRepository) GetNumberingSettings(ctx context.Context, orgID uuid.UUID) (*entity.EsfNumberingSettings, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfNumberingSettings), args.Error(1)
+}
+
+func (m *MockNumberingRepository) SaveNumberingSettings(ctx context.Context, orgID uuid.UUID, settings *entity.EsfNumberingSettings) error {
+	args := m.Called(ctx, orgID, settings)
+	return args.Error(0)
+}
+
+func (m *MockNumberingRepository) GetNumberSequences(ctx context.Context, orgID uuid.UUID) ([]entity.EsfNumberSequence, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.EsfNumberSequence), args.Error(1)
+}
+
+// ========== Document Numbering Tests ==========
+
+func TestEsfNumbering_GetReturnsNextNumber(t *testing.T) {
+	mockRepo := new(MockNumberingRepository)
+	orgID := uuid.New()
+	year := time.Now().Year()
+
+	mockRepo.On("GetNumberingSettings", mock.Anything, orgID).Return(&entity.EsfNumberingSettings{Prefix: "INV", Width: 6}, nil)
+	mockRepo.On("GetNumberSequences", mock.Anything, orgID).Return([]entity.EsfNumberSequence{
+		{Year: year, LastNumber: 122},
+		{Year: year - 1, LastNumber: 4810},
+	}, nil)
+
+	service := NewEsfNumberingService(mockRepo, logrus.New())
+	result, err := service.GetNumbering(context.Background(), orgID)
+	require.NoError(t, err)
+
+	assert.Equal(t, fmt.Sprintf("INV-%d-000123", year), result.NextNumber)
+	assert.Len(t, result.Sequences, 2)
+	assert.Nil(t, result.UpdatedAt)
+}
+
+func TestEsfNumbering_GetStartsNewYear(t *testing.T) {
+	mockRepo := new(MockNumberingRepository)
+	orgID := uuid.New()
+	year := time.Now().Year()
+
+	mockRepo.On("GetNumberingSettings", mock.Anything, orgID).Return(&entity.EsfNumberingSettings{Width: 4}, nil)
+	mockRepo.On("GetNumberSequences", mock.Anything, orgID).Return([]entity.EsfNumberSequence{{Year: year - 1, LastNumber: 77}}, nil)
+
+	service := NewEsfNumberingService(mockRepo, logrus.New())
+	result, err := service.GetNumbering(context.Background(), orgID)
+	require.NoError(t, err)
+
+	assert.Equal(t, fmt.Sprintf("%d-0001", year), result.NextNumber)
+}
+
+func TestEsfNumbering_UpdateValidatesSettings(t *testing.T) {
+	mockRepo := new(MockNumberingRepository)
+	service := NewEsfNumberingService(mockRepo, logrus.New())
+
+	_, err := service.UpdateNumbering(context.Background(), uuid.New(), &models.EsfNumberingRequest{Prefix: "СФ 01", Width: 0})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 2)
+	assert.Equal(t, "prefix", appErr.Fields[0].Field)
+	assert.Equal(t, "width", appErr.Fields[1].Field)
+	mockRepo.AssertNotCalled(t, "SaveNumberingSettings", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfNumbering_UpdateSavesSettings(t *testing.T) {
+	mockRepo := new(MockNumberingRepository)
+	orgID := uuid.New()
+
+	mockRepo.On("SaveNumberingSettings", mock.Anything, orgID, mock.Anything).Return(nil)
+	mockRepo.On("GetNumberingSettings", mock.Anything, orgID).Return(&entity.EsfNumberingSettings{Prefix: "SF", Width: 5}, nil)
+	mockRepo.On("GetNumberSequences", mock.Anything, orgID).Return([]entity.EsfNumberSequence{}, nil)
+
+	service := NewEsfNumberingService(mockRepo, logrus.New())
+	result, err := service.UpdateNumbering(context.Background(), orgID, &models.EsfNumberingRequest{Prefix: " SF ", Width: 5})
+	require.NoError(t, err)
+
+	assert.Equal(t, fmt.Sprintf("SF-%d-00001", time.Now().Year()), result.NextNumber)
+	mockRepo.AssertCalled(t, "SaveNumberingSettings", mock.Anything, orgID, mock.MatchedBy(func(s *entity.EsfNumberingSettings) bool {
+		return s.Prefix == "SF" && s.Width == 5
+	}))
+}
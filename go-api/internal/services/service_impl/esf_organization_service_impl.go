@@ -15,6 +15,7 @@ import (
 	"github.com/rusgainew/tunduck-app/pkg/entity"
 	"github.com/rusgainew/tunduck-app/pkg/logger"
 	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/tin"
 	"github.com/sirupsen/logrus"
 )
 
@@ -58,6 +59,7 @@ func (s *esfOrganizationServiceImpl) GetAllOrganizations(ctx context.Context) ([
 			ID:          org.ID.String(),
 			Name:        org.Name,
 			Description: org.Description,
+			Tin:         org.Tin,
 			Token:       org.Token,
 			DBName:      org.DBName,
 		}
@@ -82,6 +84,7 @@ func (s *esfOrganizationServiceImpl) GetOrganizationByID(ctx context.Context, id
 					ID:          org.ID.String(),
 					Name:        org.Name,
 					Description: org.Description,
+					Tin:         org.Tin,
 					Token:       org.Token,
 					DBName:      org.DBName,
 				}, nil
@@ -110,6 +113,7 @@ func (s *esfOrganizationServiceImpl) GetOrganizationByID(ctx context.Context, id
 		ID:          org.ID.String(),
 		Name:        org.Name,
 		Description: org.Description,
+		Tin:         org.Tin,
 		Token:       org.Token,
 		DBName:      org.DBName,
 	}
@@ -127,6 +131,10 @@ func (s *esfOrganizationServiceImpl) CreateOrganization(ctx context.Context, org
 		s.logger.Warn(ctx, "Organization name is required", logrus.Fields{})
 		return uuid.Nil, "", apperror.ValidationError("organization name is required")
 	}
+	org.Tin = strings.TrimSpace(org.Tin)
+	if err := s.validateTin(ctx, uuid.Nil, org.Tin); err != nil {
+		return uuid.Nil, "", err
+	}
 
 	// Формируем имя базы данных
 	dbName := sanitizeDatabaseName(org.Name) + "_db"
@@ -143,6 +151,7 @@ func (s *esfOrganizationServiceImpl) CreateOrganization(ctx context.Context, org
 		ID:          uuid.New(),
 		Name:        org.Name,
 		Description: org.Description,
+		Tin:         org.Tin,
 		Token:       org.Token,
 		DBName:      dbName,
 	}
@@ -156,6 +165,28 @@ func (s *esfOrganizationServiceImpl) CreateOrganization(ctx context.Context, org
 	return entity.ID, dbName, nil
 }
 
+// validateTin проверяет ИНН организации: по нему документы доставляются покупателям,
+// поэтому ИНН не может принадлежать двум организациям. Пустой ИНН допускается.
+func (s *esfOrganizationServiceImpl) validateTin(ctx context.Context, id uuid.UUID, orgTin string) error {
+	if orgTin == "" {
+		return nil
+	}
+	if err := tin.Validate(orgTin); err != nil {
+		return apperror.FieldValidationError("invalid organization", []apperror.FieldError{{Field: "tin", Message: err.Error()}})
+	}
+
+	existing, err := s.repo.GetByTin(ctx, orgTin)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to check organization TIN", err, logrus.Fields{"tin": orgTin})
+		return apperror.DatabaseError("checking organization TIN", err)
+	}
+	if existing != nil && existing.ID != id {
+		return apperror.New(apperror.ErrOrgExists, "organization with this TIN already exists").
+			WithFieldErrors([]apperror.FieldError{{Field: "tin", Message: "TIN is already used by organization " + existing.Name}})
+	}
+	return nil
+}
+
 // sanitizeDatabaseName очищает имя от недопустимых символов для имени БД
 // Оставляет только буквы, цифры и подчеркивания, приводит к нижнему регистру
 func sanitizeDatabaseName(name string) string {
@@ -187,11 +218,17 @@ func (s *esfOrganizationServiceImpl) UpdateOrganization(ctx context.Context, org
 		s.logger.Warn(ctx, "Organization name is required", logrus.Fields{})
 		return apperror.ValidationError("organization name is required")
 	}
+	org.Tin = strings.TrimSpace(org.Tin)
+	id, _ := uuid.Parse(org.ID)
+	if err := s.validateTin(ctx, id, org.Tin); err != nil {
+		return err
+	}
 
 	// Конвертируем model в entity для обновления
 	entity := &entity.EstOrganization{
 		Name:        org.Name,
 		Description: org.Description,
+		Tin:         org.Tin,
 		Token:       org.Token,
 		DBName:      org.DBName,
 	}
@@ -238,6 +275,7 @@ func (s *esfOrganizationServiceImpl) GetAllOrganizationsPaginated(ctx context.Co
 			ID:          org.ID.String(),
 			Name:        org.Name,
 			Description: org.Description,
+			Tin:         org.Tin,
 			Token:       org.Token,
 			DBName:      org.DBName,
 		}
@@ -1,10 +1,16 @@
 package service_impl
 
 import (
+	"context"
 	"testing"
 
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // ========== EsfOrganizationService Tests ==========
@@ -17,5 +23,25 @@ func TestEsfOrganizationService_Framework(t *testing.T) {
 	assert.NotNil(t, service)
 }
 
+func TestEsfOrganizationService_ValidatesTin(t *testing.T) {
+	existing := &entity.EstOrganization{ID: uuid.New(), Name: "ОсОО Ала-Тоо", Tin: "01503201910012"}
+	service := NewEsfOrganizationService(&stubOrganizationRepository{org: existing}, logrus.New())
+
+	_, _, err := service.CreateOrganization(context.Background(), &models.EsfOrganizationModel{Name: "Дубль", Tin: " 01503201910012 "})
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrOrgExists, appErr.Code)
+
+	_, _, err = service.CreateOrganization(context.Background(), &models.EsfOrganizationModel{Name: "Ошибка", Tin: "123"})
+	appErr, ok = err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "tin", appErr.Fields[0].Field)
+
+	// Организация сохраняет собственный ИНН при изменении
+	err = service.UpdateOrganization(context.Background(), &models.EsfOrganizationModel{ID: existing.ID.String(), Name: "ОсОО Ала-Тоо", Tin: existing.Tin})
+	assert.NoError(t, err)
+}
+
 // Note: Детальные unit тесты могут быть добавлены
 // после финализации структур entity и interfaces
@@ -0,0 +1,406 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/recurrence"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRecurringCatchUp ограничивает число пропущенных периодов, выпускаемых по шаблону за один запуск
+// (например, после простоя планировщика); остальные периоды выпускаются при следующих запусках
+const maxRecurringCatchUp = 12
+
+// maxRecurringPreview ограничивает число выпусков в предпросмотре
+const maxRecurringPreview = 36
+
+type esfRecurringService struct {
+	repo      repository.EsfRecurringRepository
+	documents services.EsfDocumentService
+	logger    *logger.Logger
+}
+
+// NewEsfRecurringService создает сервис шаблонов; документы шаблонов проверяет и создает documents
+func NewEsfRecurringService(repo repository.EsfRecurringRepository, documents services.EsfDocumentService, log *logrus.Logger) services.EsfRecurringService {
+	return &esfRecurringService{
+		repo:      repo,
+		documents: documents,
+		logger:    logger.New(log),
+	}
+}
+
+// GetRecurringTemplates возвращает шаблоны повторяющихся документов организации
+func (s *esfRecurringService) GetRecurringTemplates(ctx context.Context, orgID uuid.UUID) ([]models.EsfRecurringTemplateModel, error) {
+	templates, err := s.repo.GetRecurringTemplates(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching recurring templates", err)
+	}
+
+	result := make([]models.EsfRecurringTemplateModel, 0, len(templates))
+	for i := range templates {
+		model, err := recurringTemplateToModel(&templates[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *model)
+	}
+	return result, nil
+}
+
+// GetRecurringTemplate возвращает шаблон повторяющегося документа
+func (s *esfRecurringService) GetRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfRecurringTemplateModel, error) {
+	template, err := s.repo.GetRecurringTemplateByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching recurring template", err)
+	}
+	return recurringTemplateToModel(template)
+}
+
+// CreateRecurringTemplate проверяет и сохраняет шаблон повторяющегося документа
+func (s *esfRecurringService) CreateRecurringTemplate(ctx context.Context, orgID uuid.UUID, req *models.EsfRecurringTemplateRequest) (*models.EsfRecurringTemplateModel, error) {
+	s.logger.Info(ctx, "Creating recurring template", logrus.Fields{"org_id": orgID.String()})
+
+	template, err := s.recurringTemplateFromRequest(ctx, orgID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRecurringTemplate(ctx, orgID, template); err != nil {
+		return nil, repositoryError("creating recurring template", err)
+	}
+
+	s.logger.Info(ctx, "Recurring template created successfully", logrus.Fields{"org_id": orgID.String(), "template_id": template.ID.String()})
+	return s.GetRecurringTemplate(ctx, orgID, template.ID)
+}
+
+// UpdateRecurringTemplate заменяет шаблон; дата следующего выпуска рассчитывается по новому правилу.
+// Периоды, за которые документ уже создан, повторно не выпускаются.
+func (s *esfRecurringService) UpdateRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID, req *models.EsfRecurringTemplateRequest) (*models.EsfRecurringTemplateModel, error) {
+	s.logger.Info(ctx, "Updating recurring template", logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+
+	if _, err := s.repo.GetRecurringTemplateByID(ctx, orgID, id); err != nil {
+		return nil, repositoryError("fetching recurring template", err)
+	}
+
+	template, err := s.recurringTemplateFromRequest(ctx, orgID, req)
+	if err != nil {
+		return nil, err
+	}
+	template.ID = id
+
+	if err := s.repo.UpdateRecurringTemplate(ctx, orgID, template); err != nil {
+		return nil, repositoryError("updating recurring template", err)
+	}
+
+	s.logger.Info(ctx, "Recurring template updated successfully", logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+	return s.GetRecurringTemplate(ctx, orgID, id)
+}
+
+// DeleteRecurringTemplate удаляет шаблон; созданные по нему документы сохраняются
+func (s *esfRecurringService) DeleteRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	s.logger.Info(ctx, "Deleting recurring template", logrus.Fields{"org_id": orgID.String(), "template_id": id.String()})
+
+	if err := s.repo.DeleteRecurringTemplate(ctx, orgID, id); err != nil {
+		return repositoryError("deleting recurring template", err)
+	}
+	return nil
+}
+
+// PreviewRecurringTemplate возвращает до count предстоящих выпусков по шаблону
+func (s *esfRecurringService) PreviewRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID, count int) ([]models.EsfRecurringPreviewItem, error) {
+	if count < 1 || count > maxRecurringPreview {
+		return nil, apperror.ValidationError(fmt.Sprintf("count must be between 1 and %d", maxRecurringPreview))
+	}
+
+	template, err := s.repo.GetRecurringTemplateByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching recurring template", err)
+	}
+
+	items := []models.EsfRecurringPreviewItem{}
+	if !template.IsActive || template.NextRunDate == nil {
+		return items, nil
+	}
+
+	today := recurrence.Day(time.Now())
+	for _, date := range recurringRule(template).Upcoming(*template.NextRunDate, count) {
+		status := models.RecurringRunScheduled
+		if !date.After(today) {
+			status = models.RecurringRunDue
+		}
+		items = append(items, models.EsfRecurringPreviewItem{Period: recurrence.Period(date), RunDate: date, Status: status})
+	}
+	return items, nil
+}
+
+// GetRecurringRuns возвращает выполненные выпуски по шаблону
+func (s *esfRecurringService) GetRecurringRuns(ctx context.Context, orgID uuid.UUID, id uuid.UUID) ([]models.EsfRecurringRunModel, error) {
+	if _, err := s.repo.GetRecurringTemplateByID(ctx, orgID, id); err != nil {
+		return nil, repositoryError("fetching recurring template", err)
+	}
+
+	runs, err := s.repo.GetRecurringRuns(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching recurring runs", err)
+	}
+
+	result := make([]models.EsfRecurringRunModel, len(runs))
+	for i, run := range runs {
+		result[i] = recurringRunToModel(&run)
+	}
+	return result, nil
+}
+
+// RunRecurringTemplates создает черновики по шаблонам, дата выпуска которых наступила к now.
+// Каждый период резервируется до создания документа, поэтому повторный или параллельный запуск
+// не создает второй документ за период. Если документ создать не удалось, резерв снимается,
+// дата выпуска не сдвигается и выпуск повторяется при следующем запуске.
+func (s *esfRecurringService) RunRecurringTemplates(ctx context.Context, orgID uuid.UUID, now time.Time) (*models.EsfRecurringRunReport, error) {
+	today := recurrence.Day(now)
+	templates, err := s.repo.GetDueRecurringTemplates(ctx, orgID, today)
+	if err != nil {
+		return nil, repositoryError("fetching due recurring templates", err)
+	}
+
+	report := &models.EsfRecurringRunReport{
+		Created: []models.EsfRecurringRunModel{},
+		Failed:  []models.EsfRecurringRunFailure{},
+	}
+	for i := range templates {
+		if err := s.runRecurringTemplate(ctx, orgID, &templates[i], today, report); err != nil {
+			return report, err
+		}
+	}
+
+	if len(templates) > 0 {
+		s.logger.Info(ctx, "Recurring templates processed", logrus.Fields{
+			"org_id":  orgID.String(),
+			"created": len(report.Created),
+			"failed":  len(report.Failed),
+			"skipped": report.Skipped,
+		})
+	}
+	return report, nil
+}
+
+// runRecurringTemplate выпускает наступившие периоды шаблона и сохраняет дату следующего выпуска
+func (s *esfRecurringService) runRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate, today time.Time, report *models.EsfRecurringRunReport) error {
+	rule := recurringRule(template)
+	next := template.NextRunDate
+	lastError := ""
+
+	for issued := 0; next != nil && !next.After(today) && issued < maxRecurringCatchUp; issued++ {
+		runDate := *next
+		period := recurrence.Period(runDate)
+
+		created, err := s.issueRecurringDocument(ctx, orgID, template, runDate)
+		if err != nil {
+			if appErr, ok := err.(*apperror.AppError); ok && appErr.Code == apperror.ErrDatabase {
+				return err
+			}
+			lastError = recurringRunError(err)
+			s.logger.Warn(ctx, "Recurring document was not created", logrus.Fields{
+				"org_id":      orgID.String(),
+				"template_id": template.ID.String(),
+				"period":      period,
+				"error":       lastError,
+			})
+			report.Failed = append(report.Failed, models.EsfRecurringRunFailure{TemplateID: template.ID, Period: period, Error: lastError})
+			break
+		}
+		if created != nil {
+			report.Created = append(report.Created, recurringRunToModel(created))
+		} else {
+			report.Skipped++
+		}
+
+		if date, ok := rule.Next(runDate); ok {
+			next = &date
+		} else {
+			next = nil
+		}
+	}
+
+	if err := s.repo.SaveRecurringSchedule(ctx, orgID, template.ID, next, lastError); err != nil {
+		return repositoryError("saving recurring schedule", err)
+	}
+	return nil
+}
+
+// issueRecurringDocument резервирует период и создает черновик по шаблону.
+// Возвращает nil без ошибки, если документ за период уже был создан.
+func (s *esfRecurringService) issueRecurringDocument(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate, runDate time.Time) (*entity.EsfRecurringRun, error) {
+	var req models.EsfCreateDocumentRequest
+	if err := json.Unmarshal([]byte(template.Document), &req); err != nil {
+		return nil, apperror.NewWithDetails(apperror.ErrInvalidDocument, "recurring template document is invalid", err.Error())
+	}
+	req.DeliveryDate = &runDate
+
+	run := &entity.EsfRecurringRun{TemplateID: template.ID, Period: recurrence.Period(runDate), RunDate: runDate}
+	reserved, err := s.repo.ReserveRecurringRun(ctx, orgID, run)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, nil
+	}
+
+	resp, err := s.documents.CreateDocument(ctx, orgID, &req)
+	if err != nil {
+		if releaseErr := s.repo.DeleteRecurringRun(ctx, orgID, run.ID); releaseErr != nil {
+			s.logger.Error(ctx, "Failed to release recurring run", releaseErr, logrus.Fields{"org_id": orgID.String(), "run_id": run.ID.String()})
+		}
+		return nil, err
+	}
+
+	documentID, err := uuid.Parse(resp.DocumentUuid)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "invalid created document id").WithError(err)
+	}
+	if err := s.repo.CompleteRecurringRun(ctx, orgID, run.ID, documentID); err != nil {
+		return nil, err
+	}
+	run.DocumentID = &documentID
+	return run, nil
+}
+
+// recurringTemplateFromRequest проверяет запрос и формирует шаблон с датой первого выпуска
+func (s *esfRecurringService) recurringTemplateFromRequest(ctx context.Context, orgID uuid.UUID, req *models.EsfRecurringTemplateRequest) (*entity.EsfRecurringTemplate, error) {
+	template := &entity.EsfRecurringTemplate{
+		Name:       strings.TrimSpace(req.Name),
+		Frequency:  strings.ToLower(strings.TrimSpace(req.Rule.Frequency)),
+		Interval:   req.Rule.Interval,
+		DayOfMonth: req.Rule.DayOfMonth,
+		StartDate:  recurrence.Day(req.Rule.StartDate),
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+	if template.Interval == 0 {
+		template.Interval = 1
+	}
+	if req.Rule.EndDate != nil {
+		end := recurrence.Day(*req.Rule.EndDate)
+		template.EndDate = &end
+	}
+
+	var fields []apperror.FieldError
+	if template.Name == "" {
+		fields = append(fields, apperror.FieldError{Field: "name", Message: "name is required"})
+	}
+	if err := recurringRule(template).Validate(); err != nil {
+		fields = append(fields, apperror.FieldError{Field: "rule", Message: err.Error()})
+	}
+	if len(fields) > 0 {
+		return nil, apperror.FieldValidationError("invalid recurring template", fields)
+	}
+
+	// Документ проверяется так же, как при создании, кроме курса валюты: он определяется на дату выпуска
+	document := req.Document
+	document.ID, document.Number, document.Status = nil, "", ""
+	document.ExternalDocumentUuid, document.OriginalDocumentId, document.CorrectionReason = "", nil, ""
+	document.DeliveryDate = nil
+	if err := s.documents.ValidateDocumentDraft(ctx, orgID, &document); err != nil {
+		if appErr, ok := err.(*apperror.AppError); ok {
+			return nil, withFieldPrefix(appErr, "document.")
+		}
+		return nil, err
+	}
+
+	payload, err := json.Marshal(document)
+	if err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "failed to encode recurring template document").WithError(err)
+	}
+	template.Document = string(payload)
+
+	// Первый выпуск не раньше дня сохранения: прошедшие периоды не выпускаются задним числом
+	rule := recurringRule(template)
+	if today := recurrence.Day(time.Now()); rule.Start.Before(today) {
+		rule.Start = today
+	}
+	if first, ok := rule.First(); ok {
+		template.NextRunDate = &first
+	}
+
+	if username, ok := logger.FromContext(ctx, logger.UsernameKey); ok {
+		template.UpdatedBy = fmt.Sprint(username)
+	}
+	return template, nil
+}
+
+func recurringRule(t *entity.EsfRecurringTemplate) recurrence.Rule {
+	return recurrence.Rule{
+		Frequency:  t.Frequency,
+		Interval:   t.Interval,
+		DayOfMonth: t.DayOfMonth,
+		Start:      t.StartDate,
+		End:        t.EndDate,
+	}
+}
+
+// withFieldPrefix добавляет префикс к полям ошибки валидации вложенного документа
+func withFieldPrefix(err *apperror.AppError, prefix string) *apperror.AppError {
+	for i := range err.Fields {
+		err.Fields[i].Field = prefix + err.Fields[i].Field
+	}
+	return err
+}
+
+// recurringRunError формирует текст ошибки выпуска вместе с ошибками полей документа
+func recurringRunError(err error) string {
+	appErr, ok := err.(*apperror.AppError)
+	if !ok {
+		return err.Error()
+	}
+	message := appErr.Message
+	if appErr.Details != "" {
+		message += ": " + appErr.Details
+	}
+	for _, f := range appErr.Fields {
+		message += "; " + f.Field + ": " + f.Message
+	}
+	return message
+}
+
+func recurringTemplateToModel(t *entity.EsfRecurringTemplate) (*models.EsfRecurringTemplateModel, error) {
+	model := &models.EsfRecurringTemplateModel{
+		ID:   t.ID,
+		Name: t.Name,
+		Rule: models.EsfRecurrenceRuleModel{
+			Frequency:  t.Frequency,
+			Interval:   t.Interval,
+			DayOfMonth: t.DayOfMonth,
+			StartDate:  t.StartDate,
+			EndDate:    t.EndDate,
+		},
+		IsActive:    t.IsActive,
+		NextRunDate: t.NextRunDate,
+		LastError:   t.LastError,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		UpdatedBy:   t.UpdatedBy,
+	}
+	if err := json.Unmarshal([]byte(t.Document), &model.Document); err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "failed to decode recurring template document").WithError(err)
+	}
+	return model, nil
+}
+
+func recurringRunToModel(r *entity.EsfRecurringRun) models.EsfRecurringRunModel {
+	return models.EsfRecurringRunModel{
+		TemplateID: r.TemplateID,
+		Period:     r.Period,
+		RunDate:    r.RunDate,
+		DocumentID: r.DocumentID,
+		CreatedAt:  r.CreatedAt,
+	}
+}
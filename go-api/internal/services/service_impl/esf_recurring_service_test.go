@@ -0,0 +1,254 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRecurringRepository мок шаблонов повторяющихся документов
+type MockRecurringRepository struct {
+	mock.Mock
+}
+
+func (m *MockRecurringRepository) GetRecurringTemplates(ctx context.Context, orgID uuid.UUID) ([]entity.EsfRecurringTemplate, error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).([]entity.EsfRecurringTemplate), args.Error(1)
+}
+
+func (m *MockRecurringRepository) GetRecurringTemplateByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfRecurringTemplate, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfRecurringTemplate), args.Error(1)
+}
+
+func (m *MockRecurringRepository) GetDueRecurringTemplates(ctx context.Context, orgID uuid.UUID, date time.Time) ([]entity.EsfRecurringTemplate, error) {
+	args := m.Called(ctx, orgID, date)
+	return args.Get(0).([]entity.EsfRecurringTemplate), args.Error(1)
+}
+
+func (m *MockRecurringRepository) CreateRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate) error {
+	args := m.Called(ctx, orgID, template)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRepository) UpdateRecurringTemplate(ctx context.Context, orgID uuid.UUID, template *entity.EsfRecurringTemplate) error {
+	args := m.Called(ctx, orgID, template)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRepository) DeleteRecurringTemplate(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, id)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRepository) SaveRecurringSchedule(ctx context.Context, orgID uuid.UUID, id uuid.UUID, nextRunDate *time.Time, lastError string) error {
+	args := m.Called(ctx, orgID, id, nextRunDate, lastError)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRepository) GetRecurringRuns(ctx context.Context, orgID uuid.UUID, templateID uuid.UUID) ([]entity.EsfRecurringRun, error) {
+	args := m.Called(ctx, orgID, templateID)
+	return args.Get(0).([]entity.EsfRecurringRun), args.Error(1)
+}
+
+func (m *MockRecurringRepository) ReserveRecurringRun(ctx context.Context, orgID uuid.UUID, run *entity.EsfRecurringRun) (bool, error) {
+	args := m.Called(ctx, orgID, run)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRecurringRepository) CompleteRecurringRun(ctx context.Context, orgID uuid.UUID, runID uuid.UUID, documentID uuid.UUID) error {
+	args := m.Called(ctx, orgID, runID, documentID)
+	return args.Error(0)
+}
+
+func (m *MockRecurringRepository) DeleteRecurringRun(ctx context.Context, orgID uuid.UUID, runID uuid.UUID) error {
+	args := m.Called(ctx, orgID, runID)
+	return args.Error(0)
+}
+
+func newRecurringTemplate(t *testing.T, next time.Time, document models.EsfCreateDocumentRequest) entity.EsfRecurringTemplate {
+	payload, err := json.Marshal(document)
+	require.NoError(t, err)
+	return entity.EsfRecurringTemplate{
+		ID:          uuid.New(),
+		Name:        "Абонентская плата",
+		Document:    string(payload),
+		Frequency:   "monthly",
+		Interval:    1,
+		DayOfMonth:  5,
+		StartDate:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		IsActive:    true,
+		NextRunDate: &next,
+	}
+}
+
+// ========== Recurring Template Tests ==========
+
+func TestEsfRecurring_CreateValidatesTemplate(t *testing.T) {
+	mockRepo := new(MockRecurringRepository)
+	documents := new(MockDocumentRepository)
+	service := NewEsfRecurringService(mockRepo, NewEsfDocumentService(documents, nil, logrus.New()), logrus.New())
+
+	_, err := service.CreateRecurringTemplate(context.Background(), uuid.New(), &models.EsfRecurringTemplateRequest{
+		Rule: models.EsfRecurrenceRuleModel{Frequency: "weekly", DayOfMonth: 5, StartDate: time.Now()},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 2)
+	assert.Equal(t, "name", appErr.Fields[0].Field)
+	assert.Equal(t, "rule", appErr.Fields[1].Field)
+	mockRepo.AssertNotCalled(t, "CreateRecurringTemplate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfRecurring_CreateSchedulesFromToday(t *testing.T) {
+	mockRepo := new(MockRecurringRepository)
+	documents := new(MockDocumentRepository)
+	orgID := uuid.New()
+
+	var saved *entity.EsfRecurringTemplate
+	mockRepo.On("CreateRecurringTemplate", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(2).(*entity.EsfRecurringTemplate)
+		saved.ID = uuid.New()
+	}).Return(nil)
+	mockRepo.On("GetRecurringTemplateByID", mock.Anything, orgID, mock.Anything).Return(&entity.EsfRecurringTemplate{Document: "{}"}, nil)
+
+	service := NewEsfRecurringService(mockRepo, NewEsfDocumentService(documents, nil, logrus.New()), logrus.New())
+	docID := uuid.New()
+	_, err := service.CreateRecurringTemplate(context.Background(), orgID, &models.EsfRecurringTemplateRequest{
+		Name:     " Абонентская плата ",
+		Rule:     models.EsfRecurrenceRuleModel{Frequency: "monthly", DayOfMonth: 31, StartDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Document: models.EsfCreateDocumentRequest{ID: &docID, Number: "INV-2020-000001", TaxRateVATCode: "1"},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, saved)
+	assert.Equal(t, "Абонентская плата", saved.Name)
+	assert.Equal(t, 1, saved.Interval)
+	assert.True(t, saved.IsActive)
+	require.NotNil(t, saved.NextRunDate)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	assert.False(t, saved.NextRunDate.Before(today))
+	assert.True(t, saved.NextRunDate.Before(today.AddDate(0, 1, 1)))
+
+	var document models.EsfCreateDocumentRequest
+	require.NoError(t, json.Unmarshal([]byte(saved.Document), &document))
+	assert.Nil(t, document.ID)
+	assert.Empty(t, document.Number)
+	assert.Equal(t, "1", document.TaxRateVATCode)
+}
+
+func TestEsfRecurring_RunCreatesDraftsForDuePeriods(t *testing.T) {
+	mockRepo := new(MockRecurringRepository)
+	documents := new(MockDocumentRepository)
+	orgID := uuid.New()
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	template := newRecurringTemplate(t, time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC), models.EsfCreateDocumentRequest{TaxRateVATCode: "1"})
+
+	mockRepo.On("GetDueRecurringTemplates", mock.Anything, orgID, time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)).Return([]entity.EsfRecurringTemplate{template}, nil)
+	mockRepo.On("ReserveRecurringRun", mock.Anything, orgID, mock.Anything).Return(true, nil)
+	var deliveryDates []time.Time
+	documents.On("CreateDocument", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		deliveryDates = append(deliveryDates, args.Get(2).(*entity.EsfDocument).DeliveryDate)
+	}).Return(nil)
+	mockRepo.On("CompleteRecurringRun", mock.Anything, orgID, mock.Anything, mock.Anything).Return(nil)
+	next := time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("SaveRecurringSchedule", mock.Anything, orgID, template.ID, &next, "").Return(nil)
+
+	service := NewEsfRecurringService(mockRepo, NewEsfDocumentService(documents, nil, logrus.New()), logrus.New())
+	report, err := service.RunRecurringTemplates(context.Background(), orgID, now)
+	require.NoError(t, err)
+
+	require.Len(t, report.Created, 2)
+	assert.Equal(t, "2026-02", report.Created[0].Period)
+	assert.Equal(t, "2026-03", report.Created[1].Period)
+	assert.Equal(t, []time.Time{time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)}, deliveryDates)
+	mockRepo.AssertExpectations(t)
+	documents.AssertExpectations(t)
+}
+
+func TestEsfRecurring_RunSkipsIssuedPeriod(t *testing.T) {
+	mockRepo := new(MockRecurringRepository)
+	documents := new(MockDocumentRepository)
+	orgID := uuid.New()
+	template := newRecurringTemplate(t, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC), models.EsfCreateDocumentRequest{TaxRateVATCode: "1"})
+
+	mockRepo.On("GetDueRecurringTemplates", mock.Anything, orgID, mock.Anything).Return([]entity.EsfRecurringTemplate{template}, nil)
+	mockRepo.On("ReserveRecurringRun", mock.Anything, orgID, mock.Anything).Return(false, nil)
+	mockRepo.On("SaveRecurringSchedule", mock.Anything, orgID, template.ID, mock.Anything, "").Return(nil)
+
+	service := NewEsfRecurringService(mockRepo, NewEsfDocumentService(documents, nil, logrus.New()), logrus.New())
+	report, err := service.RunRecurringTemplates(context.Background(), orgID, time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Empty(t, report.Created)
+	assert.Equal(t, 1, report.Skipped)
+	documents.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEsfRecurring_RunReleasesFailedPeriod(t *testing.T) {
+	mockRepo := new(MockRecurringRepository)
+	documents := new(MockDocumentRepository)
+	orgID := uuid.New()
+	runDate := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+	// Курс валюты определить нельзя: сервис курсов не подключен
+	template := newRecurringTemplate(t, runDate, models.EsfCreateDocumentRequest{TaxRateVATCode: "1", CurrencyCode: "USD"})
+
+	var run *entity.EsfRecurringRun
+	mockRepo.On("GetDueRecurringTemplates", mock.Anything, orgID, mock.Anything).Return([]entity.EsfRecurringTemplate{template}, nil)
+	mockRepo.On("ReserveRecurringRun", mock.Anything, orgID, mock.Anything).Run(func(args mock.Arguments) {
+		run = args.Get(2).(*entity.EsfRecurringRun)
+		run.ID = uuid.New()
+	}).Return(true, nil)
+	mockRepo.On("DeleteRecurringRun", mock.Anything, orgID, mock.Anything).Return(nil)
+	mockRepo.On("SaveRecurringSchedule", mock.Anything, orgID, template.ID, &runDate, mock.MatchedBy(func(e string) bool {
+		return e != ""
+	})).Return(nil)
+
+	service := NewEsfRecurringService(mockRepo, NewEsfDocumentService(documents, nil, logrus.New()), logrus.New())
+	report, err := service.RunRecurringTemplates(context.Background(), orgID, runDate.AddDate(0, 0, 3))
+	require.NoError(t, err)
+
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, "2026-03", report.Failed[0].Period)
+	assert.Contains(t, report.Failed[0].Error, "currencyRate")
+	mockRepo.AssertCalled(t, "DeleteRecurringRun", mock.Anything, orgID, run.ID)
+	documents.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEsfRecurring_Preview(t *testing.T) {
+	mockRepo := new(MockRecurringRepository)
+	documents := new(MockDocumentRepository)
+	orgID := uuid.New()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	template := newRecurringTemplate(t, today, models.EsfCreateDocumentRequest{})
+	template.DayOfMonth = today.Day()
+	mockRepo.On("GetRecurringTemplateByID", mock.Anything, orgID, template.ID).Return(&template, nil)
+
+	service := NewEsfRecurringService(mockRepo, NewEsfDocumentService(documents, nil, logrus.New()), logrus.New())
+	items, err := service.PreviewRecurringTemplate(context.Background(), orgID, template.ID, 3)
+	require.NoError(t, err)
+
+	require.Len(t, items, 3)
+	assert.Equal(t, models.RecurringRunDue, items[0].Status)
+	assert.Equal(t, models.RecurringRunScheduled, items[1].Status)
+	assert.Equal(t, today.Format("2006-01"), items[0].Period)
+
+	_, err = service.PreviewRecurringTemplate(context.Background(), orgID, template.ID, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/reference"
+	"github.com/sirupsen/logrus"
+)
+
+// referenceCodesTTL время, через которое коды активных версий перечитываются из БД
+const referenceCodesTTL = 5 * time.Minute
+
+type esfReferenceService struct {
+	repo   repository.EsfReferenceRepository
+	logger *logger.Logger
+
+	mu       sync.RWMutex
+	codes    *reference.Set
+	loadedAt time.Time
+}
+
+// NewEsfReferenceService создает сервис справочников кодов ЭСФ
+func NewEsfReferenceService(repo repository.EsfReferenceRepository, log *logrus.Logger) services.EsfReferenceService {
+	return &esfReferenceService{
+		repo:   repo,
+		logger: logger.New(log),
+	}
+}
+
+// SeedBundled загружает встроенные версии справочников, которых еще нет в БД.
+// Новая встроенная версия становится активной.
+func (s *esfReferenceService) SeedBundled(ctx context.Context) error {
+	files, err := reference.Bundled()
+	if err != nil {
+		return apperror.New(apperror.ErrConfigError, "invalid bundled reference catalogs").WithError(err)
+	}
+
+	seeded := 0
+	for _, file := range files {
+		exists, err := s.repo.VersionExists(ctx, file.Catalog, file.Version)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		version := &entity.EsfReferenceVersion{Catalog: file.Catalog, Version: file.Version, Checksum: file.Checksum}
+		items := make([]entity.EsfReferenceItem, len(file.Items))
+		for i, item := range file.Items {
+			items[i] = entity.EsfReferenceItem{Code: item.Code, AltCode: item.AltCode, Name: item.Name, SortOrder: i}
+		}
+
+		if err := s.repo.CreateVersion(ctx, version, items, true); err != nil {
+			return err
+		}
+		s.logger.Info(ctx, "Reference catalog version loaded", logrus.Fields{"catalog": file.Catalog, "version": file.Version, "items": len(items)})
+		seeded++
+	}
+
+	if seeded > 0 {
+		s.resetCodes()
+	}
+	return nil
+}
+
+// ListCatalogs возвращает активные версии справочников без кодов
+func (s *esfReferenceService) ListCatalogs(ctx context.Context) ([]models.EsfReferenceCatalogModel, error) {
+	versions, err := s.repo.GetActiveVersions(ctx)
+	if err != nil {
+		return nil, repositoryError("fetching reference catalogs", err)
+	}
+
+	ids := make([]uuid.UUID, len(versions))
+	for i := range versions {
+		ids[i] = versions[i].ID
+	}
+	items, err := s.repo.GetItems(ctx, ids...)
+	if err != nil {
+		return nil, repositoryError("fetching reference items", err)
+	}
+
+	counts := make(map[uuid.UUID]int, len(versions))
+	for _, item := range items {
+		counts[item.VersionID]++
+	}
+
+	result := make([]models.EsfReferenceCatalogModel, len(versions))
+	for i, v := range versions {
+		result[i] = models.EsfReferenceCatalogModel{Catalog: v.Catalog, Version: v.Version, LoadedAt: v.CreatedAt, ItemCount: counts[v.ID]}
+	}
+	return result, nil
+}
+
+// GetCatalog возвращает коды активной версии справочника
+func (s *esfReferenceService) GetCatalog(ctx context.Context, catalog string) (*models.EsfReferenceCatalogModel, error) {
+	version, err := s.repo.GetActiveVersion(ctx, catalog)
+	if err != nil {
+		return nil, repositoryError("fetching reference catalog", err)
+	}
+
+	items, err := s.repo.GetItems(ctx, version.ID)
+	if err != nil {
+		return nil, repositoryError("fetching reference items", err)
+	}
+
+	result := &models.EsfReferenceCatalogModel{
+		Catalog:   version.Catalog,
+		Version:   version.Version,
+		LoadedAt:  version.CreatedAt,
+		ItemCount: len(items),
+		Items:     make([]models.EsfReferenceItemModel, len(items)),
+	}
+	for i, item := range items {
+		result.Items[i] = models.EsfReferenceItemModel{Code: item.Code, AltCode: item.AltCode, Name: item.Name}
+	}
+	return result, nil
+}
+
+// ValidateDocument проверяет коды документа и позиций по активным версиям справочников.
+// Пустые коды и коды справочников без активной версии не проверяются.
+func (s *esfReferenceService) ValidateDocument(ctx context.Context, doc *entity.EsfDocument) *apperror.AppError {
+	codes, err := s.activeCodes(ctx)
+	if err != nil {
+		s.logger.Error(ctx, "Failed to load reference catalogs", err, logrus.Fields{})
+		return repositoryError("loading reference catalogs", err)
+	}
+
+	var fields []apperror.FieldError
+	check := func(field, catalog, code string) {
+		if code == "" || !codes.Loaded(catalog) || codes.Contains(catalog, code) {
+			return
+		}
+		fields = append(fields, apperror.FieldError{
+			Field:   field,
+			Message: fmt.Sprintf("code %q is not in the %s catalog", code, catalog),
+		})
+	}
+
+	check("operationTypeCode", entity.ReferenceOperationType, doc.OperationTypeCode)
+	check("deliveryTypeCode", entity.ReferenceDeliveryType, doc.DeliveryTypeCode)
+	check("paymentCode", entity.ReferencePayment, doc.PaymentCode)
+	check("taxRateVATCode", entity.ReferenceTaxRateVAT, doc.TaxRateVATCode)
+	check("deliveryCode", entity.ReferenceDelivery, doc.DeliveryCode)
+	check("currencyCode", entity.ReferenceCurrency, doc.CurrencyCode)
+	check("countryCode", entity.ReferenceCountry, doc.CountryCode)
+	for i, entry := range doc.CatalogEntries {
+		check(entryFieldName(i, "unitClassificationCode"), entity.ReferenceUnit, entry.UnitClassificationCode)
+	}
+
+	if len(fields) > 0 {
+		return apperror.FieldValidationError("document contains codes missing from reference catalogs", fields)
+	}
+	return nil
+}
+
+// activeCodes возвращает коды активных версий, перечитывая их из БД раз в referenceCodesTTL
+func (s *esfReferenceService) activeCodes(ctx context.Context) (*reference.Set, error) {
+	s.mu.RLock()
+	codes, loadedAt := s.codes, s.loadedAt
+	s.mu.RUnlock()
+	if codes != nil && time.Since(loadedAt) < referenceCodesTTL {
+		return codes, nil
+	}
+
+	versions, err := s.repo.GetActiveVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(versions))
+	catalogs := make(map[uuid.UUID]string, len(versions))
+	for i, v := range versions {
+		ids[i] = v.ID
+		catalogs[v.ID] = v.Catalog
+	}
+	items, err := s.repo.GetItems(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	codes = reference.NewSet()
+	for _, v := range versions {
+		codes.Add(v.Catalog)
+	}
+	for _, item := range items {
+		codes.Add(catalogs[item.VersionID], item.Code, item.AltCode)
+	}
+
+	s.mu.Lock()
+	s.codes, s.loadedAt = codes, time.Now()
+	s.mu.Unlock()
+	return codes, nil
+}
+
+func (s *esfReferenceService) resetCodes() {
+	s.mu.Lock()
+	s.codes = nil
+	s.mu.Unlock()
+}
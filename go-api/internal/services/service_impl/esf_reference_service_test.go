@@ -0,0 +1,132 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/reference"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReferenceRepository мок репозитория справочников
+type MockReferenceRepository struct {
+	mock.Mock
+}
+
+func (m *MockReferenceRepository) GetActiveVersions(ctx context.Context) ([]entity.EsfReferenceVersion, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entity.EsfReferenceVersion), args.Error(1)
+}
+
+func (m *MockReferenceRepository) GetActiveVersion(ctx context.Context, catalog string) (*entity.EsfReferenceVersion, error) {
+	args := m.Called(ctx, catalog)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfReferenceVersion), args.Error(1)
+}
+
+func (m *MockReferenceRepository) GetItems(ctx context.Context, versionIDs ...uuid.UUID) ([]entity.EsfReferenceItem, error) {
+	args := m.Called(ctx, versionIDs)
+	return args.Get(0).([]entity.EsfReferenceItem), args.Error(1)
+}
+
+func (m *MockReferenceRepository) VersionExists(ctx context.Context, catalog, version string) (bool, error) {
+	args := m.Called(ctx, catalog, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReferenceRepository) CreateVersion(ctx context.Context, version *entity.EsfReferenceVersion, items []entity.EsfReferenceItem, activate bool) error {
+	args := m.Called(ctx, version, items, activate)
+	return args.Error(0)
+}
+
+// newReferenceRepository возвращает репозиторий с активными справочниками валют и единиц измерения
+func newReferenceRepository() *MockReferenceRepository {
+	currency, unit := uuid.New(), uuid.New()
+	repo := new(MockReferenceRepository)
+	repo.On("GetActiveVersions", mock.Anything).Return([]entity.EsfReferenceVersion{
+		{ID: currency, Catalog: entity.ReferenceCurrency, Version: "1", Active: true},
+		{ID: unit, Catalog: entity.ReferenceUnit, Version: "1", Active: true},
+	}, nil)
+	repo.On("GetItems", mock.Anything, mock.Anything).Return([]entity.EsfReferenceItem{
+		{VersionID: currency, Code: "KGS", AltCode: "417", Name: "Кыргызский сом"},
+		{VersionID: unit, Code: "796", Name: "Штука"},
+	}, nil)
+	return repo
+}
+
+// ========== Reference Catalog Tests ==========
+
+func TestEsfReference_SeedBundledLoadsMissingVersions(t *testing.T) {
+	files, err := reference.Bundled()
+	require.NoError(t, err)
+
+	repo := new(MockReferenceRepository)
+	repo.On("VersionExists", mock.Anything, entity.ReferenceCurrency, mock.Anything).Return(true, nil)
+	repo.On("VersionExists", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+	repo.On("CreateVersion", mock.Anything, mock.Anything, mock.Anything, true).Return(nil)
+
+	service := NewEsfReferenceService(repo, logrus.New())
+	require.NoError(t, service.SeedBundled(context.Background()))
+
+	repo.AssertNumberOfCalls(t, "CreateVersion", len(files)-1)
+	for _, call := range repo.Calls {
+		if call.Method == "CreateVersion" {
+			version := call.Arguments.Get(1).(*entity.EsfReferenceVersion)
+			assert.NotEqual(t, entity.ReferenceCurrency, version.Catalog)
+			assert.NotEmpty(t, version.Checksum)
+		}
+	}
+}
+
+func TestEsfReference_ValidateDocument(t *testing.T) {
+	service := NewEsfReferenceService(newReferenceRepository(), logrus.New())
+
+	doc := &entity.EsfDocument{
+		CurrencyCode: "417",
+		// справочник стран не загружен, код не проверяется
+		CountryCode: "ZZ",
+		CatalogEntries: []entity.EsfEntries{
+			{UnitClassificationCode: "796"},
+			{UnitClassificationCode: "999"},
+		},
+	}
+	appErr := service.ValidateDocument(context.Background(), doc)
+	require.NotNil(t, appErr)
+	assert.Equal(t, apperror.ErrFieldValidation, appErr.Code)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "catalogEntries[1].unitClassificationCode", appErr.Fields[0].Field)
+
+	doc.CurrencyCode = "XXX"
+	doc.CatalogEntries = doc.CatalogEntries[:1]
+	appErr = service.ValidateDocument(context.Background(), doc)
+	require.NotNil(t, appErr)
+	assert.Equal(t, "currencyCode", appErr.Fields[0].Field)
+}
+
+func TestEsfReference_DocumentServiceRejectsUnknownCodes(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetReferenceService(NewEsfReferenceService(newReferenceRepository(), logrus.New()))
+
+	_, err := service.CreateDocument(context.Background(), uuid.New(), &models.EsfCreateDocumentRequest{
+		CurrencyCode:   "XXX",
+		TaxRateVATCode: "1",
+		CatalogEntries: []models.EsfEntriesModel{{UnitClassificationCode: "796", Quantity: 1, Price: money.MustParse("100")}},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "currencyCode", appErr.Fields[0].Field)
+	mockRepo.AssertNotCalled(t, "CreateDocument", mock.Anything, mock.Anything, mock.Anything)
+}
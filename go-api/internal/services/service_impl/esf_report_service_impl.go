@@ -0,0 +1,34 @@
+package service_impl
+
+import (
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/cache"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/tax"
+	"github.com/sirupsen/logrus"
+)
+
+type esfReportService struct {
+	repo          repository.EsfReportRepository
+	contractors   repository.EsfContractorRepository
+	cacheManager  cache.CacheManager
+	taxCalculator *tax.Calculator
+	logger        *logger.Logger
+}
+
+// NewEsfReportService создает сервис отчетов по документам.
+// Наименования контрагентов берутся из справочника contractors; без него (nil) - только из документов.
+func NewEsfReportService(repo repository.EsfReportRepository, contractors repository.EsfContractorRepository, log *logrus.Logger) services.EsfReportService {
+	return &esfReportService{
+		repo:          repo,
+		contractors:   contractors,
+		taxCalculator: tax.NewCalculator(tax.DefaultRateTable()),
+		logger:        logger.New(log),
+	}
+}
+
+// SetCacheManager подключает общий кэш, в котором аналитика хранит агрегаты
+func (s *esfReportService) SetCacheManager(cacheManager cache.CacheManager) {
+	s.cacheManager = cacheManager
+}
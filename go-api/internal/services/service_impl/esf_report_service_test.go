@@ -0,0 +1,24 @@
+package service_impl
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReportRepository мок сумм документов для отчетов
+type MockReportRepository struct {
+	mock.Mock
+}
+
+func (m *MockReportRepository) GetLedgerDocuments(ctx context.Context, orgID uuid.UUID, filter repository.LedgerFilter) ([]repository.LedgerDocument, error) {
+	args := m.Called(ctx, orgID, filter)
+	return args.Get(0).([]repository.LedgerDocument), args.Error(1)
+}
+
+func (m *MockReportRepository) GetDocumentAggregates(ctx context.Context, orgID uuid.UUID, filter repository.LedgerFilter, groupBy repository.AggregateGroup, limit int) ([]repository.AggregateBucket, error) {
+	args := m.Called(ctx, orgID, filter, groupBy, limit)
+	return args.Get(0).([]repository.AggregateBucket), args.Error(1)
+}
@@ -0,0 +1,158 @@
+package service_impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/internal/services"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/signature"
+	"github.com/sirupsen/logrus"
+)
+
+// maxSigningKeyNameLength ограничивает наименование ключа подписи
+const maxSigningKeyNameLength = 255
+
+type esfSigningKeyService struct {
+	repo   repository.EsfSigningKeyRepository
+	logger *logger.Logger
+}
+
+func NewEsfSigningKeyService(repo repository.EsfSigningKeyRepository, log *logrus.Logger) services.EsfSigningKeyService {
+	return &esfSigningKeyService{
+		repo:   repo,
+		logger: logger.New(log),
+	}
+}
+
+// GetSigningKeys возвращает ключи подписи организации
+func (s *esfSigningKeyService) GetSigningKeys(ctx context.Context, orgID uuid.UUID) ([]models.EsfSigningKeyModel, error) {
+	keys, err := s.repo.GetSigningKeys(ctx, orgID)
+	if err != nil {
+		return nil, repositoryError("fetching signing keys", err)
+	}
+
+	result := make([]models.EsfSigningKeyModel, len(keys))
+	for i := range keys {
+		result[i] = signingKeyToModel(&keys[i])
+	}
+	return result, nil
+}
+
+// GetSigningKey возвращает ключ подписи организации
+func (s *esfSigningKeyService) GetSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*models.EsfSigningKeyModel, error) {
+	key, err := s.repo.GetSigningKeyByID(ctx, orgID, id)
+	if err != nil {
+		return nil, repositoryError("fetching signing key", err)
+	}
+	model := signingKeyToModel(key)
+	return &model, nil
+}
+
+// RegisterSigningKey регистрирует открытый ключ подписи пользователя или организации
+func (s *esfSigningKeyService) RegisterSigningKey(ctx context.Context, orgID uuid.UUID, req *models.EsfSigningKeyRequest) (*models.EsfSigningKeyModel, error) {
+	s.logger.Info(ctx, "Registering signing key", logrus.Fields{"org_id": orgID.String(), "scope": req.Scope})
+
+	var fieldErrors []apperror.FieldError
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "":
+		fieldErrors = append(fieldErrors, apperror.FieldError{Field: "name", Message: "name is required"})
+	case len([]rune(name)) > maxSigningKeyNameLength:
+		fieldErrors = append(fieldErrors, apperror.FieldError{Field: "name", Message: fmt.Sprintf("name must not exceed %d characters", maxSigningKeyNameLength)})
+	}
+
+	scope := entity.SigningKeyScope(strings.ToLower(strings.TrimSpace(req.Scope)))
+	if scope == "" {
+		scope = entity.SigningKeyScopeUser
+	}
+	if scope != entity.SigningKeyScopeUser && scope != entity.SigningKeyScopeOrganization {
+		fieldErrors = append(fieldErrors, apperror.FieldError{Field: "scope", Message: "scope must be user or organization"})
+	}
+
+	publicKey, err := signature.ParsePublicKey(req.PublicKey)
+	if err != nil {
+		fieldErrors = append(fieldErrors, apperror.FieldError{Field: "publicKey", Message: err.Error()})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, apperror.FieldValidationError("invalid signing key", fieldErrors)
+	}
+
+	key := &entity.EsfSigningKey{
+		ID:          uuid.New(),
+		Name:        name,
+		Scope:       scope,
+		Algorithm:   string(publicKey.Algorithm),
+		PublicKey:   publicKey.PEM(),
+		Fingerprint: publicKey.Fingerprint,
+		CreatedBy:   actorName(ctx),
+	}
+	if scope == entity.SigningKeyScopeUser {
+		if key.UserID = actorID(ctx); key.UserID == "" {
+			return nil, apperror.UnauthorizedError("personal signing key requires an authenticated user")
+		}
+	}
+
+	existing, err := s.repo.GetSigningKeyByFingerprint(ctx, orgID, key.Fingerprint)
+	if err != nil {
+		if appErr, ok := err.(*apperror.AppError); !ok || appErr.Code != apperror.ErrNotFound {
+			return nil, repositoryError("checking signing key", err)
+		}
+	} else {
+		return nil, apperror.New(apperror.ErrAlreadyExists, "signing key is already registered").
+			WithFieldErrors([]apperror.FieldError{{Field: "publicKey", Message: "key is already registered as " + existing.Name}})
+	}
+
+	if err := s.repo.CreateSigningKey(ctx, orgID, key); err != nil {
+		return nil, repositoryError("creating signing key", err)
+	}
+
+	s.logger.Info(ctx, "Signing key registered", logrus.Fields{"org_id": orgID.String(), "key_id": key.ID.String(), "fingerprint": key.Fingerprint})
+	model := signingKeyToModel(key)
+	return &model, nil
+}
+
+// RevokeSigningKey отзывает ключ подписи. Личный ключ может отозвать только его владелец.
+func (s *esfSigningKeyService) RevokeSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID) error {
+	s.logger.Info(ctx, "Revoking signing key", logrus.Fields{"org_id": orgID.String(), "key_id": id.String()})
+
+	key, err := s.repo.GetSigningKeyByID(ctx, orgID, id)
+	if err != nil {
+		return repositoryError("fetching signing key", err)
+	}
+	if key.Scope == entity.SigningKeyScopeUser && key.UserID != actorID(ctx) {
+		return apperror.ForbiddenError("personal signing key can be revoked only by its owner")
+	}
+	if key.IsRevoked() {
+		return apperror.NewWithDetails(apperror.ErrConflict, "signing key is already revoked", key.RevokedAt.Format(time.RFC3339))
+	}
+
+	if err := s.repo.RevokeSigningKey(ctx, orgID, id, time.Now()); err != nil {
+		return repositoryError("revoking signing key", err)
+	}
+
+	s.logger.Info(ctx, "Signing key revoked", logrus.Fields{"org_id": orgID.String(), "key_id": id.String()})
+	return nil
+}
+
+func signingKeyToModel(k *entity.EsfSigningKey) models.EsfSigningKeyModel {
+	return models.EsfSigningKeyModel{
+		ID:          k.ID,
+		Name:        k.Name,
+		Scope:       string(k.Scope),
+		UserID:      k.UserID,
+		Algorithm:   k.Algorithm,
+		PublicKey:   k.PublicKey,
+		Fingerprint: k.Fingerprint,
+		CreatedAt:   k.CreatedAt,
+		CreatedBy:   k.CreatedBy,
+		RevokedAt:   k.RevokedAt,
+	}
+}
@@ -0,0 +1,94 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/logger"
+	"github.com/rusgainew/tunduck-app/pkg/signature"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSigningKeyRepository мок ключей подписи
+type MockSigningKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockSigningKeyRepository) GetSigningKeys(ctx context.Context, orgID uuid.UUID) ([]entity.EsfSigningKey, error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).([]entity.EsfSigningKey), args.Error(1)
+}
+
+func (m *MockSigningKeyRepository) GetSigningKeyByID(ctx context.Context, orgID uuid.UUID, id uuid.UUID) (*entity.EsfSigningKey, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfSigningKey), args.Error(1)
+}
+
+func (m *MockSigningKeyRepository) GetSigningKeyByFingerprint(ctx context.Context, orgID uuid.UUID, fingerprint string) (*entity.EsfSigningKey, error) {
+	args := m.Called(ctx, orgID, fingerprint)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfSigningKey), args.Error(1)
+}
+
+func (m *MockSigningKeyRepository) CreateSigningKey(ctx context.Context, orgID uuid.UUID, key *entity.EsfSigningKey) error {
+	args := m.Called(ctx, orgID, key)
+	return args.Error(0)
+}
+
+func (m *MockSigningKeyRepository) RevokeSigningKey(ctx context.Context, orgID uuid.UUID, id uuid.UUID, revokedAt time.Time) error {
+	args := m.Called(ctx, orgID, id, revokedAt)
+	return args.Error(0)
+}
+
+// ========== Signing Key Tests ==========
+
+func TestEsfSigningKey_Register(t *testing.T) {
+	mockRepo := new(MockSigningKeyRepository)
+	orgID := uuid.New()
+	_, publicPEM, err := signature.GenerateKey(signature.ECDSAP256)
+	require.NoError(t, err)
+
+	mockRepo.On("GetSigningKeyByFingerprint", mock.Anything, orgID, mock.Anything).Return(nil, apperror.NotFoundError("signing key"))
+	mockRepo.On("CreateSigningKey", mock.Anything, orgID, mock.Anything).Return(nil)
+
+	service := NewEsfSigningKeyService(mockRepo, logrus.New())
+
+	t.Run("personal key requires user", func(t *testing.T) {
+		_, err := service.RegisterSigningKey(context.Background(), orgID, &models.EsfSigningKeyRequest{Name: "Ключ", PublicKey: string(publicPEM)})
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		assert.Equal(t, apperror.ErrUnauthorized, appErr.Code)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := service.RegisterSigningKey(context.Background(), orgID, &models.EsfSigningKeyRequest{Name: "Ключ", Scope: "team", PublicKey: "abc"})
+		appErr, ok := err.(*apperror.AppError)
+		require.True(t, ok)
+		require.Len(t, appErr.Fields, 2)
+		assert.Equal(t, "scope", appErr.Fields[0].Field)
+		assert.Equal(t, "publicKey", appErr.Fields[1].Field)
+	})
+
+	t.Run("personal key", func(t *testing.T) {
+		result, err := service.RegisterSigningKey(logger.WithContext(context.Background(), logger.UserIDKey, "user-1"), orgID,
+			&models.EsfSigningKeyRequest{Name: " Главный бухгалтер ", PublicKey: string(publicPEM)})
+		require.NoError(t, err)
+		assert.Equal(t, "Главный бухгалтер", result.Name)
+		assert.Equal(t, "user", result.Scope)
+		assert.Equal(t, "user-1", result.UserID)
+		assert.Equal(t, "ecdsa-p256", result.Algorithm)
+	})
+}
@@ -0,0 +1,91 @@
+package service_impl
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+)
+
+// GetVATBalance рассчитывает НДС к уплате за период: НДС исходящих документов книги продаж
+// за вычетом НДС принятых к учету документов поставщиков книги покупок
+func (s *esfReportService) GetVATBalance(ctx context.Context, orgID uuid.UUID, req *models.EsfVatBalanceRequest) (*models.EsfVatBalanceReport, error) {
+	output, fields := periodFilter(req.From, req.To, nil, defaultLedgerStatuses, maxLedgerPeriod)
+	if len(fields) > 0 {
+		return nil, apperror.FieldValidationError("invalid VAT balance parameters", fields)
+	}
+	output.Direction = entity.DocumentDirectionOutgoing
+	input := output
+	input.Direction = entity.DocumentDirectionIncoming
+	input.Statuses = defaultPurchaseStatuses
+
+	s.logger.Info(ctx, "Building VAT balance", logrus.Fields{
+		"org_id": orgID.String(),
+		"from":   output.From.Format(currency.DateLayout),
+		"to":     output.To.AddDate(0, 0, -1).Format(currency.DateLayout),
+	})
+
+	sales, err := s.aggregates(ctx, orgID, output, repository.AggregateByVATCode, 0)
+	if err != nil {
+		return nil, err
+	}
+	purchases, err := s.aggregates(ctx, orgID, input, repository.AggregateByVATCode, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]*models.EsfVatBalanceRate)
+	rate := func(code string) *models.EsfVatBalanceRate {
+		if r, ok := byCode[code]; ok {
+			return r
+		}
+		r := &models.EsfVatBalanceRate{VatCode: code}
+		if vat, err := s.taxCalculator.Rates().VAT(code); err == nil {
+			r.VatName = vat.Name
+			r.VatPercent = vat.Percent
+		}
+		byCode[code] = r
+		return r
+	}
+	for _, b := range sales {
+		r := rate(b.Key)
+		r.OutputDocuments = b.Documents
+		r.OutputBase = b.AmountWithoutTaxes
+		r.OutputVat = b.VatAmount
+	}
+	for _, b := range purchases {
+		r := rate(b.Key)
+		r.InputDocuments = b.Documents
+		r.InputBase = b.AmountWithoutTaxes
+		r.InputVat = b.VatAmount
+	}
+
+	report := &models.EsfVatBalanceReport{
+		From:     output.From,
+		To:       output.To.AddDate(0, 0, -1),
+		Currency: currency.Base,
+		Rates:    make([]models.EsfVatBalanceRate, 0, len(byCode)),
+	}
+	for _, r := range byCode {
+		r.NetVat = r.OutputVat.Sub(r.InputVat)
+		report.Rates = append(report.Rates, *r)
+
+		t := &report.Totals
+		t.OutputDocuments += r.OutputDocuments
+		t.OutputBase = t.OutputBase.Add(r.OutputBase)
+		t.OutputVat = t.OutputVat.Add(r.OutputVat)
+		t.InputDocuments += r.InputDocuments
+		t.InputBase = t.InputBase.Add(r.InputBase)
+		t.InputVat = t.InputVat.Add(r.InputVat)
+	}
+	report.Totals.NetVat = report.Totals.OutputVat.Sub(report.Totals.InputVat)
+	sort.Slice(report.Rates, func(i, j int) bool { return report.Rates[i].VatCode < report.Rates[j].VatCode })
+
+	return report, nil
+}
@@ -0,0 +1,74 @@
+package service_impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== VAT Balance Tests ==========
+
+func TestEsfVatBalance_NetsInputAgainstOutput(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	orgID := uuid.New()
+	period := repository.LedgerFilter{
+		From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	output, input := period, period
+	output.Statuses, output.Direction = defaultLedgerStatuses, entity.DocumentDirectionOutgoing
+	input.Statuses, input.Direction = defaultPurchaseStatuses, entity.DocumentDirectionIncoming
+
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, output, repository.AggregateByVATCode, 0).
+		Return([]repository.AggregateBucket{aggregateBucket("1", 2, "1000.00", "120.00"), aggregateBucket("3", 1, "500", "0")}, nil)
+	mockRepo.On("GetDocumentAggregates", mock.Anything, orgID, input, repository.AggregateByVATCode, 0).
+		Return([]repository.AggregateBucket{aggregateBucket("1", 3, "1500.10", "180.01")}, nil)
+
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+	report, err := service.GetVATBalance(context.Background(), orgID, &models.EsfVatBalanceRequest{
+		From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, report.Rates, 2)
+	vat12 := report.Rates[0]
+	assert.Equal(t, "1", vat12.VatCode)
+	assert.Equal(t, "НДС 12%", vat12.VatName)
+	assert.Equal(t, "120.00", vat12.OutputVat.String())
+	assert.Equal(t, "180.01", vat12.InputVat.String())
+	assert.Equal(t, "-60.01", vat12.NetVat.String())
+	assert.Equal(t, 0, report.Rates[1].InputDocuments)
+
+	assert.Equal(t, 3, report.Totals.OutputDocuments)
+	assert.Equal(t, 3, report.Totals.InputDocuments)
+	assert.Equal(t, "1500.00", report.Totals.OutputBase.String())
+	assert.Equal(t, "-60.01", report.Totals.NetVat.String())
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), report.To)
+}
+
+func TestEsfVatBalance_ValidatesPeriod(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+
+	_, err := service.GetVATBalance(context.Background(), uuid.New(), &models.EsfVatBalanceRequest{
+		From: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	require.Len(t, appErr.Fields, 1)
+	assert.Equal(t, "to", appErr.Fields[0].Field)
+	mockRepo.AssertNotCalled(t, "GetDocumentAggregates", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
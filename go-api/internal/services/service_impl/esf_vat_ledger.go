@@ -0,0 +1,287 @@
+package service_impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/currency"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// LedgerSales книга продаж - исходящие документы организации
+	LedgerSales = "sales"
+	// LedgerPurchases книга покупок - входящие документы поставщиков
+	LedgerPurchases = "purchases"
+)
+
+// maxLedgerPeriod ограничивает период книги продаж и покупок
+const maxLedgerPeriod = 366 * 24 * time.Hour
+
+// defaultLedgerStatuses документы, включаемые в книгу продаж по умолчанию:
+// черновики и аннулированные документы налоговых обязательств не создают
+var defaultLedgerStatuses = []entity.DocumentStatus{
+	entity.DocumentStatusSigned,
+	entity.DocumentStatusSubmitting,
+	entity.DocumentStatusSubmitted,
+	entity.DocumentStatusAccepted,
+}
+
+// defaultPurchaseStatuses документы поставщиков, включаемые в книгу покупок по умолчанию:
+// входящий НДС принимается к зачету только по принятым к учету документам
+var defaultPurchaseStatuses = []entity.DocumentStatus{
+	entity.DocumentStatusAccepted,
+}
+
+// ledgerCSVHeader колонки CSV книги продаж и покупок
+var ledgerCSVHeader = []string{
+	"row_type", "vat_code", "vat_name", "vat_rate", "contractor_tin", "contractor_name",
+	"document_number", "delivery_date", "status", "is_correction", "documents", "entries",
+	"taxable_base", "vat_amount", "sales_tax_amount", "total_amount",
+}
+
+// GetVATLedger возвращает книгу продаж или покупок за период, сгруппированную по ставке НДС
+// и контрагенту: покупателю исходящих документов или поставщику входящих
+func (s *esfReportService) GetVATLedger(ctx context.Context, orgID uuid.UUID, req *models.EsfVatLedgerRequest) (*models.EsfVatLedgerReport, error) {
+	filter, ledger, err := ledgerFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(ctx, "Building VAT ledger", logrus.Fields{
+		"org_id": orgID.String(),
+		"ledger": ledger,
+		"from":   req.From.Format(currency.DateLayout),
+		"to":     req.To.Format(currency.DateLayout),
+	})
+
+	rows, err := s.repo.GetLedgerDocuments(ctx, orgID, filter)
+	if err != nil {
+		return nil, repositoryError("fetching ledger documents", err)
+	}
+
+	names, err := s.contractorNames(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.EsfVatLedgerReport{
+		Ledger:   ledger,
+		From:     filter.From,
+		To:       filter.To.AddDate(0, 0, -1),
+		Currency: currency.Base,
+		Statuses: make([]string, len(filter.Statuses)),
+		Groups:   []models.EsfVatLedgerGroup{},
+	}
+	for i, status := range filter.Statuses {
+		report.Statuses[i] = status.String()
+	}
+
+	// Строки приходят упорядоченными по ставке и ИНН, поэтому группы собираются за один проход
+	for i := range rows {
+		row := &rows[i]
+		if n := len(report.Groups); n == 0 || report.Groups[n-1].VatCode != row.TaxRateVATCode {
+			group := models.EsfVatLedgerGroup{VatCode: row.TaxRateVATCode, Contractors: []models.EsfVatLedgerContractor{}}
+			if rate, err := s.taxCalculator.Rates().VAT(row.TaxRateVATCode); err == nil {
+				group.VatName = rate.Name
+				group.VatPercent = rate.Percent
+			}
+			report.Groups = append(report.Groups, group)
+		}
+		group := &report.Groups[len(report.Groups)-1]
+
+		if n := len(group.Contractors); n == 0 || group.Contractors[n-1].ContractorTin != row.ContractorTin {
+			group.Contractors = append(group.Contractors, models.EsfVatLedgerContractor{
+				ContractorTin:  row.ContractorTin,
+				ContractorName: ledgerContractorName(names, row),
+			})
+		}
+		contractor := &group.Contractors[len(group.Contractors)-1]
+
+		doc := ledgerDocumentToModel(row)
+		contractor.Documents = append(contractor.Documents, doc)
+		addLedgerTotals(&contractor.Totals, &doc)
+		addLedgerTotals(&group.Totals, &doc)
+		addLedgerTotals(&report.Totals, &doc)
+	}
+
+	return report, nil
+}
+
+// ExportVATLedgerCSV возвращает книгу продаж или покупок в CSV: строки документов, затем итоги контрагента,
+// ставки и отчета. Файл начинается с BOM, чтобы Excel распознал кодировку UTF-8.
+func (s *esfReportService) ExportVATLedgerCSV(ctx context.Context, orgID uuid.UUID, req *models.EsfVatLedgerRequest) ([]byte, error) {
+	report, err := s.GetVATLedger(ctx, orgID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	_ = w.Write(ledgerCSVHeader)
+
+	for _, group := range report.Groups {
+		rate := strconv.FormatFloat(group.VatPercent, 'f', -1, 64)
+		for _, c := range group.Contractors {
+			for _, doc := range c.Documents {
+				_ = w.Write(append([]string{
+					"document", group.VatCode, group.VatName, rate, c.ContractorTin, c.ContractorName,
+					doc.Number, doc.DeliveryDate.Format(currency.DateLayout), doc.Status,
+					strconv.FormatBool(doc.IsCorrection), "1", strconv.Itoa(doc.Entries),
+				}, ledgerAmounts(doc.TaxableBase, doc.VatAmount, doc.SalesTaxAmount, doc.TotalAmount)...))
+			}
+			_ = w.Write(ledgerTotalsRow("contractor", group.VatCode, group.VatName, rate, c.ContractorTin, c.ContractorName, c.Totals))
+		}
+		_ = w.Write(ledgerTotalsRow("vat_code", group.VatCode, group.VatName, rate, "", "", group.Totals))
+	}
+	_ = w.Write(ledgerTotalsRow("total", "", "", "", "", "", report.Totals))
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, apperror.New(apperror.ErrInternal, "failed to write VAT ledger").WithError(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ledgerFilter проверяет параметры книги и возвращает условия выборки:
+// книга продаж строится по исходящим документам, книга покупок - по входящим
+func ledgerFilter(req *models.EsfVatLedgerRequest) (repository.LedgerFilter, string, error) {
+	var fields []apperror.FieldError
+
+	ledger := strings.ToLower(strings.TrimSpace(req.Ledger))
+	direction, defaults := entity.DocumentDirectionOutgoing, defaultLedgerStatuses
+	switch ledger {
+	case "", LedgerSales:
+		ledger = LedgerSales
+	case LedgerPurchases:
+		direction, defaults = entity.DocumentDirectionIncoming, defaultPurchaseStatuses
+	default:
+		fields = append(fields, apperror.FieldError{Field: "ledger", Message: fmt.Sprintf("unknown ledger %q, expected %q or %q", req.Ledger, LedgerSales, LedgerPurchases)})
+	}
+
+	filter, periodFields := periodFilter(req.From, req.To, req.Statuses, defaults, maxLedgerPeriod)
+	filter.Direction = direction
+	fields = append(fields, periodFields...)
+
+	if len(fields) > 0 {
+		return repository.LedgerFilter{}, "", apperror.FieldValidationError("invalid VAT ledger parameters", fields)
+	}
+	return filter, ledger, nil
+}
+
+// periodFilter проверяет период и статусы отчета; To включается в период, пустые статусы
+// заменяются статусами defaults
+func periodFilter(from, to time.Time, rawStatuses []string, defaults []entity.DocumentStatus, maxPeriod time.Duration) (repository.LedgerFilter, []apperror.FieldError) {
+	var fields []apperror.FieldError
+
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		fields = append(fields, apperror.FieldError{Field: "to", Message: "period end must not be before its start"})
+	} else if to.Sub(from) >= maxPeriod {
+		fields = append(fields, apperror.FieldError{Field: "to", Message: fmt.Sprintf("period must not exceed %d days", int(maxPeriod.Hours()/24))})
+	}
+
+	statuses := defaults
+	if len(rawStatuses) > 0 {
+		statuses = make([]entity.DocumentStatus, 0, len(rawStatuses))
+		seen := make(map[entity.DocumentStatus]bool, len(rawStatuses))
+		for _, raw := range rawStatuses {
+			status := entity.DocumentStatus(strings.ToLower(strings.TrimSpace(raw)))
+			if !status.IsValid() {
+				fields = append(fields, apperror.FieldError{Field: "status", Message: fmt.Sprintf("unknown document status %q", raw)})
+				continue
+			}
+			if !seen[status] {
+				seen[status] = true
+				statuses = append(statuses, status)
+			}
+		}
+	}
+
+	return repository.LedgerFilter{From: from, To: to.AddDate(0, 0, 1), Statuses: statuses}, fields
+}
+
+// contractorNames возвращает справочник контрагентов организации по ID и ИНН;
+// без справочника наименования берутся только из документов
+func (s *esfReportService) contractorNames(ctx context.Context, orgID uuid.UUID) (map[string]string, error) {
+	if s.contractors == nil {
+		return map[string]string{}, nil
+	}
+	contractors, err := s.contractors.GetContractors(ctx, orgID, "")
+	if err != nil {
+		return nil, repositoryError("fetching contractors", err)
+	}
+	names := make(map[string]string, len(contractors)*2)
+	for _, c := range contractors {
+		names[c.ID.String()] = c.Name
+		names[c.Tin] = c.Name
+	}
+	return names, nil
+}
+
+// ledgerContractorName ищет наименование по контрагенту документа, затем по ИНН;
+// для входящего документа без контрагента в справочнике берется наименование поставщика из документа
+func ledgerContractorName(names map[string]string, row *repository.LedgerDocument) string {
+	if row.ContractorID != nil {
+		if name, ok := names[row.ContractorID.String()]; ok {
+			return name
+		}
+	}
+	if name, ok := names[row.ContractorTin]; ok {
+		return name
+	}
+	return row.SupplierName
+}
+
+func ledgerDocumentToModel(row *repository.LedgerDocument) models.EsfVatLedgerDocument {
+	return models.EsfVatLedgerDocument{
+		ID:             row.ID,
+		Number:         row.Number,
+		DeliveryDate:   row.DeliveryDate,
+		Status:         row.Status.String(),
+		IsCorrection:   row.OriginalDocumentID != nil,
+		CurrencyCode:   row.CurrencyCode,
+		Entries:        row.Entries,
+		TaxableBase:    row.AmountWithoutTaxes,
+		VatAmount:      row.VatAmount,
+		SalesTaxAmount: row.SalesTaxAmount,
+		TotalAmount:    row.TotalAmount,
+	}
+}
+
+func addLedgerTotals(t *models.EsfVatLedgerTotals, doc *models.EsfVatLedgerDocument) {
+	t.Documents++
+	t.Entries += doc.Entries
+	t.TaxableBase = t.TaxableBase.Add(doc.TaxableBase)
+	t.VatAmount = t.VatAmount.Add(doc.VatAmount)
+	t.SalesTaxAmount = t.SalesTaxAmount.Add(doc.SalesTaxAmount)
+	t.TotalAmount = t.TotalAmount.Add(doc.TotalAmount)
+}
+
+func ledgerTotalsRow(rowType, vatCode, vatName, rate, tin, name string, t models.EsfVatLedgerTotals) []string {
+	return append([]string{
+		rowType, vatCode, vatName, rate, tin, name, "", "", "", "",
+		strconv.Itoa(t.Documents), strconv.Itoa(t.Entries),
+	}, ledgerAmounts(t.TaxableBase, t.VatAmount, t.SalesTaxAmount, t.TotalAmount)...)
+}
+
+func ledgerAmounts(amounts ...money.Amount) []string {
+	result := make([]string, len(amounts))
+	for i, a := range amounts {
+		result[i] = a.String()
+	}
+	return result
+}
@@ -0,0 +1,179 @@
+package service_impl
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/rusgainew/tunduck-app/internal/models"
+	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func ledgerRow(vatCode, tin, number string, base, vat, salesTax string) repository.LedgerDocument {
+	row := repository.LedgerDocument{
+		ID:                 uuid.New(),
+		Number:             number,
+		DeliveryDate:       time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		Status:             entity.DocumentStatusAccepted,
+		TaxRateVATCode:     vatCode,
+		ContractorTin:      tin,
+		Entries:            2,
+		AmountWithoutTaxes: money.MustParse(base),
+		VatAmount:          money.MustParse(vat),
+		SalesTaxAmount:     money.MustParse(salesTax),
+	}
+	row.TotalAmount = money.Sum(row.AmountWithoutTaxes, row.VatAmount, row.SalesTaxAmount)
+	return row
+}
+
+func newLedgerService(orgID uuid.UUID, rows []repository.LedgerDocument) (*MockReportRepository, *esfReportService) {
+	mockRepo := new(MockReportRepository)
+	mockRepo.On("GetLedgerDocuments", mock.Anything, orgID, mock.Anything).Return(rows, nil)
+	contractors := new(MockContractorRepository)
+	contractors.On("GetContractors", mock.Anything, orgID, "").Return([]entity.EsfContractor{*newContractor(uuid.New())}, nil)
+	service := NewEsfReportService(mockRepo, contractors, logrus.New())
+	return mockRepo, service.(*esfReportService)
+}
+
+// ========== VAT Ledger Tests ==========
+
+func TestEsfVatLedger_GroupsAndReconciles(t *testing.T) {
+	orgID := uuid.New()
+	correction := ledgerRow("1", "01503201910012", "ЭСФ-2024-00003", "-100.10", "-12.01", "0")
+	correction.OriginalDocumentID = &uuid.UUID{}
+	rows := []repository.LedgerDocument{
+		ledgerRow("1", "01503201910012", "ЭСФ-2024-00001", "1000.10", "120.01", "0"),
+		correction,
+		ledgerRow("1", "02201199900123", "ЭСФ-2024-00002", "0.10", "0.01", "0.02"),
+		ledgerRow("3", "02201199900123", "ЭСФ-2024-00004", "500", "0", "10"),
+	}
+	mockRepo, service := newLedgerService(orgID, rows)
+
+	report, err := service.GetVATLedger(context.Background(), orgID, &models.EsfVatLedgerRequest{
+		From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	mockRepo.AssertCalled(t, "GetLedgerDocuments", mock.Anything, orgID, repository.LedgerFilter{
+		From:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Statuses:  defaultLedgerStatuses,
+		Direction: entity.DocumentDirectionOutgoing,
+	})
+	assert.Equal(t, LedgerSales, report.Ledger)
+	assert.Equal(t, []string{"signed", "submitting", "submitted", "accepted"}, report.Statuses)
+
+	require.Len(t, report.Groups, 2)
+	vat12 := report.Groups[0]
+	assert.Equal(t, "НДС 12%", vat12.VatName)
+	assert.Equal(t, float64(12), vat12.VatPercent)
+	require.Len(t, vat12.Contractors, 2)
+	assert.Equal(t, "ОсОО Ала-Тоо", vat12.Contractors[0].ContractorName)
+	assert.Empty(t, vat12.Contractors[1].ContractorName)
+	assert.True(t, vat12.Contractors[0].Documents[1].IsCorrection)
+	assert.Equal(t, money.MustParse("900.00"), vat12.Contractors[0].Totals.TaxableBase)
+	assert.Equal(t, money.MustParse("900.10"), vat12.Totals.TaxableBase)
+	assert.Equal(t, money.MustParse("108.01"), vat12.Totals.VatAmount)
+	assert.Equal(t, 3, vat12.Totals.Documents)
+	assert.Equal(t, 6, vat12.Totals.Entries)
+
+	// Итоги отчета совпадают с суммой всех строк
+	var base, vat, salesTax, total money.Amount
+	for _, row := range rows {
+		base, vat = base.Add(row.AmountWithoutTaxes), vat.Add(row.VatAmount)
+		salesTax, total = salesTax.Add(row.SalesTaxAmount), total.Add(row.TotalAmount)
+	}
+	assert.Equal(t, models.EsfVatLedgerTotals{
+		Documents: 4, Entries: 8, TaxableBase: base, VatAmount: vat, SalesTaxAmount: salesTax, TotalAmount: total,
+	}, report.Totals)
+	assert.Equal(t, "1400.10", report.Totals.TaxableBase.String())
+}
+
+func TestEsfVatLedger_ExportCSV(t *testing.T) {
+	orgID := uuid.New()
+	_, service := newLedgerService(orgID, []repository.LedgerDocument{
+		ledgerRow("1", "01503201910012", "ЭСФ-2024-00001", "1000.10", "120.01", "0"),
+		ledgerRow("1", "01503201910012", "ЭСФ-2024-00002", "0.20", "0.02", "0"),
+	})
+
+	content, err := service.ExportVATLedgerCSV(context.Background(), orgID, &models.EsfVatLedgerRequest{
+		From: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(content), "\ufeff"))
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(content), "\ufeff"))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, ledgerCSVHeader, records[0])
+	assert.Equal(t, []string{
+		"document", "1", "НДС 12%", "12", "01503201910012", "ОсОО Ала-Тоо", "ЭСФ-2024-00001", "2024-03-15", "accepted", "false", "1", "2",
+		"1000.10", "120.01", "0.00", "1120.11",
+	}, records[1])
+	assert.Equal(t, []string{"contractor", "1000.30", "120.03"}, []string{records[3][0], records[3][12], records[3][13]})
+	assert.Equal(t, "vat_code", records[4][0])
+	assert.Equal(t, []string{"total", "2", "4", "1000.30", "120.03", "0.00", "1120.33"},
+		append([]string{records[5][0]}, records[5][10:]...))
+}
+
+func TestEsfVatLedger_PurchaseLedger(t *testing.T) {
+	orgID := uuid.New()
+	row := ledgerRow("1", "02201199900123", "А-17", "1000", "120", "0")
+	row.Direction = entity.DocumentDirectionIncoming
+	row.SupplierName = "ОсОО Поставщик"
+	mockRepo, service := newLedgerService(orgID, []repository.LedgerDocument{row})
+
+	report, err := service.GetVATLedger(context.Background(), orgID, &models.EsfVatLedgerRequest{
+		From:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC),
+		Ledger: "Purchases",
+	})
+	require.NoError(t, err)
+
+	mockRepo.AssertCalled(t, "GetLedgerDocuments", mock.Anything, orgID, repository.LedgerFilter{
+		From:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:        time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Statuses:  defaultPurchaseStatuses,
+		Direction: entity.DocumentDirectionIncoming,
+	})
+	assert.Equal(t, LedgerPurchases, report.Ledger)
+	assert.Equal(t, []string{"accepted"}, report.Statuses)
+	require.Len(t, report.Groups, 1)
+	require.Len(t, report.Groups[0].Contractors, 1)
+	assert.Equal(t, "02201199900123", report.Groups[0].Contractors[0].ContractorTin)
+	assert.Equal(t, "ОсОО Поставщик", report.Groups[0].Contractors[0].ContractorName)
+	assert.Equal(t, "120.00", report.Totals.VatAmount.String())
+}
+
+func TestEsfVatLedger_ValidatesParameters(t *testing.T) {
+	mockRepo := new(MockReportRepository)
+	service := NewEsfReportService(mockRepo, nil, logrus.New())
+
+	_, err := service.GetVATLedger(context.Background(), uuid.New(), &models.EsfVatLedgerRequest{
+		From:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		Ledger:   "returns",
+		Statuses: []string{"signed", "unknown"},
+	})
+
+	appErr, ok := err.(*apperror.AppError)
+	require.True(t, ok)
+	fields := make([]string, len(appErr.Fields))
+	for i, f := range appErr.Fields {
+		fields[i] = f.Field
+	}
+	assert.Equal(t, []string{"ledger", "to", "status"}, fields)
+	mockRepo.AssertNotCalled(t, "GetLedgerDocuments", mock.Anything, mock.Anything, mock.Anything)
+}
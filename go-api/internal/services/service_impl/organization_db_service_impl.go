@@ -91,8 +91,8 @@ func (s *OrganizationDBServiceImpl) CreateOrganizationDatabase(ctx context.Conte
 		s.logger.WithError(err).Warn("Failed to create uuid-ossp extension")
 	}
 
-	// Выполняем миграции для новой БД (создаем таблицы EsfDocument и EsfEntries)
-	if err := newDB.AutoMigrate(&entity.EsfDocument{}, &entity.EsfEntries{}); err != nil {
+	// Выполняем миграции для новой БД (создаем таблицы документов организации)
+	if err := newDB.AutoMigrate(entity.OrganizationModels()...); err != nil {
 		s.logger.WithError(err).Error("Failed to migrate organization database")
 		return fmt.Errorf("failed to migrate organization database: %w", err)
 	}
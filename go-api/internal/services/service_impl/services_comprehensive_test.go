@@ -10,8 +10,11 @@ import (
 	"github.com/google/uuid"
 	"github.com/rusgainew/tunduck-app/internal/models"
 	"github.com/rusgainew/tunduck-app/internal/repository"
+	"github.com/rusgainew/tunduck-app/pkg/apperror"
 	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
 	"github.com/rusgainew/tunduck-app/pkg/pagination"
+	"github.com/rusgainew/tunduck-app/pkg/tax"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -45,6 +48,16 @@ func (m *MockDocumentRepository) CreateDocument(ctx context.Context, orgID uuid.
 	return args.Error(0)
 }
 
+func (m *MockDocumentRepository) CreateDocuments(ctx context.Context, orgID uuid.UUID, docs []entity.EsfDocument) error {
+	args := m.Called(ctx, orgID, docs)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) SaveDocuments(ctx context.Context, orgID uuid.UUID, created, updated []entity.EsfDocument) error {
+	args := m.Called(ctx, orgID, created, updated)
+	return args.Error(0)
+}
+
 func (m *MockDocumentRepository) UpdateDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument) error {
 	args := m.Called(ctx, orgID, doc)
 	return args.Error(0)
@@ -55,6 +68,102 @@ func (m *MockDocumentRepository) DeleteDocument(ctx context.Context, orgID uuid.
 	return args.Error(0)
 }
 
+func (m *MockDocumentRepository) UpdateDocumentStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from, to entity.DocumentStatus, reason string) error {
+	args := m.Called(ctx, orgID, id, from, to, reason)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) UpdateBuyerStatus(ctx context.Context, orgID uuid.UUID, id uuid.UUID, update repository.BuyerStatusUpdate) error {
+	args := m.Called(ctx, orgID, id, update)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) GetDeliveredCopy(ctx context.Context, orgID uuid.UUID, sellerOrgID uuid.UUID, sellerDocID uuid.UUID) (*entity.EsfDocument, error) {
+	args := m.Called(ctx, orgID, sellerOrgID, sellerDocID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfDocument), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetDocumentApprovals(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentApproval, error) {
+	args := m.Called(ctx, orgID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.EsfDocumentApproval), args.Error(1)
+}
+
+func (m *MockDocumentRepository) DecideDocumentApproval(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, approval *entity.EsfDocumentApproval, to entity.DocumentStatus) error {
+	args := m.Called(ctx, orgID, doc, approval, to)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) MarkDocumentSubmitted(ctx context.Context, orgID uuid.UUID, id uuid.UUID, from entity.DocumentStatus, responseID, externalUUID string) error {
+	args := m.Called(ctx, orgID, id, from, responseID, externalUUID)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) GetDocumentCorrections(ctx context.Context, orgID uuid.UUID, originalID uuid.UUID) ([]entity.EsfDocument, error) {
+	args := m.Called(ctx, orgID, originalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.EsfDocument), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetDocumentRevisions(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentRevision, error) {
+	args := m.Called(ctx, orgID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.EsfDocumentRevision), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetDocumentRevision(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID, revision int) (*entity.EsfDocumentRevision, error) {
+	args := m.Called(ctx, orgID, documentID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfDocumentRevision), args.Error(1)
+}
+
+func (m *MockDocumentRepository) GetPrintTemplate(ctx context.Context, orgID uuid.UUID, name string) (*entity.EsfPrintTemplate, error) {
+	args := m.Called(ctx, orgID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.EsfPrintTemplate), args.Error(1)
+}
+
+func (m *MockDocumentRepository) SavePrintTemplate(ctx context.Context, orgID uuid.UUID, tpl *entity.EsfPrintTemplate) error {
+	args := m.Called(ctx, orgID, tpl)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) DeletePrintTemplate(ctx context.Context, orgID uuid.UUID, name string) error {
+	args := m.Called(ctx, orgID, name)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) GetDocumentSignatures(ctx context.Context, orgID uuid.UUID, documentID uuid.UUID) ([]entity.EsfDocumentSignature, error) {
+	args := m.Called(ctx, orgID, documentID)
+	return args.Get(0).([]entity.EsfDocumentSignature), args.Error(1)
+}
+
+func (m *MockDocumentRepository) SignDocument(ctx context.Context, orgID uuid.UUID, doc *entity.EsfDocument, signature *entity.EsfDocumentSignature, to entity.DocumentStatus) error {
+	args := m.Called(ctx, orgID, doc, signature, to)
+	return args.Error(0)
+}
+
+func (m *MockDocumentRepository) SearchDocuments(ctx context.Context, orgID uuid.UUID, query string, params pagination.PaginationParams) ([]repository.DocumentSearchHit, int64, error) {
+	args := m.Called(ctx, orgID, query, params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]repository.DocumentSearchHit), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockDocumentRepository) GetAllDocumentsPaginated(ctx context.Context, orgID uuid.UUID, params pagination.PaginationParams, filters pagination.DocumentFilterParams) ([]entity.EsfDocument, int64, error) {
 	args := m.Called(ctx, orgID, params, filters)
 	if args.Get(0) == nil {
@@ -65,6 +174,71 @@ func (m *MockDocumentRepository) GetAllDocumentsPaginated(ctx context.Context, o
 
 var _ repository.EsfDocumentRepository = (*MockDocumentRepository)(nil)
 
+// documentOption изменяет документ, построенный newTestDocument
+type documentOption func(*entity.EsfDocument)
+
+// newTestDocument возвращает исходящий черновик с одной позицией 10 x 100 сомов и НДС 12% сверху цены
+func newTestDocument(id uuid.UUID, opts ...documentOption) *entity.EsfDocument {
+	doc := &entity.EsfDocument{
+		ID:                   id,
+		Number:               "INV-2026-000007",
+		Status:               entity.DocumentStatusDraft,
+		UpdatedAt:            time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC),
+		ContractorTin:        "01234567890123",
+		SupplyContractNumber: "Д-42",
+		TaxRateVATCode:       "1",
+		IsPriceWithoutTaxes:  true,
+		DeliveryDate:         time.Date(2026, 3, 1, 0, 0, 0, 0, time.FixedZone("KGT", 6*3600)),
+	}
+	withEntries(testEntry(uuid.MustParse("a0000000-0000-0000-0000-000000000001"), 10, money.MustParse("100")))(doc)
+	for _, opt := range opts {
+		opt(doc)
+	}
+	return doc
+}
+
+// testEntry возвращает позицию документа без рассчитанных сумм
+func testEntry(id uuid.UUID, quantity float64, price money.Amount) entity.EsfEntries {
+	return entity.EsfEntries{ID: id, SalesTaxCode: "001", Quantity: quantity, Price: price}
+}
+
+// withStatus задает статус документа
+func withStatus(status entity.DocumentStatus) documentOption {
+	return func(doc *entity.EsfDocument) {
+		doc.Status = status
+	}
+}
+
+// withEntries заменяет позиции документа и рассчитывает суммы позиций и итоги документа
+func withEntries(entries ...entity.EsfEntries) documentOption {
+	return func(doc *entity.EsfDocument) {
+		lines := make([]tax.LineInput, len(entries))
+		for i, entry := range entries {
+			lines[i] = tax.LineInput{Quantity: entry.Quantity, Price: entry.Price, SalesTaxCode: entry.SalesTaxCode}
+		}
+		result, err := tax.NewCalculator(nil).CalculateDocument(tax.DocumentInput{
+			VATCode:           doc.TaxRateVATCode,
+			PriceWithoutTaxes: doc.IsPriceWithoutTaxes,
+			Lines:             lines,
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		doc.CatalogEntries = make([]entity.EsfEntries, len(entries))
+		for i, entry := range entries {
+			entry.DocumentID = doc.ID
+			entry.AmountWithoutTaxes = result.Lines[i].AmountWithoutTaxes
+			entry.VatAmount = result.Lines[i].VatAmount
+			entry.SalesTaxAmount = result.Lines[i].SalesTaxAmount
+			entry.TotalAmount = result.Lines[i].TotalAmount
+			doc.CatalogEntries[i] = entry
+		}
+		doc.TotalCurrencyValueWithoutTaxes = result.AmountWithoutTaxes
+		doc.TotalCurrencyValue = result.TotalAmount
+	}
+}
+
 // ========== GetAllDocuments Tests ==========
 
 func TestEsfDocumentGetAll_Success(t *testing.T) {
@@ -128,6 +302,33 @@ func TestEsfDocumentGetByID_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestEsfDocumentGetByID_IsCachedPerOrganization tests that a cached document is
+// served from the cache and is not returned for another organization
+func TestEsfDocumentGetByID_IsCachedPerOrganization(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID, otherOrgID := uuid.New(), uuid.New()
+	docID := uuid.New()
+	doc := newTestDocument(docID)
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(doc, nil).Once()
+	mockRepo.On("GetDocumentByID", mock.Anything, otherOrgID, docID).Return(nil, apperror.New(apperror.ErrNotFound, "document not found")).Once()
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	service.SetCacheManager(memoryCacheManager{newMemoryCache()})
+
+	first, err := service.GetDocumentByID(context.Background(), orgID, docID)
+	assert.NoError(t, err)
+	cached, err := service.GetDocumentByID(context.Background(), orgID, docID)
+	assert.NoError(t, err)
+	assert.Equal(t, doc.Number, cached.Number)
+	assert.True(t, first.DeliveryDate.Equal(*cached.DeliveryDate))
+	assert.Equal(t, first.CatalogEntries, cached.CatalogEntries)
+
+	_, err = service.GetDocumentByID(context.Background(), otherOrgID, docID)
+	assert.Error(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
 // ========== CreateDocument Tests ==========
 
 func TestEsfDocumentCreate_Success(t *testing.T) {
@@ -164,17 +365,35 @@ func TestEsfDocumentCreate_Error(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestEsfDocumentCreate_KeepsRepositoryErrorCode(t *testing.T) {
+	mockRepo := new(MockDocumentRepository)
+	orgID := uuid.New()
+
+	// Ошибка организации не должна превращаться в ошибку БД
+	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Return(apperror.New(apperror.ErrOrgNotFound, "organization not found"))
+
+	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
+	_, err := service.CreateDocument(context.Background(), orgID, &models.EsfCreateDocumentRequest{ForeignName: "Test"})
+
+	appErr, ok := err.(*apperror.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, apperror.ErrOrgNotFound, appErr.Code)
+}
+
 // ========== UpdateDocument Tests ==========
 
 func TestEsfDocumentUpdate_Success(t *testing.T) {
 	mockRepo := new(MockDocumentRepository)
 	orgID := uuid.New()
 
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("UpdateDocument", mock.Anything, orgID, mock.Anything).Return(nil)
 
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
 	req := &models.EsfEditDocumentRequest{
-		ID: uuid.New(),
+		ID: docID,
 		EsfCreateDocumentRequest: models.EsfCreateDocumentRequest{
 			ForeignName: "Updated",
 		},
@@ -189,11 +408,14 @@ func TestEsfDocumentUpdate_Error(t *testing.T) {
 	mockRepo := new(MockDocumentRepository)
 	orgID := uuid.New()
 
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("UpdateDocument", mock.Anything, orgID, mock.Anything).Return(errors.New("update failed"))
 
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
 	req := &models.EsfEditDocumentRequest{
-		ID: uuid.New(),
+		ID: docID,
 		EsfCreateDocumentRequest: models.EsfCreateDocumentRequest{
 			ForeignName: "Updated",
 		},
@@ -211,6 +433,7 @@ func TestEsfDocumentDelete_Success(t *testing.T) {
 	orgID := uuid.New()
 	docID := uuid.New()
 
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("DeleteDocument", mock.Anything, orgID, docID).Return(nil)
 
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
@@ -225,6 +448,7 @@ func TestEsfDocumentDelete_Error(t *testing.T) {
 	orgID := uuid.New()
 	docID := uuid.New()
 
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("DeleteDocument", mock.Anything, orgID, docID).Return(errors.New("deletion failed"))
 
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
@@ -409,6 +633,7 @@ func TestEsfDocumentConcurrentOperations(t *testing.T) {
 	// Mock multiple operations
 	mockRepo.On("GetAllDocuments", mock.Anything, orgID).Return([]entity.EsfDocument{}, nil)
 	mockRepo.On("CreateDocument", mock.Anything, orgID, mock.Anything).Return(nil)
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, mock.Anything).Return(&entity.EsfDocument{Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("UpdateDocument", mock.Anything, orgID, mock.Anything).Return(nil)
 	mockRepo.On("DeleteDocument", mock.Anything, orgID, mock.Anything).Return(nil)
 
@@ -474,11 +699,13 @@ func TestEsfDocumentUpdateNonExistentDocument(t *testing.T) {
 	mockRepo := new(MockDocumentRepository)
 	orgID := uuid.New()
 
-	mockRepo.On("UpdateDocument", mock.Anything, orgID, mock.Anything).Return(errors.New("document not found"))
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(nil, apperror.New(apperror.ErrDocumentNotFound, "document not found"))
 
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
 	err := service.UpdateDocument(context.Background(), orgID, &models.EsfEditDocumentRequest{
-		ID: uuid.New(),
+		ID: docID,
 	})
 
 	assert.Error(t, err)
@@ -568,11 +795,14 @@ func BenchmarkEsfDocumentUpdate(b *testing.B) {
 	mockRepo := new(MockDocumentRepository)
 	orgID := uuid.New()
 
+	docID := uuid.New()
+
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("UpdateDocument", mock.Anything, orgID, mock.Anything).Return(nil)
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
 
 	req := &models.EsfEditDocumentRequest{
-		ID: uuid.New(),
+		ID: docID,
 		EsfCreateDocumentRequest: models.EsfCreateDocumentRequest{
 			ForeignName: "Updated Benchmark Doc",
 		},
@@ -589,6 +819,7 @@ func BenchmarkEsfDocumentDelete(b *testing.B) {
 	orgID := uuid.New()
 	docID := uuid.New()
 
+	mockRepo.On("GetDocumentByID", mock.Anything, orgID, docID).Return(&entity.EsfDocument{ID: docID, Status: entity.DocumentStatusDraft}, nil)
 	mockRepo.On("DeleteDocument", mock.Anything, orgID, docID).Return(nil)
 	service := NewEsfDocumentService(mockRepo, nil, logrus.New())
 
@@ -38,8 +38,11 @@ const (
 	ErrPasswordMismatch ErrorCode = "PASSWORD_MISMATCH"
 
 	// Document errors
-	ErrDocumentNotFound ErrorCode = "DOCUMENT_NOT_FOUND"
-	ErrInvalidDocument  ErrorCode = "INVALID_DOCUMENT"
+	ErrDocumentNotFound        ErrorCode = "DOCUMENT_NOT_FOUND"
+	ErrInvalidDocument         ErrorCode = "INVALID_DOCUMENT"
+	ErrDocumentLocked          ErrorCode = "DOCUMENT_LOCKED"
+	ErrInvalidStatusTransition ErrorCode = "INVALID_STATUS_TRANSITION"
+	ErrApprovalRequired        ErrorCode = "APPROVAL_REQUIRED"
 
 	// Organization errors
 	ErrOrgNotFound ErrorCode = "ORGANIZATION_NOT_FOUND"
@@ -57,14 +60,21 @@ const (
 	ErrConfigError ErrorCode = "CONFIG_ERROR"
 )
 
+// FieldError описывает ошибку валидации конкретного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
 // AppError представляет структурированную ошибку приложения
 type AppError struct {
-	Code       ErrorCode `json:"code"`
-	Message    string    `json:"message"`
-	Details    string    `json:"details,omitempty"`
-	HTTPStatus int       `json:"-"`
-	Err        error     `json:"-"` // Оригинальная ошибка для логирования
-	StackTrace string    `json:"-"`
+	Code       ErrorCode    `json:"code"`
+	Message    string       `json:"message"`
+	Details    string       `json:"details,omitempty"`
+	Fields     []FieldError `json:"fields,omitempty"`
+	HTTPStatus int          `json:"-"`
+	Err        error        `json:"-"` // Оригинальная ошибка для логирования
+	StackTrace string       `json:"-"`
 }
 
 // Error реализует интерфейс error
@@ -106,6 +116,12 @@ func (e *AppError) WithDetails(details string) *AppError {
 	return e
 }
 
+// WithFieldErrors добавляет ошибки валидации отдельных полей
+func (e *AppError) WithFieldErrors(fields []FieldError) *AppError {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
 // WithHTTPStatus устанавливает HTTP статус
 func (e *AppError) WithHTTPStatus(status int) *AppError {
 	e.HTTPStatus = status
@@ -134,7 +150,8 @@ func getHTTPStatus(code ErrorCode) int {
 
 	// 409 Conflict
 	case ErrAlreadyExists, ErrConflict, ErrUserExists, ErrEmailExists,
-		ErrUsernameExists, ErrOrgExists, ErrAccountBlocked:
+		ErrUsernameExists, ErrOrgExists, ErrAccountBlocked,
+		ErrDocumentLocked, ErrInvalidStatusTransition, ErrApprovalRequired:
 		return http.StatusConflict
 
 	// 500 Internal Server Error
@@ -160,9 +177,10 @@ func (e *AppError) Is(target error) bool {
 
 // ErrorResponse структура для отправки ошибки в HTTP ответе
 type ErrorResponse struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details string       `json:"details,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"`
 }
 
 // ToResponse преобразует AppError в ErrorResponse
@@ -171,6 +189,7 @@ func (e *AppError) ToResponse() *ErrorResponse {
 		Code:    string(e.Code),
 		Message: e.Message,
 		Details: e.Details,
+		Fields:  e.Fields,
 	}
 }
 
@@ -180,6 +199,10 @@ func ValidationError(message string) *AppError {
 	return New(ErrValidation, message)
 }
 
+func FieldValidationError(message string, fields []FieldError) *AppError {
+	return New(ErrFieldValidation, message).WithFieldErrors(fields)
+}
+
 func UnauthorizedError(message string) *AppError {
 	return New(ErrUnauthorized, message)
 }
@@ -0,0 +1,67 @@
+// Package blobstore хранит двоичные объекты (вложения документов) по ключу.
+//
+// Ключ - путь из сегментов, разделенных '/', например "org/document/attachment".
+// Реализация выбирается конфигурацией: LocalStore хранит объекты в каталоге файловой системы;
+// хранилище, совместимое с S3, подключается реализацией того же интерфейса.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound объект с ключом не найден
+var ErrNotFound = errors.New("blob not found")
+
+// keySegmentPattern допустимый сегмент ключа
+var keySegmentPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// BlobStore хранилище двоичных объектов
+type BlobStore interface {
+	// Put сохраняет объект целиком, заменяя существующий; возвращает число записанных байт.
+	// При ошибке частично записанный объект не остается в хранилище.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get открывает объект на чтение; возвращает ErrNotFound, если объекта нет
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; удаление отсутствующего объекта не считается ошибкой
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidateKey проверяет ключ объекта
+func ValidateKey(key string) error {
+	if key == "" {
+		return errors.New("blob key is empty")
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if !keySegmentPattern.MatchString(segment) || strings.Contains(segment, "..") {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// NewFromEnv создает хранилище по переменным окружения:
+// ATTACHMENTS_STORAGE - тип хранилища (local, по умолчанию),
+// ATTACHMENTS_DIR - каталог локального хранилища (по умолчанию attachments).
+// Возвращает хранилище и его описание для журнала.
+func NewFromEnv() (BlobStore, string, error) {
+	switch storage := os.Getenv("ATTACHMENTS_STORAGE"); storage {
+	case "", "local":
+		dir := os.Getenv("ATTACHMENTS_DIR")
+		if dir == "" {
+			dir = "attachments"
+		}
+		store, err := NewLocalStore(dir)
+		if err != nil {
+			return nil, "", err
+		}
+		return store, "local:" + dir, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported attachments storage %q", storage)
+	}
+}
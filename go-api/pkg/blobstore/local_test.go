@@ -0,0 +1,65 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocalStore tests the put/get/delete cycle of the filesystem store
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	written, err := store.Put(ctx, "org/doc/file", strings.NewReader("scan"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), written)
+
+	r, err := store.Get(ctx, "org/doc/file")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "scan", string(data))
+
+	require.NoError(t, store.Delete(ctx, "org/doc/file"))
+	require.NoError(t, store.Delete(ctx, "org/doc/file"))
+	_, err = store.Get(ctx, "org/doc/file")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// TestLocalStorePutFailure tests that a failed upload leaves no blob behind
+func TestLocalStorePutFailure(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	require.NoError(t, err)
+
+	_, err = store.Put(context.Background(), "org/doc/file", io.MultiReader(strings.NewReader("partial"), failingReader{}))
+	require.Error(t, err)
+
+	entries, err := os.ReadDir(filepath.Join(root, "org", "doc"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestValidateKey tests that keys cannot escape the storage root
+func TestValidateKey(t *testing.T) {
+	assert.NoError(t, ValidateKey("3f1c/9a2b/file.pdf"))
+	for _, key := range []string{"", "../etc/passwd", "org//doc", "/abs", "org/.hidden", "org/a..b"} {
+		assert.Error(t, ValidateKey(key), key)
+	}
+}
@@ -0,0 +1,73 @@
+// Package currency содержит официальные курсы валют к сому: разбор файлов курсов,
+// интерфейс источника курсов и пересчет сумм.
+//
+// Курс задается как количество сомов за Nominal единиц валюты (как публикует
+// Национальный банк); для пересчета используется курс за одну единицу.
+package currency
+
+import (
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/rusgainew/tunduck-app/pkg/reference"
+)
+
+// Base буквенный код национальной валюты
+const Base = "KGS"
+
+var (
+	numericOnce  sync.Once
+	numericCodes map[string]string
+)
+
+// Normalize приводит код валюты к буквенному коду ISO 4217.
+// Цифровые коды переводятся по встроенному справочнику валют; неизвестные коды возвращаются как есть.
+func Normalize(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	numericOnce.Do(loadNumericCodes)
+	if alpha, ok := numericCodes[code]; ok {
+		return alpha
+	}
+	return code
+}
+
+// IsBase проверяет, что документ выписан в сомах. Пустой код считается сомом.
+func IsBase(code string) bool {
+	code = Normalize(code)
+	return code == "" || code == Base
+}
+
+// ToCurrency пересчитывает сумму в сомах в валюту по курсу за единицу валюты.
+// Возвращает money.ErrRange, если результат не помещается в decimal(15,2).
+func ToCurrency(amount money.Amount, rate float64) (money.Amount, error) {
+	if rate <= 0 {
+		return 0, nil
+	}
+	return amount.Div(rate)
+}
+
+func loadNumericCodes() {
+	numericCodes = make(map[string]string)
+	files, err := reference.Bundled()
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		if file.Catalog != entity.ReferenceCurrency {
+			continue
+		}
+		for _, item := range file.Items {
+			if item.AltCode != "" {
+				numericCodes[item.AltCode] = item.Code
+			}
+		}
+	}
+}
+
+// RoundRate округляет курс до 4 знаков, как он хранится в документе
+func RoundRate(rate float64) float64 {
+	return math.Round(rate*10000) / 10000
+}
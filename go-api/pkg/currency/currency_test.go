@@ -0,0 +1,80 @@
+package currency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalize tests numeric to alphabetic currency code conversion
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "USD", Normalize("840"))
+	assert.Equal(t, "USD", Normalize(" usd "))
+	assert.Equal(t, "XXX", Normalize("XXX"))
+	assert.True(t, IsBase("417"))
+	assert.True(t, IsBase(""))
+	assert.False(t, IsBase("EUR"))
+}
+
+// TestParse tests rates file parsing
+func TestParse(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		rates, err := Parse([]byte("date;currency;rate;nominal\n2024-01-15;840;89,45;\n2024-01-15;KZT;19.5;100\n"), "csv")
+		require.NoError(t, err)
+		require.Len(t, rates, 2)
+		assert.Equal(t, "USD", rates[0].Currency)
+		assert.Equal(t, 89.45, rates[0].PerUnit())
+		assert.Equal(t, 0.195, rates[1].PerUnit())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		rates, err := Parse([]byte(`[{"date": "2024-01-15", "currency": "EUR", "rate": 97.8}]`), FormatJSON)
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), rates[0].Date)
+		assert.Equal(t, 1, rates[0].Nominal)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Parse([]byte("date,currency,rate\n2024-01-15,USD,89\n2024-01-15,840,90\n"), "csv")
+		assert.ErrorContains(t, err, "duplicate rate")
+
+		_, err = Parse([]byte("date,currency,rate\n2024-01-15,KGS,1\n"), "csv")
+		assert.ErrorContains(t, err, "base currency")
+
+		_, err = Parse([]byte("date,currency\n2024-01-15,USD\n"), "csv")
+		assert.ErrorContains(t, err, `column "rate" is required`)
+	})
+}
+
+// TestFileFetcher tests that the file fetcher returns rates of the requested date
+func TestFileFetcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.csv")
+	require.NoError(t, os.WriteFile(path, []byte("date,currency,rate\n2024-01-15,USD,89.45\n2024-01-16,USD,89.5\n2024-01-16,EUR,97.8\n"), 0o600))
+
+	rates, err := NewFileFetcher(path).Fetch(context.Background(), time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	assert.Equal(t, "EUR", rates[0].Currency)
+	assert.Equal(t, 89.5, rates[1].Rate)
+}
+
+// TestToCurrency tests conversion of som amounts to the document currency
+func TestToCurrency(t *testing.T) {
+	v, err := ToCurrency(money.MustParse("1120"), 89.45)
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("12.52"), v)
+
+	v, err = ToCurrency(money.MustParse("1120"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, money.Amount(0), v)
+
+	_, err = ToCurrency(money.MustParse("9999999999999"), 0.001)
+	assert.ErrorIs(t, err, money.ErrRange)
+}
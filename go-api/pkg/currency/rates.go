@@ -0,0 +1,197 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/tabular"
+)
+
+// DateLayout формат даты курса
+const DateLayout = "2006-01-02"
+
+// FormatJSON формат файла курсов в JSON; CSV и XLSX читаются через пакет tabular
+const FormatJSON = "json"
+
+// Rate официальный курс валюты на дату
+type Rate struct {
+	Currency string    `json:"currency"`
+	Date     time.Time `json:"-"`
+	// Количество сомов за Nominal единиц валюты
+	Rate    float64 `json:"rate"`
+	Nominal int     `json:"nominal"`
+}
+
+// PerUnit возвращает курс за одну единицу валюты
+func (r Rate) PerUnit() float64 {
+	if r.Nominal <= 1 {
+		return r.Rate
+	}
+	return r.Rate / float64(r.Nominal)
+}
+
+// Fetcher источник официальных курсов. Реализации получают курсы из файла,
+// API Национального банка и т.п.; Fetch возвращает курсы, установленные на дату.
+type Fetcher interface {
+	Fetch(ctx context.Context, date time.Time) ([]Rate, error)
+}
+
+// Parse разбирает файл курсов.
+//
+// JSON - массив объектов {"date": "2024-01-15", "currency": "USD", "rate": 89.45, "nominal": 1};
+// CSV и XLSX - таблица с колонками date, currency, rate и необязательной nominal.
+func Parse(data []byte, format string) ([]Rate, error) {
+	var rates []Rate
+	var err error
+	if strings.EqualFold(format, FormatJSON) {
+		rates, err = parseJSON(data)
+	} else {
+		rates, err = parseTable(data, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("rates file contains no rates")
+	}
+
+	seen := make(map[string]int, len(rates))
+	for i := range rates {
+		r := &rates[i]
+		r.Currency = Normalize(r.Currency)
+		if r.Nominal == 0 {
+			r.Nominal = 1
+		}
+		switch {
+		case len(r.Currency) != 3:
+			return nil, fmt.Errorf("rate %d: invalid currency code %q", i+1, r.Currency)
+		case r.Currency == Base:
+			return nil, fmt.Errorf("rate %d: rate of the base currency cannot be set", i+1)
+		case r.Rate <= 0:
+			return nil, fmt.Errorf("rate %d: rate must be positive", i+1)
+		case r.Nominal < 0:
+			return nil, fmt.Errorf("rate %d: nominal must be positive", i+1)
+		}
+
+		key := r.Currency + r.Date.Format(DateLayout)
+		if first, ok := seen[key]; ok {
+			return nil, fmt.Errorf("rate %d: duplicate rate for %s on %s (see rate %d)", i+1, r.Currency, r.Date.Format(DateLayout), first)
+		}
+		seen[key] = i + 1
+	}
+	return rates, nil
+}
+
+func parseJSON(data []byte) ([]Rate, error) {
+	var items []struct {
+		Rate
+		Date string `json:"date"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("invalid rates file: %w", err)
+	}
+
+	rates := make([]Rate, len(items))
+	for i, item := range items {
+		date, err := time.Parse(DateLayout, item.Date)
+		if err != nil {
+			return nil, fmt.Errorf("rate %d: invalid date %q", i+1, item.Date)
+		}
+		rates[i] = item.Rate
+		rates[i].Date = date
+	}
+	return rates, nil
+}
+
+func parseTable(data []byte, format string) ([]Rate, error) {
+	table, err := tabular.Read(data, format, tabular.Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{"nominal": -1}
+	for _, name := range []string{"date", "currency", "rate"} {
+		columns[name] = -1
+	}
+	for i, header := range table.Header {
+		if _, ok := columns[strings.ToLower(header)]; ok {
+			columns[strings.ToLower(header)] = i
+		}
+	}
+	for _, name := range []string{"date", "currency", "rate"} {
+		if columns[name] < 0 {
+			return nil, fmt.Errorf("column %q is required", name)
+		}
+	}
+
+	rates := make([]Rate, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		date, err := time.Parse(DateLayout, row.Value(columns["date"]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid date %q", row.Number, row.Value(columns["date"]))
+		}
+		value, err := strconv.ParseFloat(strings.Replace(row.Value(columns["rate"]), ",", ".", 1), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: invalid rate %q", row.Number, row.Value(columns["rate"]))
+		}
+		rate := Rate{Currency: row.Value(columns["currency"]), Date: date, Rate: value}
+		if columns["nominal"] >= 0 && row.Value(columns["nominal"]) != "" {
+			if rate.Nominal, err = strconv.Atoi(row.Value(columns["nominal"])); err != nil {
+				return nil, fmt.Errorf("row %d: invalid nominal %q", row.Number, row.Value(columns["nominal"]))
+			}
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// FileFetcher читает курсы из файла (JSON, CSV или XLSX по расширению).
+// Файл может содержать курсы за несколько дат; Fetch возвращает курсы на запрошенную дату.
+type FileFetcher struct {
+	path string
+}
+
+// NewFileFetcher создает источник курсов из файла
+func NewFileFetcher(path string) *FileFetcher {
+	return &FileFetcher{path: path}
+}
+
+// Fetch возвращает курсы из файла на дату
+func (f *FileFetcher) Fetch(ctx context.Context, date time.Time) ([]Rate, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	rates, err := Parse(data, strings.TrimPrefix(strings.ToLower(filepath.Ext(f.path)), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(f.path), err)
+	}
+
+	day := date.Format(DateLayout)
+	var result []Rate
+	for _, r := range rates {
+		if r.Date.Format(DateLayout) == day {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Currency < result[j].Currency })
+	return result, nil
+}
+
+// NewFetcherFromEnv создает источник курсов по переменной окружения CURRENCY_RATES_FILE
+// (путь к файлу курсов). Возвращает nil, если источник не настроен.
+func NewFetcherFromEnv() (Fetcher, string) {
+	path := os.Getenv("CURRENCY_RATES_FILE")
+	if path == "" {
+		return nil, ""
+	}
+	return NewFileFetcher(path), "file:" + filepath.Base(path)
+}
@@ -0,0 +1,88 @@
+// Package diff вычисляет пополевую разницу между двумя JSON-совместимыми значениями.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change описывает изменение одного поля.
+// Field - путь к полю в формате "catalogEntries[0].quantity".
+// From равен nil, если поле добавлено, To равен nil, если поле удалено.
+type Change struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Compare возвращает изменения между from и to, отсортированные по пути поля.
+// Значения сравниваются по их JSON представлению.
+func Compare(from, to interface{}) ([]Change, error) {
+	left, err := Flatten(from)
+	if err != nil {
+		return nil, err
+	}
+	right, err := Flatten(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	for field, lv := range left {
+		rv, ok := right[field]
+		if !ok {
+			changes = append(changes, Change{Field: field, From: lv})
+			continue
+		}
+		if !reflect.DeepEqual(lv, rv) {
+			changes = append(changes, Change{Field: field, From: lv, To: rv})
+		}
+	}
+	for field, rv := range right {
+		if _, ok := left[field]; !ok {
+			changes = append(changes, Change{Field: field, To: rv})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// Flatten преобразует значение в плоский словарь "путь поля" -> скалярное значение.
+// Пустые объекты и массивы не попадают в результат.
+func Flatten(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("diff: encoding value: %w", err)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("diff: decoding value: %w", err)
+	}
+
+	result := make(map[string]interface{})
+	flatten("", decoded, result)
+	return result, nil
+}
+
+func flatten(prefix string, v interface{}, out map[string]interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flatten(path, child, out)
+		}
+	case []interface{}:
+		for i, child := range value {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	default:
+		out[prefix] = value
+	}
+}
@@ -0,0 +1,66 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type testDocument struct {
+	Name    string      `json:"name"`
+	Comment string      `json:"comment,omitempty"`
+	Entries []testEntry `json:"entries"`
+}
+
+// TestCompare tests field-level changes between two values
+func TestCompare(t *testing.T) {
+	from := testDocument{
+		Name:    "Invoice",
+		Entries: []testEntry{{Quantity: 1, Price: 100}, {Quantity: 2, Price: 50}},
+	}
+	to := testDocument{
+		Name:    "Invoice",
+		Comment: "updated",
+		Entries: []testEntry{{Quantity: 3, Price: 100}},
+	}
+
+	changes, err := Compare(from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Change{
+		{Field: "comment", To: "updated"},
+		{Field: "entries[0].quantity", From: 1.0, To: 3.0},
+		{Field: "entries[1].price", From: 50.0},
+		{Field: "entries[1].quantity", From: 2.0},
+	}, changes)
+}
+
+// TestCompare_NoChanges tests that equal values produce an empty diff
+func TestCompare_NoChanges(t *testing.T) {
+	doc := testDocument{Name: "Invoice", Entries: []testEntry{{Quantity: 1, Price: 1}}}
+
+	changes, err := Compare(doc, doc)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+// TestFlatten tests nested paths
+func TestFlatten(t *testing.T) {
+	flat, err := Flatten(map[string]interface{}{
+		"a": map[string]interface{}{"b": []int{1, 2}},
+		"c": nil,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"a.b[0]": 1.0,
+		"a.b[1]": 2.0,
+		"c":      nil,
+	}, flat)
+}
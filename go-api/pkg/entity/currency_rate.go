@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CurrencyRate официальный курс валюты к сому на дату. Хранится в основной БД и общий для всех организаций.
+type CurrencyRate struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	// Буквенный код ISO 4217
+	CurrencyCode string    `gorm:"size:3;not null;uniqueIndex:idx_currency_rate_date" json:"currencyCode"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_currency_rate_date" json:"date"`
+	// Количество сомов за Nominal единиц валюты
+	Rate    float64 `gorm:"type:decimal(15,6);not null" json:"rate"`
+	Nominal int     `gorm:"not null;default:1" json:"nominal"`
+	// Источник курса: имя файла, fetcher и т.п.
+	Source    string    `gorm:"size:100" json:"source"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (CurrencyRate) TableName() string {
+	return "currency_rates"
+}
+
+// PerUnit возвращает курс за одну единицу валюты
+func (r *CurrencyRate) PerUnit() float64 {
+	if r.Nominal <= 1 {
+		return r.Rate
+	}
+	return r.Rate / float64(r.Nominal)
+}
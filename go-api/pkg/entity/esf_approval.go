@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// ApprovalPolicyID идентификатор единственной записи политики согласования в БД организации
+const ApprovalPolicyID = 1
+
+// EsfApprovalPolicy политика согласования исходящих документов организации (принцип четырех глаз).
+// Документ требует согласования, если его сумма больше порога или код вида операции входит в список;
+// включенная политика без порога и видов операции распространяется на все исходящие документы.
+// До первого сохранения политика выключена.
+type EsfApprovalPolicy struct {
+	ID      int  `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	Enabled bool `gorm:"not null;default:false" json:"enabled"`
+	// Порог суммы документа в сомах; 0 - сумма не учитывается
+	ThresholdAmount money.Amount `gorm:"type:decimal(15,2);not null;default:0" json:"thresholdAmount"`
+	// Коды видов операции через запятую
+	OperationTypeCodes string `gorm:"size:500" json:"operationTypeCodes"`
+	// Число согласований разных пользователей
+	RequiredApprovals int       `gorm:"not null;default:1" json:"requiredApprovals"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+	// Пользователь, изменивший политику
+	UpdatedBy string `gorm:"size:255" json:"updatedBy"`
+}
+
+func (EsfApprovalPolicy) TableName() string {
+	return "esf_approval_policies"
+}
+
+// OperationTypes возвращает коды видов операции, требующих согласования
+func (p *EsfApprovalPolicy) OperationTypes() []string {
+	var codes []string
+	for _, code := range strings.Split(p.OperationTypeCodes, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// ApprovalDecision решение согласующего по документу
+type ApprovalDecision string
+
+const (
+	ApprovalDecisionApproved ApprovalDecision = "approved" // Согласовано
+	ApprovalDecisionRejected ApprovalDecision = "rejected" // Отклонено, документ возвращен в черновик
+)
+
+// String возвращает строковое представление решения
+func (d ApprovalDecision) String() string {
+	return string(d)
+}
+
+// ApprovalStatus итог согласования текущего содержимого документа
+type ApprovalStatus string
+
+const (
+	ApprovalStatusNotRequired ApprovalStatus = "not_required" // Документ не требует согласования
+	ApprovalStatusPending     ApprovalStatus = "pending"      // Ожидает согласований
+	ApprovalStatusApproved    ApprovalStatus = "approved"     // Согласован
+	ApprovalStatusRejected    ApprovalStatus = "rejected"     // Отклонен согласующим
+)
+
+// String возвращает строковое представление итога
+func (s ApprovalStatus) String() string {
+	return string(s)
+}
+
+// EsfDocumentApproval решение согласующего. Решение относится к содержимому документа
+// на момент согласования (SHA-256 канонического представления, как у подписи):
+// после изменения документа прежние согласования не учитываются.
+type EsfDocumentApproval struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DocumentID uuid.UUID `gorm:"type:uuid;not null;index" json:"documentId"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	Decision ApprovalDecision `gorm:"size:20;not null" json:"decision"`
+	Reason   string           `gorm:"type:text" json:"reason"`
+	Digest   string           `gorm:"size:64;not null" json:"digest"`
+	// Решение, которым завершено согласование: последнее необходимое согласование или отклонение
+	Final bool `gorm:"not null;default:false" json:"final"`
+
+	// Согласующий (из JWT)
+	ApproverID   string `gorm:"size:64;not null;index" json:"approverId"`
+	ApproverName string `gorm:"size:255" json:"approverName"`
+}
+
+func (EsfDocumentApproval) TableName() string {
+	return "esf_document_approvals"
+}
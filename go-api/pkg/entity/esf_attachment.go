@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EsfAttachment файл, приложенный к документу (договор, накладная, скан).
+// Содержимое хранится в хранилище вложений по ключу StorageKey, в БД - только описание и контрольная сумма.
+type EsfAttachment struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DocumentID uuid.UUID `gorm:"type:uuid;not null;index" json:"documentId"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	FileName    string `gorm:"size:255;not null" json:"fileName"`
+	ContentType string `gorm:"size:100;not null" json:"contentType"`
+	Size        int64  `gorm:"not null" json:"size"`
+	// SHA-256 содержимого в шестнадцатеричном виде; проверяется при выдаче файла
+	Checksum   string `gorm:"size:64;not null" json:"checksum"`
+	StorageKey string `gorm:"size:255;not null" json:"-"`
+
+	// Пользователь, загрузивший файл
+	UploadedBy string `gorm:"size:255" json:"uploadedBy"`
+}
+
+func (EsfAttachment) TableName() string {
+	return "esf_attachments"
+}
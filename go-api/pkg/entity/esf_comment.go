@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EsfDocumentComment комментарий к документу. Ответ ссылается на первый комментарий ветки,
+// поэтому ветка имеет один уровень вложенности. Удаленный комментарий сохраняется в БД.
+type EsfDocumentComment struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DocumentID uuid.UUID      `gorm:"type:uuid;not null;index" json:"documentId"`
+	ParentID   *uuid.UUID     `gorm:"type:uuid;index" json:"parentId"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Автор комментария (из JWT)
+	AuthorID   string `gorm:"size:64;not null;index" json:"authorId"`
+	AuthorName string `gorm:"size:255" json:"authorName"`
+
+	Body string `gorm:"type:text;not null" json:"body"`
+	// Дата последнего редактирования текста; пусто, если комментарий не редактировался
+	EditedAt *time.Time `json:"editedAt"`
+
+	// Пользователи, упомянутые в тексте через @username
+	Mentions []EsfCommentMention `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"mentions"`
+}
+
+func (EsfDocumentComment) TableName() string {
+	return "esf_document_comments"
+}
+
+// EsfCommentMention упоминание пользователя в комментарии
+type EsfCommentMention struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CommentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_esf_comment_mention" json:"commentId"`
+	UserID    string    `gorm:"size:64;not null;uniqueIndex:idx_esf_comment_mention;index" json:"userId"`
+	Username  string    `gorm:"size:50;not null" json:"username"`
+}
+
+func (EsfCommentMention) TableName() string {
+	return "esf_comment_mentions"
+}
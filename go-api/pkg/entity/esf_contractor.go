@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EsfContractor контрагент (покупатель) из справочника организации.
+// Документы, ссылающиеся на контрагента, получают из справочника ИНН, резидентство и код страны.
+type EsfContractor struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Наименование контрагента
+	Name string `gorm:"size:255;not null" json:"name"`
+	// ИНН, уникален среди неудаленных контрагентов
+	Tin string `gorm:"size:14;not null;uniqueIndex:idx_esf_contractors_tin,where:deleted_at IS NULL" json:"tin"`
+	// Субъект Кыргызской Республики
+	IsResident bool `gorm:"not null" json:"isResident"`
+	// Код страны
+	CountryCode string `gorm:"size:2" json:"countryCode"`
+	// Контактные данные
+	ContactPerson string `gorm:"size:255" json:"contactPerson"`
+	Phone         string `gorm:"size:50" json:"phone"`
+	Email         string `gorm:"size:255" json:"email"`
+	Address       string `gorm:"type:text" json:"address"`
+	// Банковские счета
+	BankAccounts []EsfContractorBankAccount `gorm:"foreignKey:ContractorID;constraint:OnDelete:CASCADE" json:"bankAccounts"`
+}
+
+func (EsfContractor) TableName() string {
+	return "esf_contractors"
+}
+
+// DefaultBankAccount возвращает номер основного банковского счета или пустую строку
+func (c *EsfContractor) DefaultBankAccount() string {
+	for _, account := range c.BankAccounts {
+		if account.IsDefault {
+			return account.Account
+		}
+	}
+	return ""
+}
+
+// HasBankAccount проверяет, принадлежит ли счет контрагенту
+func (c *EsfContractor) HasBankAccount(account string) bool {
+	for _, a := range c.BankAccounts {
+		if a.Account == account {
+			return true
+		}
+	}
+	return false
+}
+
+// EsfContractorBankAccount банковский счет контрагента
+type EsfContractorBankAccount struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ContractorID uuid.UUID `gorm:"type:uuid;not null;index" json:"contractorId"`
+	// Номер счета
+	Account string `gorm:"size:50;not null" json:"account"`
+	// Наименование и БИК банка
+	BankName string `gorm:"size:255" json:"bankName"`
+	Bic      string `gorm:"size:20" json:"bic"`
+	// Счет, подставляемый в документы по умолчанию
+	IsDefault bool `gorm:"not null;default:false" json:"isDefault"`
+}
+
+func (EsfContractorBankAccount) TableName() string {
+	return "esf_contractor_bank_accounts"
+}
@@ -0,0 +1,30 @@
+package entity
+
+// BuyerStatus решение покупателя по исходящему документу, доставленному в его организацию на платформе
+type BuyerStatus string
+
+const (
+	BuyerStatusDelivered BuyerStatus = "delivered" // Копия документа доставлена покупателю
+	BuyerStatusAccepted  BuyerStatus = "accepted"  // Покупатель принял документ к учету
+	BuyerStatusDisputed  BuyerStatus = "disputed"  // Покупатель оспорил документ
+	BuyerStatusCancelled BuyerStatus = "cancelled" // Покупатель аннулировал копию документа
+)
+
+// String возвращает строковое представление решения покупателя
+func (s BuyerStatus) String() string {
+	return string(s)
+}
+
+// BuyerStatusFor возвращает решение покупателя, соответствующее статусу его входящей копии
+func BuyerStatusFor(status DocumentStatus) BuyerStatus {
+	switch status {
+	case DocumentStatusAccepted:
+		return BuyerStatusAccepted
+	case DocumentStatusRejected:
+		return BuyerStatusDisputed
+	case DocumentStatusCancelled:
+		return BuyerStatusCancelled
+	default:
+		return BuyerStatusDelivered
+	}
+}
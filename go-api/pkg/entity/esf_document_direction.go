@@ -0,0 +1,47 @@
+package entity
+
+// DocumentDirection направление документа относительно организации
+type DocumentDirection string
+
+const (
+	DocumentDirectionOutgoing DocumentDirection = "outgoing" // Выписан организацией, покупатель - ContractorTin
+	DocumentDirectionIncoming DocumentDirection = "incoming" // Получен от поставщика SupplierTin для учета входящего НДС
+)
+
+// incomingStatusTransitions допустимые переходы входящих документов: документ поставщика
+// не подписывается и не отправляется организацией, а принимается к учету или отклоняется
+var incomingStatusTransitions = map[DocumentStatus][]DocumentStatus{
+	DocumentStatusDraft:     {DocumentStatusAccepted, DocumentStatusRejected, DocumentStatusCancelled},
+	DocumentStatusAccepted:  {DocumentStatusCancelled},
+	DocumentStatusRejected:  {DocumentStatusDraft, DocumentStatusCancelled},
+	DocumentStatusCancelled: {},
+}
+
+// IsValid проверяет, валидно ли направление
+func (d DocumentDirection) IsValid() bool {
+	return d == DocumentDirectionOutgoing || d == DocumentDirectionIncoming
+}
+
+// String возвращает строковое представление направления
+func (d DocumentDirection) String() string {
+	return string(d)
+}
+
+// CanTransition проверяет, допустим ли переход статуса документа этого направления
+func (d DocumentDirection) CanTransition(from, to DocumentStatus) bool {
+	for _, allowed := range d.AllowedTransitions(from) {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions возвращает статусы, в которые можно перейти из from.
+// Пустое направление трактуется как исходящее.
+func (d DocumentDirection) AllowedTransitions(from DocumentStatus) []DocumentStatus {
+	if d == DocumentDirectionIncoming {
+		return incomingStatusTransitions[from]
+	}
+	return from.AllowedTransitions()
+}
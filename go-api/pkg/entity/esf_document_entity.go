@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +16,46 @@ type EsfDocument struct {
 	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Номер документа, присваивается при выходе из черновика без пропусков в пределах года: INV-2026-000123
+	Number string `gorm:"size:50;not null;default:''" json:"number"`
+	// Год и порядковый номер документа; по ним выполняется сортировка по номеру
+	NumberYear int   `gorm:"not null;default:0;uniqueIndex:idx_esf_documents_number_order,priority:1,where:number_seq > 0" json:"numberYear"`
+	NumberSeq  int64 `gorm:"not null;default:0;uniqueIndex:idx_esf_documents_number_order,priority:2" json:"numberSeq"`
+
+	// Статус жизненного цикла документа
+	Status DocumentStatus `gorm:"size:20;not null;default:'draft';index" json:"status"`
+	// Дата последней смены статуса
+	StatusChangedAt *time.Time `json:"statusChangedAt"`
+	// Причина последней смены статуса (отклонение, аннулирование)
+	StatusReason string `gorm:"type:text" json:"statusReason"`
+	// Идентификатор ответа налоговой службы на последнюю отправку
+	ExternalResponseID string `gorm:"size:100" json:"externalResponseId"`
+	// Идентификатор документа в информационной системе налоговой службы
+	ExternalDocumentUUID string `gorm:"size:100;index" json:"externalDocumentUuid"`
+	// Дата отправки документа в налоговую службу
+	SubmittedAt *time.Time `json:"submittedAt"`
+
+	// Направление: исходящий документ организации или входящий документ поставщика
+	Direction DocumentDirection `gorm:"size:10;not null;default:'outgoing';index" json:"direction"`
+	// ИНН и наименование поставщика входящего документа; для исходящих поставщик - сама организация
+	SupplierTin  string `gorm:"size:14;index" json:"supplierTin"`
+	SupplierName string `gorm:"size:255" json:"supplierName"`
+
+	// Связанный документ другой организации платформы: копия покупателя у исходящего документа
+	// или оригинал продавца у входящего. Доставленная продавцом копия не редактируется.
+	// У покупателя может быть только одна копия документа продавца.
+	CounterpartyOrgID      *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_esf_documents_delivered_copy,priority:1,where:direction = 'incoming'" json:"counterpartyOrgId"`
+	CounterpartyDocumentID *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_esf_documents_delivered_copy,priority:2" json:"counterpartyDocumentId"`
+	// Решение покупателя по доставленному исходящему документу
+	BuyerStatus          BuyerStatus `gorm:"size:20" json:"buyerStatus"`
+	BuyerStatusReason    string      `gorm:"type:text" json:"buyerStatusReason"`
+	BuyerStatusChangedAt *time.Time  `json:"buyerStatusChangedAt"`
+
+	// Исходный документ, если это корректировочная счет-фактура
+	OriginalDocumentID *uuid.UUID `gorm:"type:uuid;index" json:"originalDocumentId"`
+	// Причина корректировки
+	CorrectionReason string `gorm:"type:text" json:"correctionReason"`
+
 	// false Наименование иностранца или Наименование на иностранном языке
 	ForeignName string `gorm:"size:255" json:"foreignName"`
 	// true Отправить от имени филиала
@@ -30,11 +71,13 @@ type EsfDocument struct {
 	// true Код вида операции
 	OperationTypeCode string `gorm:"size:20;not null" json:"operationTypeCode" valid:"required"`
 	// true Дата поставки
-	DeliveryDate time.Time `gorm:"not null" json:"deliveryDate" valid:"required"`
+	DeliveryDate time.Time `gorm:"not null;index" json:"deliveryDate" valid:"required"`
 	// true Код типа поставки
 	DeliveryTypeCode string `gorm:"size:20;not null" json:"deliveryTypeCode" valid:"required"`
 	// true Субъект Кыргызской Республики
 	IsResident bool `gorm:"not null" json:"isResident" valid:"required"`
+	// Контрагент из справочника организации; ИНН, резидентство и страна заполняются из него
+	ContractorID *uuid.UUID `gorm:"type:uuid;index" json:"contractorId"`
 	// true ИНН покупателя
 	ContractorTin string `gorm:"size:14;not null" json:"contractorTin" valid:"required"`
 	// false Номер банковского счета поставщика
@@ -48,9 +91,9 @@ type EsfDocument struct {
 	// false Курс валюты к сому
 	CurrencyRate float64 `gorm:"type:decimal(10,4)" json:"currencyRate"`
 	// false Общая стоимость в валюте
-	TotalCurrencyValue float64 `gorm:"type:decimal(15,2)" json:"totalCurrencyValue"`
+	TotalCurrencyValue money.Amount `gorm:"type:decimal(15,2)" json:"totalCurrencyValue"`
 	// false Общая стоимость в валюте без налогов
-	TotalCurrencyValueWithoutTaxes float64 `gorm:"type:decimal(15,2)" json:"totalCurrencyValueWithoutTaxes"`
+	TotalCurrencyValueWithoutTaxes money.Amount `gorm:"type:decimal(15,2)" json:"totalCurrencyValueWithoutTaxes"`
 	// false Номер договора на поставку
 	SupplyContractNumber string `gorm:"size:100" json:"supplyContractNumber"`
 	// false Дата договора на поставку
@@ -66,19 +109,19 @@ type EsfDocument struct {
 	// true Товары и услуги
 	CatalogEntries []EsfEntries `gorm:"foreignKey:DocumentID;constraint:OnDelete:CASCADE" json:"catalogEntries"`
 	// false Начальные остатки,сальдо на начало периода
-	OpeningBalances float64 `gorm:"type:decimal(15,2);default:0" json:"openingBalances"`
+	OpeningBalances money.Amount `gorm:"type:decimal(15,2);default:0" json:"openingBalances"`
 	// false Начисленные взносы
-	AssessedContributionsAmount float64 `gorm:"type:decimal(15,2);default:0" json:"assessedContributionsAmount"`
+	AssessedContributionsAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"assessedContributionsAmount"`
 	// false Поступления,оплачено
-	PaidAmount float64 `gorm:"type:decimal(15,2);default:0" json:"paidAmount"`
+	PaidAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"paidAmount"`
 	// false Штрафы
-	PenaltiesAmount float64 `gorm:"type:decimal(15,2);default:0" json:"penaltiesAmount"`
+	PenaltiesAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"penaltiesAmount"`
 	// false пени
-	FinesAmount float64 `gorm:"type:decimal(15,2);default:0" json:"finesAmount"`
+	FinesAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"finesAmount"`
 	// false Конечные остатки,сальдо на конец периода
-	ClosingBalances float64 `gorm:"type:decimal(15,2);default:0" json:"closingBalances"`
+	ClosingBalances money.Amount `gorm:"type:decimal(15,2);default:0" json:"closingBalances"`
 	// false Сумма к оплате
-	AmountToBePaid float64 `gorm:"type:decimal(15,2);default:0" json:"amountToBePaid"`
+	AmountToBePaid money.Amount `gorm:"type:decimal(15,2);default:0" json:"amountToBePaid"`
 	// false Лицевой счет
 	PersonalAccountNumber string `gorm:"size:50" json:"personalAccountNumber"`
 }
@@ -86,3 +129,18 @@ type EsfDocument struct {
 func (EsfDocument) TableName() string {
 	return "esf_documents"
 }
+
+// IsCorrection проверяет, является ли документ корректировочной счет-фактурой
+func (d *EsfDocument) IsCorrection() bool {
+	return d.OriginalDocumentID != nil
+}
+
+// IsIncoming проверяет, является ли документ входящим документом поставщика
+func (d *EsfDocument) IsIncoming() bool {
+	return d.Direction == DocumentDirectionIncoming
+}
+
+// IsDelivered проверяет, что документ - копия, доставленная продавцом из другой организации платформы
+func (d *EsfDocument) IsDelivered() bool {
+	return d.IsIncoming() && d.CounterpartyDocumentID != nil
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevisionAction тип изменения документа, зафиксированного в ревизии
+type RevisionAction string
+
+const (
+	RevisionActionCreate RevisionAction = "create" // Документ создан
+	RevisionActionUpdate RevisionAction = "update" // Документ отредактирован
+	RevisionActionStatus RevisionAction = "status" // Изменен статус документа
+	RevisionActionDelete RevisionAction = "delete" // Документ удален
+)
+
+// EsfDocumentRevision неизменяемый снимок документа (шапка и позиции) после изменения.
+// Ревизии нумеруются последовательно в рамках документа, начиная с 1.
+type EsfDocumentRevision struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DocumentID uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex:idx_esf_document_revision" json:"documentId"`
+	Revision   int            `gorm:"not null;uniqueIndex:idx_esf_document_revision" json:"revision"`
+	Action     RevisionAction `gorm:"size:20;not null" json:"action"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+
+	// Автор изменения (из JWT); пусто для системных изменений
+	AuthorID   string `gorm:"size:64;index" json:"authorId"`
+	AuthorName string `gorm:"size:255" json:"authorName"`
+
+	// Снимок EsfDocument с позициями в формате JSON
+	Snapshot string `gorm:"type:jsonb;not null" json:"snapshot"`
+}
+
+func (EsfDocumentRevision) TableName() string {
+	return "esf_document_revisions"
+}
@@ -0,0 +1,72 @@
+package entity
+
+// DocumentStatus представляет статус жизненного цикла документа ЭСФ
+type DocumentStatus string
+
+const (
+	DocumentStatusDraft      DocumentStatus = "draft"      // Черновик - документ можно редактировать
+	DocumentStatusReady      DocumentStatus = "ready"      // Готов к подписанию
+	DocumentStatusSigned     DocumentStatus = "signed"     // Подписан
+	DocumentStatusSubmitting DocumentStatus = "submitting" // Отправляется в налоговую службу
+	DocumentStatusSubmitted  DocumentStatus = "submitted"  // Отправлен в налоговую службу
+	DocumentStatusAccepted   DocumentStatus = "accepted"   // Принят налоговой службой
+	DocumentStatusRejected   DocumentStatus = "rejected"   // Отклонен налоговой службой
+	DocumentStatusCancelled  DocumentStatus = "cancelled"  // Аннулирован
+)
+
+// documentStatusTransitions определяет допустимые переходы между статусами
+var documentStatusTransitions = map[DocumentStatus][]DocumentStatus{
+	DocumentStatusDraft:  {DocumentStatusReady, DocumentStatusCancelled},
+	DocumentStatusReady:  {DocumentStatusDraft, DocumentStatusSigned, DocumentStatusCancelled},
+	DocumentStatusSigned: {DocumentStatusSubmitted, DocumentStatusCancelled},
+	// Отправку ведет сервис: документ занимается до обращения к налоговой службе,
+	// поэтому вручную из этого статуса не выходят
+	DocumentStatusSubmitting: {},
+	DocumentStatusSubmitted:  {DocumentStatusAccepted, DocumentStatusRejected},
+	DocumentStatusAccepted:   {DocumentStatusCancelled},
+	DocumentStatusRejected:   {DocumentStatusDraft, DocumentStatusCancelled},
+	DocumentStatusCancelled:  {},
+}
+
+// IsValid проверяет, валиден ли статус
+func (s DocumentStatus) IsValid() bool {
+	_, ok := documentStatusTransitions[s]
+	return ok
+}
+
+// String возвращает строковое представление статуса
+func (s DocumentStatus) String() string {
+	return string(s)
+}
+
+// CanTransitionTo проверяет, допустим ли переход в указанный статус
+func (s DocumentStatus) CanTransitionTo(next DocumentStatus) bool {
+	for _, allowed := range documentStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedTransitions возвращает статусы, в которые можно перейти из текущего
+func (s DocumentStatus) AllowedTransitions() []DocumentStatus {
+	return documentStatusTransitions[s]
+}
+
+// IsEditable проверяет, можно ли редактировать или удалять документ в этом статусе.
+// Пустой статус трактуется как черновик.
+func (s DocumentStatus) IsEditable() bool {
+	return s == DocumentStatusDraft || s == ""
+}
+
+// IsVoid проверяет, что документ отклонен или аннулирован и не влияет на итоговые суммы
+func (s DocumentStatus) IsVoid() bool {
+	return s == DocumentStatusRejected || s == DocumentStatusCancelled
+}
+
+// IsRegistered проверяет, что документ отправлен в налоговую службу или принят ею
+// и, в отличие от черновиков и документов на подготовке, влияет на итоговые суммы
+func (s DocumentStatus) IsRegistered() bool {
+	return s == DocumentStatusSubmitted || s == DocumentStatusAccepted
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rusgainew/tunduck-app/pkg/money"
 	"gorm.io/gorm"
 )
 
@@ -32,23 +33,34 @@ type EsfEntries struct {
 
 	// Price - цена за единицу товара или услуги
 	// без учета налогов
-	Price float64 `gorm:"type:decimal(15,2);not null" json:"price" valid:"required"`
+	Price money.Amount `gorm:"type:decimal(15,2);not null" json:"price" valid:"required"`
 
 	// VatAmount - сумма налога на добавленную стоимость (НДС)
 	// для данной позиции
-	VatAmount float64 `gorm:"type:decimal(15,2);default:0" json:"vatAmount"`
+	VatAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"vatAmount"`
 
 	// SalesTaxAmount - сумма акцизного налога
 	// для подакцизных товаров
-	SalesTaxAmount float64 `gorm:"type:decimal(15,2);default:0" json:"salesTaxAmount"`
+	SalesTaxAmount money.Amount `gorm:"type:decimal(15,2);default:0" json:"salesTaxAmount"`
 
 	// AmountWithoutTaxes - общая сумма за позицию
 	// без учета НДС и акцизов
-	AmountWithoutTaxes float64 `gorm:"type:decimal(15,2);not null" json:"amountWithoutTaxes" valid:"required"`
+	AmountWithoutTaxes money.Amount `gorm:"type:decimal(15,2);not null" json:"amountWithoutTaxes" valid:"required"`
 
 	// TotalAmount - итоговая сумма за позицию
 	// с учетом всех налогов
-	TotalAmount float64 `gorm:"type:decimal(15,2);not null" json:"totalAmount" valid:"required"`
+	TotalAmount money.Amount `gorm:"type:decimal(15,2);not null" json:"totalAmount" valid:"required"`
+
+	// OriginalEntryID - корректируемая позиция исходного документа
+	// (только для корректировочных счетов-фактур). В этом случае Quantity и Price
+	// содержат значения после корректировки, а суммы - разницу к предыдущему состоянию.
+	OriginalEntryID *uuid.UUID `gorm:"type:uuid;index" json:"originalEntryId"`
+
+	// QuantityDelta - изменение количества по позиции
+	QuantityDelta float64 `gorm:"type:decimal(15,4);default:0" json:"quantityDelta"`
+
+	// PriceDelta - изменение цены по позиции
+	PriceDelta money.Amount `gorm:"type:decimal(15,2);default:0" json:"priceDelta"`
 }
 
 func (EsfEntries) TableName() string {
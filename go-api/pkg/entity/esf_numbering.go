@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+// NumberingSettingsID идентификатор единственной записи настроек нумерации в БД организации
+const NumberingSettingsID = 1
+
+// EsfNumberingSettings настройки нумерации документов организации.
+// До первого сохранения действуют значения по умолчанию из пакета numbering.
+type EsfNumberingSettings struct {
+	ID     int    `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	Prefix string `gorm:"size:20;not null" json:"prefix"`
+	// Width - число цифр порядкового номера
+	Width     int       `gorm:"not null" json:"width"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+	// Пользователь, изменивший настройки
+	UpdatedBy string `gorm:"size:255" json:"updatedBy"`
+}
+
+func (EsfNumberingSettings) TableName() string {
+	return "esf_numbering_settings"
+}
+
+// EsfNumberSequence счетчик номеров документов за календарный год.
+// Счетчик увеличивается в транзакции создания документа, поэтому номера идут без пропусков.
+type EsfNumberSequence struct {
+	Year       int       `gorm:"primaryKey;autoIncrement:false" json:"year"`
+	LastNumber int64     `gorm:"not null;default:0" json:"lastNumber"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+func (EsfNumberSequence) TableName() string {
+	return "esf_number_sequences"
+}
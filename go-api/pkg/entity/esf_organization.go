@@ -16,6 +16,9 @@ type EstOrganization struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   *time.Time `gorm:"index"`
+
+	// ИНН организации; по нему исходящие документы доставляются покупателям на платформе
+	Tin string `gorm:"size:14;index"`
 }
 
 // Validate проверяет валидность данных организации
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PrintTemplateInvoice имя печатной формы счета-фактуры
+const PrintTemplateInvoice = "invoice"
+
+// EsfPrintTemplate печатная форма организации в формате html/template.
+// Если у организации нет своей формы, используется встроенная форма по умолчанию.
+type EsfPrintTemplate struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex" json:"name"`
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+	// Пользователь, загрузивший форму
+	UpdatedBy string `gorm:"size:255" json:"updatedBy"`
+}
+
+func (EsfPrintTemplate) TableName() string {
+	return "esf_print_templates"
+}
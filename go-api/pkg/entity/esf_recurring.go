@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EsfRecurringTemplate шаблон повторяющегося документа (например, ежемесячного счета за услуги).
+// Планировщик создает по шаблону черновики в даты, заданные правилом повторения.
+type EsfRecurringTemplate struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Name string `gorm:"size:255;not null" json:"name"`
+	// Документ в формате запроса создания документа (JSON)
+	Document string `gorm:"type:jsonb;not null" json:"document"`
+
+	// Правило повторения, см. пакет recurrence
+	Frequency  string     `gorm:"size:20;not null" json:"frequency"`
+	Interval   int        `gorm:"not null;default:1" json:"interval"`
+	DayOfMonth int        `gorm:"not null" json:"dayOfMonth"`
+	StartDate  time.Time  `gorm:"type:date;not null" json:"startDate"`
+	EndDate    *time.Time `gorm:"type:date" json:"endDate"`
+	IsActive   bool       `gorm:"not null;default:true" json:"isActive"`
+
+	// Дата следующего выпуска; nil - все выпуски по правилу выполнены
+	NextRunDate *time.Time `gorm:"type:date;index" json:"nextRunDate"`
+	// Ошибка последнего выпуска; выпуск повторяется при следующем запуске планировщика
+	LastError string `gorm:"type:text" json:"lastError"`
+
+	// Пользователь, изменивший шаблон
+	UpdatedBy string `gorm:"size:255" json:"updatedBy"`
+}
+
+func (EsfRecurringTemplate) TableName() string {
+	return "esf_recurring_templates"
+}
+
+// EsfRecurringRun выпуск документа по шаблону за расчетный период.
+// Уникальность шаблона и периода гарантирует, что за период создается не больше одного документа,
+// даже если планировщик запущен в нескольких экземплярах приложения.
+type EsfRecurringRun struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	TemplateID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_esf_recurring_run_period" json:"templateId"`
+	// Расчетный период в формате 2006-01
+	Period     string     `gorm:"size:7;not null;uniqueIndex:idx_esf_recurring_run_period" json:"period"`
+	RunDate    time.Time  `gorm:"type:date;not null" json:"runDate"`
+	DocumentID *uuid.UUID `gorm:"type:uuid" json:"documentId"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (EsfRecurringRun) TableName() string {
+	return "esf_recurring_runs"
+}
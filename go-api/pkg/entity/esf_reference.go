@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Справочники кодов ЭСФ
+const (
+	ReferenceOperationType = "operationType"
+	ReferenceDeliveryType  = "deliveryType"
+	ReferencePayment       = "payment"
+	ReferenceTaxRateVAT    = "taxRateVAT"
+	ReferenceDelivery      = "delivery"
+	ReferenceCurrency      = "currency"
+	ReferenceCountry       = "country"
+	ReferenceUnit          = "unit"
+)
+
+// ReferenceCatalogs возвращает все справочники кодов ЭСФ
+func ReferenceCatalogs() []string {
+	return []string{
+		ReferenceOperationType,
+		ReferenceDeliveryType,
+		ReferencePayment,
+		ReferenceTaxRateVAT,
+		ReferenceDelivery,
+		ReferenceCurrency,
+		ReferenceCountry,
+		ReferenceUnit,
+	}
+}
+
+// EsfReferenceVersion версия справочника. Хранится в основной БД;
+// документы проверяются по активной версии, у справочника активна одна версия.
+type EsfReferenceVersion struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Catalog string    `gorm:"size:50;not null;uniqueIndex:idx_reference_catalog_version" json:"catalog"`
+	Version string    `gorm:"size:50;not null;uniqueIndex:idx_reference_catalog_version" json:"version"`
+	Active  bool      `gorm:"not null;default:false;index" json:"active"`
+	// Контрольная сумма файла, из которого загружена версия
+	Checksum  string    `gorm:"size:64" json:"checksum"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+func (EsfReferenceVersion) TableName() string {
+	return "esf_reference_versions"
+}
+
+// EsfReferenceItem код справочника в конкретной версии
+type EsfReferenceItem struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	VersionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reference_item_code" json:"versionId"`
+	Code      string    `gorm:"size:20;not null;uniqueIndex:idx_reference_item_code" json:"code"`
+	// Альтернативный код, который также принимается при проверке (например, цифровой код ISO валюты)
+	AltCode   string `gorm:"size:20" json:"altCode,omitempty"`
+	Name      string `gorm:"size:255;not null" json:"name"`
+	SortOrder int    `gorm:"not null;default:0" json:"sortOrder"`
+}
+
+func (EsfReferenceItem) TableName() string {
+	return "esf_reference_items"
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SigningKeyScope владелец ключа подписи
+type SigningKeyScope string
+
+const (
+	SigningKeyScopeUser         SigningKeyScope = "user"         // Личный ключ пользователя
+	SigningKeyScopeOrganization SigningKeyScope = "organization" // Ключ организации
+)
+
+// EsfSigningKey открытый ключ, которым проверяются подписи документов организации.
+// Закрытый ключ хранится у владельца и на сервер не передается.
+type EsfSigningKey struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	Name  string          `gorm:"size:255;not null" json:"name"`
+	Scope SigningKeyScope `gorm:"size:20;not null" json:"scope"`
+	// Владелец личного ключа; пусто для ключа организации
+	UserID string `gorm:"size:64;index" json:"userId"`
+
+	Algorithm string `gorm:"size:20;not null" json:"algorithm"`
+	// Открытый ключ в PEM
+	PublicKey   string `gorm:"type:text;not null" json:"publicKey"`
+	Fingerprint string `gorm:"size:64;not null;uniqueIndex" json:"fingerprint"`
+
+	CreatedBy string `gorm:"size:255" json:"createdBy"`
+	// Отозванным ключом нельзя подписывать; ранее сделанные подписи остаются проверяемыми
+	RevokedAt *time.Time `json:"revokedAt"`
+}
+
+func (EsfSigningKey) TableName() string {
+	return "esf_signing_keys"
+}
+
+// IsRevoked проверяет, отозван ли ключ
+func (k *EsfSigningKey) IsRevoked() bool {
+	return k.RevokedAt != nil
+}
+
+// EsfDocumentSignature подпись документа. Вместе с подписью хранится подписанное
+// каноническое представление документа, чтобы при проверке показать изменения после подписания.
+type EsfDocumentSignature struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	DocumentID uuid.UUID `gorm:"type:uuid;not null;index" json:"documentId"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+
+	KeyID       uuid.UUID `gorm:"type:uuid;not null;index" json:"keyId"`
+	Algorithm   string    `gorm:"size:20;not null" json:"algorithm"`
+	Fingerprint string    `gorm:"size:64;not null" json:"fingerprint"`
+
+	// Каноническое представление документа, SHA-256 от него и подпись в base64
+	Payload   string `gorm:"type:text;not null" json:"payload"`
+	Digest    string `gorm:"size:64;not null" json:"digest"`
+	Signature string `gorm:"type:text;not null" json:"signature"`
+
+	// Подписант (из JWT)
+	SignerID   string `gorm:"size:64" json:"signerId"`
+	SignerName string `gorm:"size:255" json:"signerName"`
+}
+
+func (EsfDocumentSignature) TableName() string {
+	return "esf_document_signatures"
+}
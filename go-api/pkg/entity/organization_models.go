@@ -0,0 +1,25 @@
+package entity
+
+// OrganizationModels возвращает модели, которые хранятся в отдельной БД организации.
+// Используется при создании БД организации и при миграции существующих БД.
+func OrganizationModels() []interface{} {
+	return []interface{}{
+		&EsfDocument{},
+		&EsfEntries{},
+		&EsfDocumentRevision{},
+		&EsfPrintTemplate{},
+		&EsfContractor{},
+		&EsfContractorBankAccount{},
+		&EsfNumberingSettings{},
+		&EsfNumberSequence{},
+		&EsfRecurringTemplate{},
+		&EsfRecurringRun{},
+		&EsfAttachment{},
+		&EsfSigningKey{},
+		&EsfDocumentSignature{},
+		&EsfDocumentComment{},
+		&EsfCommentMention{},
+		&EsfApprovalPolicy{},
+		&EsfDocumentApproval{},
+	}
+}
@@ -0,0 +1,242 @@
+package esfxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+var (
+	tinPattern      = regexp.MustCompile(`^[0-9]{14}$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z0-9]{3}$`)
+	countryPattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// Unmarshal разбирает файл обмена с одним (receipt) или несколькими (receipts)
+// счетами-фактурами и проверяет его по схеме. Ошибки схемы возвращаются как *SchemaError.
+func Unmarshal(data []byte) ([]entity.EsfDocument, error) {
+	receipts, err := decodeReceipts(data)
+	if err != nil {
+		return nil, &SchemaError{Issues: []Issue{{Receipt: -1, Field: "receipts", Message: err.Error()}}}
+	}
+	if len(receipts) == 0 {
+		return nil, &SchemaError{Issues: []Issue{{Receipt: -1, Field: "receipts", Message: "element receipts must contain at least one receipt"}}}
+	}
+
+	docs := make([]entity.EsfDocument, len(receipts))
+	var issues []Issue
+	for i := range receipts {
+		v := &validator{receipt: i}
+		docs[i] = receipts[i].toEntity(v)
+		issues = append(issues, v.issues...)
+	}
+
+	if len(issues) > 0 {
+		return nil, &SchemaError{Issues: issues}
+	}
+	return docs, nil
+}
+
+// decodeReceipts определяет корневой элемент и разбирает файл
+func decodeReceipts(data []byte) ([]Receipt, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("document has no root element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed xml: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "receipts":
+			var root Receipts
+			if err := decoder.DecodeElement(&root, &start); err != nil {
+				return nil, fmt.Errorf("malformed xml: %w", err)
+			}
+			return root.Receipts, nil
+		case "receipt":
+			var receipt Receipt
+			if err := decoder.DecodeElement(&receipt, &start); err != nil {
+				return nil, fmt.Errorf("malformed xml: %w", err)
+			}
+			return []Receipt{receipt}, nil
+		default:
+			return nil, fmt.Errorf("unexpected root element %q, expected receipts or receipt", start.Name.Local)
+		}
+	}
+}
+
+// toEntity преобразует receipt в документ, собирая нарушения схемы в v
+func (r *Receipt) toEntity(v *validator) entity.EsfDocument {
+	doc := entity.EsfDocument{
+		ForeignName:                    v.text("foreignName", r.ForeignName, false, 255, nil),
+		IsBranchDataSent:               v.boolean("isBranchDataSent", r.IsBranchDataSent, true),
+		IsPriceWithoutTaxes:            v.boolean("isPriceWithoutTaxes", r.IsPriceWithoutTaxes, true),
+		AffiliateTin:                   v.text("affiliateTin", r.AffiliateTin, false, 14, tinPattern),
+		IsIndustry:                     v.boolean("isIndustry", r.IsIndustry, false),
+		OwnedCrmReceiptCode:            v.text("ownedCrmReceiptCode", r.OwnedCrmReceiptCode, false, 100, nil),
+		OperationTypeCode:              v.text("operationTypeCode", r.OperationTypeCode, true, 20, nil),
+		DeliveryDate:                   v.date("deliveryDate", r.DeliveryDate, true),
+		DeliveryTypeCode:               v.text("deliveryTypeCode", r.DeliveryTypeCode, true, 20, nil),
+		IsResident:                     v.boolean("isResident", r.IsResident, true),
+		ContractorTin:                  v.text("contractorTin", r.ContractorTin, true, 14, tinPattern),
+		SupplierBankAccount:            v.text("supplierBankAccount", r.SupplierBankAccount, false, 50, nil),
+		ContractorBankAccount:          v.text("contractorBankAccount", r.ContractorBankAccount, false, 50, nil),
+		CurrencyCode:                   v.text("currencyCode", r.CurrencyCode, true, 3, currencyPattern),
+		CountryCode:                    v.text("countryCode", r.CountryCode, false, 2, countryPattern),
+		CurrencyRate:                   v.decimal("currencyRate", r.CurrencyRate, false),
+		TotalCurrencyValue:             v.amount("totalCurrencyValue", r.TotalCurrencyValue, false),
+		TotalCurrencyValueWithoutTaxes: v.amount("totalCurrencyValueWithoutTaxes", r.TotalCurrencyValueWithoutTaxes, false),
+		SupplyContractNumber:           v.text("supplyContractNumber", r.SupplyContractNumber, false, 100, nil),
+		ContractStartDate:              v.date("contractStartDate", r.ContractStartDate, false),
+		Comment:                        v.text("comment", r.Comment, false, 0, nil),
+		DeliveryCode:                   v.text("deliveryCode", r.DeliveryCode, false, 20, nil),
+		PaymentCode:                    v.text("paymentCode", r.PaymentCode, true, 20, nil),
+		TaxRateVATCode:                 v.text("taxRateVATCode", r.TaxRateVATCode, true, 20, nil),
+		OpeningBalances:                v.amount("openingBalances", r.OpeningBalances, false),
+		AssessedContributionsAmount:    v.amount("assessedContributionsAmount", r.AssessedContributionsAmount, false),
+		PaidAmount:                     v.amount("paidAmount", r.PaidAmount, false),
+		PenaltiesAmount:                v.amount("penaltiesAmount", r.PenaltiesAmount, false),
+		FinesAmount:                    v.amount("finesAmount", r.FinesAmount, false),
+		ClosingBalances:                v.amount("closingBalances", r.ClosingBalances, false),
+		AmountToBePaid:                 v.amount("amountToBePaid", r.AmountToBePaid, false),
+		PersonalAccountNumber:          v.text("personalAccountNumber", r.PersonalAccountNumber, false, 50, nil),
+	}
+
+	if len(r.CatalogEntries) == 0 {
+		v.fail("catalogEntries", "element catalogEntries must contain at least one catalogEntry")
+	}
+
+	doc.CatalogEntries = make([]entity.EsfEntries, len(r.CatalogEntries))
+	for i, e := range r.CatalogEntries {
+		field := func(name string) string { return fmt.Sprintf("catalogEntries[%d].%s", i, name) }
+		doc.CatalogEntries[i] = entity.EsfEntries{
+			UnitClassificationCode: v.text(field("unitClassificationCode"), e.UnitClassificationCode, true, 20, nil),
+			SalesTaxCode:           v.text(field("salesTaxCode"), e.SalesTaxCode, true, 50, nil),
+			CustomsAuthorityCode:   v.text(field("customsAuthorityCode"), e.CustomsAuthorityCode, false, 50, nil),
+			Quantity:               v.decimal(field("quantity"), e.Quantity, true),
+			Price:                  v.amount(field("price"), e.Price, true),
+			VatAmount:              v.amount(field("vatAmount"), e.VatAmount, false),
+			SalesTaxAmount:         v.amount(field("salesTaxAmount"), e.SalesTaxAmount, false),
+			AmountWithoutTaxes:     v.amount(field("amountWithoutTaxes"), e.AmountWithoutTaxes, false),
+			TotalAmount:            v.amount(field("totalAmount"), e.TotalAmount, false),
+		}
+	}
+
+	return doc
+}
+
+// validator проверяет значения элементов по правилам схемы
+type validator struct {
+	receipt int
+	issues  []Issue
+}
+
+func (v *validator) fail(field, message string) {
+	v.issues = append(v.issues, Issue{Receipt: v.receipt, Field: field, Message: message})
+}
+
+// text проверяет строковый элемент: обязательность, maxLength (0 - без ограничения) и шаблон
+func (v *validator) text(field, value string, required bool, maxLength int, pattern *regexp.Regexp) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		if required {
+			v.fail(field, "missing required element")
+		}
+	case maxLength > 0 && utf8.RuneCountInString(value) > maxLength:
+		v.fail(field, fmt.Sprintf("value length %d exceeds maxLength %d", utf8.RuneCountInString(value), maxLength))
+	case pattern != nil && !pattern.MatchString(value):
+		v.fail(field, fmt.Sprintf("value %q does not match pattern %s", value, strings.Trim(pattern.String(), "^$")))
+	}
+	return value
+}
+
+// boolean проверяет элемент типа xs:boolean
+func (v *validator) boolean(field, value string, required bool) bool {
+	switch strings.TrimSpace(value) {
+	case "true", "1":
+		return true
+	case "false", "0":
+		return false
+	case "":
+		if required {
+			v.fail(field, "missing required element")
+		}
+		return false
+	default:
+		v.fail(field, fmt.Sprintf("value %q is not a valid xs:boolean", value))
+		return false
+	}
+}
+
+// decimal проверяет элемент типа xs:decimal
+func (v *validator) decimal(field, value string, required bool) float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if required {
+			v.fail(field, "missing required element")
+		}
+		return 0
+	}
+
+	// xs:decimal не допускает экспоненту и специальные значения
+	if strings.ContainsAny(value, "eEnN") {
+		v.fail(field, fmt.Sprintf("value %q is not a valid xs:decimal", value))
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		v.fail(field, fmt.Sprintf("value %q is not a valid xs:decimal", value))
+		return 0
+	}
+	return f
+}
+
+// amount проверяет денежный элемент типа xs:decimal с точностью до тыйына
+func (v *validator) amount(field, value string, required bool) money.Amount {
+	if v.decimal(field, value, required) == 0 {
+		return 0
+	}
+	a, err := money.Parse(strings.TrimSpace(value))
+	if err != nil {
+		v.fail(field, fmt.Sprintf("value %q is not a valid amount: %v", value, err))
+		return 0
+	}
+	return a
+}
+
+// date проверяет элемент типа xs:date
+func (v *validator) date(field, value string, required bool) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if required {
+			v.fail(field, "missing required element")
+		}
+		return time.Time{}
+	}
+
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		v.fail(field, fmt.Sprintf("value %q is not a valid xs:date (YYYY-MM-DD)", value))
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,110 @@
+package esfxml
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// Marshal сериализует документы в файл обмена с корневым элементом receipts
+func Marshal(docs ...entity.EsfDocument) ([]byte, error) {
+	root := Receipts{Receipts: make([]Receipt, len(docs))}
+	for i := range docs {
+		root.Receipts[i] = FromEntity(&docs[i])
+	}
+
+	out, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// FromEntity преобразует документ в элемент receipt
+func FromEntity(doc *entity.EsfDocument) Receipt {
+	r := Receipt{
+		ForeignName:                    doc.ForeignName,
+		IsBranchDataSent:               formatBool(doc.IsBranchDataSent),
+		IsPriceWithoutTaxes:            formatBool(doc.IsPriceWithoutTaxes),
+		AffiliateTin:                   doc.AffiliateTin,
+		IsIndustry:                     formatBool(doc.IsIndustry),
+		OwnedCrmReceiptCode:            doc.OwnedCrmReceiptCode,
+		OperationTypeCode:              doc.OperationTypeCode,
+		DeliveryDate:                   formatDate(doc.DeliveryDate),
+		DeliveryTypeCode:               doc.DeliveryTypeCode,
+		IsResident:                     formatBool(doc.IsResident),
+		ContractorTin:                  doc.ContractorTin,
+		SupplierBankAccount:            doc.SupplierBankAccount,
+		ContractorBankAccount:          doc.ContractorBankAccount,
+		CurrencyCode:                   doc.CurrencyCode,
+		CountryCode:                    doc.CountryCode,
+		CurrencyRate:                   formatOptional(doc.CurrencyRate, 4),
+		TotalCurrencyValue:             formatAmount(doc.TotalCurrencyValue),
+		TotalCurrencyValueWithoutTaxes: formatAmount(doc.TotalCurrencyValueWithoutTaxes),
+		SupplyContractNumber:           doc.SupplyContractNumber,
+		ContractStartDate:              formatDate(doc.ContractStartDate),
+		Comment:                        doc.Comment,
+		DeliveryCode:                   doc.DeliveryCode,
+		PaymentCode:                    doc.PaymentCode,
+		TaxRateVATCode:                 doc.TaxRateVATCode,
+		CatalogEntries:                 make([]CatalogEntry, len(doc.CatalogEntries)),
+		OpeningBalances:                formatOptionalAmount(doc.OpeningBalances),
+		AssessedContributionsAmount:    formatOptionalAmount(doc.AssessedContributionsAmount),
+		PaidAmount:                     formatOptionalAmount(doc.PaidAmount),
+		PenaltiesAmount:                formatOptionalAmount(doc.PenaltiesAmount),
+		FinesAmount:                    formatOptionalAmount(doc.FinesAmount),
+		ClosingBalances:                formatOptionalAmount(doc.ClosingBalances),
+		AmountToBePaid:                 formatOptionalAmount(doc.AmountToBePaid),
+		PersonalAccountNumber:          doc.PersonalAccountNumber,
+	}
+
+	for i, e := range doc.CatalogEntries {
+		r.CatalogEntries[i] = CatalogEntry{
+			UnitClassificationCode: e.UnitClassificationCode,
+			SalesTaxCode:           e.SalesTaxCode,
+			CustomsAuthorityCode:   e.CustomsAuthorityCode,
+			Quantity:               strconv.FormatFloat(e.Quantity, 'f', -1, 64),
+			Price:                  formatAmount(e.Price),
+			VatAmount:              formatAmount(e.VatAmount),
+			SalesTaxAmount:         formatAmount(e.SalesTaxAmount),
+			AmountWithoutTaxes:     formatAmount(e.AmountWithoutTaxes),
+			TotalAmount:            formatAmount(e.TotalAmount),
+		}
+	}
+
+	return r
+}
+
+func formatBool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+func formatAmount(v money.Amount) string {
+	return v.String()
+}
+
+// formatOptionalAmount не выводит нулевые суммы необязательных элементов
+func formatOptionalAmount(v money.Amount) string {
+	if v.IsZero() {
+		return ""
+	}
+	return v.String()
+}
+
+// formatOptional не выводит нулевые значения необязательных элементов
+func formatOptional(v float64, precision int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', precision, 64)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateLayout)
+}
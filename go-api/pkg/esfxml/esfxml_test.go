@@ -0,0 +1,155 @@
+package esfxml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDocument() entity.EsfDocument {
+	return entity.EsfDocument{
+		ForeignName:                    "Tunduck LLC",
+		IsPriceWithoutTaxes:            true,
+		IsResident:                     true,
+		OperationTypeCode:              "10",
+		DeliveryDate:                   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		DeliveryTypeCode:               "1",
+		ContractorTin:                  "01234567890123",
+		CurrencyCode:                   "KGS",
+		CurrencyRate:                   1,
+		TotalCurrencyValue:             money.MustParse("1120"),
+		TotalCurrencyValueWithoutTaxes: money.MustParse("1000"),
+		Comment:                        "Поставка <срочно> & в срок",
+		PaymentCode:                    "1",
+		TaxRateVATCode:                 "1",
+		CatalogEntries: []entity.EsfEntries{{
+			UnitClassificationCode: "796",
+			SalesTaxCode:           "001",
+			Quantity:               2.5,
+			Price:                  money.MustParse("400"),
+			VatAmount:              money.MustParse("120"),
+			AmountWithoutTaxes:     money.MustParse("1000"),
+			TotalAmount:            money.MustParse("1120"),
+		}},
+	}
+}
+
+func TestMarshalUnmarshal_RoundTrip(t *testing.T) {
+	doc := sampleDocument()
+
+	data, err := Marshal(doc, doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<receipts>")
+	assert.Contains(t, string(data), "<deliveryDate>2024-03-15</deliveryDate>")
+	assert.Contains(t, string(data), "<quantity>2.5</quantity>")
+
+	docs, err := Unmarshal(data)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	got := docs[1]
+	assert.Equal(t, doc.ContractorTin, got.ContractorTin)
+	assert.Equal(t, doc.Comment, got.Comment)
+	assert.True(t, got.DeliveryDate.Equal(doc.DeliveryDate))
+	assert.True(t, got.IsPriceWithoutTaxes)
+	assert.False(t, got.IsBranchDataSent)
+	assert.Equal(t, money.MustParse("1120"), got.TotalCurrencyValue)
+	require.Len(t, got.CatalogEntries, 1)
+	assert.Equal(t, doc.CatalogEntries[0].Quantity, got.CatalogEntries[0].Quantity)
+	assert.Equal(t, doc.CatalogEntries[0].TotalAmount, got.CatalogEntries[0].TotalAmount)
+}
+
+func TestUnmarshal_SingleReceipt(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<receipt>
+  <isBranchDataSent>0</isBranchDataSent>
+  <isPriceWithoutTaxes>1</isPriceWithoutTaxes>
+  <operationTypeCode>10</operationTypeCode>
+  <deliveryDate>2024-01-31</deliveryDate>
+  <deliveryTypeCode>1</deliveryTypeCode>
+  <isResident>true</isResident>
+  <contractorTin>01234567890123</contractorTin>
+  <currencyCode>417</currencyCode>
+  <paymentCode>1</paymentCode>
+  <taxRateVATCode>1</taxRateVATCode>
+  <catalogEntries>
+    <catalogEntry>
+      <unitClassificationCode>796</unitClassificationCode>
+      <salesTaxCode>001</salesTaxCode>
+      <quantity>1</quantity>
+      <price>100</price>
+    </catalogEntry>
+  </catalogEntries>
+</receipt>`)
+
+	docs, err := Unmarshal(data)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.True(t, docs[0].IsPriceWithoutTaxes)
+	assert.Equal(t, "417", docs[0].CurrencyCode)
+}
+
+func TestUnmarshal_SchemaViolations(t *testing.T) {
+	data := []byte(`<receipts>
+  <receipt>
+    <isBranchDataSent>yes</isBranchDataSent>
+    <isPriceWithoutTaxes>true</isPriceWithoutTaxes>
+    <operationTypeCode>10</operationTypeCode>
+    <deliveryDate>31.01.2024</deliveryDate>
+    <deliveryTypeCode>1</deliveryTypeCode>
+    <isResident>true</isResident>
+    <contractorTin>12345</contractorTin>
+    <currencyCode>KGS</currencyCode>
+    <paymentCode>1</paymentCode>
+    <taxRateVATCode>1</taxRateVATCode>
+    <catalogEntries>
+      <catalogEntry>
+        <unitClassificationCode>796</unitClassificationCode>
+        <salesTaxCode>001</salesTaxCode>
+        <quantity>1e3</quantity>
+      </catalogEntry>
+    </catalogEntries>
+  </receipt>
+  <receipt>
+    <isBranchDataSent>false</isBranchDataSent>
+  </receipt>
+</receipts>`)
+
+	_, err := Unmarshal(data)
+	schemaErr, ok := err.(*SchemaError)
+	require.True(t, ok)
+
+	first := map[string]string{}
+	second := map[string]string{}
+	for _, issue := range schemaErr.Issues {
+		if issue.Receipt == 0 {
+			first[issue.Field] = issue.Message
+		} else {
+			second[issue.Field] = issue.Message
+		}
+	}
+
+	assert.Equal(t, `value "yes" is not a valid xs:boolean`, first["isBranchDataSent"])
+	assert.Contains(t, first["deliveryDate"], "xs:date")
+	assert.Equal(t, `value "12345" does not match pattern [0-9]{14}`, first["contractorTin"])
+	assert.Contains(t, first["catalogEntries[0].quantity"], "xs:decimal")
+	assert.Equal(t, "missing required element", first["catalogEntries[0].price"])
+	assert.Len(t, first, 5)
+
+	assert.Equal(t, "missing required element", second["contractorTin"])
+	assert.Contains(t, second, "catalogEntries")
+}
+
+func TestUnmarshal_MalformedDocument(t *testing.T) {
+	for _, data := range []string{"", "<receipts><receipt>", "<invoice/>", "<receipts/>"} {
+		_, err := Unmarshal([]byte(data))
+		schemaErr, ok := err.(*SchemaError)
+		require.True(t, ok, "input %q", data)
+		require.Len(t, schemaErr.Issues, 1)
+		assert.Equal(t, -1, schemaErr.Issues[0].Receipt)
+	}
+}
@@ -0,0 +1,98 @@
+// Package esfxml сериализует документы ЭСФ в XML формат обмена налоговой службы и обратно.
+//
+// Файл обмена содержит корневой элемент receipts с одним или несколькими receipt.
+// Имена элементов совпадают с полями API налоговой службы. При импорте принимается
+// как receipts, так и одиночный receipt. Значения проверяются по правилам схемы
+// (обязательность, тип, длина, шаблон), все нарушения возвращаются одним SchemaError.
+package esfxml
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+const (
+	// DateLayout формат xs:date
+	DateLayout = "2006-01-02"
+	// ContentType MIME тип файла обмена
+	ContentType = "application/xml"
+)
+
+// Receipts корневой элемент файла обмена
+type Receipts struct {
+	XMLName  xml.Name  `xml:"receipts"`
+	Receipts []Receipt `xml:"receipt"`
+}
+
+// Receipt счет-фактура. Все значения хранятся строками, чтобы ошибки типов
+// возвращались как ошибки конкретных элементов, а не разбора всего файла.
+type Receipt struct {
+	XMLName                        xml.Name       `xml:"receipt"`
+	ForeignName                    string         `xml:"foreignName,omitempty"`
+	IsBranchDataSent               string         `xml:"isBranchDataSent"`
+	IsPriceWithoutTaxes            string         `xml:"isPriceWithoutTaxes"`
+	AffiliateTin                   string         `xml:"affiliateTin,omitempty"`
+	IsIndustry                     string         `xml:"isIndustry,omitempty"`
+	OwnedCrmReceiptCode            string         `xml:"ownedCrmReceiptCode,omitempty"`
+	OperationTypeCode              string         `xml:"operationTypeCode"`
+	DeliveryDate                   string         `xml:"deliveryDate"`
+	DeliveryTypeCode               string         `xml:"deliveryTypeCode"`
+	IsResident                     string         `xml:"isResident"`
+	ContractorTin                  string         `xml:"contractorTin"`
+	SupplierBankAccount            string         `xml:"supplierBankAccount,omitempty"`
+	ContractorBankAccount          string         `xml:"contractorBankAccount,omitempty"`
+	CurrencyCode                   string         `xml:"currencyCode"`
+	CountryCode                    string         `xml:"countryCode,omitempty"`
+	CurrencyRate                   string         `xml:"currencyRate,omitempty"`
+	TotalCurrencyValue             string         `xml:"totalCurrencyValue,omitempty"`
+	TotalCurrencyValueWithoutTaxes string         `xml:"totalCurrencyValueWithoutTaxes,omitempty"`
+	SupplyContractNumber           string         `xml:"supplyContractNumber,omitempty"`
+	ContractStartDate              string         `xml:"contractStartDate,omitempty"`
+	Comment                        string         `xml:"comment,omitempty"`
+	DeliveryCode                   string         `xml:"deliveryCode,omitempty"`
+	PaymentCode                    string         `xml:"paymentCode"`
+	TaxRateVATCode                 string         `xml:"taxRateVATCode"`
+	CatalogEntries                 []CatalogEntry `xml:"catalogEntries>catalogEntry"`
+	OpeningBalances                string         `xml:"openingBalances,omitempty"`
+	AssessedContributionsAmount    string         `xml:"assessedContributionsAmount,omitempty"`
+	PaidAmount                     string         `xml:"paidAmount,omitempty"`
+	PenaltiesAmount                string         `xml:"penaltiesAmount,omitempty"`
+	FinesAmount                    string         `xml:"finesAmount,omitempty"`
+	ClosingBalances                string         `xml:"closingBalances,omitempty"`
+	AmountToBePaid                 string         `xml:"amountToBePaid,omitempty"`
+	PersonalAccountNumber          string         `xml:"personalAccountNumber,omitempty"`
+}
+
+// CatalogEntry позиция счета-фактуры
+type CatalogEntry struct {
+	UnitClassificationCode string `xml:"unitClassificationCode"`
+	SalesTaxCode           string `xml:"salesTaxCode"`
+	CustomsAuthorityCode   string `xml:"customsAuthorityCode,omitempty"`
+	Quantity               string `xml:"quantity"`
+	Price                  string `xml:"price"`
+	VatAmount              string `xml:"vatAmount,omitempty"`
+	SalesTaxAmount         string `xml:"salesTaxAmount,omitempty"`
+	AmountWithoutTaxes     string `xml:"amountWithoutTaxes,omitempty"`
+	TotalAmount            string `xml:"totalAmount,omitempty"`
+}
+
+// Issue нарушение схемы. Receipt - номер счета-фактуры в файле (с 0),
+// -1 для ошибок файла целиком. Field - путь к элементу в стиле API (catalogEntries[0].price).
+type Issue struct {
+	Receipt int
+	Field   string
+	Message string
+}
+
+// SchemaError содержит все нарушения схемы, найденные в файле
+type SchemaError struct {
+	Issues []Issue
+}
+
+// Error реализует интерфейс error
+func (e *SchemaError) Error() string {
+	if len(e.Issues) == 0 {
+		return "xml schema validation failed"
+	}
+	return fmt.Sprintf("xml schema validation failed: %s: %s (%d issue(s))", e.Issues[0].Field, e.Issues[0].Message, len(e.Issues))
+}
@@ -15,6 +15,7 @@ type ContextKey string
 const (
 	RequestIDKey   ContextKey = "request_id"
 	UserIDKey      ContextKey = "user_id"
+	UsernameKey    ContextKey = "username"
 	OrganizationID ContextKey = "org_id"
 	TraceIDKey     ContextKey = "trace_id"
 )
@@ -51,6 +51,9 @@ func ErrorHandlingMiddleware(logger *logrus.Logger) fiber.Handler {
 			if appErr.Details != "" {
 				response["details"] = appErr.Details
 			}
+			if len(appErr.Fields) > 0 {
+				response["fields"] = appErr.Fields
+			}
 
 		} else if fe, ok := err.(*fiber.Error); ok {
 			// Обработка Fiber ошибок
@@ -0,0 +1,269 @@
+// Package money реализует точные денежные суммы.
+//
+// Amount хранит сумму в тыйынах (1/100 сома) целым числом, поэтому сложение
+// и сравнение сумм не накапливают погрешность двоичного представления float64.
+// Умножение и деление на дробные множители (количество, ставки, курсы)
+// выполняются в рациональных числах с округлением результата «половина от нуля».
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Amount денежная сумма в тыйынах
+type Amount int64
+
+// Scale число тыйынов в соме
+const Scale = 100
+
+// maxMinor наибольшая сумма, помещающаяся в колонку decimal(15,2)
+const maxMinor = 999999999999999
+
+// decimalPattern допустимая запись суммы: десятичное число, возможно с экспонентой (1e3 в JSON)
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d{1,2})?$`)
+
+// roundingEpsilon компенсирует погрешность двоичного представления float64
+// при округлении до тыйынов в FromFloat
+const roundingEpsilon = 1e-6
+
+var (
+	// ErrPrecision сумма содержит больше двух знаков после запятой
+	ErrPrecision = errors.New("amount must not have more than 2 decimal places")
+	// ErrRange сумма не помещается в decimal(15,2)
+	ErrRange = errors.New("amount is out of range")
+)
+
+// FromFloat округляет число до тыйынов. Используется на границах, где сумма
+// уже представлена float64 (расчеты с курсами, данные старых форматов).
+func FromFloat(v float64) Amount {
+	return Amount(math.Round(v*Scale + math.Copysign(roundingEpsilon, v)))
+}
+
+// Parse разбирает десятичную запись суммы без потери точности.
+// Допускается запятая в качестве разделителя и пробелы между разрядами;
+// сумма с более чем двумя значащими знаками после запятой отклоняется.
+// Исключение - погрешность float64 в данных, записанных до перехода на Amount
+// (например, 0.30000000000000004 в JSON снимков документов): такие значения округляются.
+func Parse(s string) (Amount, error) {
+	return parse(s, false)
+}
+
+// MustParse разбирает сумму и паникует при ошибке; предназначена для констант и тестов
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func parse(s string, round bool) (Amount, error) {
+	clean := strings.NewReplacer(" ", "", "\u00a0", "", ",", ".").Replace(strings.TrimSpace(s))
+	if !decimalPattern.MatchString(clean) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	r, ok := new(big.Rat).SetString(clean)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	r.Mul(r, big.NewRat(Scale, 1))
+	if !r.IsInt() && !round && !isFloatNoise(r) {
+		return 0, ErrPrecision
+	}
+	return fromRat(r)
+}
+
+// Minor возвращает сумму в тыйынах
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// Float64 возвращает сумму в сомах; используется только для вывода и расчетов с курсами
+func (a Amount) Float64() float64 {
+	return float64(a) / Scale
+}
+
+// String возвращает сумму с двумя знаками после точки, например "-1234.50"
+func (a Amount) String() string {
+	sign, v := "", int64(a)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/Scale, v%Scale)
+}
+
+// IsZero сообщает, равна ли сумма нулю
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// Neg возвращает сумму с противоположным знаком
+func (a Amount) Neg() Amount {
+	return -a
+}
+
+// Abs возвращает модуль суммы
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Add возвращает сумму a + b
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+// Sub возвращает разность a - b
+func (a Amount) Sub(b Amount) Amount {
+	return a - b
+}
+
+// Mul умножает сумму на десятичный множитель (например, цену на количество)
+// и округляет результат до тыйынов. Результат вне decimal(15,2) возвращает ErrRange.
+func (a Amount) Mul(factor float64) (Amount, error) {
+	return a.mulRat(decimalRat(factor), big.NewRat(1, 1))
+}
+
+// Percent возвращает percent процентов от суммы, округленные до тыйынов
+func (a Amount) Percent(percent float64) (Amount, error) {
+	return a.mulRat(decimalRat(percent), big.NewRat(100, 1))
+}
+
+// Share возвращает долю part/whole суммы, округленную до тыйынов.
+// Используется для выделения налога из суммы с налогами; при whole == 0 возвращает 0.
+func (a Amount) Share(part, whole float64) (Amount, error) {
+	if whole == 0 {
+		return 0, nil
+	}
+	return a.mulRat(decimalRat(part), decimalRat(whole))
+}
+
+// Div делит сумму на десятичный делитель (например, курс валюты)
+// и округляет результат до тыйынов; при делителе 0 возвращает 0
+func (a Amount) Div(divisor float64) (Amount, error) {
+	if divisor == 0 {
+		return 0, nil
+	}
+	return a.mulRat(big.NewRat(1, 1), decimalRat(divisor))
+}
+
+func (a Amount) mulRat(num, den *big.Rat) (Amount, error) {
+	r := new(big.Rat).SetInt64(int64(a))
+	r.Mul(r, num)
+	r.Quo(r, den)
+	return fromRat(r)
+}
+
+// Sum возвращает сумму всех слагаемых
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
+
+// decimalRat переводит float64 в рациональное число по его кратчайшей десятичной записи,
+// так что 0.1 становится ровно 1/10, а не ближайшим двоичным приближением
+func decimalRat(v float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(v, 'f', -1, 64))
+	return r
+}
+
+// isFloatNoise сообщает, что число тыйынов отличается от целого не более чем на roundingEpsilon
+func isFloatNoise(r *big.Rat) bool {
+	rounded, err := fromRat(r)
+	if err != nil {
+		return false
+	}
+	d := new(big.Rat).Sub(r, new(big.Rat).SetInt64(int64(rounded)))
+	return d.Abs(d).Cmp(decimalRat(roundingEpsilon)) <= 0
+}
+
+// fromRat округляет число тыйынов до целого «половина от нуля» и проверяет диапазон
+func fromRat(r *big.Rat) (Amount, error) {
+	num := new(big.Int).Abs(r.Num())
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if m.Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if !q.IsInt64() || q.Int64() > maxMinor {
+		return 0, ErrRange
+	}
+	if r.Sign() < 0 {
+		return Amount(-q.Int64()), nil
+	}
+	return Amount(q.Int64()), nil
+}
+
+// MarshalJSON кодирует сумму точным числом с двумя знаками после точки
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON принимает сумму числом или строкой: 1234.5, "1234.50", "1 234,50"
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", s)
+		}
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value сохраняет сумму в БД десятичной строкой, которую PostgreSQL приводит к numeric без потерь
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan читает сумму из numeric, а также из float и integer колонок существующих данных.
+// Значения с большим числом знаков округляются до тыйынов.
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	case float64:
+		*a = FromFloat(v)
+	case float32:
+		*a = FromFloat(float64(v))
+	case int64:
+		*a = Amount(v * Scale)
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanString(s string) error {
+	v, err := parse(s, true)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
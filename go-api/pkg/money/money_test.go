@@ -0,0 +1,112 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParse tests exact parsing of decimal amounts
+func TestParse(t *testing.T) {
+	cases := map[string]Amount{
+		"0":                0,
+		"1234.5":           123450,
+		"-0.01":            -1,
+		"1 234,50":         123450,
+		"0.10":             10,
+		"1e3":              100000,
+		"12.300":           1230,
+		"9999999999999.99": 999999999999999,
+	}
+	for input, expected := range cases {
+		a, err := Parse(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, a, input)
+	}
+
+	_, err := Parse("10.005")
+	assert.ErrorIs(t, err, ErrPrecision)
+	_, err = Parse("10.00001")
+	assert.ErrorIs(t, err, ErrPrecision)
+	// Погрешность float64 в ранее сохраненном JSON
+	a, err := Parse("0.30000000000000004")
+	require.NoError(t, err)
+	assert.Equal(t, Amount(30), a)
+	_, err = Parse("10000000000000")
+	assert.ErrorIs(t, err, ErrRange)
+	for _, input := range []string{"", "abc", "1/3", "0x10", "1e400"} {
+		_, err = Parse(input)
+		assert.Error(t, err, input)
+	}
+}
+
+// TestString tests formatting of amounts
+func TestString(t *testing.T) {
+	assert.Equal(t, "0.00", Amount(0).String())
+	assert.Equal(t, "1234.05", Amount(123405).String())
+	assert.Equal(t, "-0.50", Amount(-50).String())
+}
+
+// TestArithmetic tests that multiplication and division round half away from zero
+func TestArithmetic(t *testing.T) {
+	// 0.1 + 0.2 в float64 дает 0.30000000000000004
+	assert.Equal(t, MustParse("0.30"), MustParse("0.10").Add(MustParse("0.20")))
+
+	mul := func(v Amount, err error) Amount {
+		require.NoError(t, err)
+		return v
+	}
+	assert.Equal(t, MustParse("16.67"), mul(MustParse("33.33").Mul(0.5)))
+	assert.Equal(t, MustParse("3.36"), mul(MustParse("1.12").Mul(3)))
+	assert.Equal(t, MustParse("0.13"), mul(MustParse("1.05").Percent(12)))
+	assert.Equal(t, MustParse("-0.13"), mul(MustParse("-1.05").Percent(12)))
+	assert.Equal(t, MustParse("120.00"), mul(MustParse("1120").Share(12, 112)))
+	assert.Equal(t, MustParse("12.52"), mul(MustParse("1120").Div(89.45)))
+	assert.Equal(t, Amount(0), mul(MustParse("1120").Div(0)))
+
+	_, err := MustParse("9999999999999").Mul(1000)
+	assert.ErrorIs(t, err, ErrRange)
+	_, err = MustParse("9999999999999").Div(0.001)
+	assert.ErrorIs(t, err, ErrRange)
+	assert.Equal(t, MustParse("6.00"), Sum(MustParse("1"), MustParse("2"), MustParse("3")))
+}
+
+// TestJSON tests encoding amounts as exact numbers and decoding numbers and strings
+func TestJSON(t *testing.T) {
+	var v struct {
+		Price Amount  `json:"price"`
+		Total *Amount `json:"total"`
+		Paid  Amount  `json:"paid"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"price": 0.1, "total": "1 234,50", "paid": null}`), &v))
+	assert.Equal(t, Amount(10), v.Price)
+	assert.Equal(t, Amount(123450), *v.Total)
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price": 0.10, "total": 1234.50, "paid": 0.00}`, string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"price": 0.001}`), &v))
+}
+
+// TestScan tests reading amounts from numeric and legacy float columns
+func TestScan(t *testing.T) {
+	var a Amount
+	require.NoError(t, a.Scan([]byte("1234.50")))
+	assert.Equal(t, Amount(123450), a)
+	require.NoError(t, a.Scan(0.1+0.2))
+	assert.Equal(t, Amount(30), a)
+	require.NoError(t, a.Scan("2.345"))
+	assert.Equal(t, Amount(235), a)
+	require.NoError(t, a.Scan(int64(7)))
+	assert.Equal(t, Amount(700), a)
+	require.NoError(t, a.Scan(nil))
+	assert.Equal(t, Amount(0), a)
+	assert.Error(t, a.Scan(true))
+
+	value, err := MustParse("-12.3").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "-12.30", value)
+}
@@ -0,0 +1,51 @@
+// Package numbering формирует человекочитаемые номера документов вида INV-2026-000123:
+// префикс организации, год и порядковый номер в пределах года.
+package numbering
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// DefaultPrefix префикс номера, если организация не задала свой
+	DefaultPrefix = "INV"
+	// DefaultWidth число цифр порядкового номера по умолчанию
+	DefaultWidth = 6
+	// MaxPrefixLength наибольшая длина префикса
+	MaxPrefixLength = 20
+	// MaxWidth наибольшее число цифр порядкового номера
+	MaxWidth = 12
+)
+
+// prefixPattern допустимый префикс: латинские буквы, цифры и разделители, начинается с буквы или цифры.
+// Пустой префикс допускается, тогда номер начинается с года.
+var prefixPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9/_-]*)?$`)
+
+// Format возвращает номер документа; порядковый номер дополняется нулями до width цифр
+func Format(prefix string, year int, seq int64, width int) string {
+	number := fmt.Sprintf("%d-%0*d", year, width, seq)
+	if prefix == "" {
+		return number
+	}
+	return prefix + "-" + number
+}
+
+// ValidatePrefix проверяет префикс номера
+func ValidatePrefix(prefix string) error {
+	if len(prefix) > MaxPrefixLength {
+		return fmt.Errorf("prefix must not exceed %d characters", MaxPrefixLength)
+	}
+	if !prefixPattern.MatchString(prefix) {
+		return fmt.Errorf("prefix may contain only latin letters, digits, '-', '_' and '/' and must start with a letter or digit")
+	}
+	return nil
+}
+
+// ValidateWidth проверяет число цифр порядкового номера
+func ValidateWidth(width int) error {
+	if width < 1 || width > MaxWidth {
+		return fmt.Errorf("width must be between 1 and %d", MaxWidth)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package numbering
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFormat tests document number formatting
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "INV-2026-000123", Format(DefaultPrefix, 2026, 123, DefaultWidth))
+	assert.Equal(t, "2026-07", Format("", 2026, 7, 2))
+	// Порядковый номер длиннее width не обрезается
+	assert.Equal(t, "KG/INV-2026-1234", Format("KG/INV", 2026, 1234, 3))
+}
+
+// TestValidate tests numbering settings validation
+func TestValidate(t *testing.T) {
+	assert.NoError(t, ValidatePrefix(""))
+	assert.NoError(t, ValidatePrefix("INV_KG-1"))
+	assert.Error(t, ValidatePrefix("-INV"))
+	assert.Error(t, ValidatePrefix("СФ"))
+	assert.Error(t, ValidatePrefix("ABCDEFGHIJKLMNOPQRSTU"))
+
+	assert.NoError(t, ValidateWidth(DefaultWidth))
+	assert.Error(t, ValidateWidth(0))
+	assert.Error(t, ValidateWidth(MaxWidth+1))
+}
@@ -13,10 +13,13 @@ type FilterParams struct {
 
 // DocumentFilterParams спеціалізована структура для фільтрації документів
 type DocumentFilterParams struct {
-	Status        string // active, archived
+	Status        string // draft, ready, signed, submitted, accepted, rejected, cancelled
 	CreatedAfter  string // ISO 8601 дата
 	CreatedBefore string
-	Search        string // пошук по назві/опису
+	Search        string // повнотекстовий пошук по номеру, ІПН, назві, договору, коментарю, рахунку та кодам позицій
+	Number        string // пошук по номеру документа (частина номера)
+	Direction     string // outgoing - виставлені документи, incoming - документи постачальників
+	SupplierTin   string // ІПН постачальника вхідного документа
 }
 
 // OrganizationFilterParams спеціалізована структура для фільтрації організацій
@@ -39,6 +42,9 @@ func ExtractDocumentFilters(ctx *fiber.Ctx) DocumentFilterParams {
 		CreatedAfter:  ctx.Query("created_after", ""),
 		CreatedBefore: ctx.Query("created_before", ""),
 		Search:        ctx.Query("search", ""),
+		Number:        ctx.Query("number", ""),
+		Direction:     ctx.Query("direction", ""),
+		SupplierTin:   ctx.Query("supplier_tin", ""),
 	}
 }
 
@@ -62,7 +68,8 @@ func ExtractUserFilters(ctx *fiber.Ctx) UserFilterParams {
 // HasFilters перевіряє, чи встановлені якісь фільтри
 func (f DocumentFilterParams) HasFilters() bool {
 	return f.Status != "" || f.CreatedAfter != "" ||
-		f.CreatedBefore != "" || strings.TrimSpace(f.Search) != ""
+		f.CreatedBefore != "" || strings.TrimSpace(f.Search) != "" || f.Number != "" ||
+		f.Direction != "" || f.SupplierTin != ""
 }
 
 // HasFilters перевіряє, чи встановлені якісь фільтри
@@ -0,0 +1,55 @@
+package pdf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FontDirEnv - переменная окружения с каталогом шрифтов для печатных форм
+const FontDirEnv = "PDF_FONT_DIR"
+
+const (
+	fontFamily      = "DejaVu"
+	regularFontFile = "DejaVuSans.ttf"
+	boldFontFile    = "DejaVuSans-Bold.ttf"
+)
+
+// defaultFontDirs - стандартные каталоги шрифтов DejaVu (Debian/Ubuntu, Alpine, Arch)
+var defaultFontDirs = []string{
+	"/usr/share/fonts/truetype/dejavu",
+	"/usr/share/fonts/dejavu",
+	"/usr/share/fonts/TTF",
+}
+
+// fontSet - TrueType шрифты с кириллицей, встраиваемые в PDF
+type fontSet struct {
+	regular []byte
+	bold    []byte
+}
+
+// loadFonts загружает шрифты из каталога dir. Если dir пустой, используется
+// PDF_FONT_DIR, а затем стандартные каталоги шрифтов.
+func loadFonts(dir string) (*fontSet, error) {
+	dirs := defaultFontDirs
+	if dir == "" {
+		dir = os.Getenv(FontDirEnv)
+	}
+	if dir != "" {
+		dirs = []string{dir}
+	}
+
+	for _, d := range dirs {
+		regular, err := os.ReadFile(filepath.Join(d, regularFontFile))
+		if err != nil {
+			continue
+		}
+		bold, err := os.ReadFile(filepath.Join(d, boldFontFile))
+		if err != nil {
+			continue
+		}
+		return &fontSet{regular: regular, bold: bold}, nil
+	}
+
+	return nil, fmt.Errorf("fonts %s and %s not found in %v, set %s", regularFontFile, boldFontFile, dirs, FontDirEnv)
+}
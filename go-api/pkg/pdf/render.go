@@ -0,0 +1,545 @@
+// Package pdf преобразует печатные формы в формате HTML в PDF без внешних программ.
+//
+// Поддерживается подмножество HTML, достаточное для счетов-фактур и актов:
+// h1-h3, p, div, br, hr, b/strong, table (thead/tbody/tfoot, tr, th, td).
+// Атрибуты: align у блоков и ячеек, width (в процентах) и colspan у ячеек,
+// border="0" у таблицы. Остальные теги выводятся как текст их содержимого.
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	pageMargin   = 15.0
+	baseFontSize = 10.0
+	cellFontSize = 9.0
+	cellPadding  = 1.5
+)
+
+// blockStyle - оформление блочного элемента
+type blockStyle struct {
+	size  float64
+	bold  bool
+	after float64
+}
+
+var blockStyles = map[atom.Atom]blockStyle{
+	atom.H1:  {size: 16, bold: true, after: 3},
+	atom.H2:  {size: 13, bold: true, after: 2},
+	atom.H3:  {size: 11, bold: true, after: 1.5},
+	atom.P:   {size: baseFontSize, after: 2},
+	atom.Div: {size: baseFontSize},
+}
+
+// Renderer формирует PDF из HTML печатной формы
+type Renderer struct {
+	fonts *fontSet
+}
+
+// NewRenderer создает рендерер со шрифтами из каталога fontDir.
+// Если fontDir пустой, шрифты ищутся в PDF_FONT_DIR и стандартных каталогах.
+func NewRenderer(fontDir string) (*Renderer, error) {
+	fonts, err := loadFonts(fontDir)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{fonts: fonts}, nil
+}
+
+// Render записывает в w PDF документ, сверстанный по HTML source
+func (r *Renderer) Render(w io.Writer, source string) error {
+	root, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return fmt.Errorf("parsing print template output: %w", err)
+	}
+
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(pageMargin, pageMargin, pageMargin)
+	doc.SetAutoPageBreak(true, pageMargin)
+	doc.SetCreationDate(time.Now())
+	doc.AddUTF8FontFromBytes(fontFamily, "", r.fonts.regular)
+	doc.AddUTF8FontFromBytes(fontFamily, "B", r.fonts.bold)
+	doc.AddPage()
+
+	l := &layout{pdf: doc, style: blockStyle{size: baseFontSize}}
+	l.walk(root)
+	l.flush()
+
+	if err := doc.Error(); err != nil {
+		return fmt.Errorf("rendering pdf: %w", err)
+	}
+	return doc.Output(w)
+}
+
+// run - фрагмент текста абзаца с единым начертанием
+type run struct {
+	text string
+	bold bool
+}
+
+// layout обходит DOM и выводит блоки на страницы
+type layout struct {
+	pdf   *fpdf.Fpdf
+	style blockStyle
+	align string
+	runs  []run
+	bold  int
+}
+
+func (l *layout) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		l.text(n.Data)
+		return
+	case html.DocumentNode:
+		l.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head:
+		if title := find(n, atom.Title); title != nil {
+			l.pdf.SetTitle(strings.TrimSpace(textContent(title)), true)
+		}
+	case atom.Script, atom.Style:
+	case atom.B, atom.Strong:
+		l.bold++
+		l.children(n)
+		l.bold--
+	case atom.Br:
+		l.flush()
+	case atom.Hr:
+		l.flush()
+		left, _, right, _ := l.pdf.GetMargins()
+		width, _ := l.pdf.GetPageSize()
+		y := l.pdf.GetY() + 1
+		l.pdf.Line(left, y, width-right, y)
+		l.pdf.SetY(y + 2)
+	case atom.Table:
+		l.flush()
+		l.table(n)
+	case atom.H1, atom.H2, atom.H3, atom.P, atom.Div:
+		l.flush()
+		prevStyle, prevAlign := l.style, l.align
+		l.style = blockStyles[n.DataAtom]
+		if align := attr(n, "align"); align != "" {
+			l.align = align
+		}
+		l.children(n)
+		l.flush()
+		l.pdf.Ln(l.style.after)
+		l.style, l.align = prevStyle, prevAlign
+	default:
+		l.children(n)
+	}
+}
+
+func (l *layout) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		l.walk(c)
+	}
+}
+
+// text добавляет текст в текущий абзац, схлопывая пробельные символы как браузер
+func (l *layout) text(data string) {
+	text := collapseSpaces(data)
+	if text == "" {
+		return
+	}
+	if len(l.runs) == 0 || strings.HasSuffix(l.runs[len(l.runs)-1].text, " ") {
+		text = strings.TrimLeft(text, " ")
+	}
+	if text == "" {
+		return
+	}
+	l.runs = append(l.runs, run{text: text, bold: l.bold > 0})
+}
+
+// flush выводит накопленный абзац
+func (l *layout) flush() {
+	runs := l.runs
+	l.runs = nil
+	if len(runs) == 0 {
+		return
+	}
+	runs[len(runs)-1].text = strings.TrimRight(runs[len(runs)-1].text, " ")
+
+	height := lineHeight(l.style.size)
+	align := alignCode(l.align)
+
+	if align == "L" {
+		for _, r := range runs {
+			l.setFont(l.style.size, l.style.bold || r.bold)
+			l.pdf.Write(height, r.text)
+		}
+		l.pdf.Ln(height)
+		return
+	}
+
+	// Выровненный абзац выводится одним начертанием
+	var text strings.Builder
+	bold := true
+	for _, r := range runs {
+		text.WriteString(r.text)
+		bold = bold && r.bold
+	}
+	l.setFont(l.style.size, l.style.bold || bold)
+	l.pdf.WriteAligned(0, height, text.String(), align)
+	l.pdf.Ln(height)
+}
+
+func (l *layout) setFont(size float64, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	l.pdf.SetFont(fontFamily, style, size)
+}
+
+// cell - ячейка таблицы
+type cell struct {
+	text    string
+	bold    bool
+	header  bool
+	align   string
+	colspan int
+	width   float64
+}
+
+type tableRow struct {
+	cells  []cell
+	header bool
+}
+
+// table выводит таблицу, повторяя строки заголовка на каждой новой странице
+func (l *layout) table(n *html.Node) {
+	rows := collectRows(n)
+	if len(rows) == 0 {
+		return
+	}
+
+	left, _, right, bottom := l.pdf.GetMargins()
+	pageWidth, pageHeight := l.pdf.GetPageSize()
+	widths := columnWidths(rows, pageWidth-left-right)
+	border := attr(n, "border") != "0"
+
+	var header []tableRow
+	for _, row := range rows {
+		if !row.header {
+			break
+		}
+		header = append(header, row)
+	}
+
+	l.pdf.SetAutoPageBreak(false, bottom)
+	defer l.pdf.SetAutoPageBreak(true, bottom)
+
+	for i, row := range rows {
+		height := l.rowHeight(row, widths)
+		if l.pdf.GetY()+height > pageHeight-bottom && l.pdf.GetY() > pageMargin+1 {
+			l.pdf.AddPage()
+			if i >= len(header) {
+				for _, h := range header {
+					l.drawRow(h, widths, l.rowHeight(h, widths), border)
+				}
+			}
+		}
+		l.drawRow(row, widths, height, border)
+	}
+	l.pdf.Ln(2)
+}
+
+func (l *layout) rowHeight(row tableRow, widths []float64) float64 {
+	height := lineHeight(cellFontSize)
+	col := 0
+	for _, c := range row.cells {
+		w := spanWidth(widths, col, c.colspan)
+		col += c.colspan
+		l.setFont(cellFontSize, c.bold)
+		if h := float64(len(l.cellLines(c, w)))*lineHeight(cellFontSize) + 2*cellPadding; h > height {
+			height = h
+		}
+	}
+	return height
+}
+
+func (l *layout) drawRow(row tableRow, widths []float64, height float64, border bool) {
+	left, _, _, _ := l.pdf.GetMargins()
+	y := l.pdf.GetY()
+	x := left
+	col := 0
+
+	for _, c := range row.cells {
+		w := spanWidth(widths, col, c.colspan)
+		col += c.colspan
+
+		switch {
+		case c.header && border:
+			l.pdf.SetFillColor(235, 235, 235)
+			l.pdf.Rect(x, y, w, height, "FD")
+		case border:
+			l.pdf.Rect(x, y, w, height, "D")
+		}
+
+		l.setFont(cellFontSize, c.bold)
+		align := alignCode(c.align)
+		if c.align == "" && c.header {
+			align = "C"
+		}
+		for k, line := range l.cellLines(c, w) {
+			l.pdf.SetXY(x, y+cellPadding+float64(k)*lineHeight(cellFontSize))
+			l.pdf.CellFormat(w, lineHeight(cellFontSize), line, "", 0, align, false, 0, "")
+		}
+		x += w
+	}
+
+	l.pdf.SetXY(left, y+height)
+}
+
+// cellLines разбивает текст ячейки на строки по ширине колонки
+func (l *layout) cellLines(c cell, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(c.text, "\n") {
+		split := l.pdf.SplitText(paragraph, width)
+		if len(split) == 0 {
+			split = []string{""}
+		}
+		lines = append(lines, split...)
+	}
+	return lines
+}
+
+// collectRows собирает строки таблицы, включая thead, tbody и tfoot
+func collectRows(table *html.Node) []tableRow {
+	var rows []tableRow
+	var visit func(n *html.Node, inHead bool)
+	visit = func(n *html.Node, inHead bool) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			switch c.DataAtom {
+			case atom.Thead:
+				visit(c, true)
+			case atom.Tbody, atom.Tfoot:
+				visit(c, false)
+			case atom.Tr:
+				row := tableRow{header: true}
+				for td := c.FirstChild; td != nil; td = td.NextSibling {
+					if td.Type != html.ElementNode || (td.DataAtom != atom.Td && td.DataAtom != atom.Th) {
+						continue
+					}
+					rc := newCell(td)
+					row.header = row.header && rc.header
+					row.cells = append(row.cells, rc)
+				}
+				row.header = row.header || inHead
+				if len(row.cells) > 0 {
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+	visit(table, false)
+	return rows
+}
+
+func newCell(n *html.Node) cell {
+	c := cell{
+		header:  n.DataAtom == atom.Th,
+		align:   attr(n, "align"),
+		colspan: 1,
+	}
+	c.bold = c.header || find(n, atom.B) != nil || find(n, atom.Strong) != nil
+
+	if span, err := strconv.Atoi(attr(n, "colspan")); err == nil && span > 1 {
+		c.colspan = span
+	}
+	if width := strings.TrimSuffix(strings.TrimSpace(attr(n, "width")), "%"); width != "" {
+		if w, err := strconv.ParseFloat(width, 64); err == nil && w > 0 {
+			c.width = w
+		}
+	}
+
+	var lines []string
+	var current strings.Builder
+	var visit func(*html.Node)
+	visit = func(node *html.Node) {
+		for ch := node.FirstChild; ch != nil; ch = ch.NextSibling {
+			switch {
+			case ch.Type == html.TextNode:
+				current.WriteString(collapseSpaces(ch.Data))
+			case ch.Type == html.ElementNode && (ch.DataAtom == atom.Br || ch.DataAtom == atom.P || ch.DataAtom == atom.Div):
+				if ch.DataAtom != atom.Br {
+					visit(ch)
+				}
+				lines = append(lines, strings.TrimSpace(current.String()))
+				current.Reset()
+			case ch.Type == html.ElementNode:
+				visit(ch)
+			}
+		}
+	}
+	visit(n)
+	if rest := strings.TrimSpace(current.String()); rest != "" || len(lines) == 0 {
+		lines = append(lines, rest)
+	}
+	c.text = strings.Join(lines, "\n")
+
+	return c
+}
+
+// columnWidths вычисляет ширину колонок. Проценты берутся из первой строки
+// без объединенных ячеек, оставшаяся ширина делится поровну.
+func columnWidths(rows []tableRow, total float64) []float64 {
+	columns := 0
+	for _, row := range rows {
+		span := 0
+		for _, c := range row.cells {
+			span += c.colspan
+		}
+		if span > columns {
+			columns = span
+		}
+	}
+
+	percents := make([]float64, columns)
+	for _, row := range rows {
+		if len(row.cells) != columns {
+			continue
+		}
+		for i, c := range row.cells {
+			percents[i] = c.width
+		}
+		break
+	}
+
+	var specified float64
+	unspecified := 0
+	for _, p := range percents {
+		if p > 0 {
+			specified += p
+		} else {
+			unspecified++
+		}
+	}
+
+	// Проценты нормируются к 100; если они не оставляют места колонкам без ширины,
+	// такие колонки получают равную долю
+	scale := 1.0
+	rest := 100 - specified
+	switch {
+	case unspecified == 0 && specified > 0:
+		scale = 100 / specified
+	case unspecified > 0 && rest <= 0:
+		rest = float64(unspecified) * 100 / float64(columns)
+		scale = (100 - rest) / specified
+	}
+
+	widths := make([]float64, columns)
+	for i, p := range percents {
+		if p > 0 {
+			widths[i] = total * p * scale / 100
+		} else {
+			widths[i] = total * rest / 100 / float64(unspecified)
+		}
+	}
+	return widths
+}
+
+func spanWidth(widths []float64, from, span int) float64 {
+	var w float64
+	for i := from; i < from+span && i < len(widths); i++ {
+		w += widths[i]
+	}
+	return w
+}
+
+func lineHeight(size float64) float64 {
+	return size * 0.45
+}
+
+func alignCode(align string) string {
+	switch strings.ToLower(align) {
+	case "center":
+		return "C"
+	case "right":
+		return "R"
+	default:
+		return "L"
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// find возвращает первый потомок с тегом a
+func find(n *html.Node, a atom.Atom) *html.Node {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.DataAtom == a {
+			return c
+		}
+		if found := find(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	var visit func(*html.Node)
+	visit = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			b.WriteString(node.Data)
+		}
+		for c := node.FirstChild; c != nil; c = c.NextSibling {
+			visit(c)
+		}
+	}
+	visit(n)
+	return b.String()
+}
+
+// collapseSpaces схлопывает пробельные символы и заменяет символы вне
+// базовой плоскости Unicode, которые не поддерживаются шрифтом
+// collapseSpaces схлопывает пробельные символы и заменяет символы вне
+// базовой плоскости Unicode, которые не поддерживаются шрифтом
+func collapseSpaces(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f':
+			if !space {
+				b.WriteByte(' ')
+			}
+			space = true
+		case r > 0xFFFF:
+			b.WriteByte('?')
+			space = false
+		default:
+			b.WriteRune(r)
+			space = false
+		}
+	}
+	return b.String()
+}
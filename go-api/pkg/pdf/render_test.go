@@ -0,0 +1,83 @@
+package pdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	r, err := NewRenderer("")
+	if err != nil {
+		t.Skipf("pdf fonts are not installed: %v", err)
+	}
+	return r
+}
+
+func parseTable(t *testing.T, source string) *html.Node {
+	t.Helper()
+	root, err := html.Parse(strings.NewReader(source))
+	require.NoError(t, err)
+	table := find(root, atom.Table)
+	require.NotNil(t, table)
+	return table
+}
+
+func TestRender_ProducesPDF(t *testing.T) {
+	r := newTestRenderer(t)
+
+	var rows strings.Builder
+	for i := 0; i < 120; i++ {
+		rows.WriteString("<tr><td>Товар 🙂 с длинным наименованием, которое переносится на несколько строк</td><td align=\"right\">1 120,00</td></tr>")
+	}
+
+	var out bytes.Buffer
+	err := r.Render(&out, `<html><head><title>Счет-фактура</title></head><body>
+		<h1 align="center">Счет-фактура № 1</h1>
+		<p>Поставщик: <b>ОсОО «Тест»</b><br>ИНН 01234567890123</p>
+		<hr>
+		<table><thead><tr><th width="70%">Наименование</th><th>Сумма</th></tr></thead>
+		<tbody>`+rows.String()+`</tbody>
+		<tfoot><tr><td colspan="2" align="right"><b>Итого: 1 120,00</b></td></tr></tfoot></table>
+		<p align="right">Одна тысяча сто двадцать сомов 00 тыйынов</p>
+	</body></html>`)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+	assert.Greater(t, bytes.Count(out.Bytes(), []byte("/Type /Page\n")), 1)
+}
+
+func TestCollectRows_HeaderAndCells(t *testing.T) {
+	rows := collectRows(parseTable(t, `<table>
+		<thead><tr><td>Наименование</td><td>Сумма</td></tr></thead>
+		<tr><th>Раздел</th><th>1</th></tr>
+		<tr><td>Строка<br>вторая  строка</td><td align="right"><strong>10</strong></td></tr>
+		<tr><td colspan="2">Итого</td></tr>
+	</table>`))
+
+	require.Len(t, rows, 4)
+	assert.True(t, rows[0].header)
+	assert.True(t, rows[1].header)
+	assert.False(t, rows[2].header)
+	assert.Equal(t, "Строка\nвторая строка", rows[2].cells[0].text)
+	assert.True(t, rows[2].cells[1].bold)
+	assert.Equal(t, "right", rows[2].cells[1].align)
+	assert.Equal(t, 2, rows[3].cells[0].colspan)
+}
+
+func TestColumnWidths(t *testing.T) {
+	rows := collectRows(parseTable(t, `<table>
+		<tr><td colspan="3">Заголовок</td></tr>
+		<tr><td width="50%">a</td><td>b</td><td>c</td></tr>
+	</table>`))
+	assert.InDeltaSlice(t, []float64{90, 45, 45}, columnWidths(rows, 180), 1e-9)
+
+	rows = collectRows(parseTable(t, `<table><tr><td width="80%">a</td><td width="80%">b</td><td>c</td></tr></table>`))
+	assert.InDeltaSlice(t, []float64{60, 60, 60}, columnWidths(rows, 180), 1e-9)
+}
@@ -7,11 +7,13 @@ const (
 	RoleAdmin  Role = "admin"  // Администратор - полный доступ
 	RoleUser   Role = "user"   // Обычный пользователь - ограниченный доступ
 	RoleViewer Role = "viewer" // Просмотр - только чтение
+	// Согласующий - читает и согласует документы, но не создает и не изменяет их
+	RoleApprover Role = "approver"
 )
 
 // IsValid проверяет, валидна ли роль
 func (r Role) IsValid() bool {
-	return r == RoleAdmin || r == RoleUser || r == RoleViewer
+	return r == RoleAdmin || r == RoleUser || r == RoleViewer || r == RoleApprover
 }
 
 // String возвращает строковое представление роли
@@ -34,6 +36,8 @@ const (
 	PermissionReadDocument   Permission = "read:document"
 	PermissionUpdateDocument Permission = "update:document"
 	PermissionDeleteDocument Permission = "delete:document"
+	// Согласование документов по политике организации
+	PermissionApproveDocument Permission = "approve:document"
 
 	// Права для пользователей
 	PermissionCreateUser Permission = "create:user"
@@ -44,6 +48,12 @@ const (
 	// Права для ролей
 	PermissionAssignRole Permission = "assign:role"
 	PermissionViewRoles  Permission = "view:roles"
+
+	// Официальные курсы валют общие для всех организаций
+	PermissionManageCurrencyRates Permission = "manage:currency_rates"
+
+	// Работа с документами любой организации платформы, а не только текущей
+	PermissionAccessAllOrganizations Permission = "access:all_organizations"
 )
 
 // RolePermissions определяет какие разрешения есть у каждой роли
@@ -52,8 +62,11 @@ var RolePermissions = map[Role][]Permission{
 		// Администратор имеет все права
 		PermissionCreateOrganization, PermissionReadOrganization, PermissionUpdateOrganization, PermissionDeleteOrganization,
 		PermissionCreateDocument, PermissionReadDocument, PermissionUpdateDocument, PermissionDeleteDocument,
+		PermissionApproveDocument,
 		PermissionCreateUser, PermissionReadUser, PermissionUpdateUser, PermissionDeleteUser,
 		PermissionAssignRole, PermissionViewRoles,
+		PermissionManageCurrencyRates,
+		PermissionAccessAllOrganizations,
 	},
 	RoleUser: {
 		// Обычный пользователь может читать и создавать
@@ -68,6 +81,12 @@ var RolePermissions = map[Role][]Permission{
 		PermissionReadUser,
 		PermissionViewRoles,
 	},
+	RoleApprover: {
+		// Согласующий читает и согласует документы
+		PermissionReadOrganization,
+		PermissionReadDocument, PermissionApproveDocument,
+		PermissionReadUser, PermissionViewRoles,
+	},
 }
 
 // HasPermission проверяет, есть ли у роли определенное разрешение
@@ -0,0 +1,103 @@
+// Package recurrence рассчитывает даты повторяющихся документов.
+//
+// Правило задает выпуск документа в день DayOfMonth каждые Interval месяцев, начиная с Start.
+// Если в месяце нет такого дня (31 февраля), документ выпускается в последний день месяца.
+// Каждой дате соответствует расчетный период - месяц выпуска в формате 2006-01;
+// по периоду проверяется, что документ за период уже создан.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FrequencyMonthly ежемесячная периодичность; Interval задает число месяцев между выпусками
+const FrequencyMonthly = "monthly"
+
+// PeriodLayout формат расчетного периода
+const PeriodLayout = "2006-01"
+
+// MaxInterval наибольший интервал между выпусками в месяцах
+const MaxInterval = 12
+
+// Rule правило повторения
+type Rule struct {
+	Frequency  string
+	Interval   int
+	DayOfMonth int
+	// Первая возможная дата выпуска
+	Start time.Time
+	// Последняя возможная дата выпуска; nil - без ограничения
+	End *time.Time
+}
+
+// Validate проверяет правило
+func (r Rule) Validate() error {
+	switch {
+	case r.Frequency != FrequencyMonthly:
+		return fmt.Errorf("unsupported frequency %q, only %q is supported", r.Frequency, FrequencyMonthly)
+	case r.Interval < 1 || r.Interval > MaxInterval:
+		return fmt.Errorf("interval must be between 1 and %d months", MaxInterval)
+	case r.DayOfMonth < 1 || r.DayOfMonth > 31:
+		return errors.New("day of month must be between 1 and 31")
+	case r.Start.IsZero():
+		return errors.New("start date is required")
+	case r.End != nil && Day(*r.End).Before(Day(r.Start)):
+		return errors.New("end date must not be before the start date")
+	}
+	return nil
+}
+
+// First возвращает первую дату выпуска; ok = false, если до End нет ни одной даты
+func (r Rule) First() (time.Time, bool) {
+	start := Day(r.Start)
+	first := r.inMonth(start.Year(), start.Month())
+	if first.Before(start) {
+		first = r.inMonth(start.Year(), start.Month()+1)
+	}
+	return first, r.within(first)
+}
+
+// Next возвращает дату выпуска, следующую за датой выпуска after
+func (r Rule) Next(after time.Time) (time.Time, bool) {
+	after = Day(after)
+	next := r.inMonth(after.Year(), after.Month()+time.Month(r.Interval))
+	return next, r.within(next)
+}
+
+// Upcoming возвращает до count дат выпуска, начиная с from (from - дата выпуска по правилу)
+func (r Rule) Upcoming(from time.Time, count int) []time.Time {
+	var dates []time.Time
+	date, ok := Day(from), r.within(Day(from))
+	for ok && len(dates) < count {
+		dates = append(dates, date)
+		date, ok = r.Next(date)
+	}
+	return dates
+}
+
+// inMonth возвращает день выпуска в месяце; month может выходить за 1..12
+func (r Rule) inMonth(year int, month time.Month) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := r.DayOfMonth
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+func (r Rule) within(date time.Time) bool {
+	return r.End == nil || !date.After(Day(*r.End))
+}
+
+// Period возвращает расчетный период даты выпуска
+func Period(date time.Time) string {
+	return date.Format(PeriodLayout)
+}
+
+// Day отбрасывает время, оставляя календарную дату в UTC
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
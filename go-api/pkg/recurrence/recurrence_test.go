@@ -0,0 +1,60 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// TestRuleValidate tests rule validation
+func TestRuleValidate(t *testing.T) {
+	end := date(2026, 1, 1)
+	valid := Rule{Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 5, Start: date(2026, 1, 10)}
+	assert.NoError(t, valid.Validate())
+
+	for name, rule := range map[string]Rule{
+		"frequency": {Frequency: "weekly", Interval: 1, DayOfMonth: 5, Start: end},
+		"interval":  {Frequency: FrequencyMonthly, Interval: 13, DayOfMonth: 5, Start: end},
+		"day":       {Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 32, Start: end},
+		"start":     {Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 5},
+		"end":       {Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 5, Start: date(2026, 2, 1), End: &end},
+	} {
+		assert.Error(t, rule.Validate(), name)
+	}
+}
+
+// TestRuleFirst tests the first run date relative to the start date
+func TestRuleFirst(t *testing.T) {
+	rule := Rule{Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 5, Start: date(2026, 1, 10)}
+	first, ok := rule.First()
+	require.True(t, ok)
+	assert.Equal(t, date(2026, 2, 5), first)
+
+	rule.Start = date(2026, 1, 5)
+	first, _ = rule.First()
+	assert.Equal(t, date(2026, 1, 5), first)
+
+	end := date(2026, 1, 31)
+	rule.Start, rule.End = date(2026, 1, 6), &end
+	_, ok = rule.First()
+	assert.False(t, ok)
+}
+
+// TestRuleUpcoming tests month-end clamping, intervals and the end date
+func TestRuleUpcoming(t *testing.T) {
+	rule := Rule{Frequency: FrequencyMonthly, Interval: 1, DayOfMonth: 31, Start: date(2026, 1, 1)}
+	first, _ := rule.First()
+	assert.Equal(t, []time.Time{date(2026, 1, 31), date(2026, 2, 28), date(2026, 3, 31), date(2026, 4, 30)}, rule.Upcoming(first, 4))
+
+	end := date(2026, 7, 15)
+	quarterly := Rule{Frequency: FrequencyMonthly, Interval: 3, DayOfMonth: 15, Start: date(2025, 12, 20), End: &end}
+	first, _ = quarterly.First()
+	assert.Equal(t, []time.Time{date(2026, 1, 15), date(2026, 4, 15), date(2026, 7, 15)}, quarterly.Upcoming(first, 10))
+	assert.Equal(t, "2026-04", Period(date(2026, 4, 15)))
+}
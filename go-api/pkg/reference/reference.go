@@ -0,0 +1,127 @@
+// Package reference содержит справочники кодов ЭСФ, поставляемые вместе с приложением,
+// и набор кодов для проверки документов.
+//
+// Каждый файл data/*.json описывает одну версию справочника. При запуске приложения
+// версии, которых еще нет в основной БД, загружаются и становятся активными.
+// Чтобы обновить справочник, добавьте файл с новой версией или измените version в существующем.
+package reference
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+//go:embed data/*.json
+var bundled embed.FS
+
+// File версия справочника
+type File struct {
+	Catalog string `json:"catalog"`
+	Version string `json:"version"`
+	Items   []Item `json:"items"`
+	// SHA-256 содержимого файла
+	Checksum string `json:"-"`
+}
+
+// Item код справочника
+type Item struct {
+	Code    string `json:"code"`
+	AltCode string `json:"altCode,omitempty"`
+	Name    string `json:"name"`
+}
+
+// Bundled возвращает справочники, встроенные в приложение
+func Bundled() ([]File, error) {
+	entries, err := bundled.ReadDir("data")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, 0, len(entries))
+	for _, entry := range entries {
+		data, err := bundled.ReadFile(path.Join("data", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		file, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		files = append(files, *file)
+	}
+	return files, nil
+}
+
+// Parse разбирает и проверяет файл справочника
+func Parse(data []byte) (*File, error) {
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid reference file: %w", err)
+	}
+
+	if strings.TrimSpace(file.Catalog) == "" {
+		return nil, fmt.Errorf("catalog is required")
+	}
+	if strings.TrimSpace(file.Version) == "" {
+		return nil, fmt.Errorf("version is required")
+	}
+	if len(file.Items) == 0 {
+		return nil, fmt.Errorf("catalog %s has no items", file.Catalog)
+	}
+
+	seen := make(map[string]bool, len(file.Items))
+	for i, item := range file.Items {
+		if item.Code == "" || item.Name == "" {
+			return nil, fmt.Errorf("item %d: code and name are required", i)
+		}
+		if seen[item.Code] {
+			return nil, fmt.Errorf("item %d: duplicate code %q", i, item.Code)
+		}
+		seen[item.Code] = true
+	}
+
+	sum := sha256.Sum256(data)
+	file.Checksum = hex.EncodeToString(sum[:])
+	return &file, nil
+}
+
+// Set набор допустимых кодов активных версий справочников
+type Set struct {
+	codes map[string]map[string]struct{}
+}
+
+// NewSet создает пустой набор
+func NewSet() *Set {
+	return &Set{codes: make(map[string]map[string]struct{})}
+}
+
+// Add добавляет коды справочника; пустые коды пропускаются
+func (s *Set) Add(catalog string, codes ...string) {
+	set, ok := s.codes[catalog]
+	if !ok {
+		set = make(map[string]struct{})
+		s.codes[catalog] = set
+	}
+	for _, code := range codes {
+		if code != "" {
+			set[code] = struct{}{}
+		}
+	}
+}
+
+// Loaded сообщает, загружен ли справочник. Коды справочников, которые не загружены, не проверяются.
+func (s *Set) Loaded(catalog string) bool {
+	_, ok := s.codes[catalog]
+	return ok
+}
+
+// Contains сообщает, есть ли код в справочнике
+func (s *Set) Contains(catalog, code string) bool {
+	_, ok := s.codes[catalog][code]
+	return ok
+}
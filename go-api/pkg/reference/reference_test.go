@@ -0,0 +1,60 @@
+package reference
+
+import (
+	"testing"
+
+	"github.com/rusgainew/tunduck-app/pkg/entity"
+	"github.com/rusgainew/tunduck-app/pkg/tax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBundled tests that every ESF catalog is shipped with the application
+func TestBundled(t *testing.T) {
+	files, err := Bundled()
+	require.NoError(t, err)
+
+	byCatalog := make(map[string]File)
+	for _, f := range files {
+		_, duplicate := byCatalog[f.Catalog]
+		assert.False(t, duplicate, "catalog %s is bundled twice", f.Catalog)
+		assert.Len(t, f.Checksum, 64)
+		byCatalog[f.Catalog] = f
+	}
+
+	for _, catalog := range entity.ReferenceCatalogs() {
+		assert.Contains(t, byCatalog, catalog)
+	}
+
+	t.Run("VAT rate codes match the tax rate table", func(t *testing.T) {
+		rates := tax.DefaultRateTable()
+		for _, item := range byCatalog[entity.ReferenceTaxRateVAT].Items {
+			_, err := rates.VAT(item.Code)
+			assert.NoError(t, err, "code %s", item.Code)
+		}
+	})
+}
+
+// TestParse tests reference file validation
+func TestParse(t *testing.T) {
+	_, err := Parse([]byte(`{"catalog": "unit", "version": "1", "items": [{"code": "796", "name": "Штука"}, {"code": "796", "name": "Шт"}]}`))
+	assert.ErrorContains(t, err, "duplicate code")
+
+	_, err = Parse([]byte(`{"catalog": "unit", "items": [{"code": "796", "name": "Штука"}]}`))
+	assert.ErrorContains(t, err, "version is required")
+
+	_, err = Parse([]byte(`{"catalog": "unit", "version": "1", "items": []}`))
+	assert.Error(t, err)
+}
+
+// TestSet tests code lookup
+func TestSet(t *testing.T) {
+	set := NewSet()
+	set.Add(entity.ReferenceCurrency, "KGS", "417", "")
+
+	assert.True(t, set.Loaded(entity.ReferenceCurrency))
+	assert.False(t, set.Loaded(entity.ReferenceUnit))
+	assert.True(t, set.Contains(entity.ReferenceCurrency, "417"))
+	assert.False(t, set.Contains(entity.ReferenceCurrency, ""))
+	assert.False(t, set.Contains(entity.ReferenceUnit, "796"))
+}
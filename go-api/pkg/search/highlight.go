@@ -0,0 +1,98 @@
+// Package search содержит вспомогательные функции полнотекстового поиска.
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Разметка совпадений в подсветке
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// Terms разбивает поисковую строку на уникальные термы без учета регистра.
+// Кавычки и операторы websearch ("-", "or") отбрасываются.
+func Terms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		term := strings.Trim(field, `"'-+()`)
+		if term == "" || term == "or" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// Highlight оборачивает вхождения термов в text разметкой <mark>...</mark>.
+// Текст экранируется для безопасного вывода в HTML.
+// Второе значение равно false, если совпадений нет.
+func Highlight(text string, terms []string) (string, bool) {
+	if text == "" || len(terms) == 0 {
+		return html.EscapeString(text), false
+	}
+
+	lower := strings.ToLower(text)
+	type span struct{ start, end int }
+	var spans []span
+
+	for _, term := range terms {
+		for offset := 0; offset < len(lower); {
+			idx := strings.Index(lower[offset:], term)
+			if idx < 0 {
+				break
+			}
+			start := offset + idx
+			spans = append(spans, span{start, start + len(term)})
+			offset = start + len(term)
+		}
+	}
+
+	if len(spans) == 0 {
+		return html.EscapeString(text), false
+	}
+
+	// Объединяем пересекающиеся совпадения
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, sp := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if sp.start <= last.end {
+			if sp.end > last.end {
+				last.end = sp.end
+			}
+			continue
+		}
+		merged = append(merged, sp)
+	}
+
+	// strings.ToLower может изменить длину строки в байтах для некоторых символов,
+	// в этом случае позиции не совпадут с исходным текстом
+	if len(lower) != len(text) || !utf8.ValidString(text) {
+		return html.EscapeString(text), true
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, sp := range merged {
+		b.WriteString(html.EscapeString(text[prev:sp.start]))
+		b.WriteString(HighlightStart)
+		b.WriteString(html.EscapeString(text[sp.start:sp.end]))
+		b.WriteString(HighlightEnd)
+		prev = sp.end
+	}
+	b.WriteString(html.EscapeString(text[prev:]))
+
+	return b.String(), true
+}
+
+// EscapeLike экранирует спецсимволы шаблона LIKE/ILIKE
+func EscapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
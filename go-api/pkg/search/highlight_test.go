@@ -0,0 +1,45 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTerms tests query tokenization
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"ооо", "ромашка", "123"}, Terms(`"ООО Ромашка" or -123 ромашка`))
+	assert.Empty(t, Terms("   "))
+}
+
+// TestHighlight tests match markup and escaping
+func TestHighlight(t *testing.T) {
+	t.Run("Marks all case-insensitive matches", func(t *testing.T) {
+		out, ok := Highlight("Договор Д-12 к договору", []string{"договор"})
+		assert.True(t, ok)
+		assert.Equal(t, "<mark>Договор</mark> Д-12 к <mark>договор</mark>у", out)
+	})
+
+	t.Run("Merges overlapping matches", func(t *testing.T) {
+		out, ok := Highlight("01234567", []string{"123", "345"})
+		assert.True(t, ok)
+		assert.Equal(t, "0<mark>12345</mark>67", out)
+	})
+
+	t.Run("Escapes HTML", func(t *testing.T) {
+		out, ok := Highlight("<b>tin</b>", []string{"tin"})
+		assert.True(t, ok)
+		assert.Equal(t, "&lt;b&gt;<mark>tin</mark>&lt;/b&gt;", out)
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		out, ok := Highlight("abc", []string{"x"})
+		assert.False(t, ok)
+		assert.Equal(t, "abc", out)
+	})
+}
+
+// TestEscapeLike tests LIKE pattern escaping
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `100\% a\_b \\`, EscapeLike(`100% a_b \`))
+}
@@ -0,0 +1,200 @@
+// Package signature подписывает данные и проверяет подписи ключами Ed25519 и ECDSA P-256.
+//
+// Открытые ключи принимаются в PEM (блок PUBLIC KEY) или в base64 DER в формате PKIX,
+// подписи - в base64. Ed25519 подписывает само сообщение, ECDSA - его SHA-256
+// (подпись в ASN.1 DER, как у openssl dgst -sha256 -sign).
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Algorithm алгоритм подписи
+type Algorithm string
+
+const (
+	Ed25519   Algorithm = "ed25519"    // Ed25519 (RFC 8032)
+	ECDSAP256 Algorithm = "ecdsa-p256" // ECDSA на кривой P-256 с SHA-256
+)
+
+// ErrInvalidSignature подпись не соответствует сообщению или ключу
+var ErrInvalidSignature = errors.New("signature is invalid")
+
+// PublicKey открытый ключ проверки подписи
+type PublicKey struct {
+	Algorithm Algorithm
+	// Отпечаток ключа: SHA-256 от DER в шестнадцатеричном виде
+	Fingerprint string
+
+	der []byte
+	key crypto.PublicKey
+}
+
+// ParsePublicKey разбирает открытый ключ в PEM или base64 DER.
+// Поддерживаются только ключи Ed25519 и ECDSA P-256.
+func ParsePublicKey(data string) (*PublicKey, error) {
+	data = strings.TrimSpace(data)
+	var der []byte
+	if block, _ := pem.Decode([]byte(data)); block != nil {
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unexpected PEM block %q, expected PUBLIC KEY", block.Type)
+		}
+		der = block.Bytes
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("public key must be PEM or base64 DER")
+		}
+		der = decoded
+	}
+
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+
+	result := &PublicKey{der: der, key: key, Fingerprint: Digest(der)}
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		result.Algorithm = Ed25519
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s, expected P-256", k.Curve.Params().Name)
+		}
+		result.Algorithm = ECDSAP256
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T, expected Ed25519 or ECDSA P-256", key)
+	}
+	return result, nil
+}
+
+// PEM возвращает ключ в PEM
+func (k *PublicKey) PEM() string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: k.der}))
+}
+
+// Verify проверяет подпись сообщения; при несовпадении возвращает ErrInvalidSignature
+func (k *PublicKey) Verify(message, sig []byte) error {
+	var ok bool
+	switch pub := k.key.(type) {
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(pub, message, sig)
+	case *ecdsa.PublicKey:
+		sum := sha256.Sum256(message)
+		ok = ecdsa.VerifyASN1(pub, sum[:], sig)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign подписывает сообщение закрытым ключом Ed25519 или ECDSA P-256
+func Sign(key crypto.Signer, message []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, message), nil
+	case *ecdsa.PrivateKey:
+		sum := sha256.Sum256(message)
+		return ecdsa.SignASN1(rand.Reader, k, sum[:])
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, expected Ed25519 or ECDSA P-256", key)
+	}
+}
+
+// ParsePrivateKey разбирает закрытый ключ в PEM (PKCS #8 или EC PRIVATE KEY)
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key must be PEM")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q, expected PRIVATE KEY", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s, expected P-256", k.Curve.Params().Name)
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T, expected Ed25519 or ECDSA P-256", key)
+	}
+}
+
+// GenerateKey создает пару ключей и возвращает закрытый ключ в PEM (PKCS #8) и открытый ключ в PEM
+func GenerateKey(algorithm Algorithm) (privatePEM, publicPEM []byte, err error) {
+	var priv, pub interface{}
+	switch algorithm {
+	case Ed25519:
+		pub, priv, err = ed25519.GenerateKey(rand.Reader)
+	case ECDSAP256:
+		var k *ecdsa.PrivateKey
+		k, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		priv, pub = k, k.Public()
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), nil
+}
+
+// DecodeSignature разбирает подпись в base64 (стандартном или URL-варианте, с дополнением или без)
+func DecodeSignature(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if sig, err := enc.DecodeString(s); err == nil && len(sig) > 0 {
+			return sig, nil
+		}
+	}
+	return nil, fmt.Errorf("signature must be base64-encoded")
+}
+
+// EncodeSignature кодирует подпись в base64
+func EncodeSignature(sig []byte) string {
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+// Digest возвращает SHA-256 данных в шестнадцатеричном виде
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,65 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSignVerify tests signing and verification with both supported algorithms
+func TestSignVerify(t *testing.T) {
+	message := []byte(`{"format":"esf-document/v1"}`)
+
+	for _, algorithm := range []Algorithm{Ed25519, ECDSAP256} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			privatePEM, publicPEM, err := GenerateKey(algorithm)
+			require.NoError(t, err)
+
+			priv, err := ParsePrivateKey(privatePEM)
+			require.NoError(t, err)
+			pub, err := ParsePublicKey(string(publicPEM))
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, pub.Algorithm)
+			assert.Len(t, pub.Fingerprint, 64)
+
+			sig, err := Sign(priv, message)
+			require.NoError(t, err)
+
+			decoded, err := DecodeSignature(EncodeSignature(sig))
+			require.NoError(t, err)
+			assert.NoError(t, pub.Verify(message, decoded))
+			assert.ErrorIs(t, pub.Verify([]byte(`{"format":"esf-document/v2"}`), decoded), ErrInvalidSignature)
+		})
+	}
+}
+
+// TestParsePublicKey tests accepted public key encodings
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+
+	fromDER, err := ParsePublicKey(base64.StdEncoding.EncodeToString(der))
+	require.NoError(t, err)
+	fromPEM, err := ParsePublicKey(fromDER.PEM())
+	require.NoError(t, err)
+	assert.Equal(t, fromDER.Fingerprint, fromPEM.Fingerprint)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	rsaDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	_, err = ParsePublicKey(string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaDER})))
+	assert.ErrorContains(t, err, "unsupported public key type")
+
+	_, err = ParsePublicKey("not a key")
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+// Package spell формирует суммы прописью для печатных форм документов.
+package spell
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// currencyUnits описывает склонение основной и дробной денежной единицы
+type currencyUnits struct {
+	major    [3]string
+	minor    [3]string
+	feminine bool
+}
+
+// currencies - валюты с поддержкой прописи, ключ - буквенный или цифровой код ISO 4217
+var currencies = map[string]currencyUnits{
+	"KGS": {major: [3]string{"сом", "сома", "сомов"}, minor: [3]string{"тыйын", "тыйына", "тыйынов"}},
+	"417": {major: [3]string{"сом", "сома", "сомов"}, minor: [3]string{"тыйын", "тыйына", "тыйынов"}},
+	"RUB": {major: [3]string{"рубль", "рубля", "рублей"}, minor: [3]string{"копейка", "копейки", "копеек"}},
+	"643": {major: [3]string{"рубль", "рубля", "рублей"}, minor: [3]string{"копейка", "копейки", "копеек"}},
+	"USD": {major: [3]string{"доллар США", "доллара США", "долларов США"}, minor: [3]string{"цент", "цента", "центов"}},
+	"840": {major: [3]string{"доллар США", "доллара США", "долларов США"}, minor: [3]string{"цент", "цента", "центов"}},
+	"EUR": {major: [3]string{"евро", "евро", "евро"}, minor: [3]string{"цент", "цента", "центов"}},
+	"978": {major: [3]string{"евро", "евро", "евро"}, minor: [3]string{"цент", "цента", "центов"}},
+	"KZT": {major: [3]string{"тенге", "тенге", "тенге"}, minor: [3]string{"тиын", "тиына", "тиынов"}},
+	"398": {major: [3]string{"тенге", "тенге", "тенге"}, minor: [3]string{"тиын", "тиына", "тиынов"}},
+}
+
+var (
+	unitsMasculine = []string{"", "один", "два", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	unitsFeminine  = []string{"", "одна", "две", "три", "четыре", "пять", "шесть", "семь", "восемь", "девять"}
+	teens          = []string{"десять", "одиннадцать", "двенадцать", "тринадцать", "четырнадцать", "пятнадцать",
+		"шестнадцать", "семнадцать", "восемнадцать", "девятнадцать"}
+	tens     = []string{"", "", "двадцать", "тридцать", "сорок", "пятьдесят", "шестьдесят", "семьдесят", "восемьдесят", "девяносто"}
+	hundreds = []string{"", "сто", "двести", "триста", "четыреста", "пятьсот", "шестьсот", "семьсот", "восемьсот", "девятьсот"}
+)
+
+// scales - разряды по три цифры, начиная с тысяч
+var scales = []struct {
+	forms    [3]string
+	feminine bool
+}{
+	{forms: [3]string{"тысяча", "тысячи", "тысяч"}, feminine: true},
+	{forms: [3]string{"миллион", "миллиона", "миллионов"}},
+	{forms: [3]string{"миллиард", "миллиарда", "миллиардов"}},
+	{forms: [3]string{"триллион", "триллиона", "триллионов"}},
+}
+
+// Amount возвращает денежную сумму прописью, например
+// "Одна тысяча сто двадцать сомов 50 тыйынов". Дробная часть выводится цифрами.
+// Для валют без описанного склонения после суммы выводится код валюты.
+func Amount(value money.Amount, currencyCode string) string {
+	cents := value.Abs().Minor()
+	major, minor := cents/100, cents%100
+
+	var b strings.Builder
+	if value < 0 && cents > 0 {
+		b.WriteString("минус ")
+	}
+
+	units, ok := currencies[strings.ToUpper(strings.TrimSpace(currencyCode))]
+	b.WriteString(Number(major, units.feminine))
+	if ok {
+		fmt.Fprintf(&b, " %s %02d %s", plural(major, units.major), minor, plural(minor, units.minor))
+	} else {
+		fmt.Fprintf(&b, " %s %02d", strings.TrimSpace(currencyCode), minor)
+	}
+
+	return capitalize(b.String())
+}
+
+// Number возвращает целое неотрицательное число прописью.
+// feminine задает женский род для единиц ("одна", "две").
+func Number(n int64, feminine bool) string {
+	if n <= 0 {
+		return "ноль"
+	}
+
+	var groups []int64
+	for n > 0 {
+		groups = append(groups, n%1000)
+		n /= 1000
+	}
+
+	var words []string
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if group == 0 {
+			continue
+		}
+		switch {
+		case i == 0:
+			words = append(words, triplet(group, feminine)...)
+		case i-1 < len(scales):
+			scale := scales[i-1]
+			words = append(words, triplet(group, scale.feminine)...)
+			words = append(words, plural(group, scale.forms))
+		default:
+			// Числа больше триллионов в документах не встречаются
+			words = append(words, fmt.Sprint(group))
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// triplet возвращает прописью число от 1 до 999
+func triplet(n int64, feminine bool) []string {
+	var words []string
+	if h := n / 100; h > 0 {
+		words = append(words, hundreds[h])
+	}
+
+	rest := n % 100
+	switch {
+	case rest >= 10 && rest < 20:
+		words = append(words, teens[rest-10])
+	default:
+		if t := rest / 10; t > 0 {
+			words = append(words, tens[t])
+		}
+		if u := rest % 10; u > 0 {
+			if feminine {
+				words = append(words, unitsFeminine[u])
+			} else {
+				words = append(words, unitsMasculine[u])
+			}
+		}
+	}
+
+	return words
+}
+
+// plural выбирает форму слова для числа n: [1, 2-4, 5-20]
+func plural(n int64, forms [3]string) string {
+	n %= 100
+	switch {
+	case n >= 11 && n <= 14:
+		return forms[2]
+	case n%10 == 1:
+		return forms[0]
+	case n%10 >= 2 && n%10 <= 4:
+		return forms[1]
+	default:
+		return forms[2]
+	}
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,54 @@
+package spell
+
+import (
+	"testing"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		n        int64
+		feminine bool
+		want     string
+	}{
+		{0, false, "ноль"},
+		{1, false, "один"},
+		{2, true, "две"},
+		{11, false, "одиннадцать"},
+		{21, false, "двадцать один"},
+		{115, false, "сто пятнадцать"},
+		{1000, false, "одна тысяча"},
+		{2001, false, "две тысячи один"},
+		{5000, false, "пять тысяч"},
+		{12345, false, "двенадцать тысяч триста сорок пять"},
+		{1000000, false, "один миллион"},
+		{3202022, false, "три миллиона двести две тысячи двадцать два"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Number(tt.n, tt.feminine), "n=%d", tt.n)
+	}
+}
+
+func TestAmount(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     string
+	}{
+		{"1120", "KGS", "Одна тысяча сто двадцать сомов 00 тыйынов"},
+		{"1.5", "417", "Один сом 50 тыйынов"},
+		{"22.01", "KGS", "Двадцать два сома 01 тыйын"},
+		{"0.03", "RUB", "Ноль рублей 03 копейки"},
+		{"1001.21", "USD", "Одна тысяча один доллар США 21 цент"},
+		{"-224", "KGS", "Минус двести двадцать четыре сома 00 тыйынов"},
+		{"14.99", "EUR", "Четырнадцать евро 99 центов"},
+		{"3", "CNY", "Три CNY 00"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Amount(money.MustParse(tt.value), tt.currency), "value=%s currency=%s", tt.value, tt.currency)
+	}
+}
@@ -0,0 +1,176 @@
+// Package tabular читает табличные файлы (CSV, XLSX) в единое представление:
+// строка заголовков и строки данных с номерами строк исходного файла.
+package tabular
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Поддерживаемые форматы файлов
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Row строка данных. Number - номер строки в файле (заголовок - строка 1).
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// Table прочитанная таблица
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Options параметры чтения файла
+type Options struct {
+	// Разделитель CSV; если не задан, определяется по строке заголовков (',' или ';')
+	Delimiter rune
+	// Лист XLSX; если не задан, используется первый лист
+	Sheet string
+}
+
+// Read читает файл в формате format (csv или xlsx)
+func Read(data []byte, format string, opts Options) (*Table, error) {
+	var (
+		records []Row
+		err     error
+	)
+
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		records, err = readCSV(data, opts.Delimiter)
+	case FormatXLSX:
+		records, err = readXLSX(data, opts.Sheet)
+	default:
+		return nil, fmt.Errorf("unsupported file format %q, expected csv or xlsx", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("file has no header row")
+	}
+
+	table := &Table{Header: make([]string, len(records[0].Cells))}
+	for i, h := range records[0].Cells {
+		table.Header[i] = strings.TrimSpace(h)
+	}
+
+	for _, record := range records[1:] {
+		if !isBlank(record.Cells) {
+			table.Rows = append(table.Rows, record)
+		}
+	}
+
+	return table, nil
+}
+
+// Column возвращает индекс колонки по заголовку (без учета регистра) или -1
+func (t *Table) Column(name string) int {
+	for i, h := range t.Header {
+		if strings.EqualFold(h, strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Value возвращает значение ячейки колонки col; отсутствующие ячейки пустые
+func (r Row) Value(col int) string {
+	if col < 0 || col >= len(r.Cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.Cells[col])
+}
+
+func readCSV(data []byte, delimiter rune) ([]Row, error) {
+	// Excel сохраняет CSV в UTF-8 с BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, errors.New("csv file must be UTF-8 encoded")
+	}
+
+	if delimiter == 0 {
+		delimiter = detectDelimiter(data)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	var records []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed csv: %w", err)
+		}
+		// Пустые строки CSV пропускаются читателем, поэтому номер берется из позиции поля
+		line, _ := reader.FieldPos(0)
+		records = append(records, Row{Number: line, Cells: record})
+	}
+}
+
+// detectDelimiter выбирает ',' или ';' по строке заголовков
+// (Excel с русской локалью сохраняет CSV с ';')
+func detectDelimiter(data []byte) rune {
+	header := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		header = data[:i]
+	}
+	if bytes.Count(header, []byte{';'}) > bytes.Count(header, []byte{','}) {
+		return ';'
+	}
+	return ','
+}
+
+func readXLSX(data []byte, sheet string) ([]Row, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("malformed xlsx: %w", err)
+	}
+	defer file.Close()
+
+	if sheet == "" {
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("xlsx file has no sheets")
+		}
+		sheet = sheets[0]
+	}
+
+	// Сырые значения: даты приходят серийными номерами Excel, числа - без форматирования
+	rows, err := file.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, fmt.Errorf("reading sheet %q: %w", sheet, err)
+	}
+
+	records := make([]Row, len(rows))
+	for i, cells := range rows {
+		records[i] = Row{Number: i + 1, Cells: cells}
+	}
+	return records, nil
+}
+
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package tabular
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRead_CSVDetectsDelimiter(t *testing.T) {
+	data := []byte("\xef\xbb\xbfДокумент;ИНН;Количество\n1;01234567890123;\"1,5\"\n\n2;11111111111111\n")
+
+	table, err := Read(data, "CSV", Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Документ", "ИНН", "Количество"}, table.Header)
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, 2, table.Rows[0].Number)
+	assert.Equal(t, "1,5", table.Rows[0].Value(table.Column("количество")))
+	assert.Equal(t, 4, table.Rows[1].Number)
+	assert.Equal(t, "", table.Rows[1].Value(2))
+	assert.Equal(t, -1, table.Column("missing"))
+}
+
+func TestRead_XLSX(t *testing.T) {
+	f := excelize.NewFile()
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]interface{}{"doc", "qty"}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]interface{}{"A-1", 2.5}))
+	var buf bytes.Buffer
+	require.NoError(t, f.Write(&buf))
+
+	table, err := Read(buf.Bytes(), FormatXLSX, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, table.Rows, 1)
+	assert.Equal(t, "A-1", table.Rows[0].Value(0))
+	assert.Equal(t, "2.5", table.Rows[0].Value(1))
+}
+
+func TestRead_Errors(t *testing.T) {
+	_, err := Read([]byte("a,b"), "ods", Options{})
+	assert.Error(t, err)
+
+	_, err = Read([]byte(""), FormatCSV, Options{})
+	assert.Error(t, err)
+
+	_, err = Read([]byte("not a zip"), FormatXLSX, Options{})
+	assert.Error(t, err)
+}
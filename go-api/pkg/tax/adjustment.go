@@ -0,0 +1,45 @@
+package tax
+
+// CalculateAdjustment рассчитывает разницу сумм позиции до и после корректировки.
+// В отличие от CalculateDocument допускает нулевое количество (полный возврат позиции).
+// Ошибки позиции возвращаются с Line = 0.
+func (c *Calculator) CalculateAdjustment(vatCode string, priceWithoutTaxes bool, before, after LineInput) (LineResult, error) {
+	var issues []Issue
+
+	vat, err := c.rates.VAT(vatCode)
+	if err != nil {
+		issues = append(issues, Issue{Line: -1, Field: "taxRateVATCode", Message: err.Error()})
+	}
+
+	st, err := c.rates.SalesTax(after.SalesTaxCode)
+	if err != nil {
+		issues = append(issues, Issue{Line: 0, Field: "salesTaxCode", Message: err.Error()})
+	}
+
+	if after.Quantity < 0 {
+		issues = append(issues, Issue{Line: 0, Field: "quantity", Message: "quantity must not be negative after correction"})
+	}
+	if after.Price < 0 {
+		issues = append(issues, Issue{Line: 0, Field: "price", Message: "price must not be negative after correction"})
+	}
+
+	if len(issues) > 0 {
+		return LineResult{}, &CalculationError{Issues: issues}
+	}
+
+	b, err := calculateLine(before.Quantity, before.Price, vat.Percent, st.Percent, priceWithoutTaxes)
+	if err != nil {
+		return LineResult{}, &CalculationError{Issues: []Issue{{Line: 0, Field: "price", Message: "line amount is out of range"}}}
+	}
+	a, err := calculateLine(after.Quantity, after.Price, vat.Percent, st.Percent, priceWithoutTaxes)
+	if err != nil {
+		return LineResult{}, &CalculationError{Issues: []Issue{{Line: 0, Field: "price", Message: "line amount is out of range"}}}
+	}
+
+	return LineResult{
+		AmountWithoutTaxes: a.AmountWithoutTaxes.Sub(b.AmountWithoutTaxes),
+		VatAmount:          a.VatAmount.Sub(b.VatAmount),
+		SalesTaxAmount:     a.SalesTaxAmount.Sub(b.SalesTaxAmount),
+		TotalAmount:        a.TotalAmount.Sub(b.TotalAmount),
+	}, nil
+}
@@ -0,0 +1,157 @@
+package tax
+
+import (
+	"fmt"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+)
+
+// LineInput входные данные позиции документа для расчета налогов
+type LineInput struct {
+	Quantity     float64
+	Price        money.Amount
+	SalesTaxCode string
+}
+
+// LineResult рассчитанные суммы по позиции
+type LineResult struct {
+	AmountWithoutTaxes money.Amount `json:"amountWithoutTaxes"`
+	VatAmount          money.Amount `json:"vatAmount"`
+	SalesTaxAmount     money.Amount `json:"salesTaxAmount"`
+	TotalAmount        money.Amount `json:"totalAmount"`
+}
+
+// DocumentInput входные данные документа для расчета налогов
+type DocumentInput struct {
+	VATCode           string
+	PriceWithoutTaxes bool
+	Lines             []LineInput
+}
+
+// DocumentResult рассчитанные суммы по документу и по каждой позиции
+type DocumentResult struct {
+	Lines              []LineResult `json:"lines"`
+	AmountWithoutTaxes money.Amount `json:"amountWithoutTaxes"`
+	VatAmount          money.Amount `json:"vatAmount"`
+	SalesTaxAmount     money.Amount `json:"salesTaxAmount"`
+	TotalAmount        money.Amount `json:"totalAmount"`
+}
+
+// Issue описывает ошибку во входных данных.
+// Line равен -1 для полей уровня документа.
+type Issue struct {
+	Line    int
+	Field   string
+	Message string
+}
+
+// CalculationError содержит все ошибки входных данных, найденные при расчете
+type CalculationError struct {
+	Issues []Issue
+}
+
+// Error реализует интерфейс error
+func (e *CalculationError) Error() string {
+	if len(e.Issues) == 0 {
+		return "tax calculation failed"
+	}
+	return fmt.Sprintf("tax calculation failed: %s (%d issue(s))", e.Issues[0].Message, len(e.Issues))
+}
+
+// Calculator рассчитывает НДС, налог с продаж и итоговые суммы документа
+type Calculator struct {
+	rates *RateTable
+}
+
+// NewCalculator создает калькулятор с указанной таблицей ставок
+func NewCalculator(rates *RateTable) *Calculator {
+	if rates == nil {
+		rates = DefaultRateTable()
+	}
+	return &Calculator{rates: rates}
+}
+
+// Rates возвращает таблицу ставок калькулятора
+func (c *Calculator) Rates() *RateTable {
+	return c.rates
+}
+
+// CalculateDocument рассчитывает суммы по всем позициям и итоги документа.
+// Суммы по позициям округляются до копеек, итоги документа равны сумме округленных позиций.
+func (c *Calculator) CalculateDocument(in DocumentInput) (*DocumentResult, error) {
+	var issues []Issue
+
+	vat, err := c.rates.VAT(in.VATCode)
+	if err != nil {
+		issues = append(issues, Issue{Line: -1, Field: "taxRateVATCode", Message: err.Error()})
+	}
+
+	result := &DocumentResult{Lines: make([]LineResult, len(in.Lines))}
+
+	for i, line := range in.Lines {
+		if line.Quantity <= 0 {
+			issues = append(issues, Issue{Line: i, Field: "quantity", Message: "quantity must be greater than zero"})
+		}
+		if line.Price < 0 {
+			issues = append(issues, Issue{Line: i, Field: "price", Message: "price must not be negative"})
+		}
+
+		st, err := c.rates.SalesTax(line.SalesTaxCode)
+		if err != nil {
+			issues = append(issues, Issue{Line: i, Field: "salesTaxCode", Message: err.Error()})
+			continue
+		}
+
+		lr, err := calculateLine(line.Quantity, line.Price, vat.Percent, st.Percent, in.PriceWithoutTaxes)
+		if err != nil {
+			issues = append(issues, Issue{Line: i, Field: "price", Message: "line amount is out of range"})
+			continue
+		}
+		result.Lines[i] = lr
+
+		result.AmountWithoutTaxes += lr.AmountWithoutTaxes
+		result.VatAmount += lr.VatAmount
+		result.SalesTaxAmount += lr.SalesTaxAmount
+		result.TotalAmount += lr.TotalAmount
+	}
+
+	if len(issues) > 0 {
+		return nil, &CalculationError{Issues: issues}
+	}
+
+	return result, nil
+}
+
+// calculateLine рассчитывает суммы позиции: без налогов, НДС, налог с продаж, итого.
+// Если цена указана с налогами, налоги выделяются из суммы, а база получается вычитанием,
+// поэтому итог позиции всегда равен сумме составляющих.
+// Возвращает money.ErrRange, если сумма позиции не помещается в decimal(15,2).
+func calculateLine(quantity float64, price money.Amount, vatPercent, stPercent float64, priceWithoutTaxes bool) (LineResult, error) {
+	amount, err := price.Mul(quantity)
+	if err != nil {
+		return LineResult{}, err
+	}
+
+	if priceWithoutTaxes {
+		r := LineResult{AmountWithoutTaxes: amount}
+		if r.VatAmount, err = amount.Percent(vatPercent); err != nil {
+			return LineResult{}, err
+		}
+		if r.SalesTaxAmount, err = amount.Percent(stPercent); err != nil {
+			return LineResult{}, err
+		}
+		r.TotalAmount = money.Sum(r.AmountWithoutTaxes, r.VatAmount, r.SalesTaxAmount)
+		return r, nil
+	}
+
+	divisor := 100 + vatPercent + stPercent
+	r := LineResult{TotalAmount: amount}
+	if r.VatAmount, err = amount.Share(vatPercent, divisor); err != nil {
+		return LineResult{}, err
+	}
+	if r.SalesTaxAmount, err = amount.Share(stPercent, divisor); err != nil {
+		return LineResult{}, err
+	}
+	r.AmountWithoutTaxes = amount.Sub(r.VatAmount).Sub(r.SalesTaxAmount)
+	return r, nil
+}
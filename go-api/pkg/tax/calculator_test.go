@@ -0,0 +1,193 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/rusgainew/tunduck-app/pkg/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCalculateDocument tests line and document totals
+func TestCalculateDocument(t *testing.T) {
+	calc := NewCalculator(DefaultRateTable())
+
+	t.Run("Price without taxes adds VAT on top", func(t *testing.T) {
+		result, err := calc.CalculateDocument(DocumentInput{
+			VATCode:           "1",
+			PriceWithoutTaxes: true,
+			Lines: []LineInput{
+				{Quantity: 3, Price: money.MustParse("33.33"), SalesTaxCode: "001"},
+				{Quantity: 1.5, Price: money.MustParse("10"), SalesTaxCode: "001"},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, money.MustParse("99.99"), result.Lines[0].AmountWithoutTaxes)
+		assert.Equal(t, money.MustParse("12"), result.Lines[0].VatAmount)
+		assert.Equal(t, money.MustParse("111.99"), result.Lines[0].TotalAmount)
+
+		assert.Equal(t, money.MustParse("15"), result.Lines[1].AmountWithoutTaxes)
+		assert.Equal(t, money.MustParse("1.8"), result.Lines[1].VatAmount)
+		assert.Equal(t, money.MustParse("16.8"), result.Lines[1].TotalAmount)
+
+		assert.Equal(t, money.MustParse("114.99"), result.AmountWithoutTaxes)
+		assert.Equal(t, money.MustParse("13.8"), result.VatAmount)
+		assert.Equal(t, money.MustParse("128.79"), result.TotalAmount)
+	})
+
+	t.Run("Price with taxes extracts VAT and reconciles", func(t *testing.T) {
+		result, err := calc.CalculateDocument(DocumentInput{
+			VATCode: "1",
+			Lines:   []LineInput{{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "001"}},
+		})
+		require.NoError(t, err)
+
+		line := result.Lines[0]
+		assert.Equal(t, money.MustParse("100"), line.TotalAmount)
+		assert.Equal(t, money.MustParse("10.71"), line.VatAmount)
+		assert.Equal(t, money.MustParse("89.29"), line.AmountWithoutTaxes)
+		assert.Equal(t, line.TotalAmount, money.Sum(line.AmountWithoutTaxes, line.VatAmount, line.SalesTaxAmount))
+	})
+
+	t.Run("Sales tax is applied by item code", func(t *testing.T) {
+		rates := NewRateTable(
+			[]Rate{{Code: "1", Percent: 12}},
+			[]Rate{{Code: "retail", Percent: 2}},
+			nil,
+		)
+		result, err := NewCalculator(rates).CalculateDocument(DocumentInput{
+			VATCode:           "1",
+			PriceWithoutTaxes: true,
+			Lines:             []LineInput{{Quantity: 2, Price: money.MustParse("50"), SalesTaxCode: "retail"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, money.MustParse("12"), result.VatAmount)
+		assert.Equal(t, money.MustParse("2"), result.SalesTaxAmount)
+		assert.Equal(t, money.MustParse("114"), result.TotalAmount)
+	})
+
+	t.Run("Default sales tax rates depend on activity and payment form", func(t *testing.T) {
+		result, err := calc.CalculateDocument(DocumentInput{
+			VATCode:           "1",
+			PriceWithoutTaxes: true,
+			Lines: []LineInput{
+				{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "002"},
+				{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "004"},
+				{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "005"},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, money.MustParse("1"), result.Lines[0].SalesTaxAmount)
+		assert.Equal(t, money.MustParse("2"), result.Lines[1].SalesTaxAmount)
+		assert.Equal(t, money.MustParse("5"), result.Lines[2].SalesTaxAmount)
+		assert.Equal(t, money.MustParse("8"), result.SalesTaxAmount)
+		assert.Equal(t, money.MustParse("344"), result.TotalAmount)
+
+		result, err = calc.CalculateDocument(DocumentInput{
+			VATCode: "1",
+			Lines:   []LineInput{{Quantity: 1, Price: money.MustParse("114"), SalesTaxCode: "004"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("12"), result.VatAmount)
+		assert.Equal(t, money.MustParse("2"), result.SalesTaxAmount)
+		assert.Equal(t, money.MustParse("100"), result.AmountWithoutTaxes)
+	})
+
+	t.Run("Unknown sales tax code is rejected", func(t *testing.T) {
+		_, err := calc.CalculateDocument(DocumentInput{
+			VATCode: "1",
+			Lines:   []LineInput{{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "999"}},
+		})
+		calcErr, ok := err.(*CalculationError)
+		require.True(t, ok)
+		require.Len(t, calcErr.Issues, 1)
+		assert.Equal(t, "salesTaxCode", calcErr.Issues[0].Field)
+	})
+
+	t.Run("Line amount out of range is rejected", func(t *testing.T) {
+		_, err := calc.CalculateDocument(DocumentInput{
+			VATCode: "1",
+			Lines:   []LineInput{{Quantity: 1000, Price: money.MustParse("9999999999999"), SalesTaxCode: "001"}},
+		})
+		calcErr, ok := err.(*CalculationError)
+		require.True(t, ok)
+		require.Len(t, calcErr.Issues, 1)
+		assert.Equal(t, "price", calcErr.Issues[0].Field)
+	})
+
+	t.Run("Invalid input is reported per field", func(t *testing.T) {
+		_, err := calc.CalculateDocument(DocumentInput{
+			VATCode: "unknown",
+			Lines:   []LineInput{{Quantity: 0, Price: money.MustParse("-1"), SalesTaxCode: "001"}},
+		})
+		require.Error(t, err)
+
+		calcErr, ok := err.(*CalculationError)
+		require.True(t, ok)
+		assert.Len(t, calcErr.Issues, 3)
+		assert.Equal(t, -1, calcErr.Issues[0].Line)
+		assert.Equal(t, "taxRateVATCode", calcErr.Issues[0].Field)
+		assert.Equal(t, "quantity", calcErr.Issues[1].Field)
+		assert.Equal(t, "price", calcErr.Issues[2].Field)
+	})
+}
+
+// TestCalculateDocumentIsExact tests that totals do not drift on amounts that float64 cannot represent
+func TestCalculateDocumentIsExact(t *testing.T) {
+	lines := make([]LineInput, 10)
+	for i := range lines {
+		lines[i] = LineInput{Quantity: 1, Price: money.MustParse("0.10"), SalesTaxCode: "001"}
+	}
+	result, err := NewCalculator(DefaultRateTable()).CalculateDocument(DocumentInput{VATCode: "2", PriceWithoutTaxes: true, Lines: lines})
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("1.00"), result.TotalAmount)
+
+	result, err = NewCalculator(DefaultRateTable()).CalculateDocument(DocumentInput{
+		VATCode:           "1",
+		PriceWithoutTaxes: true,
+		Lines:             []LineInput{{Quantity: 0.125, Price: money.MustParse("8.40"), SalesTaxCode: "001"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("1.05"), result.AmountWithoutTaxes)
+	assert.Equal(t, money.MustParse("0.13"), result.VatAmount)
+}
+
+// TestCalculateAdjustment tests line deltas for correction invoices
+func TestCalculateAdjustment(t *testing.T) {
+	calc := NewCalculator(DefaultRateTable())
+
+	t.Run("Quantity decrease produces negative delta", func(t *testing.T) {
+		delta, err := calc.CalculateAdjustment("1", true,
+			LineInput{Quantity: 10, Price: money.MustParse("100"), SalesTaxCode: "001"},
+			LineInput{Quantity: 8, Price: money.MustParse("100"), SalesTaxCode: "001"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("-200"), delta.AmountWithoutTaxes)
+		assert.Equal(t, money.MustParse("-24"), delta.VatAmount)
+		assert.Equal(t, money.MustParse("-224"), delta.TotalAmount)
+	})
+
+	t.Run("Full return is allowed", func(t *testing.T) {
+		delta, err := calc.CalculateAdjustment("1", false,
+			LineInput{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "001"},
+			LineInput{Quantity: 0, Price: money.MustParse("100"), SalesTaxCode: "001"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("-100"), delta.TotalAmount)
+		assert.Equal(t, money.MustParse("-10.71"), delta.VatAmount)
+	})
+
+	t.Run("Negative result is rejected", func(t *testing.T) {
+		_, err := calc.CalculateAdjustment("1", true,
+			LineInput{Quantity: 1, Price: money.MustParse("100"), SalesTaxCode: "001"},
+			LineInput{Quantity: -1, Price: money.MustParse("100"), SalesTaxCode: "001"},
+		)
+		require.Error(t, err)
+		calcErr, ok := err.(*CalculationError)
+		require.True(t, ok)
+		assert.Equal(t, "quantity", calcErr.Issues[0].Field)
+	})
+}
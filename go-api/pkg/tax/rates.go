@@ -0,0 +1,76 @@
+package tax
+
+import "fmt"
+
+// Rate описывает налоговую ставку в процентах
+type Rate struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Percent float64 `json:"percent"`
+}
+
+// RateTable содержит ставки НДС и налога с продаж по их кодам
+type RateTable struct {
+	vat       map[string]Rate
+	salesTax  map[string]Rate
+	defaultST *Rate
+}
+
+// NewRateTable создает таблицу ставок.
+// Если задан defaultSalesTax, он применяется к кодам товаров, отсутствующим в таблице налога с продаж.
+func NewRateTable(vat, salesTax []Rate, defaultSalesTax *Rate) *RateTable {
+	t := &RateTable{
+		vat:       make(map[string]Rate, len(vat)),
+		salesTax:  make(map[string]Rate, len(salesTax)),
+		defaultST: defaultSalesTax,
+	}
+	for _, r := range vat {
+		t.vat[r.Code] = r
+	}
+	for _, r := range salesTax {
+		t.salesTax[r.Code] = r
+	}
+	return t
+}
+
+// DefaultRateTable возвращает ставки, действующие по умолчанию:
+// НДС 12% / 0% / без НДС и ставки налога с продаж для плательщиков НДС
+// по виду деятельности и форме расчетов. Неизвестный код налога с продаж является ошибкой.
+func DefaultRateTable() *RateTable {
+	return NewRateTable(
+		[]Rate{
+			{Code: "1", Name: "НДС 12%", Percent: 12},
+			{Code: "2", Name: "НДС 0%", Percent: 0},
+			{Code: "3", Name: "Без НДС", Percent: 0},
+		},
+		[]Rate{
+			{Code: "001", Name: "Торговая деятельность, безналичный расчет", Percent: 0},
+			{Code: "002", Name: "Торговая деятельность, наличный расчет", Percent: 1},
+			{Code: "003", Name: "Неторговая деятельность, безналичный расчет", Percent: 1},
+			{Code: "004", Name: "Неторговая деятельность, наличный расчет", Percent: 2},
+			{Code: "005", Name: "Услуги мобильной связи", Percent: 5},
+			{Code: "006", Name: "Освобождено от налога с продаж", Percent: 0},
+		},
+		nil,
+	)
+}
+
+// VAT возвращает ставку НДС по коду
+func (t *RateTable) VAT(code string) (Rate, error) {
+	r, ok := t.vat[code]
+	if !ok {
+		return Rate{}, fmt.Errorf("unknown VAT rate code %q", code)
+	}
+	return r, nil
+}
+
+// SalesTax возвращает ставку налога с продаж по коду товара или услуги
+func (t *RateTable) SalesTax(code string) (Rate, error) {
+	if r, ok := t.salesTax[code]; ok {
+		return r, nil
+	}
+	if t.defaultST != nil {
+		return *t.defaultST, nil
+	}
+	return Rate{}, fmt.Errorf("unknown sales tax code %q", code)
+}
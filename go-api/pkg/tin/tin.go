@@ -0,0 +1,80 @@
+// Package tin проверяет ИНН налогоплательщиков Кыргызской Республики.
+//
+// ИНН состоит из 14 цифр: первая цифра - вид налогоплательщика (0 - юридическое
+// лицо, 1 и 2 - физическое лицо), цифры со 2 по 9 - дата регистрации или рождения
+// в формате ДДММГГГГ, остальные 5 - порядковый номер. Отдельной контрольной цифры
+// в ИНН нет, поэтому контрольной частью считается встроенная дата: она должна быть
+// существующей календарной датой не позже текущего дня.
+package tin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Length длина ИНН
+const Length = 14
+
+// minYear самый ранний допустимый год даты в ИНН
+const minYear = 1900
+
+var (
+	ErrLength = errors.New("TIN must contain 14 digits")
+	ErrDigits = errors.New("TIN must contain only digits")
+	ErrKind   = errors.New("TIN must start with 0 (legal entity), 1 or 2 (individual)")
+	ErrDate   = errors.New("TIN contains an invalid registration or birth date")
+)
+
+// Kind вид налогоплательщика по первой цифре ИНН
+type Kind int
+
+const (
+	KindLegalEntity Kind = iota
+	KindIndividual
+)
+
+// Validate проверяет формат и контрольную часть ИНН
+func Validate(tin string) error {
+	_, err := Parse(tin)
+	return err
+}
+
+// Parse проверяет ИНН и возвращает вид налогоплательщика
+func Parse(tin string) (Kind, error) {
+	if len(tin) != Length {
+		return 0, ErrLength
+	}
+	for i := 0; i < len(tin); i++ {
+		if tin[i] < '0' || tin[i] > '9' {
+			return 0, ErrDigits
+		}
+	}
+
+	var kind Kind
+	switch tin[0] {
+	case '0':
+		kind = KindLegalEntity
+	case '1', '2':
+		kind = KindIndividual
+	default:
+		return 0, ErrKind
+	}
+
+	if err := checkDate(tin[1:9]); err != nil {
+		return 0, err
+	}
+	return kind, nil
+}
+
+// checkDate проверяет дату в формате ДДММГГГГ
+func checkDate(value string) error {
+	date, err := time.Parse("02012006", value)
+	if err != nil {
+		return ErrDate
+	}
+	if date.Year() < minYear || date.After(time.Now()) {
+		return fmt.Errorf("%w: year %d is out of range", ErrDate, date.Year())
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package tin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParse tests TIN format and embedded date validation
+func TestParse(t *testing.T) {
+	kind, err := Parse("01503201910012")
+	require.NoError(t, err)
+	assert.Equal(t, KindLegalEntity, kind)
+
+	kind, err = Parse("22901198500345")
+	require.NoError(t, err)
+	assert.Equal(t, KindIndividual, kind)
+
+	tests := []struct {
+		name string
+		tin  string
+		err  error
+	}{
+		{"too short", "0150320191001", ErrLength},
+		{"letters", "0150320191001A", ErrDigits},
+		{"unknown kind", "31503201910012", ErrKind},
+		{"invalid month", "01513201910012", ErrDate},
+		{"invalid day", "03102201910012", ErrDate},
+		{"future date", "01503299910012", ErrDate},
+		{"too old", "01503180010012", ErrDate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, Validate(tt.tin), tt.err)
+		})
+	}
+}